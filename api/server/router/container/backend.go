@@ -43,6 +43,7 @@ type stateBackend interface {
 	ContainerStop(name string, seconds *int) error
 	ContainerUnpause(name string) error
 	ContainerUpdate(name string, hostConfig *container.HostConfig) (container.ContainerUpdateOKBody, error)
+	ContainerUpdateImage(name string, config *types.ContainerUpdateImageConfig) (container.ContainerCreateCreatedBody, error)
 	ContainerWait(ctx context.Context, name string, condition containerpkg.WaitCondition) (<-chan containerpkg.StateStatus, error)
 }
 
@@ -51,7 +52,9 @@ type monitorBackend interface {
 	ContainerChanges(name string) ([]archive.Change, error)
 	ContainerInspect(name string, size bool, version string) (interface{}, error)
 	ContainerLogs(ctx context.Context, name string, config *types.ContainerLogsOptions) (msgs <-chan *backend.LogMessage, tty bool, err error)
+	ContainersLogs(ctx context.Context, filter filters.Args, config *types.ContainerLogsOptions) (<-chan *types.ContainerLogMessage, error)
 	ContainerStats(ctx context.Context, name string, config *backend.ContainerStatsConfig) error
+	ContainerStatsHistory(name string, config *backend.ContainerStatsHistoryConfig) (*types.StatsHistory, error)
 	ContainerTop(name string, psArgs string) (*container.ContainerTopOKBody, error)
 
 	Containers(config *types.ContainerListOptions) ([]*types.Container, error)
@@ -65,6 +68,8 @@ type attachBackend interface {
 // systemBackend includes functions to implement to provide system wide containers functionality
 type systemBackend interface {
 	ContainersPrune(ctx context.Context, pruneFilters filters.Args) (*types.ContainersPruneReport, error)
+	ContainersNetworkTraffic() ([]types.NetworkTraffic, error)
+	ContainersNetworkTrafficReset(key string) error
 }
 
 type commitBackend interface {
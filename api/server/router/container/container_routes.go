@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/containerd/containerd/platforms"
 	"github.com/docker/docker/api/server/httpstatus"
@@ -16,6 +17,7 @@ import (
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	timetypes "github.com/docker/docker/api/types/time"
 	"github.com/docker/docker/api/types/versions"
 	containerpkg "github.com/docker/docker/container"
 	"github.com/docker/docker/errdefs"
@@ -123,6 +125,39 @@ func (s *containerRouter) getContainersStats(ctx context.Context, w http.Respons
 	return s.backend.ContainerStats(ctx, vars["name"], config)
 }
 
+func (s *containerRouter) getContainersStatsHistory(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	config := &backend.ContainerStatsHistoryConfig{}
+	for key, dst := range map[string]*time.Time{"since": &config.Since, "until": &config.Until} {
+		t, tNano, err := timetypes.ParseTimestamps(r.Form.Get(key), -1)
+		if err != nil {
+			return errdefs.InvalidParameter(errors.Wrapf(err, "invalid %s", key))
+		}
+		if t != -1 {
+			*dst = time.Unix(t, tNano)
+		}
+	}
+	if !config.Until.IsZero() && config.Until.Before(config.Since) {
+		return errdefs.InvalidParameter(errors.New("since cannot be after until"))
+	}
+	if v := r.Form.Get("resolution"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return errdefs.InvalidParameter(errors.Errorf("invalid resolution %q", v))
+		}
+		config.Resolution = d
+	}
+
+	history, err := s.backend.ContainerStatsHistory(vars["name"], config)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, history)
+}
+
 func (s *containerRouter) getContainersLogs(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -133,33 +168,92 @@ func (s *containerRouter) getContainersLogs(ctx context.Context, w http.Response
 	// daemon is going to stream. By sending this initial HTTP 200 we can't report
 	// any error after the stream starts (i.e. container not found, wrong parameters)
 	// with the appropriate status code.
-	stdout, stderr := httputils.BoolValue(r, "stdout"), httputils.BoolValue(r, "stderr")
-	if !(stdout || stderr) {
+	logsConfig, err := logsOptions(r)
+	if err != nil {
+		return err
+	}
+	if !(logsConfig.ShowStdout || logsConfig.ShowStderr) {
 		return errdefs.InvalidParameter(errors.New("Bad parameters: you must choose at least one stream"))
 	}
 
 	containerName := vars["name"]
+
+	msgs, tty, err := s.backend.ContainerLogs(ctx, containerName, logsConfig)
+	if err != nil {
+		return err
+	}
+
+	// if has a tty, we're not muxing streams. if it doesn't, we are. simple.
+	// this is the point of no return for writing a response. once we call
+	// WriteLogStream, the response has been started and errors will be
+	// returned in band by WriteLogStream
+	httputils.WriteLogStream(ctx, w, msgs, logsConfig, !tty)
+	return nil
+}
+
+// logsOptions returns the options of the logs requested by r.
+func logsOptions(r *http.Request) (*types.ContainerLogsOptions, error) {
 	logsConfig := &types.ContainerLogsOptions{
 		Follow:     httputils.BoolValue(r, "follow"),
 		Timestamps: httputils.BoolValue(r, "timestamps"),
 		Since:      r.Form.Get("since"),
 		Until:      r.Form.Get("until"),
 		Tail:       r.Form.Get("tail"),
-		ShowStdout: stdout,
-		ShowStderr: stderr,
+		ShowStdout: httputils.BoolValue(r, "stdout"),
+		ShowStderr: httputils.BoolValue(r, "stderr"),
 		Details:    httputils.BoolValue(r, "details"),
+		Grep:       r.Form.Get("grep"),
+		Contains:   r.Form.Get("contains"),
+	}
+	if attrs := r.Form.Get("attrs"); attrs != "" {
+		if err := json.Unmarshal([]byte(attrs), &logsConfig.Attrs); err != nil {
+			return nil, errdefs.InvalidParameter(errors.Wrap(err, "invalid attrs"))
+		}
 	}
+	if maxBytes := r.Form.Get("maxbytes"); maxBytes != "" {
+		n, err := strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil || n < 0 {
+			return nil, errdefs.InvalidParameter(errors.Errorf("invalid maxbytes: %s", maxBytes))
+		}
+		logsConfig.MaxBytes = n
+	}
+	return logsConfig, nil
+}
 
-	msgs, tty, err := s.backend.ContainerLogs(ctx, containerName, logsConfig)
+func (s *containerRouter) getContainersMergedLogs(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	filter, err := filters.FromJSON(r.Form.Get("filters"))
 	if err != nil {
 		return err
 	}
+	logsConfig, err := logsOptions(r)
+	if err != nil {
+		return err
+	}
+	// Both streams are returned unless one is selected.
+	if !(logsConfig.ShowStdout || logsConfig.ShowStderr) {
+		logsConfig.ShowStdout, logsConfig.ShowStderr = true, true
+	}
 
-	// if has a tty, we're not muxing streams. if it doesn't, we are. simple.
-	// this is the point of no return for writing a response. once we call
-	// WriteLogStream, the response has been started and errors will be
-	// returned in band by WriteLogStream
-	httputils.WriteLogStream(ctx, w, msgs, logsConfig, !tty)
+	msgs, err := s.backend.ContainersLogs(ctx, filter, logsConfig)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	output := ioutils.NewWriteFlusher(w)
+	defer output.Close()
+	output.Flush()
+
+	enc := json.NewEncoder(output)
+	for msg := range msgs {
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -458,6 +552,37 @@ func (s *containerRouter) postContainerUpdate(ctx context.Context, w http.Respon
 	return httputils.WriteJSON(w, http.StatusOK, resp)
 }
 
+func (s *containerRouter) postContainerUpdateImage(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	config := &types.ContainerUpdateImageConfig{
+		Image: r.Form.Get("image"),
+	}
+	if tmpSeconds := r.Form.Get("t"); tmpSeconds != "" {
+		valSeconds, err := strconv.Atoi(tmpSeconds)
+		if err != nil {
+			return errdefs.InvalidParameter(err)
+		}
+		config.StopTimeout = &valSeconds
+	}
+	if tmpSeconds := r.Form.Get("health-timeout"); tmpSeconds != "" {
+		valSeconds, err := strconv.Atoi(tmpSeconds)
+		if err != nil {
+			return errdefs.InvalidParameter(err)
+		}
+		config.HealthTimeout = time.Duration(valSeconds) * time.Second
+	}
+
+	resp, err := s.backend.ContainerUpdateImage(vars["name"], config)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, resp)
+}
+
 func (s *containerRouter) postContainersCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -716,3 +841,22 @@ func (s *containerRouter) postContainersPrune(ctx context.Context, w http.Respon
 	}
 	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
 }
+
+func (s *containerRouter) getContainersNetworkTraffic(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	traffic, err := s.backend.ContainersNetworkTraffic()
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, traffic)
+}
+
+func (s *containerRouter) postContainersNetworkTrafficReset(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	if err := s.backend.ContainersNetworkTrafficReset(r.Form.Get("key")); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -38,6 +38,8 @@ func (r *imageRouter) initRoutes() {
 		router.NewPostRoute("/images/delta", r.postImagesDelta),
 		router.NewPostRoute("/images/{name:.*}/push", r.postImagesPush),
 		router.NewPostRoute("/images/{name:.*}/tag", r.postImagesTag),
+		router.NewPostRoute("/images/{name:.*}/pin", r.postImagesPin),
+		router.NewPostRoute("/images/{name:.*}/unpin", r.postImagesUnpin),
 		router.NewPostRoute("/images/prune", r.postImagesPrune),
 		// DELETE
 		router.NewDeleteRoute("/images/{name:.*}", r.deleteImages),
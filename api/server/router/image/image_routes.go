@@ -75,7 +75,8 @@ func (s *imageRouter) postImagesCreate(ctx context.Context, w http.ResponseWrite
 				authConfig = &types.AuthConfig{}
 			}
 		}
-		err = s.backend.PullImage(ctx, image, tag, platform, metaHeaders, authConfig, output)
+		structuredProgress := httputils.BoolValue(r, "structured-progress")
+		err = s.backend.PullImage(ctx, image, tag, platform, metaHeaders, authConfig, structuredProgress, output)
 	} else { // import
 		src := r.Form.Get("fromSrc")
 		// 'err' MUST NOT be defined within this block, we need any error
@@ -294,6 +295,28 @@ func (s *imageRouter) postImagesTag(ctx context.Context, w http.ResponseWriter,
 	return nil
 }
 
+func (s *imageRouter) postImagesPin(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	if err := s.backend.PinImage(vars["name"], r.Form.Get("owner"), r.Form.Get("reason")); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+func (s *imageRouter) postImagesUnpin(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	if err := s.backend.UnpinImage(vars["name"], r.Form.Get("owner")); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (s *imageRouter) getImagesSearch(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
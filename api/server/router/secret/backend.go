@@ -0,0 +1,22 @@
+package secret // import "github.com/docker/docker/api/server/router/secret"
+
+import (
+	basictypes "github.com/docker/docker/api/types"
+	types "github.com/docker/docker/api/types/swarm"
+)
+
+// Backend abstracts a store of secrets and configs for standalone
+// containers.
+type Backend interface {
+	GetSecrets(opts basictypes.SecretListOptions) ([]types.Secret, error)
+	CreateSecret(s types.SecretSpec) (string, error)
+	RemoveSecret(idOrName string) error
+	GetSecret(id string) (types.Secret, error)
+	UpdateSecret(idOrName string, version uint64, spec types.SecretSpec) error
+
+	GetConfigs(opts basictypes.ConfigListOptions) ([]types.Config, error)
+	CreateConfig(s types.ConfigSpec) (string, error)
+	RemoveConfig(id string) error
+	GetConfig(id string) (types.Config, error)
+	UpdateConfig(idOrName string, version uint64, spec types.ConfigSpec) error
+}
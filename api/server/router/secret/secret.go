@@ -0,0 +1,40 @@
+package secret // import "github.com/docker/docker/api/server/router/secret"
+
+import "github.com/docker/docker/api/server/router"
+
+// secretRouter is a router to talk with the secret and config store of the
+// daemon, for containers not managed by swarm.
+type secretRouter struct {
+	backend Backend
+	routes  []router.Route
+}
+
+// NewRouter initializes a new secret router
+func NewRouter(b Backend) router.Router {
+	r := &secretRouter{
+		backend: b,
+	}
+	r.initRoutes()
+	return r
+}
+
+// Routes returns the available routes to the secret controller
+func (sr *secretRouter) Routes() []router.Route {
+	return sr.routes
+}
+
+func (sr *secretRouter) initRoutes() {
+	sr.routes = []router.Route{
+		router.NewGetRoute("/secrets", sr.getSecrets),
+		router.NewPostRoute("/secrets/create", sr.createSecret),
+		router.NewDeleteRoute("/secrets/{id}", sr.removeSecret),
+		router.NewGetRoute("/secrets/{id}", sr.getSecret),
+		router.NewPostRoute("/secrets/{id}/update", sr.updateSecret),
+
+		router.NewGetRoute("/configs", sr.getConfigs),
+		router.NewPostRoute("/configs/create", sr.createConfig),
+		router.NewDeleteRoute("/configs/{id}", sr.removeConfig),
+		router.NewGetRoute("/configs/{id}", sr.getConfig),
+		router.NewPostRoute("/configs/{id}/update", sr.updateConfig),
+	}
+}
@@ -94,3 +94,41 @@ func (v *volumeRouter) postVolumesPrune(ctx context.Context, w http.ResponseWrit
 	}
 	return httputils.WriteJSON(w, http.StatusOK, pruneReport)
 }
+
+func (v *volumeRouter) getVolumeExport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	w.Header().Set("Content-Type", "application/x-tar")
+	return v.backend.Export(ctx, vars["name"], w)
+}
+
+func (v *volumeRouter) postVolumeImport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := v.backend.Import(ctx, vars["name"], r.Body); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (v *volumeRouter) postVolumeSnapshot(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	var req volumetypes.VolumeSnapshotBody
+	if r.ContentLength != 0 {
+		if err := httputils.CheckForJSON(r); err != nil {
+			return err
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if err == io.EOF {
+				return errdefs.InvalidParameter(errors.New("got EOF while reading request body"))
+			}
+			return errdefs.InvalidParameter(err)
+		}
+	}
+
+	volume, err := v.backend.Snapshot(ctx, vars["name"], req.Name, req.Labels)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusCreated, volume)
+}
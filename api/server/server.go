@@ -13,6 +13,7 @@ import (
 	"github.com/docker/docker/api/server/router"
 	"github.com/docker/docker/api/server/router/debug"
 	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
@@ -57,7 +58,8 @@ func (s *Server) Accept(addr string, listeners ...net.Listener) {
 	for _, listener := range listeners {
 		httpServer := &HTTPServer{
 			srv: &http.Server{
-				Addr: addr,
+				Addr:        addr,
+				ConnContext: authorization.ConnContext,
 			},
 			l: listener,
 		}
@@ -78,6 +78,14 @@ type ContainerStatsConfig struct {
 	Version   string
 }
 
+// ContainerStatsHistoryConfig holds the parameters of a
+// backend.ContainerStatsHistory() call.
+type ContainerStatsHistoryConfig struct {
+	Since      time.Time
+	Until      time.Time
+	Resolution time.Duration
+}
+
 // ExecInspect holds information about a running process started
 // with docker exec.
 type ExecInspect struct {
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"io"
 	"net"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -69,6 +70,17 @@ type ContainerListOptions struct {
 	Filters filters.Args
 }
 
+// ContainerStatsHistoryOptions holds parameters to query the stats history
+// of a container with.
+type ContainerStatsHistoryOptions struct {
+	// Since and Until are timestamps or relative durations, as in
+	// ContainerLogsOptions.
+	Since string
+	Until string
+	// Resolution is the minimum interval between samples.
+	Resolution time.Duration
+}
+
 // ContainerLogsOptions holds parameters to filter logs with.
 type ContainerLogsOptions struct {
 	ShowStdout bool
@@ -79,6 +91,22 @@ type ContainerLogsOptions struct {
 	Follow     bool
 	Tail       string
 	Details    bool
+
+	// Grep, if set, is a regular expression the lines must match.
+	Grep string
+	// Contains, if set, is a substring the lines must contain.
+	Contains string
+	// Attrs are the attributes the lines must have, with the same value.
+	Attrs map[string]string
+	// MaxBytes, if positive, is the most bytes of log lines returned.
+	MaxBytes int64
+}
+
+// ContainersLogsOptions holds parameters to read the merged logs of the
+// containers matching Filters.
+type ContainersLogsOptions struct {
+	ContainerLogsOptions
+	Filters filters.Args
 }
 
 // ContainerRemoveOptions holds parameters to remove containers.
@@ -94,6 +122,14 @@ type ContainerStartOptions struct {
 	CheckpointDir string
 }
 
+// ContainerUpdateImageOptions holds parameters to replace a container with
+// an identically configured container running a different image.
+type ContainerUpdateImageOptions struct {
+	Image         string
+	StopTimeout   *time.Duration
+	HealthTimeout time.Duration
+}
+
 // CopyToContainerOptions holds information
 // about files to copy into a container
 type CopyToContainerOptions struct {
@@ -229,6 +265,17 @@ type ImageDeltaOptions struct {
 	Tag string
 }
 
+// ImagePinOptions holds parameters to pin an image with.
+type ImagePinOptions struct {
+	Owner  string
+	Reason string
+}
+
+// ImageUnpinOptions holds parameters to unpin an image with.
+type ImageUnpinOptions struct {
+	Owner string // Owner of the pin to remove; all pins are removed if empty
+}
+
 // ImageImportSource holds source information for ImageImport
 type ImageImportSource struct {
 	Source     io.Reader // Source is the data to send to the server to create this image from. You must set SourceName to "-" to leverage this.
@@ -262,6 +309,9 @@ type ImagePullOptions struct {
 	RegistryAuth  string // RegistryAuth is the base64 encoded credentials for the registry
 	PrivilegeFunc RequestPrivilegeFunc
 	Platform      string
+	// StructuredProgress requests PullProgress reports in the progress
+	// stream of a pull, in addition to the regular progress messages.
+	StructuredProgress bool
 }
 
 // RequestPrivilegeFunc is a function interface that
@@ -1,6 +1,8 @@
 package types // import "github.com/docker/docker/api/types"
 
 import (
+	"time"
+
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -27,6 +29,15 @@ type ContainerRmConfig struct {
 	ForceRemove, RemoveVolume, RemoveLink bool
 }
 
+// ContainerUpdateImageConfig holds arguments for the container update-image
+// operation, which replaces a container with an identically configured
+// container running a different image.
+type ContainerUpdateImageConfig struct {
+	Image         string        // Image to run in the new container
+	StopTimeout   *int          // Seconds to wait for the old container to stop before killing it
+	HealthTimeout time.Duration // Maximum time to wait for the new container to become healthy
+}
+
 // ExecConfig is a small subset of the Config struct that holds the configuration
 // for the exec feature of docker.
 type ExecConfig struct {
@@ -22,6 +22,15 @@ type HealthConfig struct {
 	// {"NONE"} : disable healthcheck
 	// {"CMD", args...} : exec arguments directly
 	// {"CMD-SHELL", command} : run command with system's default shell
+	// {"HTTP", options...} : GET a URL in the container's network namespace
+	// {"TCP", options...} : connect to a port in the container's network namespace
+	// {"GRPC", options...} : query the gRPC health service in the container's network namespace
+	//
+	// The HTTP, TCP and GRPC options are "key=value" strings. All of them
+	// require "port" and accept "host" (an IP address, defaults to 127.0.0.1).
+	// HTTP also accepts "path", "scheme" (http or https), "status" (a list
+	// of codes and ranges, defaults to 200-399) and one or more "header"
+	// ("Name: value"); GRPC accepts "service".
 	Test []string `json:",omitempty"`
 
 	// Zero means to inherit. Durations are expressed as integer nanoseconds.
@@ -1,7 +1,9 @@
 package container // import "github.com/docker/docker/api/types/container"
 
 import (
+	"os"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types/blkiodev"
 	"github.com/docker/docker/api/types/mount"
@@ -288,6 +290,36 @@ type DeviceMapping struct {
 type RestartPolicy struct {
 	Name              string
 	MaximumRetryCount int
+
+	// InitialDelay is the delay before the first restart. Zero means the
+	// default (100ms).
+	InitialDelay time.Duration `json:",omitempty"`
+	// MaxDelay is the upper bound of the delay between restarts. Zero
+	// means the default (1 minute).
+	MaxDelay time.Duration `json:",omitempty"`
+	// BackoffMultiplier is the factor applied to the delay after each
+	// restart. Zero means the default (2).
+	BackoffMultiplier float64 `json:",omitempty"`
+	// ResetAfter is how long the container must run for the delay to be
+	// reset to InitialDelay. Zero means the default (10 seconds).
+	ResetAfter time.Duration `json:",omitempty"`
+
+	// OnUnhealthy enables restarting the container when its healthcheck
+	// reports it as unhealthy. It applies independently of Name.
+	OnUnhealthy *UnhealthyRestartPolicy `json:",omitempty"`
+}
+
+// UnhealthyRestartPolicy configures restarting a container when it becomes
+// unhealthy.
+type UnhealthyRestartPolicy struct {
+	// GracePeriod is the time after the container started during which it
+	// is not restarted for being unhealthy.
+	GracePeriod time.Duration `json:",omitempty"`
+
+	// MaximumRestartCount is the maximum number of consecutive restarts
+	// for being unhealthy, without the container becoming healthy in
+	// between. Zero means no limit.
+	MaximumRestartCount int `json:",omitempty"`
 }
 
 // IsNone indicates whether the container has the "no" restart policy.
@@ -317,7 +349,82 @@ func (rp *RestartPolicy) IsUnlessStopped() bool {
 
 // IsSame compares two RestartPolicy to see if they are the same
 func (rp *RestartPolicy) IsSame(tp *RestartPolicy) bool {
-	return rp.Name == tp.Name && rp.MaximumRetryCount == tp.MaximumRetryCount
+	if rp.Name != tp.Name || rp.MaximumRetryCount != tp.MaximumRetryCount {
+		return false
+	}
+	if rp.InitialDelay != tp.InitialDelay || rp.MaxDelay != tp.MaxDelay || rp.BackoffMultiplier != tp.BackoffMultiplier || rp.ResetAfter != tp.ResetAfter {
+		return false
+	}
+	if rp.OnUnhealthy == nil || tp.OnUnhealthy == nil {
+		return rp.OnUnhealthy == tp.OnUnhealthy
+	}
+	return *rp.OnUnhealthy == *tp.OnUnhealthy
+}
+
+// DependencyCondition is the condition a dependency must meet before a
+// container depending on it is started.
+type DependencyCondition string
+
+const (
+	// DependencyStarted waits for the dependency to be running.
+	DependencyStarted DependencyCondition = "started"
+	// DependencyHealthy waits for the dependency to be healthy. It is the
+	// same as DependencyStarted for a dependency without healthcheck.
+	DependencyHealthy DependencyCondition = "healthy"
+	// DependencyExitedSuccessfully waits for the dependency to exit with
+	// status 0.
+	DependencyExitedSuccessfully DependencyCondition = "exited-successfully"
+)
+
+// Dependency is a container that must meet a condition before the daemon
+// starts a container on boot or because of its restart policy.
+type Dependency struct {
+	// Container is the name or ID of the dependency.
+	Container string
+	// Condition is the condition to wait for. Empty means DependencyStarted.
+	Condition DependencyCondition `json:",omitempty"`
+	// Timeout is the time to wait for the condition, after which the
+	// container is started anyway. Zero means the default (2 minutes).
+	Timeout time.Duration `json:",omitempty"`
+}
+
+// EgressPolicy restricts the destinations a container can connect to. The
+// traffic leaving the container that no rule allows is rejected, except
+// the DNS queries to the DNS servers of the container.
+type EgressPolicy struct {
+	Rules []EgressRule `json:",omitempty"`
+}
+
+// EgressRule allows the traffic to a destination. Empty fields match any
+// destination, protocol or port.
+type EgressRule struct {
+	// CIDR is the destination network, e.g. "192.0.2.0/24", or a single
+	// address.
+	CIDR string `json:",omitempty"`
+	// Host is a DNS name, whose addresses are resolved by the daemon and
+	// refreshed periodically. It is exclusive with CIDR.
+	Host string `json:",omitempty"`
+	// Protocol is "tcp", "udp" or "icmp".
+	Protocol string `json:",omitempty"`
+	// Ports is the destination port, or range of ports such as
+	// "8000-8080". It requires the "tcp" or "udp" protocol.
+	Ports string `json:",omitempty"`
+}
+
+// FileReference references a secret or a config stored by the daemon, and
+// the file it is mounted as in a container.
+type FileReference struct {
+	// Name is the name or ID of the secret or config.
+	Name string
+	// Target is the path of the file in the container. A relative path is
+	// relative to /run/secrets for secrets, and to / for configs. It
+	// defaults to the name of the secret or config.
+	Target string `json:",omitempty"`
+	// UID and GID are the owner of the file, "0" if empty.
+	UID string `json:",omitempty"`
+	GID string `json:",omitempty"`
+	// Mode is the mode of the file, 0444 if zero.
+	Mode os.FileMode `json:",omitempty"`
 }
 
 // LogMode is a type to define the available modes for logging
@@ -369,6 +476,10 @@ type Resources struct {
 	OomKillDisable       *bool           // Whether to disable OOM Killer or not
 	PidsLimit            *int64          // Setting PIDs limit for a container; Set `0` or `-1` for unlimited, or `null` to not change.
 	Ulimits              []*units.Ulimit // List of ulimits to be set in the container
+	NetworkEgressRate    int64           // Maximum rate of the traffic sent on each network interface (in bytes per second)
+	NetworkEgressBurst   int64           // Amount of egress traffic sent at once above the rate (in bytes)
+	NetworkIngressRate   int64           // Maximum rate of the traffic received on each network interface (in bytes per second)
+	NetworkIngressBurst  int64           // Amount of ingress traffic received at once above the rate (in bytes)
 
 	// Applicable to Windows
 	CPUCount           int64  `json:"CpuCount"`   // CPU count
@@ -400,6 +511,7 @@ type HostConfig struct {
 	AutoRemove      bool          // Automatically remove container when it exits
 	VolumeDriver    string        // Name of the volume driver used to mount volumes
 	VolumesFrom     []string      // List of volumes to take from other container
+	DependsOn       []Dependency  `json:",omitempty"` // Containers that must meet a condition before this container is started by the daemon
 
 	// Applicable to UNIX platforms
 	CapAdd          strslice.StrSlice // List of kernel capabilities to add to the container
@@ -426,6 +538,9 @@ type HostConfig struct {
 	ShmSize         int64             // Total shm memory usage
 	Sysctls         map[string]string `json:",omitempty"` // List of Namespaced sysctls used for the container
 	Runtime         string            `json:",omitempty"` // Runtime to use with this container
+	Secrets         []FileReference   `json:",omitempty"` // Secrets mounted in the container
+	Configs         []FileReference   `json:",omitempty"` // Configs mounted in the container
+	EgressPolicy    *EgressPolicy     `json:",omitempty"` // Destinations the container is allowed to connect to
 
 	// Applicable to Windows
 	ConsoleSize [2]uint   // Initial console size (height,width)
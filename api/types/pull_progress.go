@@ -0,0 +1,50 @@
+package types // import "github.com/docker/docker/api/types"
+
+import "time"
+
+// PullProgressID is the ID of the progress messages that carry a
+// PullProgress as auxiliary data, when structured pull progress is requested.
+const PullProgressID = "moby.pull.progress"
+
+// Phases of a layer in a PullProgress.
+const (
+	PullPhaseWaiting     = "waiting"     // Queued, waiting for a download slot
+	PullPhaseDownloading = "downloading" // Receiving and extracting layer data
+	PullPhasePatching    = "patching"    // Receiving a delta and applying it to the delta base
+	PullPhaseRetrying    = "retrying"    // Waiting to retry a failed download
+	PullPhaseExtracting  = "extracting"  // All data received, registering the layer
+	PullPhaseComplete    = "complete"    // Layer downloaded and registered
+	PullPhaseExists      = "exists"      // Layer was already present
+)
+
+// PullProgress is the aggregate progress of an image pull.
+type PullProgress struct {
+	// Current is the number of bytes received so far, over all layers.
+	Current int64
+	// Total is the number of bytes to receive, over all layers.
+	Total int64
+	// Rate is the current transfer rate, in bytes per second.
+	Rate int64 `json:",omitempty"`
+	// ETA is the estimated number of seconds until all bytes are received.
+	ETA int64 `json:",omitempty"`
+	// Retries is the number of download retries, over all layers.
+	Retries int `json:",omitempty"`
+	// Done is set on the final report of the pull.
+	Done bool `json:",omitempty"`
+	// Layers holds the progress of the individual layers.
+	Layers []LayerPullProgress
+}
+
+// LayerPullProgress is the progress of a single layer of an image pull.
+type LayerPullProgress struct {
+	ID      string
+	Phase   string
+	Current int64 `json:",omitempty"`
+	Total   int64 `json:",omitempty"`
+	Retries int   `json:",omitempty"`
+	// DownloadDuration is the time it took to receive the layer data.
+	DownloadDuration time.Duration `json:",omitempty"`
+	// ExtractDuration is the time it took to register the layer, once all
+	// its data was received.
+	ExtractDuration time.Duration `json:",omitempty"`
+}
@@ -79,6 +79,26 @@ type MemoryStats struct {
 	CommitPeak uint64 `json:"commitpeakbytes,omitempty"`
 	// private working set
 	PrivateWorkingSet uint64 `json:"privateworkingset,omitempty"`
+
+	// Events are the counters of the cgroup v2 memory.events file.
+	// Linux with cgroup v2 only.
+	Events *MemoryEvents `json:"events,omitempty"`
+}
+
+// MemoryEvents stores the number of times memory events occurred in the
+// cgroup of a container. Linux with cgroup v2 only.
+type MemoryEvents struct {
+	// Number of times the cgroup was reclaimed below its low boundary.
+	Low uint64 `json:"low"`
+	// Number of times usage exceeded the high boundary and processes were
+	// throttled.
+	High uint64 `json:"high"`
+	// Number of times usage was about to exceed the limit.
+	Max uint64 `json:"max"`
+	// Number of times usage reached the limit and allocations failed.
+	Oom uint64 `json:"oom"`
+	// Number of processes killed by the OOM killer.
+	OomKill uint64 `json:"oom_kill"`
 }
 
 // BlkioStatEntry is one small entity to store a piece of Blkio stats
@@ -104,6 +124,19 @@ type BlkioStats struct {
 	IoMergedRecursive       []BlkioStatEntry `json:"io_merged_recursive"`
 	IoTimeRecursive         []BlkioStatEntry `json:"io_time_recursive"`
 	SectorsRecursive        []BlkioStatEntry `json:"sectors_recursive"`
+
+	// All the counters of the cgroup v2 io.stat file, per device.
+	// Linux with cgroup v2 only.
+	IoStat []IOStatEntry `json:"io_stat,omitempty"`
+}
+
+// IOStatEntry stores the cgroup v2 io.stat counters of a block device, such
+// as "rbytes", "wbytes", "rios", "wios", "dbytes" and "dios".
+// Not used on Windows.
+type IOStatEntry struct {
+	Major uint64            `json:"major"`
+	Minor uint64            `json:"minor"`
+	Stats map[string]uint64 `json:"stats"`
 }
 
 // StorageStats is the disk I/O stats for read/write on Windows.
@@ -138,6 +171,19 @@ type NetworkStats struct {
 	EndpointID string `json:"endpoint_id,omitempty"`
 	// Instance ID. Not used on Linux.
 	InstanceID string `json:"instance_id,omitempty"`
+	// Bandwidth is the shaping applied to the interface. Not used on
+	// Windows.
+	Bandwidth *NetworkBandwidth `json:"bandwidth,omitempty"`
+}
+
+// NetworkBandwidth is the shaping applied to the traffic of a network
+// interface of a container. The rates are in bytes per second, and the
+// bursts in bytes.
+type NetworkBandwidth struct {
+	EgressRate   int64 `json:"egress_rate,omitempty"`
+	EgressBurst  int64 `json:"egress_burst,omitempty"`
+	IngressRate  int64 `json:"ingress_rate,omitempty"`
+	IngressBurst int64 `json:"ingress_burst,omitempty"`
 }
 
 // PidsStats contains the stats of a container's pids
@@ -149,6 +195,35 @@ type PidsStats struct {
 	Limit uint64 `json:"limit,omitempty"`
 }
 
+// PressureData is the pressure stall information of a resource, that is the
+// share of time in which tasks were stalled waiting for it.
+type PressureData struct {
+	// Percentage of time stalled over the last 10, 60 and 300 seconds.
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	// Total time stalled, in microseconds.
+	Total uint64 `json:"total"`
+}
+
+// Pressure is the pressure stall information of a resource for some and
+// for all the tasks of a container.
+type Pressure struct {
+	// At least one task was stalled.
+	Some PressureData `json:"some"`
+	// All non-idle tasks were stalled at the same time. Not reported for
+	// the CPU by older kernels.
+	Full *PressureData `json:"full,omitempty"`
+}
+
+// PressureStats contains the pressure stall information (PSI) of a
+// container. Linux with cgroup v2 only, and only if the kernel supports it.
+type PressureStats struct {
+	CPU    *Pressure `json:"cpu,omitempty"`
+	Memory *Pressure `json:"memory,omitempty"`
+	IO     *Pressure `json:"io,omitempty"`
+}
+
 // Stats is Ultimate struct aggregating all types of stats of one container
 type Stats struct {
 	// Common stats
@@ -156,8 +231,9 @@ type Stats struct {
 	PreRead time.Time `json:"preread"`
 
 	// Linux specific stats, not populated on Windows.
-	PidsStats  PidsStats  `json:"pids_stats,omitempty"`
-	BlkioStats BlkioStats `json:"blkio_stats,omitempty"`
+	PidsStats     PidsStats      `json:"pids_stats,omitempty"`
+	BlkioStats    BlkioStats     `json:"blkio_stats,omitempty"`
+	PressureStats *PressureStats `json:"pressure_stats,omitempty"`
 
 	// Windows specific stats, not populated on Linux.
 	NumProcs     uint32       `json:"num_procs"`
@@ -179,3 +255,31 @@ type StatsJSON struct {
 	// Networks request version >=1.21
 	Networks map[string]NetworkStats `json:"networks,omitempty"`
 }
+
+// StatsHistory is the recorded history of the stats of a container.
+type StatsHistory struct {
+	// Resolution is the interval between samples.
+	Resolution time.Duration `json:"resolution"`
+	// Samples are ordered from the oldest to the most recent. Only the CPU
+	// usage, memory usage and limit, block I/O bytes of all the devices,
+	// pids and network counters are set. The PreCPUStats and PreRead of
+	// each sample are those of the previous one.
+	Samples []StatsJSON `json:"samples"`
+}
+
+// NetworkTraffic is the network traffic accumulated by the successive
+// containers sharing an accounting key, across restarts and recreations of
+// the containers.
+type NetworkTraffic struct {
+	// Key is the name of the containers, or the value of the label
+	// configured as the accounting key.
+	Key string `json:"key"`
+	// RxBytes and TxBytes are the bytes received and transmitted on all
+	// the interfaces of the containers.
+	RxBytes uint64 `json:"rx_bytes"`
+	TxBytes uint64 `json:"tx_bytes"`
+	// Since is the time the accounting started, or was last reset.
+	Since time.Time `json:"since"`
+	// UpdatedAt is the time of the last sample accounted.
+	UpdatedAt time.Time `json:"updated_at"`
+}
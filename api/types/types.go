@@ -51,7 +51,16 @@ type ImageInspect struct {
 
 // ImageMetadata contains engine-local data about the image
 type ImageMetadata struct {
-	LastTagTime time.Time `json:",omitempty"`
+	LastTagTime time.Time  `json:",omitempty"`
+	Pins        []ImagePin `json:",omitempty"`
+}
+
+// ImagePin protects an image from being removed by a prune or a non-forced
+// image delete. Each owner holds at most one pin on an image.
+type ImagePin struct {
+	Owner   string
+	Reason  string `json:",omitempty"`
+	Created time.Time
 }
 
 // Container contains response of Engine API:
@@ -307,6 +316,10 @@ type Health struct {
 	Status        string               // Status is one of Starting, Healthy or Unhealthy
 	FailingStreak int                  // FailingStreak is the number of consecutive failures
 	Log           []*HealthcheckResult // Log contains the last few results (oldest first)
+
+	// RestartCount is the number of times the container was restarted for
+	// being unhealthy since it was last healthy.
+	RestartCount int `json:",omitempty"`
 }
 
 // ContainerState stores container's running state
@@ -324,6 +337,13 @@ type ContainerState struct {
 	StartedAt  string
 	FinishedAt string
 	Health     *Health `json:",omitempty"`
+
+	// RestartDelay is the current delay between restarts, as computed
+	// from the backoff parameters of the restart policy.
+	RestartDelay time.Duration `json:",omitempty"`
+	// NextRestart is the time at which the pending restart is due, while
+	// the container is restarting.
+	NextRestart string `json:",omitempty"`
 }
 
 // ContainerNode stores information about the node that a container
@@ -372,6 +392,9 @@ type ContainerJSON struct {
 	Mounts          []MountPoint
 	Config          *container.Config
 	NetworkSettings *NetworkSettings
+	// NetworkTraffic is the traffic accounted under the accounting key of
+	// the container, if network traffic accounting is enabled.
+	NetworkTraffic *NetworkTraffic `json:",omitempty"`
 }
 
 // NetworkSettings exposes the network settings in the api
@@ -398,6 +421,7 @@ type NetworkSettingsBase struct {
 	SandboxKey             string      // SandboxKey identifies the sandbox
 	SecondaryIPAddresses   []network.Address
 	SecondaryIPv6Addresses []network.Address
+	Bandwidth              map[string]NetworkBandwidth `json:",omitempty"` // Bandwidth is the shaping applied to each network interface, indexed by interface name
 }
 
 // DefaultNetworkSettings holds network information
@@ -539,7 +563,19 @@ type DiskUsage struct {
 	Containers  []*Container
 	Volumes     []*Volume
 	BuildCache  []*BuildCache
-	BuilderSize int64 // deprecated
+	BuilderSize int64          // deprecated
+	Logs        *LogsDiskUsage `json:",omitempty"`
+}
+
+// LogsDiskUsage is the disk space used by the log files of the containers
+// using the local and json-file log drivers.
+type LogsDiskUsage struct {
+	// Size is the total size of the log files.
+	Size int64
+	// Quota is the maximum size of the log files, or 0 if unlimited.
+	Quota int64
+	// Containers is the size of the log files of each container, by ID.
+	Containers map[string]int64
 }
 
 // ContainersPruneReport contains the response for Engine API:
@@ -634,3 +670,19 @@ type BuildCachePruneOptions struct {
 	KeepStorage int64
 	Filters     filters.Args
 }
+
+// ContainerLogMessage is a log message of a container in the merged logs
+// returned by GET "/containers/logs".
+type ContainerLogMessage struct {
+	ContainerID   string
+	ContainerName string
+	// Stream is "stdout" or "stderr".
+	Stream    string `json:",omitempty"`
+	Timestamp time.Time
+	// Line is the log line, including the newline ending it, if any.
+	Line string
+	// Attrs are the attributes of the line, returned with details.
+	Attrs map[string]string `json:",omitempty"`
+	// Error is set if the logs of the container cannot be read.
+	Error string `json:",omitempty"`
+}
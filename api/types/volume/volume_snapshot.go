@@ -0,0 +1,12 @@
+package volume // import "github.com/docker/docker/api/types/volume"
+
+// VolumeSnapshotBody is the configuration of the volume created by a
+// snapshot of another volume.
+type VolumeSnapshotBody struct {
+	// Name of the new volume. If not specified, Docker generates a name.
+	Name string `json:"Name"`
+
+	// User-defined key/value metadata of the new volume. The labels of the
+	// snapshotted volume are used if not specified.
+	Labels map[string]string `json:"Labels,omitempty"`
+}
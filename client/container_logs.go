@@ -2,11 +2,14 @@ package client // import "github.com/docker/docker/client"
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	timetypes "github.com/docker/docker/api/types/time"
 	"github.com/pkg/errors"
 )
@@ -34,6 +37,85 @@ import (
 // You can use github.com/docker/docker/pkg/stdcopy.StdCopy to demultiplex this
 // stream.
 func (cli *Client) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	query, err := containerLogsQuery(options)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := cli.get(ctx, "/containers/"+container+"/logs", query, nil)
+	if err != nil {
+		return nil, wrapResponseError(err, resp, "container", container)
+	}
+	return resp.body, nil
+}
+
+// ContainersLogs returns the logs of the containers matching
+// options.Filters, merged in the order of their timestamps, each message
+// tagged with its container. If options.Follow is set, the containers
+// matching the filters that start while the logs are read are joined.
+//
+// It's up to the caller to close the stream by cancelling the context. Once
+// the stream has been completely read an io.EOF error will be sent over the
+// error channel. If an error is sent all processing will be stopped.
+func (cli *Client) ContainersLogs(ctx context.Context, options types.ContainersLogsOptions) (<-chan types.ContainerLogMessage, <-chan error) {
+	messages := make(chan types.ContainerLogMessage)
+	errs := make(chan error, 1)
+
+	started := make(chan struct{})
+	go func() {
+		defer close(errs)
+
+		query, err := containerLogsQuery(options.ContainerLogsOptions)
+		if err != nil {
+			close(started)
+			errs <- err
+			return
+		}
+		if options.Filters.Len() > 0 {
+			filterJSON, err := filters.ToJSON(options.Filters)
+			if err != nil {
+				close(started)
+				errs <- err
+				return
+			}
+			query.Set("filters", filterJSON)
+		}
+
+		resp, err := cli.get(ctx, "/containers/logs", query, nil)
+		if err != nil {
+			close(started)
+			errs <- err
+			return
+		}
+		defer resp.body.Close()
+
+		decoder := json.NewDecoder(resp.body)
+
+		close(started)
+		for {
+			var msg types.ContainerLogMessage
+			if err := decoder.Decode(&msg); err != nil {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				errs <- err
+				return
+			}
+
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+	<-started
+
+	return messages, errs
+}
+
+func containerLogsQuery(options types.ContainerLogsOptions) (url.Values, error) {
 	query := url.Values{}
 	if options.ShowStdout {
 		query.Set("stdout", "1")
@@ -72,9 +154,25 @@ func (cli *Client) ContainerLogs(ctx context.Context, container string, options
 	}
 	query.Set("tail", options.Tail)
 
-	resp, err := cli.get(ctx, "/containers/"+container+"/logs", query, nil)
-	if err != nil {
-		return nil, wrapResponseError(err, resp, "container", container)
+	if options.Grep != "" {
+		query.Set("grep", options.Grep)
 	}
-	return resp.body, nil
+
+	if options.Contains != "" {
+		query.Set("contains", options.Contains)
+	}
+
+	if len(options.Attrs) > 0 {
+		attrs, err := json.Marshal(options.Attrs)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("attrs", string(attrs))
+	}
+
+	if options.MaxBytes > 0 {
+		query.Set("maxbytes", strconv.FormatInt(options.MaxBytes, 10))
+	}
+
+	return query, nil
 }
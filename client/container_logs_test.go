@@ -3,6 +3,7 @@ package client // import "github.com/docker/docker/client"
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/errdefs"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
@@ -104,6 +106,21 @@ func TestContainerLogs(t *testing.T) {
 				"until": "1136073600.000000001",
 			},
 		},
+		{
+			options: types.ContainerLogsOptions{
+				Grep:     "^ERROR",
+				Contains: "timeout",
+				Attrs:    map[string]string{"env": "prod"},
+				MaxBytes: 4096,
+			},
+			expectedQueryParams: map[string]string{
+				"tail":     "",
+				"grep":     "^ERROR",
+				"contains": "timeout",
+				"attrs":    `{"env":"prod"}`,
+				"maxbytes": "4096",
+			},
+		},
 		{
 			options: types.ContainerLogsOptions{
 				// An complete invalid date will not be passed
@@ -152,6 +169,55 @@ func TestContainerLogs(t *testing.T) {
 	}
 }
 
+func TestContainersLogs(t *testing.T) {
+	expectedURL := "/containers/logs"
+	expected := []types.ContainerLogMessage{
+		{ContainerID: "a", ContainerName: "web", Stream: "stdout", Line: "one\n"},
+		{ContainerID: "b", ContainerName: "db", Stream: "stderr", Line: "two\n"},
+	}
+	client := &Client{
+		client: newMockClient(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path != expectedURL {
+				return nil, fmt.Errorf("expected URL '%s', got '%s'", expectedURL, r.URL)
+			}
+			query := r.URL.Query()
+			if actual := query.Get("filters"); actual != `{"label":{"app=foo":true}}` {
+				return nil, fmt.Errorf("filters not set in URL query properly, got %s", actual)
+			}
+			if actual := query.Get("follow"); actual != "1" {
+				return nil, fmt.Errorf("follow not set in URL query properly, got %s", actual)
+			}
+			buf := &bytes.Buffer{}
+			for _, msg := range expected {
+				if err := json.NewEncoder(buf).Encode(msg); err != nil {
+					return nil, err
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(buf),
+			}, nil
+		}),
+	}
+
+	msgs, errs := client.ContainersLogs(context.Background(), types.ContainersLogsOptions{
+		ContainerLogsOptions: types.ContainerLogsOptions{Follow: true},
+		Filters:              filters.NewArgs(filters.Arg("label", "app=foo")),
+	})
+	var actual []types.ContainerLogMessage
+	for {
+		select {
+		case msg := <-msgs:
+			actual = append(actual, msg)
+			continue
+		case err := <-errs:
+			assert.Check(t, is.Equal(err, io.EOF))
+		}
+		break
+	}
+	assert.Check(t, is.DeepEqual(actual, expected))
+}
+
 func ExampleClient_ContainerLogs_withTimeout() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
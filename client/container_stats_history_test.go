@@ -0,0 +1,62 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestContainerStatsHistoryError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ContainerStatsHistory(context.Background(), "nothing", types.ContainerStatsHistoryOptions{})
+	assert.Check(t, errdefs.IsSystem(err), "expected a Server Error, got %[1]T: %[1]v", err)
+}
+
+func TestContainerStatsHistory(t *testing.T) {
+	expectedURL := "/containers/container_id/stats/history"
+	client := &Client{
+		client: newMockClient(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, r.URL)
+			}
+			query := r.URL.Query()
+			if since := query.Get("since"); since != "1000" {
+				return nil, fmt.Errorf("since not set in URL query properly, got %q", since)
+			}
+			if resolution := query.Get("resolution"); resolution != "1m0s" {
+				return nil, fmt.Errorf("resolution not set in URL query properly, got %q", resolution)
+			}
+			b, err := json.Marshal(types.StatsHistory{
+				Resolution: time.Minute,
+				Samples:    []types.StatsJSON{{ID: "container_id"}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	history, err := client.ContainerStatsHistory(context.Background(), "container_id", types.ContainerStatsHistoryOptions{
+		Since:      "1000",
+		Resolution: time.Minute,
+	})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(history.Resolution, time.Minute))
+	assert.Check(t, is.Len(history.Samples, 1))
+}
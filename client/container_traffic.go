@@ -0,0 +1,34 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+)
+
+// ContainersNetworkTraffic returns the network traffic the daemon accounted
+// under each key, across restarts and recreations of the containers.
+func (cli *Client) ContainersNetworkTraffic(ctx context.Context) ([]types.NetworkTraffic, error) {
+	var traffic []types.NetworkTraffic
+	resp, err := cli.get(ctx, "/containers/traffic", nil, nil)
+	defer ensureReaderClosed(resp)
+	if err != nil {
+		return nil, err
+	}
+	err = json.NewDecoder(resp.body).Decode(&traffic)
+	return traffic, err
+}
+
+// ContainersNetworkTrafficReset discards the network traffic accounted under
+// key, or under all keys if key is empty.
+func (cli *Client) ContainersNetworkTrafficReset(ctx context.Context, key string) error {
+	query := url.Values{}
+	if key != "" {
+		query.Set("key", key)
+	}
+	resp, err := cli.post(ctx, "/containers/traffic/reset", query, nil, nil)
+	ensureReaderClosed(resp)
+	return err
+}
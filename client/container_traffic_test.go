@@ -0,0 +1,74 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestContainersNetworkTrafficError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ContainersNetworkTraffic(context.Background())
+	assert.Check(t, errdefs.IsSystem(err), "expected a Server Error, got %[1]T: %[1]v", err)
+
+	err = client.ContainersNetworkTrafficReset(context.Background(), "")
+	assert.Check(t, errdefs.IsSystem(err), "expected a Server Error, got %[1]T: %[1]v", err)
+}
+
+func TestContainersNetworkTraffic(t *testing.T) {
+	expectedURL := "/containers/traffic"
+	client := &Client{
+		client: newMockClient(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, r.URL)
+			}
+			b, err := json.Marshal([]types.NetworkTraffic{{Key: "app", RxBytes: 10, TxBytes: 20}})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	traffic, err := client.ContainersNetworkTraffic(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(traffic, []types.NetworkTraffic{{Key: "app", RxBytes: 10, TxBytes: 20}}))
+}
+
+func TestContainersNetworkTrafficReset(t *testing.T) {
+	expectedURL := "/containers/traffic/reset"
+	client := &Client{
+		client: newMockClient(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, r.URL)
+			}
+			if r.Method != http.MethodPost {
+				return nil, fmt.Errorf("expected POST method, got %s", r.Method)
+			}
+			if key := r.URL.Query().Get("key"); key != "app" {
+				return nil, fmt.Errorf("key not set in URL query properly, got %q", key)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}),
+	}
+
+	err := client.ContainersNetworkTrafficReset(context.Background(), "app")
+	assert.NilError(t, err)
+}
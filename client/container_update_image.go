@@ -0,0 +1,36 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	timetypes "github.com/docker/docker/api/types/time"
+)
+
+// ContainerUpdateImage replaces a container with an identically configured
+// container running a different image. The daemon rolls back to the old
+// container if the new one fails to start or to become healthy.
+func (cli *Client) ContainerUpdateImage(ctx context.Context, containerID string, options types.ContainerUpdateImageOptions) (container.ContainerCreateCreatedBody, error) {
+	var response container.ContainerCreateCreatedBody
+
+	query := url.Values{}
+	query.Set("image", options.Image)
+	if options.StopTimeout != nil {
+		query.Set("t", timetypes.DurationToSecondsString(*options.StopTimeout))
+	}
+	if options.HealthTimeout > 0 {
+		query.Set("health-timeout", timetypes.DurationToSecondsString(options.HealthTimeout))
+	}
+
+	serverResp, err := cli.post(ctx, "/containers/"+containerID+"/update-image", query, nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return response, err
+	}
+
+	err = json.NewDecoder(serverResp.body).Decode(&response)
+	return response, err
+}
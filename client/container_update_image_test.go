@@ -0,0 +1,71 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+func TestContainerUpdateImageError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ContainerUpdateImage(context.Background(), "nothing", types.ContainerUpdateImageOptions{Image: "busybox"})
+	if !errdefs.IsSystem(err) {
+		t.Fatalf("expected a Server Error, got %[1]T: %[1]v", err)
+	}
+}
+
+func TestContainerUpdateImage(t *testing.T) {
+	expectedURL := "/containers/container_id/update-image"
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			query := req.URL.Query()
+			if image := query.Get("image"); image != "busybox:latest" {
+				return nil, fmt.Errorf("image not set in URL query properly. Expected 'busybox:latest', got %s", image)
+			}
+			if t := query.Get("t"); t != "5" {
+				return nil, fmt.Errorf("t (timeout) not set in URL query properly. Expected '5', got %s", t)
+			}
+			if ht := query.Get("health-timeout"); ht != "30" {
+				return nil, fmt.Errorf("health-timeout not set in URL query properly. Expected '30', got %s", ht)
+			}
+
+			b, err := json.Marshal(container.ContainerCreateCreatedBody{ID: "new_container_id"})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	stopTimeout := 5 * time.Second
+	resp, err := client.ContainerUpdateImage(context.Background(), "container_id", types.ContainerUpdateImageOptions{
+		Image:         "busybox:latest",
+		StopTimeout:   &stopTimeout,
+		HealthTimeout: 30 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "new_container_id" {
+		t.Fatalf("expected `new_container_id`, got %s", resp.ID)
+	}
+}
@@ -0,0 +1,34 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+)
+
+// ImagePin pins an image in the docker host, so that it is not removed by
+// prune or by a non-forced remove.
+func (cli *Client) ImagePin(ctx context.Context, imageID string, options types.ImagePinOptions) error {
+	query := url.Values{}
+	query.Set("owner", options.Owner)
+	if options.Reason != "" {
+		query.Set("reason", options.Reason)
+	}
+
+	resp, err := cli.post(ctx, "/images/"+imageID+"/pin", query, nil, nil)
+	ensureReaderClosed(resp)
+	return err
+}
+
+// ImageUnpin removes a pin from an image in the docker host.
+func (cli *Client) ImageUnpin(ctx context.Context, imageID string, options types.ImageUnpinOptions) error {
+	query := url.Values{}
+	if options.Owner != "" {
+		query.Set("owner", options.Owner)
+	}
+
+	resp, err := cli.post(ctx, "/images/"+imageID+"/unpin", query, nil, nil)
+	ensureReaderClosed(resp)
+	return err
+}
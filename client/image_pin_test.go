@@ -0,0 +1,78 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+)
+
+func TestImagePinError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+
+	err := client.ImagePin(context.Background(), "image_id", types.ImagePinOptions{Owner: "supervisor"})
+	if !errdefs.IsSystem(err) {
+		t.Fatalf("expected a Server Error, got %[1]T: %[1]v", err)
+	}
+}
+
+func TestImagePin(t *testing.T) {
+	expectedURL := "/images/image_id/pin"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != http.MethodPost {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+			query := req.URL.Query()
+			if owner := query.Get("owner"); owner != "supervisor" {
+				return nil, fmt.Errorf("owner not set in URL query properly. Expected 'supervisor', got %s", owner)
+			}
+			if reason := query.Get("reason"); reason != "rollback" {
+				return nil, fmt.Errorf("reason not set in URL query properly. Expected 'rollback', got %s", reason)
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(""))),
+			}, nil
+		}),
+	}
+
+	err := client.ImagePin(context.Background(), "image_id", types.ImagePinOptions{Owner: "supervisor", Reason: "rollback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImageUnpin(t *testing.T) {
+	expectedURL := "/images/image_id/unpin"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if owner := req.URL.Query().Get("owner"); owner != "supervisor" {
+				return nil, fmt.Errorf("owner not set in URL query properly. Expected 'supervisor', got %s", owner)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(""))),
+			}, nil
+		}),
+	}
+
+	err := client.ImageUnpin(context.Background(), "image_id", types.ImageUnpinOptions{Owner: "supervisor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -33,6 +33,9 @@ func (cli *Client) ImagePull(ctx context.Context, refStr string, options types.I
 	if options.Platform != "" {
 		query.Set("platform", strings.ToLower(options.Platform))
 	}
+	if options.StructuredProgress {
+		query.Set("structured-progress", "1")
+	}
 
 	resp, err := cli.tryImageCreate(ctx, query, options.RegistryAuth)
 	if errdefs.IsUnauthorized(err) && options.PrivilegeFunc != nil {
@@ -197,3 +197,24 @@ func TestImagePullWithoutErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestImagePullStructuredProgress(t *testing.T) {
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if v := req.URL.Query().Get("structured-progress"); v != "1" {
+				return nil, fmt.Errorf("structured-progress not set in URL query properly. Expected '1', got %s", v)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(""))),
+			}, nil
+		}),
+	}
+	resp, err := client.ImagePull(context.Background(), "myimage", types.ImagePullOptions{
+		StructuredProgress: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+}
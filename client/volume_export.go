@@ -0,0 +1,17 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// VolumeExport retrieves the content of a volume as a tar archive, and
+// returns it as an io.ReadCloser. It's up to the caller to close the stream.
+func (cli *Client) VolumeExport(ctx context.Context, volumeID string) (io.ReadCloser, error) {
+	resp, err := cli.get(ctx, "/volumes/"+volumeID+"/export", url.Values{}, nil)
+	if err != nil {
+		return nil, wrapResponseError(err, resp, "volume", volumeID)
+	}
+	return resp.body, nil
+}
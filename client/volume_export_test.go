@@ -0,0 +1,46 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestVolumeExportError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusNotFound, "no such volume")),
+	}
+	_, err := client.VolumeExport(context.Background(), "nothing")
+	assert.Check(t, errdefs.IsNotFound(err), err)
+}
+
+func TestVolumeExport(t *testing.T) {
+	expectedURL := "/volumes/volume_id/export"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != http.MethodGet {
+				return nil, fmt.Errorf("expected GET method, got %s", req.Method)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("archive"))),
+			}, nil
+		}),
+	}
+	body, err := client.VolumeExport(context.Background(), "volume_id")
+	assert.NilError(t, err)
+	defer body.Close()
+	content, err := ioutil.ReadAll(body)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(content), "archive"))
+}
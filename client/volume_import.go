@@ -0,0 +1,16 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// VolumeImport extracts a tar archive, which may be compressed, into a
+// volume.
+func (cli *Client) VolumeImport(ctx context.Context, volumeID string, content io.Reader) error {
+	headers := map[string][]string{"Content-Type": {"application/x-tar"}}
+	resp, err := cli.postRaw(ctx, "/volumes/"+volumeID+"/import", url.Values{}, content, headers)
+	defer ensureReaderClosed(resp)
+	return wrapResponseError(err, resp, "volume", volumeID)
+}
@@ -0,0 +1,51 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+)
+
+func TestVolumeImportError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusBadRequest, "invalid archive")),
+	}
+	err := client.VolumeImport(context.Background(), "volume_id", strings.NewReader("archive"))
+	assert.Check(t, errdefs.IsInvalidParameter(err), err)
+}
+
+func TestVolumeImport(t *testing.T) {
+	expectedURL := "/volumes/volume_id/import"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != http.MethodPost {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+			if contentType := req.Header.Get("Content-Type"); contentType != "application/x-tar" {
+				return nil, fmt.Errorf("expected application/x-tar content type, got %s", contentType)
+			}
+			content, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if string(content) != "archive" {
+				return nil, fmt.Errorf("expected archive body, got %q", content)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}),
+	}
+	assert.NilError(t, client.VolumeImport(context.Background(), "volume_id", strings.NewReader("archive")))
+}
@@ -0,0 +1,21 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types"
+	volumetypes "github.com/docker/docker/api/types/volume"
+)
+
+// VolumeSnapshot creates a volume with a copy of the content of a volume.
+func (cli *Client) VolumeSnapshot(ctx context.Context, volumeID string, options volumetypes.VolumeSnapshotBody) (types.Volume, error) {
+	var volume types.Volume
+	resp, err := cli.post(ctx, "/volumes/"+volumeID+"/snapshot", nil, options, nil)
+	defer ensureReaderClosed(resp)
+	if err != nil {
+		return volume, wrapResponseError(err, resp, "volume", volumeID)
+	}
+	err = json.NewDecoder(resp.body).Decode(&volume)
+	return volume, err
+}
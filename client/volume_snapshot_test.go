@@ -0,0 +1,63 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestVolumeSnapshotError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusNotImplemented, "volume driver does not support snapshots")),
+	}
+	_, err := client.VolumeSnapshot(context.Background(), "volume_id", volumetypes.VolumeSnapshotBody{})
+	assert.Check(t, errdefs.IsNotImplemented(err), err)
+}
+
+func TestVolumeSnapshot(t *testing.T) {
+	expectedURL := "/volumes/volume_id/snapshot"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != http.MethodPost {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+			var body volumetypes.VolumeSnapshotBody
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			content, err := json.Marshal(types.Volume{
+				Name:   body.Name,
+				Driver: "local",
+				Labels: body.Labels,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewReader(content)),
+			}, nil
+		}),
+	}
+
+	volume, err := client.VolumeSnapshot(context.Background(), "volume_id", volumetypes.VolumeSnapshotBody{
+		Name:   "backup",
+		Labels: map[string]string{"daily": "true"},
+	})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(volume.Name, "backup"))
+	assert.Check(t, is.DeepEqual(volume.Labels, map[string]string{"daily": "true"}))
+}
@@ -23,6 +23,7 @@ import (
 	grpcrouter "github.com/docker/docker/api/server/router/grpc"
 	"github.com/docker/docker/api/server/router/image"
 	"github.com/docker/docker/api/server/router/network"
+	secretrouter "github.com/docker/docker/api/server/router/secret"
 	sessionrouter "github.com/docker/docker/api/server/router/session"
 	systemrouter "github.com/docker/docker/api/server/router/system"
 	"github.com/docker/docker/api/server/router/volume"
@@ -200,6 +201,7 @@ func (cli *DaemonCli) start(opts *daemonOptions) (err error) {
 	}
 
 	cli.d = d
+	cli.authzMiddleware.SetLabelLookup(d.ObjectLabels)
 
 	if err := startMetricsServer(cli.Config.MetricsAddress); err != nil {
 		return errors.Wrap(err, "failed to start metrics server")
@@ -305,6 +307,7 @@ func (cli *DaemonCli) reloadConfig() {
 			return
 		}
 		cli.authzMiddleware.SetPlugins(c.AuthorizationPlugins)
+		cli.authzMiddleware.SetPolicy(c.AuthorizationPolicy)
 
 		if err := cli.d.Reload(c); err != nil {
 			logrus.Errorf("Error reconfiguring the daemon: %v", err)
@@ -462,6 +465,7 @@ func initRouter(opts routerOptions) {
 		image.NewRouter(opts.daemon.ImageService()),
 		systemrouter.NewRouter(opts.daemon, nil, opts.buildkit, opts.features),
 		volume.NewRouter(opts.daemon.VolumesService()),
+		secretrouter.NewRouter(opts.daemon),
 		build.NewRouter(opts.buildBackend, opts.daemon, opts.features),
 		sessionrouter.NewRouter(opts.sessionManager),
 		distributionrouter.NewRouter(opts.daemon.ImageService()),
@@ -510,6 +514,7 @@ func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config
 	}
 
 	cli.authzMiddleware = authorization.NewMiddleware(cli.Config.AuthorizationPlugins, pluginStore)
+	cli.authzMiddleware.SetPolicy(cli.Config.AuthorizationPolicy)
 	cli.Config.AuthzMiddleware = cli.authzMiddleware
 	s.UseMiddleware(cli.authzMiddleware)
 	return nil
@@ -25,6 +25,7 @@ import (
 	"github.com/docker/docker/daemon/logger/jsonfilelog"
 	"github.com/docker/docker/daemon/logger/local"
 	"github.com/docker/docker/daemon/logger/loggerutils/cache"
+	"github.com/docker/docker/daemon/logger/otlp"
 	"github.com/docker/docker/daemon/network"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/image"
@@ -408,6 +409,13 @@ func (container *Container) StartLogger() (logger.Logger, error) {
 			return nil, errdefs.System(errors.Wrap(err, "error creating local logs dir"))
 		}
 		info.LogPath = filepath.Join(logDir, "container.log")
+	case otlp.Name:
+		// The otlp driver buffers the logs that cannot be sent in this
+		// directory.
+		info.LogPath, err = container.GetRootResourcePath("otlp-buffer")
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	l, err := initDriver(info)
@@ -601,6 +609,14 @@ func (container *Container) RestartManager() restartmanager.RestartManager {
 	return container.restartManager
 }
 
+// RestartBackoff returns the restart backoff state of the container.
+func (container *Container) RestartBackoff() restartmanager.BackoffState {
+	if container.restartManager == nil {
+		return restartmanager.BackoffState{}
+	}
+	return container.restartManager.Backoff()
+}
+
 // ResetRestartManager initializes new restartmanager based on container config
 func (container *Container) ResetRestartManager(resetCount bool) {
 	if container.restartManager != nil {
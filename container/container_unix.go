@@ -346,6 +346,18 @@ func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfi
 	if resources.PidsLimit != nil {
 		cResources.PidsLimit = resources.PidsLimit
 	}
+	if resources.NetworkEgressRate != 0 {
+		cResources.NetworkEgressRate = networkBandwidth(resources.NetworkEgressRate)
+	}
+	if resources.NetworkEgressBurst != 0 {
+		cResources.NetworkEgressBurst = networkBandwidth(resources.NetworkEgressBurst)
+	}
+	if resources.NetworkIngressRate != 0 {
+		cResources.NetworkIngressRate = networkBandwidth(resources.NetworkIngressRate)
+	}
+	if resources.NetworkIngressBurst != 0 {
+		cResources.NetworkIngressBurst = networkBandwidth(resources.NetworkIngressBurst)
+	}
 
 	// update HostConfig of container
 	if hostConfig.RestartPolicy.Name != "" {
@@ -358,6 +370,15 @@ func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfi
 	return nil
 }
 
+// networkBandwidth returns the network rate or burst stored for an update
+// to v, where -1 removes it.
+func networkBandwidth(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
 // DetachAndUnmount uses a detached mount on all mount destinations, then
 // unmounts each volume normally.
 // This is used from daemon/archive for `docker cp`
@@ -0,0 +1,67 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ObjectLabels returns the labels of the object an API request operates on,
+// for the built-in authorization policy. The kind is the first segment of
+// the route of the request. Exec instances have the labels of their
+// container.
+func (daemon *Daemon) ObjectLabels(ctx context.Context, kind, name string) (map[string]string, error) {
+	switch kind {
+	case "containers":
+		ctr, err := daemon.GetContainer(name)
+		if err != nil {
+			return nil, err
+		}
+		return ctr.Config.Labels, nil
+	case "exec":
+		ec := daemon.execCommands.Get(name)
+		if ec == nil {
+			return nil, errExecNotFound(name)
+		}
+		ctr, err := daemon.GetContainer(ec.ContainerID)
+		if err != nil {
+			return nil, err
+		}
+		return ctr.Config.Labels, nil
+	case "images":
+		img, err := daemon.imageService.GetImage(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		if img.Config == nil {
+			return nil, nil
+		}
+		return img.Config.Labels, nil
+	case "networks":
+		nw, err := daemon.FindNetwork(name)
+		if err != nil {
+			return nil, err
+		}
+		return nw.Info().Labels(), nil
+	case "secrets":
+		secret, err := daemon.GetSecret(name)
+		if err != nil {
+			return nil, err
+		}
+		return secret.Spec.Labels, nil
+	case "configs":
+		config, err := daemon.GetConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		return config.Spec.Labels, nil
+	case "volumes":
+		v, err := daemon.volumes.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return v.Labels, nil
+	default:
+		return nil, errors.Errorf("objects of kind %s have no labels", kind)
+	}
+}
@@ -71,20 +71,32 @@ var builtinRuntimes = map[string]bool{
 // Use this to differentiate these options
 // with others like the ones in CommonTLSOptions.
 var flatOptions = map[string]bool{
-	"cluster-store-opts": true,
-	"log-opts":           true,
-	"runtimes":           true,
-	"default-ulimits":    true,
-	"features":           true,
-	"builder":            true,
+	"cluster-store-opts":   true,
+	"log-opts":             true,
+	"runtimes":             true,
+	"default-ulimits":      true,
+	"features":             true,
+	"builder":              true,
+	"events-journal":       true,
+	"event-sinks":          true,
+	"stats-history":        true,
+	"container-metrics":    true,
+	"network-traffic":      true,
+	"authorization-policy": true,
 }
 
 // skipValidateOptions contains configuration keys
 // that will be skipped from findConfigurationConflicts
 // for unknown flag validation.
 var skipValidateOptions = map[string]bool{
-	"features": true,
-	"builder":  true,
+	"features":             true,
+	"builder":              true,
+	"events-journal":       true,
+	"event-sinks":          true,
+	"stats-history":        true,
+	"container-metrics":    true,
+	"network-traffic":      true,
+	"authorization-policy": true,
 	// Corresponding flag has been removed because it was already unusable
 	"deprecated-key-path": true,
 }
@@ -146,6 +158,8 @@ type DNSConfig struct {
 type CommonConfig struct {
 	AuthzMiddleware       *authorization.Middleware `json:"-"`
 	AuthorizationPlugins  []string                  `json:"authorization-plugins,omitempty"` // AuthorizationPlugins holds list of authorization plugins
+	AuthorizationPolicy   *authorization.Policy     `json:"authorization-policy,omitempty"`  // AuthorizationPolicy holds the built-in authorization policy
+	SecretStoreKey        string                    `json:"secret-store-key,omitempty"`      // SecretStoreKey is the key secrets and configs are encrypted with, as file:<path> or keyring:<description>
 	AutoRestart           bool                      `json:"-"`
 	Context               map[string][]string       `json:"-"`
 	DisableBridge         bool                      `json:"-"`
@@ -273,6 +287,30 @@ type CommonConfig struct {
 
 	Builder BuilderConfig `json:"builder,omitempty"`
 
+	// EventsJournal configures recording events on disk, so that they can
+	// be queried across daemon restarts.
+	EventsJournal EventsJournalConfig `json:"events-journal,omitempty"`
+
+	// EventSinks are destinations events are forwarded to.
+	EventSinks []EventSinkConfig `json:"event-sinks,omitempty"`
+
+	// StatsHistory configures keeping a downsampled history of the stats
+	// of running containers.
+	StatsHistory StatsHistoryConfig `json:"stats-history,omitempty"`
+
+	// ContainerMetrics configures exporting the metrics of each running
+	// container on the metrics address.
+	ContainerMetrics ContainerMetricsConfig `json:"container-metrics,omitempty"`
+
+	// NetworkTraffic configures accounting the network traffic of the
+	// containers across their restarts and recreations.
+	NetworkTraffic NetworkTrafficConfig `json:"network-traffic,omitempty"`
+
+	// LogQuota is the maximum disk space used by the log files of all the
+	// containers using the local and json-file log drivers. Rotated log
+	// files are evicted, oldest first, to stay within the quota.
+	LogQuota opts.MemBytes `json:"log-quota,omitempty"`
+
 	ContainerdNamespace       string `json:"containerd-namespace,omitempty"`
 	ContainerdPluginNamespace string `json:"containerd-plugin-namespace,omitempty"`
 }
@@ -586,6 +624,26 @@ func Validate(config *Config) error {
 	if err := ValidateMaxUploadAttempts(config); err != nil {
 		return err
 	}
+	if err := ValidateEventsJournal(config); err != nil {
+		return err
+	}
+	if err := ValidateEventSinks(config); err != nil {
+		return err
+	}
+	if err := ValidateStatsHistory(config); err != nil {
+		return err
+	}
+	if err := ValidateContainerMetrics(config); err != nil {
+		return err
+	}
+	if err := ValidateNetworkTraffic(config); err != nil {
+		return err
+	}
+	if config.AuthorizationPolicy != nil {
+		if err := config.AuthorizationPolicy.Validate(); err != nil {
+			return err
+		}
+	}
 
 	// validate that "default" runtime is not reset
 	if runtimes := config.GetAllRuntimes(); len(runtimes) > 0 {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/docker/docker/daemon/discovery"
 	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/libnetwork/ipamutils"
 	"github.com/spf13/pflag"
 	"gotest.tools/v3/assert"
@@ -131,6 +132,45 @@ func TestDaemonConfigurationMergeConcurrentError(t *testing.T) {
 	}
 }
 
+func TestDaemonConfigurationMergeLogQuota(t *testing.T) {
+	file := fs.NewFile(t, "docker-config", fs.WithContent(`{"log-quota": "2g"}`))
+	defer file.Remove()
+
+	var logQuota opts.MemBytes
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Var(&logQuota, "log-quota", "")
+
+	cc, err := MergeDaemonConfigurations(&Config{}, flags, file.Path())
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(int64(2*1024*1024*1024), cc.LogQuota.Value()))
+}
+
+func TestDaemonConfigurationMergeAuthorizationPolicy(t *testing.T) {
+	file := fs.NewFile(t, "docker-config", fs.WithContent(`{
+		"authorization-policy": {
+			"default-action": "deny",
+			"rules": [
+				{"name": "updater", "action": "allow", "uids": [1001], "routes": ["/images/create", "/containers/create"], "methods": ["POST"]}
+			]
+		}
+	}`))
+	defer file.Remove()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	cc, err := MergeDaemonConfigurations(&Config{}, flags, file.Path())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(cc.AuthorizationPolicy, &authorization.Policy{
+		DefaultAction: "deny",
+		Rules: []authorization.PolicyRule{{
+			Name:    "updater",
+			Action:  "allow",
+			UIDs:    []uint32{1001},
+			Routes:  []string{"/images/create", "/containers/create"},
+			Methods: []string{"POST"},
+		}},
+	}))
+}
+
 func TestDaemonConfigurationMergeConflictsWithInnerStructs(t *testing.T) {
 	f, err := ioutil.TempFile("", "docker-config-")
 	if err != nil {
@@ -337,6 +377,137 @@ func TestValidateConfigurationErrors(t *testing.T) {
 			},
 			expectedErr: "invalid max download attempts: 0",
 		},
+		{
+			name: "invalid events journal max-size",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventsJournal: EventsJournalConfig{MaxSize: "0"},
+				},
+			},
+			expectedErr: "invalid events journal max-size: 0",
+		},
+		{
+			name: "invalid events journal flush-interval",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventsJournal: EventsJournalConfig{FlushInterval: "10"},
+				},
+			},
+			expectedErr: `invalid events journal flush-interval: time: missing unit in duration "10"`,
+		},
+		{
+			name: "duplicate event sink name",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventSinks: []EventSinkConfig{
+						{Name: "audit", Type: "file", Address: "/var/log/events.log"},
+						{Name: "audit", Type: "file", Address: "/var/log/events2.log"},
+					},
+				},
+			},
+			expectedErr: `duplicate event sink name "audit"`,
+		},
+		{
+			name: "invalid event sink type",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventSinks: []EventSinkConfig{{Name: "audit", Type: "syslog", Address: "/dev/log"}},
+				},
+			},
+			expectedErr: `event sink audit: invalid type "syslog"`,
+		},
+		{
+			name: "invalid event sink webhook URL",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventSinks: []EventSinkConfig{{Name: "hook", Type: "webhook", Address: "ftp://example.com"}},
+				},
+			},
+			expectedErr: `event sink hook: invalid webhook URL "ftp://example.com"`,
+		},
+		{
+			name: "relative event sink socket path",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventSinks: []EventSinkConfig{{Name: "sock", Type: "unixgram", Address: "events.sock"}},
+				},
+			},
+			expectedErr: "event sink sock: address must be an absolute path",
+		},
+		{
+			name: "invalid stats history tier",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					StatsHistory: StatsHistoryConfig{Tiers: []string{"10s"}},
+				},
+			},
+			expectedErr: `invalid stats history tier "10s": expected resolution:retention`,
+		},
+		{
+			name: "unordered stats history tiers",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					StatsHistory: StatsHistoryConfig{Tiers: []string{"1m:24h", "10s:1h"}},
+				},
+			},
+			expectedErr: `invalid stats history tier "10s:1h": tiers must be ordered by increasing resolution`,
+		},
+		{
+			name: "stats history retention shorter than resolution",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					StatsHistory: StatsHistoryConfig{Tiers: []string{"1m:10s"}},
+				},
+			},
+			expectedErr: `invalid stats history tier "1m:10s": retention must be a duration of at least the resolution`,
+		},
+		{
+			name: "container metrics interval too short",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ContainerMetrics: ContainerMetricsConfig{Interval: "100ms"},
+				},
+			},
+			expectedErr: "invalid container-metrics interval 100ms: must be at least 1s",
+		},
+		{
+			name: "conflicting container metrics labels",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ContainerMetrics: ContainerMetricsConfig{Labels: []string{"com.example.team", "com.example-team"}},
+				},
+			},
+			expectedErr: `invalid container-metrics labels "com.example.team" and "com.example-team": both are exported as container_label_com_example_team`,
+		},
+		{
+			name: "invalid network traffic interval",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					NetworkTraffic: NetworkTrafficConfig{Interval: "500ms"},
+				},
+			},
+			expectedErr: "invalid network-traffic interval 500ms: must be at least 1s",
+		},
+		{
+			name: "invalid network traffic persist interval",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					NetworkTraffic: NetworkTrafficConfig{PersistInterval: "-1m"},
+				},
+			},
+			expectedErr: "invalid network-traffic persist-interval: -1m",
+		},
+		{
+			name: "invalid authorization policy rule action",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					AuthorizationPolicy: &authorization.Policy{
+						Rules: []authorization.PolicyRule{{Name: "monitoring", Action: "permit"}},
+					},
+				},
+			},
+			expectedErr: `authorization policy rule monitoring: invalid action "permit"`,
+		},
 		// remove swarm-specific test cases
 	}
 	for _, tc := range testCases {
@@ -415,6 +586,85 @@ func TestValidateConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with events journal",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventsJournal: EventsJournalConfig{Enabled: true, MaxSize: "2m", FlushInterval: "30s"},
+				},
+			},
+		},
+		{
+			name: "with event sinks",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventSinks: []EventSinkConfig{
+						{
+							Name:       "hook",
+							Type:       "webhook",
+							Address:    "https://example.com/events",
+							Filters:    map[string][]string{"type": {"container"}},
+							MaxRetries: -1,
+							Headers:    map[string]string{"Authorization": "Bearer token"},
+						},
+						{Name: "sock", Type: "unixgram", Address: "/run/events.sock", QueueSize: 64},
+						{Name: "file", Type: "file", Address: "/var/log/docker-events.log"},
+					},
+				},
+			},
+		},
+		{
+			name: "with stats history",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					StatsHistory: StatsHistoryConfig{
+						Enabled:         true,
+						Tiers:           []string{"10s:1h", "1m:24h"},
+						Persist:         true,
+						PersistInterval: "5m",
+					},
+				},
+			},
+		},
+		{
+			name: "with container metrics",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ContainerMetrics: ContainerMetricsConfig{
+						Enabled:  true,
+						Interval: "15s",
+						Labels:   []string{"com.example.team", "app"},
+					},
+				},
+			},
+		},
+		{
+			name: "with network traffic accounting",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					NetworkTraffic: NetworkTrafficConfig{
+						Enabled:         true,
+						KeyLabel:        "io.balena.app-id",
+						Interval:        "30s",
+						PersistInterval: "5m",
+					},
+				},
+			},
+		},
+		{
+			name: "with authorization policy",
+			config: &Config{
+				CommonConfig: CommonConfig{
+					AuthorizationPolicy: &authorization.Policy{
+						DefaultAction: "deny",
+						Rules: []authorization.PolicyRule{
+							{Name: "root", Action: "allow", UIDs: []uint32{0}},
+							{Name: "monitoring", Action: "allow", GIDs: []uint32{998}, Methods: []string{"GET"}},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
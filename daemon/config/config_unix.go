@@ -39,6 +39,9 @@ type Config struct {
 	NoNewPrivileges      bool                     `json:"no-new-privileges,omitempty"`
 	IpcMode              string                   `json:"default-ipc-mode,omitempty"`
 	CgroupNamespaceMode  string                   `json:"default-cgroupns-mode,omitempty"`
+	// DeviceHotplug propagates devices plugged in after containers were
+	// started to the containers allowed to access them.
+	DeviceHotplug bool `json:"device-hotplug,omitempty"`
 	// ResolvConf is the path to the configuration of the host resolver
 	ResolvConf string `json:"resolv-conf,omitempty"`
 	Rootless   bool   `json:"rootless,omitempty"`
@@ -0,0 +1,62 @@
+package config // import "github.com/docker/docker/daemon/config"
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultContainerMetricsInterval is the default interval at which the stats
+// of the containers are sampled for the per-container metrics.
+const DefaultContainerMetricsInterval = 10 * time.Second
+
+// ContainerMetricsConfig contains the configuration of the per-container
+// metrics exported on the metrics address.
+type ContainerMetricsConfig struct {
+	// Enabled turns on exporting metrics for each running container.
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval is the interval at which the stats of the containers are
+	// sampled, e.g. "10s".
+	Interval string `json:"interval,omitempty"`
+	// Labels are the container labels added to the metrics, as
+	// "container_label_<name>" with the characters that are invalid in
+	// metric labels replaced with underscores.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// ContainerMetricsLabel returns the name of the metric label exporting the
+// container label l.
+func ContainerMetricsLabel(l string) string {
+	b := []byte(l)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	return "container_label_" + string(b)
+}
+
+// ValidateContainerMetrics validates the per-container metrics
+// configuration.
+func ValidateContainerMetrics(config *Config) error {
+	if config.ContainerMetrics.Interval != "" {
+		d, err := time.ParseDuration(config.ContainerMetrics.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid container-metrics interval: %v", err)
+		}
+		if d < time.Second {
+			return fmt.Errorf("invalid container-metrics interval %s: must be at least 1s", config.ContainerMetrics.Interval)
+		}
+	}
+	seen := make(map[string]string)
+	for _, l := range config.ContainerMetrics.Labels {
+		if l == "" {
+			return fmt.Errorf("invalid container-metrics label: label must not be empty")
+		}
+		name := ContainerMetricsLabel(l)
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("invalid container-metrics labels %q and %q: both are exported as %s", other, l, name)
+		}
+		seen[name] = l
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package config // import "github.com/docker/docker/daemon/config"
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	units "github.com/docker/go-units"
+)
+
+// EventsJournalConfig contains the configuration of the on-disk events
+// journal.
+type EventsJournalConfig struct {
+	// Enabled turns on recording events to the journal.
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxSize is the maximum size of the journal on disk, e.g. "10m".
+	MaxSize string `json:"max-size,omitempty"`
+	// FlushInterval is the maximum time events are buffered in memory
+	// before being written and synced to disk, e.g. "10s".
+	FlushInterval string `json:"flush-interval,omitempty"`
+}
+
+// ValidateEventsJournal validates the events journal configuration.
+func ValidateEventsJournal(config *Config) error {
+	if config.EventsJournal.MaxSize != "" {
+		size, err := units.RAMInBytes(config.EventsJournal.MaxSize)
+		if err != nil {
+			return fmt.Errorf("invalid events journal max-size: %v", err)
+		}
+		if size <= 0 {
+			return fmt.Errorf("invalid events journal max-size: %s", config.EventsJournal.MaxSize)
+		}
+	}
+	if config.EventsJournal.FlushInterval != "" {
+		d, err := time.ParseDuration(config.EventsJournal.FlushInterval)
+		if err != nil {
+			return fmt.Errorf("invalid events journal flush-interval: %v", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid events journal flush-interval: %s", config.EventsJournal.FlushInterval)
+		}
+	}
+	return nil
+}
+
+var validEventSinkName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// EventSinkConfig contains the configuration of a destination events are
+// forwarded to.
+type EventSinkConfig struct {
+	// Name identifies the sink. It must be unique.
+	Name string `json:"name"`
+	// Type is the type of the sink: "webhook", "unixgram" or "file".
+	Type string `json:"type"`
+	// Address is the URL of a webhook, the path of a unix datagram socket,
+	// or the path of the file events are appended to.
+	Address string `json:"address"`
+	// Filters selects the events forwarded to the sink, using the same
+	// filters as the events API.
+	Filters map[string][]string `json:"filters,omitempty"`
+	// QueueSize is the maximum number of events waiting to be delivered.
+	QueueSize int `json:"queue-size,omitempty"`
+	// MaxRetries is the number of times delivering an event is retried
+	// before it is dropped. Zero uses the default, a negative value retries
+	// indefinitely.
+	MaxRetries int `json:"max-retries,omitempty"`
+	// Headers are added to the requests sent to a webhook.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ValidateEventSinks validates the configuration of event sinks.
+func ValidateEventSinks(config *Config) error {
+	names := map[string]bool{}
+	for _, s := range config.EventSinks {
+		if !validEventSinkName.MatchString(s.Name) {
+			return fmt.Errorf("invalid event sink name %q", s.Name)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate event sink name %q", s.Name)
+		}
+		names[s.Name] = true
+
+		if s.Address == "" {
+			return fmt.Errorf("event sink %s: address is required", s.Name)
+		}
+		switch s.Type {
+		case "webhook":
+			u, err := url.Parse(s.Address)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("event sink %s: invalid webhook URL %q", s.Name, s.Address)
+			}
+		case "unixgram", "file":
+			if !filepath.IsAbs(s.Address) {
+				return fmt.Errorf("event sink %s: address must be an absolute path", s.Name)
+			}
+			if len(s.Headers) > 0 {
+				return fmt.Errorf("event sink %s: headers are only supported by webhooks", s.Name)
+			}
+		default:
+			return fmt.Errorf("event sink %s: invalid type %q", s.Name, s.Type)
+		}
+		if s.QueueSize < 0 {
+			return fmt.Errorf("event sink %s: invalid queue-size %d", s.Name, s.QueueSize)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package config // import "github.com/docker/docker/daemon/config"
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultNetworkTrafficInterval is the default interval at which the
+// network traffic of the containers is sampled.
+const DefaultNetworkTrafficInterval = 10 * time.Second
+
+// NetworkTrafficConfig contains the configuration of the accounting of the
+// network traffic of containers, kept across restarts and recreations of
+// the containers.
+type NetworkTrafficConfig struct {
+	// Enabled turns on accounting the network traffic of running
+	// containers.
+	Enabled bool `json:"enabled,omitempty"`
+	// KeyLabel is the container label the traffic is accounted under.
+	// Containers without the label, or all the containers if it is not set,
+	// are accounted under their name.
+	KeyLabel string `json:"key-label,omitempty"`
+	// Interval is the interval at which the traffic is sampled, e.g.
+	// "10s". The traffic of a container between its last sample and its
+	// exit isn't accounted.
+	Interval string `json:"interval,omitempty"`
+	// PersistInterval is the interval at which the totals are saved, e.g.
+	// "1m". They are also saved on shutdown.
+	PersistInterval string `json:"persist-interval,omitempty"`
+}
+
+// ValidateNetworkTraffic validates the network traffic accounting
+// configuration.
+func ValidateNetworkTraffic(config *Config) error {
+	if config.NetworkTraffic.Interval != "" {
+		d, err := time.ParseDuration(config.NetworkTraffic.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid network-traffic interval: %v", err)
+		}
+		if d < time.Second {
+			return fmt.Errorf("invalid network-traffic interval %s: must be at least 1s", config.NetworkTraffic.Interval)
+		}
+	}
+	if config.NetworkTraffic.PersistInterval != "" {
+		d, err := time.ParseDuration(config.NetworkTraffic.PersistInterval)
+		if err != nil {
+			return fmt.Errorf("invalid network-traffic persist-interval: %v", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid network-traffic persist-interval: %s", config.NetworkTraffic.PersistInterval)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package config // import "github.com/docker/docker/daemon/config"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatsHistoryConfig contains the configuration of the history of
+// container stats kept by the daemon.
+type StatsHistoryConfig struct {
+	// Enabled turns on recording the stats of running containers.
+	Enabled bool `json:"enabled,omitempty"`
+	// Tiers are the "resolution:retention" levels of downsampling, from the
+	// finest to the coarsest, e.g. ["10s:1h", "1m:24h"].
+	Tiers []string `json:"tiers,omitempty"`
+	// Persist saves the history to disk, so that it is kept across daemon
+	// restarts.
+	Persist bool `json:"persist,omitempty"`
+	// PersistInterval is the interval at which the history is saved, e.g.
+	// "5m". It is also saved on shutdown.
+	PersistInterval string `json:"persist-interval,omitempty"`
+}
+
+// ParseStatsHistoryTier parses a "resolution:retention" stats history tier.
+func ParseStatsHistoryTier(tier string) (resolution, retention time.Duration, err error) {
+	parts := strings.SplitN(tier, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid stats history tier %q: expected resolution:retention", tier)
+	}
+	if resolution, err = time.ParseDuration(parts[0]); err != nil || resolution < time.Second {
+		return 0, 0, fmt.Errorf("invalid stats history tier %q: resolution must be a duration of at least 1s", tier)
+	}
+	if retention, err = time.ParseDuration(parts[1]); err != nil || retention < resolution {
+		return 0, 0, fmt.Errorf("invalid stats history tier %q: retention must be a duration of at least the resolution", tier)
+	}
+	return resolution, retention, nil
+}
+
+// ValidateStatsHistory validates the stats history configuration.
+func ValidateStatsHistory(config *Config) error {
+	var previous time.Duration
+	for _, tier := range config.StatsHistory.Tiers {
+		resolution, _, err := ParseStatsHistoryTier(tier)
+		if err != nil {
+			return err
+		}
+		if resolution <= previous {
+			return fmt.Errorf("invalid stats history tier %q: tiers must be ordered by increasing resolution", tier)
+		}
+		previous = resolution
+	}
+	if config.StatsHistory.PersistInterval != "" {
+		d, err := time.ParseDuration(config.StatsHistory.PersistInterval)
+		if err != nil {
+			return fmt.Errorf("invalid stats history persist-interval: %v", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid stats history persist-interval: %s", config.StatsHistory.PersistInterval)
+		}
+	}
+	return nil
+}
@@ -1,7 +1,11 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
 	swarmtypes "github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,3 +23,69 @@ func (daemon *Daemon) SetContainerConfigReferences(name string, refs []*swarmtyp
 	c.ConfigReferences = append(c.ConfigReferences, refs...)
 	return nil
 }
+
+// GetConfigs returns the configs stored by the daemon matching the filters.
+func (daemon *Daemon) GetConfigs(opts types.ConfigListOptions) ([]swarmtypes.Config, error) {
+	if daemon.secrets == nil {
+		return nil, errSecretStoreNotConfigured
+	}
+	return daemon.secrets.ListConfigs(opts.Filters)
+}
+
+// CreateConfig stores a new config and returns its ID.
+func (daemon *Daemon) CreateConfig(spec swarmtypes.ConfigSpec) (string, error) {
+	if daemon.secrets == nil {
+		return "", errSecretStoreNotConfigured
+	}
+	id, err := daemon.secrets.CreateConfig(spec)
+	if err != nil {
+		return "", err
+	}
+	daemon.LogConfigEvent(id, spec.Name, "create")
+	return id, nil
+}
+
+// GetConfig returns the config with the given ID or name.
+func (daemon *Daemon) GetConfig(idOrName string) (swarmtypes.Config, error) {
+	if daemon.secrets == nil {
+		return swarmtypes.Config{}, errSecretStoreNotConfigured
+	}
+	return daemon.secrets.GetConfig(idOrName)
+}
+
+// UpdateConfig updates the labels of a config.
+func (daemon *Daemon) UpdateConfig(idOrName string, version uint64, spec swarmtypes.ConfigSpec) error {
+	if daemon.secrets == nil {
+		return errSecretStoreNotConfigured
+	}
+	config, err := daemon.secrets.GetConfig(idOrName)
+	if err != nil {
+		return err
+	}
+	if err := daemon.secrets.UpdateConfig(config.ID, version, spec); err != nil {
+		return err
+	}
+	daemon.LogConfigEvent(config.ID, config.Spec.Name, "update")
+	return nil
+}
+
+// RemoveConfig removes a config which is not referenced by any container.
+func (daemon *Daemon) RemoveConfig(idOrName string) error {
+	if daemon.secrets == nil {
+		return errSecretStoreNotConfigured
+	}
+	config, err := daemon.secrets.GetConfig(idOrName)
+	if err != nil {
+		return err
+	}
+	if users := daemon.fileReferenceUsers(config.ID, config.Spec.Name, func(hc *containertypes.HostConfig) []containertypes.FileReference {
+		return hc.Configs
+	}); len(users) > 0 {
+		return errdefs.Conflict(errors.Errorf("config %s is in use by the following containers: %v", config.Spec.Name, users))
+	}
+	if err := daemon.secrets.RemoveConfig(config.ID); err != nil {
+		return err
+	}
+	daemon.LogConfigEvent(config.ID, config.Spec.Name, "remove")
+	return nil
+}
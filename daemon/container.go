@@ -298,6 +298,12 @@ func validateHostConfig(hostConfig *containertypes.HostConfig, platform string)
 	if err := validateCapabilities(hostConfig); err != nil {
 		return err
 	}
+	if err := validateNetworkBandwidth(&hostConfig.Resources, hostConfig.NetworkMode, platform); err != nil {
+		return err
+	}
+	if err := validateEgressPolicy(hostConfig, platform); err != nil {
+		return err
+	}
 	if !hostConfig.Isolation.IsValid() {
 		return errors.Errorf("invalid isolation '%s' on %s", hostConfig.Isolation, runtime.GOOS)
 	}
@@ -332,6 +338,11 @@ func validateHealthCheck(healthConfig *containertypes.HealthConfig) error {
 	if healthConfig.StartPeriod != 0 && healthConfig.StartPeriod < containertypes.MinimumDuration {
 		return errors.Errorf("StartPeriod in Healthcheck cannot be less than %s", containertypes.MinimumDuration)
 	}
+	if isNetworkProbe(healthConfig.Test) {
+		if _, err := parseNetworkProbe(healthConfig.Test); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -363,10 +374,26 @@ func validateRestartPolicy(policy containertypes.RestartPolicy) error {
 		}
 	case "":
 		// do nothing
-		return nil
 	default:
 		return errors.Errorf("invalid restart policy '%s'", policy.Name)
 	}
+	if policy.InitialDelay < 0 || policy.MaxDelay < 0 || policy.ResetAfter < 0 {
+		return errors.Errorf("restart delays cannot be negative")
+	}
+	if policy.MaxDelay != 0 && policy.InitialDelay > policy.MaxDelay {
+		return errors.Errorf("initial restart delay cannot be greater than maximum restart delay")
+	}
+	if policy.BackoffMultiplier != 0 && policy.BackoffMultiplier < 1 {
+		return errors.Errorf("restart backoff multiplier cannot be less than 1")
+	}
+	if u := policy.OnUnhealthy; u != nil {
+		if u.GracePeriod < 0 {
+			return errors.Errorf("unhealthy restart grace period cannot be negative")
+		}
+		if u.MaximumRestartCount < 0 {
+			return errors.Errorf("maximum unhealthy restart count cannot be negative")
+		}
+	}
 	return nil
 }
 
@@ -836,6 +836,13 @@ func (daemon *Daemon) connectToNetwork(container *container.Container, idOrName
 		return fmt.Errorf("Updating join info failed: %v", err)
 	}
 
+	if err := daemon.applyNetworkBandwidth(container, sb); err != nil {
+		if e := ep.Leave(sb); e != nil {
+			logrus.WithError(e).Warnf("Could not leave network %s after failing to shape the traffic of container %s", idOrName, container.ID)
+		}
+		return fmt.Errorf("Shaping the network traffic failed: %v", err)
+	}
+
 	container.NetworkSettings.Ports = getPortMapInfo(sb)
 
 	daemon.LogNetworkEventWithAttributes(n, "connect", map[string]string{"container": container.ID})
@@ -935,6 +942,11 @@ func (daemon *Daemon) disconnectFromNetwork(container *container.Container, n li
 
 	delete(container.NetworkSettings.Networks, n.Name())
 
+	// Forget the shaping of the interface removed with the endpoint.
+	if err := daemon.applyNetworkBandwidth(container, sbox); err != nil {
+		logrus.WithError(err).Warnf("failed to update the network traffic shaping of container %s", container.ID)
+	}
+
 	daemon.tryDetachContainerFromClusterNetwork(n, container)
 
 	return nil
@@ -1021,6 +1033,7 @@ func (daemon *Daemon) releaseNetwork(container *container.Container) {
 	sid := container.NetworkSettings.SandboxID
 	settings := container.NetworkSettings.Networks
 	container.NetworkSettings.Ports = nil
+	container.NetworkSettings.Bandwidth = nil
 
 	if sid == "" {
 		return
@@ -5,6 +5,7 @@ package daemon // import "github.com/docker/docker/daemon"
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strconv"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/libnetwork"
+	"github.com/moby/sys/mount"
 	"github.com/opencontainers/selinux/go-selinux/label"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -158,15 +160,159 @@ func (daemon *Daemon) setupIpcDirs(c *container.Container) error {
 }
 
 func (daemon *Daemon) setupSecretDir(c *container.Container) (setupErr error) {
+	if err := daemon.resolveFileReferences(c); err != nil {
+		return err
+	}
 	if len(c.SecretReferences) == 0 && len(c.ConfigReferences) == 0 {
 		return nil
 	}
+	if daemon.secrets == nil {
+		return errSecretStoreNotConfigured
+	}
+
+	if err := daemon.createSecretsDir(c); err != nil {
+		return err
+	}
+	defer func() {
+		if setupErr != nil {
+			daemon.cleanupSecretDir(c)
+		}
+	}()
+
+	// retrieve possible remapped range start for root UID, GID
+	rootIDs := daemon.idMapping.RootPair()
+
+	for _, s := range c.SecretReferences {
+		if s.File == nil {
+			logrus.Error("secret target type is not a file target")
+			continue
+		}
+
+		// secrets are created in the SecretMountPath on the host, at a
+		// single level
+		fPath, err := c.SecretFilePath(*s)
+		if err != nil {
+			return errors.Wrap(err, "error getting secret file path")
+		}
+		logrus.WithFields(logrus.Fields{
+			"name": s.File.Name,
+			"path": fPath,
+		}).Debug("injecting secret")
+		data, err := daemon.secrets.SecretData(s.SecretID)
+		if err != nil {
+			return errors.Wrap(err, "unable to get secret from secret store")
+		}
+		if err := writeSecretFile(fPath, data, s.File.UID, s.File.GID, s.File.Mode, rootIDs); err != nil {
+			return errors.Wrapf(err, "error injecting secret %s", s.SecretName)
+		}
+	}
+
+	for _, configRef := range c.ConfigReferences {
+		if configRef.File == nil {
+			// Runtime configs are not mounted into the container, but they're
+			// a valid type of config so we should not error when we encounter
+			// one.
+			if configRef.Runtime == nil {
+				logrus.Error("config target type is not a file or runtime target")
+			}
+			continue
+		}
+
+		// configs are created in the SecretMountPath on the host, at a
+		// single level
+		fPath, err := c.ConfigFilePath(*configRef)
+		if err != nil {
+			return errors.Wrap(err, "error getting config file path")
+		}
+		logrus.WithFields(logrus.Fields{
+			"name": configRef.File.Name,
+			"path": fPath,
+		}).Debug("injecting config")
+		data, err := daemon.secrets.ConfigData(configRef.ConfigID)
+		if err != nil {
+			return errors.Wrap(err, "unable to get config from config store")
+		}
+		if err := writeSecretFile(fPath, data, configRef.File.UID, configRef.File.GID, configRef.File.Mode, rootIDs); err != nil {
+			return errors.Wrapf(err, "error injecting config %s", configRef.ConfigName)
+		}
+	}
+
+	return daemon.remountSecretDir(c)
+}
+
+// writeSecretFile writes the data of a secret or config to the secrets
+// directory of a container, owned by uid and gid in the container.
+func writeSecretFile(fPath string, data []byte, uid, gid string, mode os.FileMode, rootIDs idtools.Identity) error {
+	u, err := strconv.Atoi(uid)
+	if err != nil {
+		return err
+	}
+	g, err := strconv.Atoi(gid)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fPath, data, mode); err != nil {
+		return err
+	}
+	if err := os.Chown(fPath, rootIDs.UID+u, rootIDs.GID+g); err != nil {
+		return errors.Wrap(err, "error setting ownership")
+	}
+	return errors.Wrap(os.Chmod(fPath, mode), "error setting file mode")
+}
+
+// createSecretsDir is used to create a dir suitable for storing container secrets.
+// In practice this is using a tmpfs mount and is used for both "configs" and "secrets"
+func (daemon *Daemon) createSecretsDir(c *container.Container) error {
+	// retrieve possible remapped range start for root UID, GID
+	rootIDs := daemon.idMapping.RootPair()
+	dir, err := c.SecretMountPath()
+	if err != nil {
+		return errors.Wrap(err, "error getting container secrets dir")
+	}
+
+	// create tmpfs
+	if err := idtools.MkdirAllAndChown(dir, 0700, rootIDs); err != nil {
+		return errors.Wrap(err, "error creating secret local mount path")
+	}
 
-	return fmt.Errorf("secret store is not initialized")
+	tmpfsOwnership := fmt.Sprintf("uid=%d,gid=%d", rootIDs.UID, rootIDs.GID)
+	if err := mount.Mount("tmpfs", dir, "tmpfs", "nodev,nosuid,noexec,"+tmpfsOwnership); err != nil {
+		return errors.Wrap(err, "unable to setup secret mount")
+	}
+	return nil
+}
+
+func (daemon *Daemon) remountSecretDir(c *container.Container) error {
+	dir, err := c.SecretMountPath()
+	if err != nil {
+		return errors.Wrap(err, "error getting container secrets path")
+	}
+	if err := label.Relabel(dir, c.MountLabel, false); err != nil {
+		logrus.WithError(err).WithField("dir", dir).Warn("Error while attempting to set selinux label")
+	}
+	rootIDs := daemon.idMapping.RootPair()
+	tmpfsOwnership := fmt.Sprintf("uid=%d,gid=%d", rootIDs.UID, rootIDs.GID)
+
+	// remount secrets ro
+	if err := mount.Mount("tmpfs", dir, "tmpfs", "remount,ro,"+tmpfsOwnership); err != nil {
+		return errors.Wrap(err, "unable to remount dir as readonly")
+	}
+
+	return nil
 }
 
 func (daemon *Daemon) cleanupSecretDir(c *container.Container) {
-	// noop
+	dir, err := c.SecretMountPath()
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.ID).Warn("error getting secrets mount path for container")
+		return
+	}
+	if err := mount.RecursiveUnmount(dir); err != nil {
+		logrus.WithField("dir", dir).WithError(err).Warn("Error while attempting to unmount dir, this may prevent removal of container.")
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		logrus.WithField("dir", dir).WithError(err).Error("Error removing secret mount path")
+	}
 }
 
 func killProcessDirectly(container *container.Container) error {
@@ -103,6 +103,12 @@ func (daemon *Daemon) containerCreate(opts createOpts) (containertypes.Container
 	if opts.params.HostConfig == nil {
 		opts.params.HostConfig = &containertypes.HostConfig{}
 	}
+	if err := daemon.validateDependsOn(opts.params.Name, opts.params.HostConfig.DependsOn); err != nil {
+		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, errdefs.InvalidParameter(err)
+	}
+	if err := daemon.validateFileReferences(opts.params.HostConfig); err != nil {
+		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, errdefs.InvalidParameter(err)
+	}
 	err = daemon.adaptContainerSettings(opts.params.HostConfig, opts.params.AdjustCPUShares)
 	if err != nil {
 		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, errdefs.InvalidParameter(err)
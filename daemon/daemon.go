@@ -36,10 +36,12 @@ import (
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/discovery"
+	"github.com/docker/docker/daemon/egress"
 	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/daemon/images"
 	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
 	"github.com/docker/docker/daemon/network"
 	"github.com/docker/docker/errdefs"
 	bkconfig "github.com/moby/buildkit/cmd/buildkitd/config"
@@ -48,6 +50,7 @@ import (
 
 	// register graph drivers
 	_ "github.com/docker/docker/daemon/graphdriver/register"
+	"github.com/docker/docker/daemon/secretstore"
 	"github.com/docker/docker/daemon/stats"
 	dmetadata "github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/dockerversion"
@@ -97,8 +100,15 @@ type Daemon struct {
 	defaultLogConfig  containertypes.LogConfig
 	RegistryService   registry.Service
 	EventsService     *events.Events
+	eventsJournal     *events.Journal
+	eventForwardersMu sync.Mutex
+	eventForwarders   []*events.Forwarder
+	hotplug           *deviceHotplug
 	netController     libnetwork.NetworkController
 	volumes           *volumesservice.VolumesService
+	secrets           *secretstore.Store
+	egressMu          sync.Mutex
+	egressEnforcers   map[string]*egress.Enforcer
 	discoveryWatcher  discovery.Reloader
 	root              string
 	seccompEnabled    bool
@@ -509,7 +519,15 @@ func (daemon *Daemon) restore() error {
 	}
 	group.Wait()
 
+	// Containers with dependencies are started in the background once the
+	// daemon is up, so that waiting for their dependencies doesn't hold up
+	// the daemon start.
+	dependents := make(map[*container.Container]chan struct{})
 	for c, notifier := range restartContainers {
+		if len(c.HostConfig.DependsOn) > 0 {
+			dependents[c] = notifier
+			continue
+		}
 		group.Add(1)
 		go func(c *container.Container, chNotify chan struct{}) {
 			_ = sem.Acquire(context.Background(), 1)
@@ -592,6 +610,8 @@ func (daemon *Daemon) restore() error {
 	}
 	group.Wait()
 
+	daemon.startDependents(dependents, restartContainers)
+
 	logrus.Info("Loading containers: done.")
 
 	return nil
@@ -1081,11 +1101,22 @@ func NewDaemon(ctx context.Context, config *config.Config, pluginStore *plugin.S
 		return nil, err
 	}
 
-	d.volumes, err = volumesservice.NewVolumeService(config.Root, d.PluginStore, rootIDs, d)
+	d.volumes, err = volumesservice.NewVolumeService(config.Root, d.PluginStore, idMapping, d)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.SecretStoreKey != "" {
+		key, err := secretstore.LoadKey(config.SecretStoreKey)
+		if err != nil {
+			return nil, err
+		}
+		d.secrets, err = secretstore.New(filepath.Join(config.Root, "secrets"), key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	trustKey, err := loadOrCreateTrustKey(config.TrustKeyPath)
 	if err != nil {
 		return nil, err
@@ -1140,8 +1171,31 @@ func NewDaemon(ctx context.Context, config *config.Config, pluginStore *plugin.S
 	d.execCommands = exec.NewStore()
 	d.idIndex = truncindex.NewTruncIndex([]string{})
 	d.statsCollector = d.newStatsCollector(1 * time.Second)
+	if config.StatsHistory.Enabled {
+		if err := d.initStatsHistory(config); err != nil {
+			return nil, err
+		}
+	}
+	if config.ContainerMetrics.Enabled {
+		if err := d.initContainerMetrics(config); err != nil {
+			return nil, err
+		}
+	}
+	if config.NetworkTraffic.Enabled {
+		if err := d.initNetworkTraffic(config); err != nil {
+			return nil, err
+		}
+	}
 
 	d.EventsService = events.New()
+	if config.EventsJournal.Enabled {
+		if err := d.initEventsJournal(config); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.startEventForwarders(config.Root, config.EventSinks); err != nil {
+		return nil, err
+	}
 	d.root = config.Root
 	d.idMapping = idMapping
 	d.seccompEnabled = sysInfo.Seccomp
@@ -1196,6 +1250,12 @@ func NewDaemon(ctx context.Context, config *config.Config, pluginStore *plugin.S
 	if err := d.restore(); err != nil {
 		return nil, err
 	}
+	d.trackRestoredContainers()
+	d.restoreEgressPolicies()
+	d.initLogQuota(config)
+	if err := d.startDeviceHotplug(config); err != nil {
+		return nil, err
+	}
 	close(d.startupDone)
 
 	info := d.SystemInfo()
@@ -1288,6 +1348,17 @@ func (daemon *Daemon) ShutdownTimeout() int {
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	defer daemon.closeEventsJournal()
+	defer func() {
+		daemon.eventForwardersMu.Lock()
+		daemon.stopEventForwarders()
+		daemon.eventForwardersMu.Unlock()
+	}()
+	defer daemon.closeStatsHistory()
+	// Write the logs buffered in memory, notably those of the containers
+	// kept running with live-restore.
+	defer loggerutils.FlushBuffers()
+	daemon.stopDeviceHotplug()
 	// Keep mounts and networking running on daemon shutdown if
 	// we are to keep containers running and restore them.
 
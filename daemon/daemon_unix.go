@@ -1398,6 +1398,7 @@ func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
 	case *statsV1.Metrics:
 		return daemon.statsV1(s, t)
 	case *statsV2.Metrics:
+		addCgroup2Stats(c, s)
 		return daemon.statsV2(s, t)
 	default:
 		return nil, errors.Errorf("unexpected type of metrics %+v", t)
@@ -1520,6 +1521,7 @@ func (daemon *Daemon) statsV2(s *types.StatsJSON, stats *statsV2.Metrics) (*type
 		s.BlkioStats = types.BlkioStats{
 			IoServiceBytesRecursive: isbr,
 			// Other fields are unsupported
+			IoStat: s.BlkioStats.IoStat,
 		}
 	}
 
@@ -1587,6 +1589,13 @@ func (daemon *Daemon) statsV2(s *types.StatsJSON, stats *statsV2.Metrics) (*type
 			// Failcnt is set to the "oom" field of the "memory.events" file.
 			// See https://www.kernel.org/doc/html/latest/admin-guide/cgroup-v2.html
 			s.MemoryStats.Failcnt = stats.MemoryEvents.Oom
+			s.MemoryStats.Events = &types.MemoryEvents{
+				Low:     stats.MemoryEvents.Low,
+				High:    stats.MemoryEvents.High,
+				Max:     stats.MemoryEvents.Max,
+				Oom:     stats.MemoryEvents.Oom,
+				OomKill: stats.MemoryEvents.OomKill,
+			}
 		}
 	}
 
@@ -0,0 +1,183 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultDependencyTimeout is the time to wait for a dependency to meet
+	// its condition, after which the container is started anyway.
+	defaultDependencyTimeout = 2 * time.Minute
+
+	// dependencyPollInterval is the interval at which the state of
+	// dependencies is checked.
+	dependencyPollInterval = 250 * time.Millisecond
+)
+
+// validateDependsOn validates the dependencies of a container being created
+// with the given name, and makes sure they do not form a cycle.
+func (daemon *Daemon) validateDependsOn(name string, deps []containertypes.Dependency) error {
+	for _, dep := range deps {
+		if dep.Container == "" {
+			return errors.New("dependency container name cannot be empty")
+		}
+		switch dep.Condition {
+		case "", containertypes.DependencyStarted, containertypes.DependencyHealthy, containertypes.DependencyExitedSuccessfully:
+		default:
+			return errors.Errorf("invalid condition %q for dependency %s", dep.Condition, dep.Container)
+		}
+		if dep.Timeout < 0 {
+			return errors.Errorf("timeout for dependency %s cannot be negative", dep.Container)
+		}
+		if _, err := daemon.GetContainer(dep.Container); err != nil {
+			return errors.Wrapf(err, "invalid dependency %s", dep.Container)
+		}
+	}
+
+	if name == "" {
+		// No existing container can depend on a container with a
+		// generated name.
+		return nil
+	}
+	name = "/" + strings.TrimPrefix(name, "/")
+
+	visited := map[string]bool{}
+	var visit func(deps []containertypes.Dependency, path []string) error
+	visit = func(deps []containertypes.Dependency, path []string) error {
+		for _, dep := range deps {
+			if "/"+strings.TrimPrefix(dep.Container, "/") == name {
+				return errors.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+			}
+			c, err := daemon.GetContainer(dep.Container)
+			if err != nil || visited[c.ID] {
+				continue
+			}
+			visited[c.ID] = true
+			if err := visit(c.HostConfig.DependsOn, append(path, c.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit(deps, []string{name})
+}
+
+// waitDependencies waits for the dependencies of c to meet their condition,
+// or for their timeout to expire. scheduled, if not nil, reports whether a
+// dependency that is not running yet is about to be started by the caller.
+// Dependencies that are not running, not restarting and not scheduled are
+// not waited for, unless their condition is already met.
+func (daemon *Daemon) waitDependencies(c *container.Container, scheduled func(*container.Container) bool) {
+	for _, dep := range c.HostConfig.DependsOn {
+		log := logrus.WithFields(logrus.Fields{
+			"container":  c.ID,
+			"dependency": dep.Container,
+			"condition":  dep.Condition,
+		})
+
+		timeout := dep.Timeout
+		if timeout == 0 {
+			timeout = defaultDependencyTimeout
+		}
+		deadline := time.Now().Add(timeout)
+
+		for {
+			if daemon.IsShuttingDown() {
+				return
+			}
+			depCtr, err := daemon.GetContainer(dep.Container)
+			if err != nil {
+				log.WithError(err).Warn("dependency not found, ignoring")
+				break
+			}
+			met, pending := dependencyState(depCtr, dep.Condition)
+			if met {
+				log.Debug("dependency condition met")
+				break
+			}
+			if !pending && (scheduled == nil || !scheduled(depCtr)) {
+				log.Warn("dependency is not running and will not be started, starting container anyway")
+				break
+			}
+			if time.Now().After(deadline) {
+				log.Warnf("dependency condition not met after %v, starting container anyway", timeout)
+				break
+			}
+			time.Sleep(dependencyPollInterval)
+		}
+	}
+}
+
+// startDependents starts the restored containers with dependencies in the
+// background, once the daemon start is done, after waiting for their
+// dependencies. restartContainers are all the restored containers to start,
+// with the channels closed once they were started.
+func (daemon *Daemon) startDependents(dependents, restartContainers map[*container.Container]chan struct{}) {
+	if len(dependents) == 0 {
+		return
+	}
+
+	// scheduled reports whether a container is about to be started.
+	scheduled := func(c *container.Container) bool {
+		notifier, ok := restartContainers[c]
+		if !ok {
+			return false
+		}
+		select {
+		case <-notifier:
+			return false
+		default:
+			return true
+		}
+	}
+
+	go func() {
+		daemon.waitForStartupDone()
+		for c, notifier := range dependents {
+			go func(c *container.Container, chNotify chan struct{}) {
+				defer close(chNotify)
+
+				daemon.waitDependencies(c, scheduled)
+				if daemon.IsShuttingDown() {
+					return
+				}
+
+				log := logrus.WithField("container", c.ID)
+				log.Debug("starting container")
+				daemon.waitForNetworks(c)
+				if err := daemon.containerStart(c, "", "", true); err != nil {
+					log.WithError(err).Error("failed to start container")
+				}
+			}(c, notifier)
+		}
+	}()
+}
+
+// dependencyState returns whether dep meets condition, and whether it may
+// still meet it without being started.
+func dependencyState(dep *container.Container, condition containertypes.DependencyCondition) (met, pending bool) {
+	dep.Lock()
+	defer dep.Unlock()
+
+	pending = dep.Running || dep.Restarting
+	switch condition {
+	case containertypes.DependencyExitedSuccessfully:
+		met = !dep.Running && !dep.Restarting && dep.HasBeenStartedBefore && dep.ExitCodeValue == 0
+	case containertypes.DependencyHealthy:
+		if dep.Health == nil {
+			met = dep.Running
+		} else {
+			met = dep.Running && dep.Health.Status() == types.Healthy
+		}
+	default:
+		met = dep.Running
+	}
+	return met, pending
+}
@@ -0,0 +1,92 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/truncindex"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newDependsOnTestDaemon(t *testing.T, containers ...*container.Container) *Daemon {
+	containersReplica, err := container.NewViewDB()
+	assert.NilError(t, err)
+	daemon := &Daemon{
+		containers:        container.NewMemoryStore(),
+		containersReplica: containersReplica,
+		idIndex:           truncindex.NewTruncIndex([]string{}),
+	}
+	for _, c := range containers {
+		daemon.containers.Add(c.ID, c)
+		assert.NilError(t, daemon.idIndex.Add(c.ID))
+		_, err := daemon.reserveName(c.ID, c.Name)
+		assert.NilError(t, err)
+	}
+	return daemon
+}
+
+func newDependsOnTestContainer(id, name string, deps ...containertypes.Dependency) *container.Container {
+	return &container.Container{
+		ID:         id,
+		Name:       name,
+		State:      container.NewState(),
+		HostConfig: &containertypes.HostConfig{DependsOn: deps},
+	}
+}
+
+func TestValidateDependsOn(t *testing.T) {
+	db := newDependsOnTestContainer("1111111111", "/db")
+	app := newDependsOnTestContainer("2222222222", "/app", containertypes.Dependency{Container: "db", Condition: containertypes.DependencyHealthy})
+	// proxy depends on a container that does not exist yet, e.g. because
+	// it was removed and is being recreated.
+	proxy := newDependsOnTestContainer("3333333333", "/proxy", containertypes.Dependency{Container: "app"}, containertypes.Dependency{Container: "web"})
+	daemon := newDependsOnTestDaemon(t, db, app, proxy)
+
+	assert.NilError(t, daemon.validateDependsOn("web", []containertypes.Dependency{{Container: "app"}}))
+	assert.NilError(t, daemon.validateDependsOn("", []containertypes.Dependency{{Container: "proxy"}}))
+
+	err := daemon.validateDependsOn("web", []containertypes.Dependency{{Container: "db"}, {Container: "proxy"}})
+	assert.Check(t, is.Error(err, "dependency cycle: /web -> /proxy -> /web"))
+
+	err = daemon.validateDependsOn("web", []containertypes.Dependency{{Container: "web"}})
+	assert.Check(t, is.ErrorContains(err, "invalid dependency web"))
+
+	err = daemon.validateDependsOn("web", []containertypes.Dependency{{Container: "db", Condition: "ready"}})
+	assert.Check(t, is.Error(err, `invalid condition "ready" for dependency db`))
+
+	err = daemon.validateDependsOn("web", []containertypes.Dependency{{Container: "db", Timeout: -time.Second}})
+	assert.Check(t, is.Error(err, "timeout for dependency db cannot be negative"))
+}
+
+func TestDependencyState(t *testing.T) {
+	dep := newDependsOnTestContainer("1111111111", "/db")
+
+	met, pending := dependencyState(dep, containertypes.DependencyStarted)
+	assert.Check(t, !met)
+	assert.Check(t, !pending)
+
+	dep.SetRunning(42, true)
+	met, pending = dependencyState(dep, containertypes.DependencyStarted)
+	assert.Check(t, met)
+	assert.Check(t, pending)
+
+	dep.Health = &container.Health{}
+	dep.Health.SetStatus(types.Starting)
+	met, _ = dependencyState(dep, containertypes.DependencyHealthy)
+	assert.Check(t, !met)
+	dep.Health.SetStatus(types.Healthy)
+	met, _ = dependencyState(dep, containertypes.DependencyHealthy)
+	assert.Check(t, met)
+
+	met, _ = dependencyState(dep, containertypes.DependencyExitedSuccessfully)
+	assert.Check(t, !met)
+	dep.HasBeenStartedBefore = true
+	dep.SetStopped(&container.ExitStatus{ExitCode: 0})
+	met, pending = dependencyState(dep, containertypes.DependencyExitedSuccessfully)
+	assert.Check(t, met)
+	assert.Check(t, !pending)
+}
@@ -0,0 +1,380 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/oci"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// ueventKernelGroup and ueventUdevGroup are the netlink groups of the
+	// uevents sent by the kernel, and relayed by udev once it processed them.
+	ueventKernelGroup = 1
+	ueventUdevGroup   = 2
+
+	// udevMonitorMagic identifies the messages relayed by udev.
+	udevMonitorMagic = 0xfeedcafe
+)
+
+// uevent is a device add or remove notification from the kernel.
+type uevent struct {
+	Action    string
+	Subsystem string
+	DevName   string
+	Major     int64
+	Minor     int64
+}
+
+// devType returns the type of the device, as used in device cgroup rules.
+func (ev *uevent) devType() string {
+	if ev.Subsystem == "block" {
+		return "b"
+	}
+	return "c"
+}
+
+// parseUevent parses a kernel uevent message, such as
+// "add@/devices/virtual/block/loop0\0ACTION=add\0SUBSYSTEM=block\0...", or
+// a uevent relayed by udev. It returns false for messages that are not the
+// addition or removal of a device node.
+func parseUevent(msg []byte) (*uevent, bool) {
+	var fields [][]byte
+	if bytes.HasPrefix(msg, []byte("libudev\x00")) {
+		// The properties follow a header made of the magic number, in
+		// network byte order, and of the size of the header, the offset
+		// and the length of the properties, in host byte order.
+		if len(msg) < 24 || binary.BigEndian.Uint32(msg[8:12]) != udevMonitorMagic {
+			return nil, false
+		}
+		off, length := nl.NativeEndian().Uint32(msg[16:20]), nl.NativeEndian().Uint32(msg[20:24])
+		if uint64(off)+uint64(length) > uint64(len(msg)) {
+			return nil, false
+		}
+		fields = bytes.Split(msg[off:off+length], []byte{0})
+	} else {
+		fields = bytes.Split(msg, []byte{0})
+		if len(fields) < 2 || !bytes.Contains(fields[0], []byte("@")) {
+			return nil, false
+		}
+		fields = fields[1:]
+	}
+	ev := &uevent{Major: -1, Minor: -1}
+	for _, f := range fields {
+		kv := strings.SplitN(string(f), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "ACTION":
+			ev.Action = kv[1]
+		case "SUBSYSTEM":
+			ev.Subsystem = kv[1]
+		case "DEVNAME":
+			// udev sets the path of the node, the kernel its name.
+			ev.DevName = strings.TrimPrefix(kv[1], "/dev/")
+		case "MAJOR":
+			ev.Major, _ = strconv.ParseInt(kv[1], 10, 64)
+		case "MINOR":
+			ev.Minor, _ = strconv.ParseInt(kv[1], 10, 64)
+		}
+	}
+	if ev.Action != "add" && ev.Action != "remove" {
+		return nil, false
+	}
+	if ev.DevName == "" || ev.Major < 0 || ev.Minor < 0 {
+		return nil, false
+	}
+	return ev, true
+}
+
+// deviceHotplug propagates the devices plugged in and out after containers
+// were started.
+type deviceHotplug struct {
+	socket *os.File
+
+	mu sync.Mutex
+	// devices are the nodes created in containers, by container ID and
+	// path in the container.
+	devices map[string]map[string]uint64
+}
+
+func (h *deviceHotplug) track(id, path string, rdev uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.devices[id] == nil {
+		h.devices[id] = make(map[string]uint64)
+	}
+	h.devices[id][path] = rdev
+}
+
+// forget discards the nodes created in container id, once it stopped.
+func (h *deviceHotplug) forget(id string) {
+	h.mu.Lock()
+	delete(h.devices, id)
+	h.mu.Unlock()
+}
+
+// untrack returns the paths of the nodes created for rdev in container id,
+// and forgets about them.
+func (h *deviceHotplug) untrack(id string, rdev uint64) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var paths []string
+	for path, d := range h.devices[id] {
+		if d == rdev {
+			paths = append(paths, path)
+			delete(h.devices[id], path)
+		}
+	}
+	if len(h.devices[id]) == 0 {
+		delete(h.devices, id)
+	}
+	return paths
+}
+
+// startDeviceHotplug starts creating and removing the nodes of devices
+// plugged in and out in the running containers allowed to access them, if
+// enabled in the configuration.
+//
+// If udev is running, the uevents it relays are used, so that the nodes are
+// created once udev set the permissions of the node on the host. Otherwise,
+// the node on the host is created by devtmpfs before the kernel sends the
+// uevent.
+func (daemon *Daemon) startDeviceHotplug(cfg *config.Config) error {
+	if !cfg.DeviceHotplug {
+		return nil
+	}
+	group := uint32(ueventKernelGroup)
+	if _, err := os.Stat("/run/udev/control"); err == nil {
+		group = ueventUdevGroup
+	}
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return errors.Wrap(err, "failed to open uevent socket")
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: group}); err != nil {
+		unix.Close(fd)
+		return errors.Wrap(err, "failed to bind uevent socket")
+	}
+	// Reading from an *os.File uses the runtime poller, so that closing it
+	// interrupts the read.
+	h := &deviceHotplug{
+		socket:  os.NewFile(uintptr(fd), "uevent"),
+		devices: make(map[string]map[string]uint64),
+	}
+	daemon.hotplug = h
+
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := h.socket.Read(buf)
+			if err != nil {
+				if !daemon.IsShuttingDown() {
+					logrus.WithError(err).Error("stopped watching for hot-plugged devices")
+				}
+				return
+			}
+			if ev, ok := parseUevent(buf[:n]); ok {
+				daemon.handleUevent(ev)
+			}
+		}
+	}()
+	return nil
+}
+
+func (daemon *Daemon) stopDeviceHotplug() {
+	if daemon.hotplug != nil {
+		daemon.hotplug.socket.Close()
+	}
+}
+
+// forgetHotplugDevices discards the device nodes created in a container
+// that stopped.
+func (daemon *Daemon) forgetHotplugDevices(c *container.Container) {
+	if daemon.hotplug != nil {
+		daemon.hotplug.forget(c.ID)
+	}
+}
+
+func (daemon *Daemon) handleUevent(ev *uevent) {
+	rdev := unix.Mkdev(uint32(ev.Major), uint32(ev.Minor))
+	hostPath := filepath.Join("/dev", ev.DevName)
+	rootIDs := daemon.idMapping.RootPair()
+	for _, c := range daemon.List() {
+		if !c.IsRunning() {
+			continue
+		}
+		pid := c.GetPID()
+		if pid == 0 {
+			continue
+		}
+		root := fmt.Sprintf("/proc/%d/root", pid)
+		log := logrus.WithFields(logrus.Fields{"container": c.ID, "device": hostPath})
+
+		switch ev.Action {
+		case "add":
+			for _, path := range hotplugPaths(c, ev, hostPath) {
+				created, err := createDeviceNode(root, path, hostPath, ev.devType(), rdev, rootIDs.UID, rootIDs.GID)
+				if err != nil {
+					log.WithError(err).Warn("failed to create hot-plugged device node in container")
+					continue
+				}
+				daemon.hotplug.track(c.ID, path, rdev)
+				if created {
+					daemon.logDeviceEvent(c, "device_attach", ev, path)
+				}
+			}
+		case "remove":
+			// Remove the nodes created at start as well.
+			paths := append(daemon.hotplug.untrack(c.ID, rdev), hotplugPaths(c, ev, hostPath)...)
+			removed := map[string]bool{}
+			for _, path := range paths {
+				if removed[path] {
+					continue
+				}
+				ok, err := removeDeviceNode(root, path, rdev)
+				if err != nil {
+					log.WithError(err).Warn("failed to remove hot-plugged device node from container")
+					continue
+				}
+				if ok {
+					removed[path] = true
+					daemon.logDeviceEvent(c, "device_detach", ev, path)
+				}
+			}
+		}
+	}
+}
+
+func (daemon *Daemon) logDeviceEvent(c *container.Container, action string, ev *uevent, path string) {
+	daemon.LogContainerEventWithAttributes(c, action, map[string]string{
+		"device":  path,
+		"devtype": ev.devType(),
+		"major":   strconv.FormatInt(ev.Major, 10),
+		"minor":   strconv.FormatInt(ev.Minor, 10),
+	})
+}
+
+// hotplugPaths returns the paths in container c at which the node of the
+// device described by ev should be created: the path in the container of a
+// matching configured device, or the path on the host if the device cgroup
+// rules of c allow the device. Privileged containers are allowed all
+// devices.
+//
+// Note that the device cgroup of a container is not changed: a configured
+// device that comes back with a different number is only accessible if a
+// device cgroup rule allows it.
+func hotplugPaths(c *container.Container, ev *uevent, hostPath string) []string {
+	var paths []string
+	for _, d := range c.HostConfig.Devices {
+		if d.PathOnHost == hostPath {
+			paths = append(paths, d.PathInContainer)
+		} else if rel, err := filepath.Rel(d.PathOnHost, hostPath); err == nil && !strings.HasPrefix(rel, "..") {
+			// Devices under a configured directory.
+			paths = append(paths, filepath.Join(d.PathInContainer, rel))
+		}
+	}
+	if len(paths) > 0 {
+		return paths
+	}
+	if c.HostConfig.Privileged || deviceAllowedByRules(c.HostConfig.DeviceCgroupRules, ev) {
+		return []string{hostPath}
+	}
+	return nil
+}
+
+// deviceAllowedByRules returns whether one of the device cgroup rules, such
+// as "c 188:* rwm", allows the device described by ev.
+func deviceAllowedByRules(rules []string, ev *uevent) bool {
+	perms, err := oci.AppendDevicePermissionsFromCgroupRules(nil, rules)
+	if err != nil {
+		return false
+	}
+	for _, p := range perms {
+		if p.Type != "a" && p.Type != ev.devType() {
+			continue
+		}
+		if *p.Major != -1 && *p.Major != ev.Major {
+			continue
+		}
+		if *p.Minor != -1 && *p.Minor != ev.Minor {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// createDeviceNode creates a device node at path in the root filesystem of
+// a container, with the permissions of the node on the host if it exists.
+// It returns false if the node already existed.
+func createDeviceNode(root, path, hostPath, devType string, rdev uint64, uid, gid int) (bool, error) {
+	p, err := securejoin.SecureJoin(root, path)
+	if err != nil {
+		return false, err
+	}
+	mode := uint32(0600)
+	if fi, err := os.Stat(hostPath); err == nil {
+		mode = uint32(fi.Mode().Perm())
+	}
+	fileType := uint32(unix.S_IFCHR)
+	if devType == "b" {
+		fileType = unix.S_IFBLK
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return false, err
+	}
+	var st unix.Stat_t
+	if err := unix.Lstat(p, &st); err == nil {
+		if st.Rdev == rdev && st.Mode&unix.S_IFMT == fileType {
+			// Already present, e.g. created at start.
+			return false, nil
+		}
+		if err := os.Remove(p); err != nil {
+			return false, err
+		}
+	}
+	if err := unix.Mknod(p, fileType|mode, int(rdev)); err != nil {
+		return false, err
+	}
+	// The permissions passed to mknod are subject to the umask.
+	if err := os.Chmod(p, os.FileMode(mode)); err != nil {
+		return false, err
+	}
+	return true, os.Lchown(p, uid, gid)
+}
+
+// removeDeviceNode removes the node at path in the root filesystem of a
+// container, if it is the node of rdev. It returns whether it was removed.
+func removeDeviceNode(root, path string, rdev uint64) (bool, error) {
+	p, err := securejoin.SecureJoin(root, path)
+	if err != nil {
+		return false, err
+	}
+	var st unix.Stat_t
+	if err := unix.Lstat(p, &st); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if st.Rdev != rdev || (st.Mode&unix.S_IFMT != unix.S_IFCHR && st.Mode&unix.S_IFMT != unix.S_IFBLK) {
+		return false, nil
+	}
+	return true, os.Remove(p)
+}
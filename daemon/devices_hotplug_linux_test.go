@@ -0,0 +1,174 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+func ueventMessage(fields ...string) []byte {
+	return []byte(strings.Join(fields, "\x00") + "\x00")
+}
+
+func TestParseUevent(t *testing.T) {
+	ev, ok := parseUevent(ueventMessage(
+		"add@/devices/virtual/block/loop200",
+		"ACTION=add",
+		"DEVPATH=/devices/virtual/block/loop200",
+		"SUBSYSTEM=block",
+		"MAJOR=7",
+		"MINOR=200",
+		"DEVNAME=loop200",
+		"DEVTYPE=disk",
+	))
+	assert.Assert(t, ok)
+	assert.Check(t, is.DeepEqual(ev, &uevent{Action: "add", Subsystem: "block", DevName: "loop200", Major: 7, Minor: 200}))
+	assert.Check(t, is.Equal(ev.devType(), "b"))
+
+	// Devices without a node, and events other than add and remove, are
+	// ignored.
+	_, ok = parseUevent(ueventMessage("add@/module/loop", "ACTION=add", "SUBSYSTEM=module"))
+	assert.Check(t, !ok)
+	_, ok = parseUevent(ueventMessage("change@/devices/virtual/block/loop0", "ACTION=change", "SUBSYSTEM=block", "MAJOR=7", "MINOR=0", "DEVNAME=loop0"))
+	assert.Check(t, !ok)
+
+	// Messages relayed by udev have a header, and the path of the node.
+	ev, ok = parseUevent(udevMessage("ACTION=remove", "SUBSYSTEM=tty", "DEVNAME=/dev/ttyUSB0", "MAJOR=188", "MINOR=0"))
+	assert.Assert(t, ok)
+	assert.Check(t, is.DeepEqual(ev, &uevent{Action: "remove", Subsystem: "tty", DevName: "ttyUSB0", Major: 188, Minor: 0}))
+	_, ok = parseUevent(ueventMessage("libudev", "ACTION=add", "MAJOR=7", "MINOR=0", "DEVNAME=loop0"))
+	assert.Check(t, !ok)
+}
+
+func udevMessage(fields ...string) []byte {
+	props := ueventMessage(fields...)
+	header := make([]byte, 40)
+	copy(header, "libudev\x00")
+	binary.BigEndian.PutUint32(header[8:], udevMonitorMagic)
+	nl.NativeEndian().PutUint32(header[12:], uint32(len(header)))
+	nl.NativeEndian().PutUint32(header[16:], uint32(len(header)))
+	nl.NativeEndian().PutUint32(header[20:], uint32(len(props)))
+	return append(header, props...)
+}
+
+func TestDeviceHotplugForget(t *testing.T) {
+	h := &deviceHotplug{devices: make(map[string]map[string]uint64)}
+	h.track("a", "/dev/ttyUSB0", unix.Mkdev(188, 0))
+	h.track("b", "/dev/ttyUSB0", unix.Mkdev(188, 0))
+	h.forget("a")
+	assert.Check(t, is.Len(h.devices, 1))
+	assert.Check(t, is.DeepEqual(h.untrack("b", unix.Mkdev(188, 0)), []string{"/dev/ttyUSB0"}))
+	assert.Check(t, is.Len(h.devices, 0))
+}
+
+func TestHotplugPaths(t *testing.T) {
+	serial := &uevent{Action: "add", Subsystem: "tty", DevName: "ttyUSB0", Major: 188, Minor: 0}
+	loop := &uevent{Action: "add", Subsystem: "block", DevName: "loop200", Major: 7, Minor: 200}
+
+	testCases := []struct {
+		doc        string
+		hostConfig containertypes.HostConfig
+		ev         *uevent
+		expected   []string
+	}{
+		{
+			doc:        "no access",
+			hostConfig: containertypes.HostConfig{DeviceCgroupRules: []string{"c 189:* rwm"}},
+			ev:         serial,
+		},
+		{
+			doc:        "matching rule",
+			hostConfig: containertypes.HostConfig{DeviceCgroupRules: []string{"c 189:* rwm", "c 188:* rwm"}},
+			ev:         serial,
+			expected:   []string{"/dev/ttyUSB0"},
+		},
+		{
+			doc:        "rule for another type",
+			hostConfig: containertypes.HostConfig{DeviceCgroupRules: []string{"c 7:200 rwm"}},
+			ev:         loop,
+		},
+		{
+			doc:        "rule for all types",
+			hostConfig: containertypes.HostConfig{DeviceCgroupRules: []string{"a 7:200 rwm"}},
+			ev:         loop,
+			expected:   []string{"/dev/loop200"},
+		},
+		{
+			doc:        "privileged",
+			hostConfig: containertypes.HostConfig{Privileged: true},
+			ev:         loop,
+			expected:   []string{"/dev/loop200"},
+		},
+		{
+			doc: "configured device",
+			hostConfig: containertypes.HostConfig{Resources: containertypes.Resources{Devices: []containertypes.DeviceMapping{
+				{PathOnHost: "/dev/ttyUSB0", PathInContainer: "/dev/modem", CgroupPermissions: "rwm"},
+			}}},
+			ev:       serial,
+			expected: []string{"/dev/modem"},
+		},
+		{
+			doc: "configured directory",
+			hostConfig: containertypes.HostConfig{Resources: containertypes.Resources{Devices: []containertypes.DeviceMapping{
+				{PathOnHost: "/dev/snd", PathInContainer: "/dev/snd", CgroupPermissions: "rwm"},
+				{PathOnHost: "/dev", PathInContainer: "/host-dev", CgroupPermissions: "rwm"},
+			}}},
+			ev:       serial,
+			expected: []string{"/host-dev/ttyUSB0"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			c := &container.Container{HostConfig: &tc.hostConfig}
+			paths := hotplugPaths(c, tc.ev, filepath.Join("/dev", tc.ev.DevName))
+			assert.Check(t, is.DeepEqual(paths, tc.expected))
+		})
+	}
+}
+
+func TestCreateRemoveDeviceNode(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	root, err := ioutil.TempDir("", "device-hotplug")
+	assert.NilError(t, err)
+	defer os.RemoveAll(root)
+
+	// Nodes are created in the container's root, even through symlinks.
+	assert.NilError(t, os.Symlink("/", filepath.Join(root, "dev")))
+
+	rdev := unix.Mkdev(7, 200)
+	created, err := createDeviceNode(root, "/dev/loop200", "/nonexistent", "b", rdev, 0, 0)
+	assert.NilError(t, err)
+	assert.Check(t, created)
+
+	var st unix.Stat_t
+	assert.NilError(t, unix.Lstat(filepath.Join(root, "loop200"), &st))
+	assert.Check(t, is.Equal(st.Mode&unix.S_IFMT, uint32(unix.S_IFBLK)))
+	assert.Check(t, is.Equal(st.Mode&0777, uint32(0600)))
+	assert.Check(t, is.Equal(st.Rdev, rdev))
+
+	created, err = createDeviceNode(root, "/dev/loop200", "/nonexistent", "b", rdev, 0, 0)
+	assert.NilError(t, err)
+	assert.Check(t, !created)
+
+	// Only the node of the removed device is removed.
+	removed, err := removeDeviceNode(root, "/dev/loop200", unix.Mkdev(7, 201))
+	assert.NilError(t, err)
+	assert.Check(t, !removed)
+	removed, err = removeDeviceNode(root, "/dev/loop200", rdev)
+	assert.NilError(t, err)
+	assert.Check(t, removed)
+	_, err = os.Lstat(filepath.Join(root, "loop200"))
+	assert.Check(t, os.IsNotExist(err))
+}
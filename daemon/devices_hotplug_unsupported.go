@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
+)
+
+type deviceHotplug struct{}
+
+func (daemon *Daemon) startDeviceHotplug(cfg *config.Config) error {
+	return nil
+}
+
+func (daemon *Daemon) stopDeviceHotplug() {
+}
+
+func (daemon *Daemon) forgetHotplugDevices(c *container.Container) {
+}
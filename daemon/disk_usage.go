@@ -46,5 +46,6 @@ func (daemon *Daemon) SystemDiskUsage(ctx context.Context) (*types.DiskUsage, er
 		Containers: allContainers,
 		Volumes:    localVolumes,
 		Images:     allImages,
+		Logs:       daemon.logsDiskUsage(),
 	}, nil
 }
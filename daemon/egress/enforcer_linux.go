@@ -0,0 +1,374 @@
+package egress // import "github.com/docker/docker/daemon/egress"
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink/nl"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// refreshInterval is the interval at which the hosts of the rules are
+	// resolved again.
+	refreshInterval = time.Minute
+	// addressTTL is how long an address a host no longer resolves to stays
+	// allowed, so that connections to addresses rotated by the DNS keep
+	// being allowed for a while.
+	addressTTL = 10 * time.Minute
+)
+
+// Netfilter log (NFLOG) netlink definitions, from
+// include/uapi/linux/netfilter/nfnetlink_log.h.
+const (
+	nfulnlMsgPacket = 0
+	nfulnlMsgConfig = 1
+
+	nfulaPayload = 9
+	nfulaCfgCmd  = 1
+	nfulaCfgMode = 2
+
+	nfulnlCfgCmdBind = 1
+	nfulnlCopyPacket = 2
+
+	// nflogCopyRange is the number of bytes of the denied packets logged,
+	// enough for the IP and transport headers.
+	nflogCopyRange = 128
+)
+
+// Enforcer enforces an egress policy in the network namespace of a
+// container, and reports the connections it denies.
+type Enforcer struct {
+	nsPath string
+	rules  []rule
+	deny   func(Denial)
+
+	mu sync.Mutex
+	// seen is when each address of the hosts of the rules was last
+	// resolved.
+	seen    map[string]map[string]time.Time
+	applied map[string][]net.IP
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEnforcer enforces policy in the network namespace at nsPath, replacing
+// the filter table of the namespace, and calls deny for the connections it
+// denies. DNS queries to resolvers are allowed whatever the policy. The
+// enforcement stays in place until the namespace is destroyed, even after
+// the Enforcer is closed.
+func NewEnforcer(nsPath string, policy *containertypes.EgressPolicy, resolvers []net.IP, deny func(Denial)) (*Enforcer, error) {
+	rules, err := parseRules(policy)
+	if err != nil {
+		return nil, err
+	}
+	e := &Enforcer{
+		nsPath: nsPath,
+		rules:  append(dnsRules(resolvers), rules...),
+		deny:   deny,
+		seen:   make(map[string]map[string]time.Time),
+		closed: make(chan struct{}),
+	}
+	e.resolve(time.Now())
+	if err := e.apply(); err != nil {
+		return nil, err
+	}
+
+	sock, err := e.listen()
+	if err != nil {
+		logrus.WithError(err).Warn("failed to listen for the connections denied by the egress policy")
+	} else {
+		go e.logDenials(sock)
+	}
+	if e.hasHosts() {
+		go e.refresh()
+	}
+	return e, nil
+}
+
+// Close stops refreshing the addresses of the hosts of the policy, and
+// reporting the denied connections. It doesn't wait for them to stop, which
+// takes up to the receive timeout of the socket, or the time a pending
+// resolution of the hosts takes.
+func (e *Enforcer) Close() {
+	e.closeOnce.Do(func() {
+		close(e.closed)
+	})
+}
+
+func (e *Enforcer) isClosed() bool {
+	select {
+	case <-e.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *Enforcer) hasHosts() bool {
+	for _, r := range e.rules {
+		if r.host != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve resolves the hosts of the rules, and forgets the addresses they
+// haven't resolved to for addressTTL.
+func (e *Enforcer) resolve(now time.Time) {
+	for _, r := range e.rules {
+		if r.host == "" {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, r.host)
+		cancel()
+		if err != nil {
+			logrus.WithError(err).Warnf("failed to resolve %s for the egress policy", r.host)
+		}
+
+		e.mu.Lock()
+		seen := e.seen[r.host]
+		if seen == nil {
+			seen = make(map[string]time.Time)
+			e.seen[r.host] = seen
+		}
+		for _, a := range addrs {
+			seen[a.IP.String()] = now
+		}
+		for a, t := range seen {
+			if now.Sub(t) > addressTTL {
+				delete(seen, a)
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// addrs returns the addresses of the hosts of the rules, sorted.
+func (e *Enforcer) addrs() map[string][]net.IP {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	addrs := make(map[string][]net.IP, len(e.seen))
+	for host, seen := range e.seen {
+		list := make([]string, 0, len(seen))
+		for a := range seen {
+			list = append(list, a)
+		}
+		sort.Strings(list)
+		for _, a := range list {
+			addrs[host] = append(addrs[host], net.ParseIP(a))
+		}
+	}
+	return addrs
+}
+
+func (e *Enforcer) refresh() {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			e.resolve(now)
+			if e.isClosed() {
+				// The namespace may be gone already.
+				return
+			}
+			if err := e.apply(); err != nil {
+				logrus.WithError(err).Error("failed to update the egress policy")
+			}
+		case <-e.closed:
+			return
+		}
+	}
+}
+
+// apply replaces the filter tables of the network namespace with the rules,
+// unless the addresses of their hosts didn't change since they were last
+// applied.
+func (e *Enforcer) apply() error {
+	addrs := e.addrs()
+	if e.applied != nil && sameAddrs(e.applied, addrs) {
+		return nil
+	}
+
+	// Without IPv6 support in the kernel, no IPv6 traffic can leave the
+	// container.
+	_, err := os.Stat("/proc/sys/net/ipv6")
+	ipv6 := err == nil
+
+	err = inNetns(e.nsPath, func() error {
+		if err := restore("iptables-restore", ruleset(e.rules, addrs, false)); err != nil {
+			return err
+		}
+		if ipv6 {
+			return restore("ip6tables-restore", ruleset(e.rules, addrs, true))
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to apply the egress policy")
+	}
+	e.applied = addrs
+	return nil
+}
+
+func sameAddrs(a, b map[string][]net.IP) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for host, ips := range a {
+		other := b[host]
+		if len(ips) != len(other) {
+			return false
+		}
+		for i := range ips {
+			if !ips[i].Equal(other[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func restore(command, rules string) error {
+	cmd := exec.Command(command, "-w")
+	cmd.Stdin = strings.NewReader(rules)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Errorf("%s failed: %v: %s", command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// inNetns runs fn in the network namespace at nsPath, on a thread of its
+// own that is terminated if it can't get back to its original namespace.
+// The processes fn starts are in the network namespace too.
+func inNetns(nsPath string, fn func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		origNS, err := netns.Get()
+		if err != nil {
+			errCh <- errors.Wrap(err, "failed to get current network namespace")
+			return
+		}
+		defer origNS.Close()
+		targetNS, err := netns.GetFromPath(nsPath)
+		if err != nil {
+			errCh <- errors.Wrap(err, "failed to get the network namespace of the container")
+			return
+		}
+		defer targetNS.Close()
+		if err := netns.Set(targetNS); err != nil {
+			errCh <- errors.Wrap(err, "failed to enter the network namespace of the container")
+			return
+		}
+
+		err = fn()
+		if err := netns.Set(origNS); err != nil {
+			// Leave the thread locked, so that it is terminated with
+			// the goroutine rather than reused in the wrong namespace.
+			logrus.WithError(err).Error("failed to restore network namespace after applying the egress policy")
+		} else {
+			runtime.UnlockOSThread()
+		}
+		errCh <- err
+	}()
+	return <-errCh
+}
+
+// listen returns a netlink socket of the network namespace receiving the
+// packets logged by the NFLOG target of the rules.
+func (e *Enforcer) listen() (*nl.NetlinkSocket, error) {
+	ns, err := netns.GetFromPath(e.nsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer ns.Close()
+	sock, err := nl.GetNetlinkSocketAt(ns, netns.None(), unix.NETLINK_NETFILTER)
+	if err != nil {
+		return nil, err
+	}
+
+	bind := nflogConfig(nflogGroup)
+	bind.AddData(nl.NewRtAttr(nfulaCfgCmd, []byte{nfulnlCfgCmdBind}))
+	mode := nflogConfig(nflogGroup)
+	mode.AddData(nl.NewRtAttr(nfulaCfgMode, []byte{0, 0, 0, nflogCopyRange, nfulnlCopyPacket, 0}))
+	for _, req := range []*nl.NetlinkRequest{bind, mode} {
+		if err := sock.Send(req); err != nil {
+			sock.Close()
+			return nil, err
+		}
+	}
+	// Wake up regularly to notice the Enforcer is closed.
+	if err := sock.SetReceiveTimeout(&unix.Timeval{Sec: 1}); err != nil {
+		sock.Close()
+		return nil, err
+	}
+	return sock, nil
+}
+
+// nfgenmsg is the header of the nfnetlink messages, preceding their
+// attributes.
+type nfgenmsg []byte
+
+func (m nfgenmsg) Len() int          { return len(m) }
+func (m nfgenmsg) Serialize() []byte { return m }
+
+// nflogConfig returns a request configuring the NFLOG group.
+func nflogConfig(group uint16) *nl.NetlinkRequest {
+	req := nl.NewNetlinkRequest(unix.NFNL_SUBSYS_ULOG<<8|nfulnlMsgConfig, 0)
+	req.AddData(nfgenmsg{unix.AF_UNSPEC, unix.NFNETLINK_V0, byte(group >> 8), byte(group)})
+	return req
+}
+
+func (e *Enforcer) logDenials(sock *nl.NetlinkSocket) {
+	defer sock.Close()
+
+	for !e.isClosed() {
+		msgs, _, err := sock.Receive()
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR || err == unix.ENOBUFS {
+				continue
+			}
+			logrus.WithError(err).Warn("failed to receive the connections denied by the egress policy")
+			return
+		}
+		for _, m := range msgs {
+			if d, ok := parseLogMessage(m); ok && !e.isClosed() {
+				e.deny(d)
+			}
+		}
+	}
+}
+
+// parseLogMessage returns the connection denied by the packet logged in m.
+func parseLogMessage(m syscall.NetlinkMessage) (Denial, bool) {
+	if m.Header.Type != unix.NFNL_SUBSYS_ULOG<<8|nfulnlMsgPacket || len(m.Data) < 4 {
+		return Denial{}, false
+	}
+	attrs, err := nl.ParseRouteAttr(m.Data[4:])
+	if err != nil {
+		return Denial{}, false
+	}
+	for _, a := range attrs {
+		if a.Attr.Type&^(unix.NLA_F_NESTED|unix.NLA_F_NET_BYTEORDER) == nfulaPayload {
+			return parsePacket(a.Value)
+		}
+	}
+	return Denial{}, false
+}
@@ -0,0 +1,48 @@
+package egress // import "github.com/docker/docker/daemon/egress"
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestNflogConfig(t *testing.T) {
+	req := nflogConfig(nflogGroup)
+	req.AddData(nl.NewRtAttr(nfulaCfgCmd, []byte{nfulnlCfgCmdBind}))
+	b := req.Serialize()
+
+	// The nfgenmsg header precedes the attributes.
+	assert.Assert(t, is.Len(b, unix.SizeofNlMsghdr+4+8))
+	assert.Check(t, is.DeepEqual(b[unix.SizeofNlMsghdr:], []byte{
+		unix.AF_UNSPEC, unix.NFNETLINK_V0, 0, nflogGroup,
+		5, 0, nfulaCfgCmd, 0, nfulnlCfgCmdBind, 0, 0, 0,
+	}))
+}
+
+func TestParseLogMessage(t *testing.T) {
+	packet := []byte{
+		0x45, 0x00, 0x00, 0x3c, 0x00, 0x00, 0x40, 0x00, 0x40, 0x11, 0x00, 0x00,
+		172, 17, 0, 2, 192, 0, 2, 1,
+		0xc3, 0x50, 0x00, 0x35,
+	}
+	payload := nl.NewRtAttr(nfulaPayload, packet).Serialize()
+	m := syscall.NetlinkMessage{
+		Header: syscall.NlMsghdr{Type: unix.NFNL_SUBSYS_ULOG<<8 | nfulnlMsgPacket},
+		Data:   append([]byte{unix.AF_INET, unix.NFNETLINK_V0, 0, nflogGroup}, payload...),
+	}
+	d, ok := parseLogMessage(m)
+	assert.Assert(t, ok)
+	assert.Check(t, is.DeepEqual(d.Attributes(), map[string]string{
+		"destination": "192.0.2.1",
+		"protocol":    "udp",
+		"port":        "53",
+	}))
+
+	m.Header.Type = unix.NLMSG_ERROR
+	_, ok = parseLogMessage(m)
+	assert.Check(t, !ok)
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+// +build !linux
+
+package egress // import "github.com/docker/docker/daemon/egress"
+
+import (
+	"net"
+	"runtime"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+// Enforcer enforces an egress policy in the network namespace of a
+// container.
+type Enforcer struct{}
+
+// NewEnforcer returns an error: egress policies are only supported on Linux.
+func NewEnforcer(nsPath string, policy *containertypes.EgressPolicy, resolvers []net.IP, deny func(Denial)) (*Enforcer, error) {
+	return nil, errors.Errorf("egress policies are not supported on %s", runtime.GOOS)
+}
+
+// Close does nothing.
+func (e *Enforcer) Close() {}
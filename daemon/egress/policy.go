@@ -0,0 +1,240 @@
+// Package egress enforces the egress policies of containers, which restrict
+// the destinations the containers can connect to.
+package egress // import "github.com/docker/docker/daemon/egress"
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// chain is the chain of the filter table of the network namespace of a
+// container the traffic leaving the container goes through.
+const chain = "DOCKER-EGRESS"
+
+// nflogGroup is the netlink group the denied connections are logged to. The
+// group is local to the network namespace of the container.
+const nflogGroup = 100
+
+// rule is a parsed EgressRule.
+type rule struct {
+	network  *net.IPNet
+	host     string
+	protocol string
+	ports    string
+}
+
+// Validate validates policy.
+func Validate(policy *containertypes.EgressPolicy) error {
+	_, err := parseRules(policy)
+	return err
+}
+
+func parseRules(policy *containertypes.EgressPolicy) ([]rule, error) {
+	rules := make([]rule, 0, len(policy.Rules))
+	for i, r := range policy.Rules {
+		parsed, err := parseRule(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid egress rule %d", i)
+		}
+		rules = append(rules, parsed)
+	}
+	return rules, nil
+}
+
+func parseRule(r containertypes.EgressRule) (rule, error) {
+	var parsed rule
+	switch {
+	case r.CIDR != "" && r.Host != "":
+		return rule{}, errors.New("CIDR and Host are exclusive")
+	case r.CIDR != "":
+		if ip := net.ParseIP(r.CIDR); ip != nil {
+			parsed.network = &net.IPNet{IP: ip, Mask: net.CIDRMask(8*len(ip), 8*len(ip))}
+			if ip4 := ip.To4(); ip4 != nil {
+				parsed.network = &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+			}
+		} else {
+			_, network, err := net.ParseCIDR(r.CIDR)
+			if err != nil {
+				return rule{}, errors.Errorf("invalid CIDR %q", r.CIDR)
+			}
+			parsed.network = network
+		}
+	case r.Host != "":
+		if strings.ContainsAny(r.Host, " /:") {
+			return rule{}, errors.Errorf("invalid host %q", r.Host)
+		}
+		parsed.host = strings.TrimSuffix(strings.ToLower(r.Host), ".")
+	}
+
+	switch p := strings.ToLower(r.Protocol); p {
+	case "", "tcp", "udp", "icmp":
+		parsed.protocol = p
+	default:
+		return rule{}, errors.Errorf("invalid protocol %q: must be tcp, udp or icmp", r.Protocol)
+	}
+
+	if r.Ports != "" {
+		if parsed.protocol != "tcp" && parsed.protocol != "udp" {
+			return rule{}, errors.New("ports require the tcp or udp protocol")
+		}
+		start, end, err := nat.ParsePortRange(r.Ports)
+		if err != nil || start == 0 {
+			return rule{}, errors.Errorf("invalid ports %q", r.Ports)
+		}
+		parsed.ports = fmt.Sprint(start)
+		if end != start {
+			parsed.ports = fmt.Sprintf("%d:%d", start, end)
+		}
+	}
+	return parsed, nil
+}
+
+// dnsRules returns the rules allowing DNS queries to resolvers, which a
+// container needs whatever its policy.
+func dnsRules(resolvers []net.IP) []rule {
+	rules := make([]rule, 0, 2*len(resolvers))
+	for _, ip := range resolvers {
+		network := &net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)}
+		if ip4 := ip.To4(); ip4 != nil {
+			network = &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+		}
+		for _, protocol := range []string{"udp", "tcp"} {
+			rules = append(rules, rule{network: network, protocol: protocol, ports: "53"})
+		}
+	}
+	return rules
+}
+
+// ruleset returns the input of iptables-restore, or of ip6tables-restore if
+// ipv6 is set, replacing the filter table of the network namespace of a
+// container with one enforcing rules. addrs are the resolved addresses of
+// the hosts of the rules.
+func ruleset(rules []rule, addrs map[string][]net.IP, ipv6 bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*filter\n:INPUT ACCEPT [0:0]\n:FORWARD ACCEPT [0:0]\n:OUTPUT ACCEPT [0:0]\n:%s - [0:0]\n", chain)
+	fmt.Fprintf(&b, "-A OUTPUT -j %s\n", chain)
+	fmt.Fprintf(&b, "-A %s -o lo -j RETURN\n", chain)
+	fmt.Fprintf(&b, "-A %s -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN\n", chain)
+	if ipv6 {
+		// Neighbor discovery is needed to reach any destination.
+		for _, t := range []string{"router-solicitation", "neighbour-solicitation", "neighbour-advertisement"} {
+			fmt.Fprintf(&b, "-A %s -p ipv6-icmp -m icmp6 --icmpv6-type %s -j RETURN\n", chain, t)
+		}
+	}
+
+	for _, r := range rules {
+		var dests []string
+		switch {
+		case r.network != nil:
+			if (r.network.IP.To4() == nil) != ipv6 {
+				continue
+			}
+			dests = []string{r.network.String()}
+		case r.host != "":
+			for _, ip := range addrs[r.host] {
+				if (ip.To4() == nil) != ipv6 {
+					continue
+				}
+				dests = append(dests, ip.String())
+			}
+			if len(dests) == 0 {
+				continue
+			}
+		default:
+			dests = []string{""}
+		}
+
+		var match string
+		switch {
+		case r.protocol == "icmp" && ipv6:
+			match = " -p ipv6-icmp"
+		case r.protocol != "":
+			match = " -p " + r.protocol
+		}
+		if r.ports != "" {
+			match += fmt.Sprintf(" -m %s --dport %s", r.protocol, r.ports)
+		}
+		for _, d := range dests {
+			if d != "" {
+				d = " -d " + d
+			}
+			fmt.Fprintf(&b, "-A %s%s%s -j RETURN\n", chain, d, match)
+		}
+	}
+
+	fmt.Fprintf(&b, "-A %s -m limit --limit 10/sec --limit-burst 20 -j NFLOG --nflog-group %d\n", chain, nflogGroup)
+	fmt.Fprintf(&b, "-A %s -j REJECT\n", chain)
+	b.WriteString("COMMIT\n")
+	return b.String()
+}
+
+// Denial is a connection denied by an egress policy.
+type Denial struct {
+	Destination net.IP
+	Protocol    string
+	// Port is the destination port of tcp and udp connections.
+	Port uint16
+}
+
+// Attributes returns the attributes of the event logging d.
+func (d Denial) Attributes() map[string]string {
+	attributes := map[string]string{
+		"destination": d.Destination.String(),
+		"protocol":    d.Protocol,
+	}
+	if d.Port != 0 {
+		attributes["port"] = fmt.Sprint(d.Port)
+	}
+	return attributes
+}
+
+// parsePacket returns the connection denied by the first bytes of packet,
+// as logged by the NFLOG target.
+func parsePacket(packet []byte) (Denial, bool) {
+	if len(packet) == 0 {
+		return Denial{}, false
+	}
+	var (
+		d      Denial
+		proto  byte
+		header int
+	)
+	switch packet[0] >> 4 {
+	case 4:
+		if len(packet) < 20 {
+			return Denial{}, false
+		}
+		proto = packet[9]
+		header = int(packet[0]&0x0f) * 4
+		d.Destination = net.IP(append([]byte(nil), packet[16:20]...))
+	case 6:
+		if len(packet) < 40 {
+			return Denial{}, false
+		}
+		proto = packet[6]
+		header = 40
+		d.Destination = net.IP(append([]byte(nil), packet[24:40]...))
+	default:
+		return Denial{}, false
+	}
+
+	switch proto {
+	case 1, 58:
+		d.Protocol = "icmp"
+	case 6:
+		d.Protocol = "tcp"
+	case 17:
+		d.Protocol = "udp"
+	default:
+		d.Protocol = fmt.Sprint(proto)
+	}
+	if (proto == 6 || proto == 17) && len(packet) >= header+4 {
+		d.Port = uint16(packet[header+2])<<8 | uint16(packet[header+3])
+	}
+	return d, true
+}
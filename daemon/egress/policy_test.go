@@ -0,0 +1,163 @@
+package egress // import "github.com/docker/docker/daemon/egress"
+
+import (
+	"net"
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		rule     containertypes.EgressRule
+		expected string
+	}{
+		{rule: containertypes.EgressRule{CIDR: "192.0.2.0/24", Protocol: "tcp", Ports: "443"}},
+		{rule: containertypes.EgressRule{CIDR: "2001:db8::1"}},
+		{rule: containertypes.EgressRule{Host: "example.com.", Protocol: "UDP", Ports: "8000-8080"}},
+		{rule: containertypes.EgressRule{Protocol: "udp", Ports: "53"}},
+		{
+			rule:     containertypes.EgressRule{CIDR: "192.0.2.0/24", Host: "example.com"},
+			expected: "invalid egress rule 0: CIDR and Host are exclusive",
+		},
+		{
+			rule:     containertypes.EgressRule{CIDR: "192.0.2.0/33"},
+			expected: `invalid egress rule 0: invalid CIDR "192.0.2.0/33"`,
+		},
+		{
+			rule:     containertypes.EgressRule{Host: "example.com:443"},
+			expected: `invalid egress rule 0: invalid host "example.com:443"`,
+		},
+		{
+			rule:     containertypes.EgressRule{Protocol: "sctp"},
+			expected: `invalid egress rule 0: invalid protocol "sctp": must be tcp, udp or icmp`,
+		},
+		{
+			rule:     containertypes.EgressRule{Ports: "443"},
+			expected: "invalid egress rule 0: ports require the tcp or udp protocol",
+		},
+		{
+			rule:     containertypes.EgressRule{Protocol: "tcp", Ports: "0"},
+			expected: `invalid egress rule 0: invalid ports "0"`,
+		},
+	}
+	for _, tc := range tests {
+		err := Validate(&containertypes.EgressPolicy{Rules: []containertypes.EgressRule{tc.rule}})
+		if tc.expected == "" {
+			assert.Check(t, err, "rule: %+v", tc.rule)
+		} else {
+			assert.Check(t, is.Error(err, tc.expected), "rule: %+v", tc.rule)
+		}
+	}
+}
+
+func TestRuleset(t *testing.T) {
+	rules, err := parseRules(&containertypes.EgressPolicy{Rules: []containertypes.EgressRule{
+		{CIDR: "192.0.2.1", Protocol: "tcp", Ports: "8000-8080"},
+		{CIDR: "2001:db8::/32", Protocol: "icmp"},
+		{Host: "example.com", Protocol: "tcp", Ports: "443"},
+		{Host: "unresolved.example.com"},
+		{Protocol: "udp", Ports: "53"},
+	}})
+	assert.NilError(t, err)
+	addrs := map[string][]net.IP{
+		"example.com": {net.ParseIP("198.51.100.1"), net.ParseIP("198.51.100.2"), net.ParseIP("2001:db8::2")},
+	}
+
+	assert.Check(t, is.Equal(ruleset(rules, addrs, false), `*filter
+:INPUT ACCEPT [0:0]
+:FORWARD ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:DOCKER-EGRESS - [0:0]
+-A OUTPUT -j DOCKER-EGRESS
+-A DOCKER-EGRESS -o lo -j RETURN
+-A DOCKER-EGRESS -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN
+-A DOCKER-EGRESS -d 192.0.2.1/32 -p tcp -m tcp --dport 8000:8080 -j RETURN
+-A DOCKER-EGRESS -d 198.51.100.1 -p tcp -m tcp --dport 443 -j RETURN
+-A DOCKER-EGRESS -d 198.51.100.2 -p tcp -m tcp --dport 443 -j RETURN
+-A DOCKER-EGRESS -p udp -m udp --dport 53 -j RETURN
+-A DOCKER-EGRESS -m limit --limit 10/sec --limit-burst 20 -j NFLOG --nflog-group 100
+-A DOCKER-EGRESS -j REJECT
+COMMIT
+`))
+
+	assert.Check(t, is.Equal(ruleset(rules, addrs, true), `*filter
+:INPUT ACCEPT [0:0]
+:FORWARD ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:DOCKER-EGRESS - [0:0]
+-A OUTPUT -j DOCKER-EGRESS
+-A DOCKER-EGRESS -o lo -j RETURN
+-A DOCKER-EGRESS -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN
+-A DOCKER-EGRESS -p ipv6-icmp -m icmp6 --icmpv6-type router-solicitation -j RETURN
+-A DOCKER-EGRESS -p ipv6-icmp -m icmp6 --icmpv6-type neighbour-solicitation -j RETURN
+-A DOCKER-EGRESS -p ipv6-icmp -m icmp6 --icmpv6-type neighbour-advertisement -j RETURN
+-A DOCKER-EGRESS -d 2001:db8::/32 -p ipv6-icmp -j RETURN
+-A DOCKER-EGRESS -d 2001:db8::2 -p tcp -m tcp --dport 443 -j RETURN
+-A DOCKER-EGRESS -p udp -m udp --dport 53 -j RETURN
+-A DOCKER-EGRESS -m limit --limit 10/sec --limit-burst 20 -j NFLOG --nflog-group 100
+-A DOCKER-EGRESS -j REJECT
+COMMIT
+`))
+}
+
+func TestDNSRules(t *testing.T) {
+	rules, err := parseRules(&containertypes.EgressPolicy{Rules: []containertypes.EgressRule{
+		{CIDR: "192.0.2.1", Protocol: "tcp", Ports: "443"},
+	}})
+	assert.NilError(t, err)
+	rules = append(dnsRules([]net.IP{net.ParseIP("198.51.100.53"), net.ParseIP("2001:db8::53")}), rules...)
+
+	assert.Check(t, is.Equal(ruleset(rules, nil, false), `*filter
+:INPUT ACCEPT [0:0]
+:FORWARD ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:DOCKER-EGRESS - [0:0]
+-A OUTPUT -j DOCKER-EGRESS
+-A DOCKER-EGRESS -o lo -j RETURN
+-A DOCKER-EGRESS -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN
+-A DOCKER-EGRESS -d 198.51.100.53/32 -p udp -m udp --dport 53 -j RETURN
+-A DOCKER-EGRESS -d 198.51.100.53/32 -p tcp -m tcp --dport 53 -j RETURN
+-A DOCKER-EGRESS -d 192.0.2.1/32 -p tcp -m tcp --dport 443 -j RETURN
+-A DOCKER-EGRESS -m limit --limit 10/sec --limit-burst 20 -j NFLOG --nflog-group 100
+-A DOCKER-EGRESS -j REJECT
+COMMIT
+`))
+
+	ipv6 := ruleset(rules, nil, true)
+	assert.Check(t, is.Contains(ipv6, "-A DOCKER-EGRESS -d 2001:db8::53/128 -p udp -m udp --dport 53 -j RETURN\n"))
+	assert.Check(t, is.Contains(ipv6, "-A DOCKER-EGRESS -d 2001:db8::53/128 -p tcp -m tcp --dport 53 -j RETURN\n"))
+}
+
+func TestParsePacket(t *testing.T) {
+	// The headers of a TCP SYN from 172.17.0.2 to 192.0.2.1:443.
+	ipv4 := []byte{
+		0x45, 0x00, 0x00, 0x3c, 0x00, 0x00, 0x40, 0x00, 0x40, 0x06, 0x00, 0x00,
+		172, 17, 0, 2, 192, 0, 2, 1,
+		0xc3, 0x50, 0x01, 0xbb,
+	}
+	d, ok := parsePacket(ipv4)
+	assert.Assert(t, ok)
+	assert.Check(t, is.DeepEqual(d.Attributes(), map[string]string{
+		"destination": "192.0.2.1",
+		"protocol":    "tcp",
+		"port":        "443",
+	}))
+
+	// A UDP datagram to [2001:db8::1]:53.
+	ipv6 := make([]byte, 44)
+	ipv6[0] = 0x60
+	ipv6[6] = 17
+	copy(ipv6[24:40], net.ParseIP("2001:db8::1"))
+	ipv6[42], ipv6[43] = 0, 53
+	d, ok = parsePacket(ipv6)
+	assert.Assert(t, ok)
+	assert.Check(t, d.Destination.Equal(net.ParseIP("2001:db8::1")))
+	assert.Check(t, is.Equal(d.Protocol, "udp"))
+	assert.Check(t, is.Equal(d.Port, uint16(53)))
+
+	_, ok = parsePacket(ipv4[:12])
+	assert.Check(t, !ok)
+}
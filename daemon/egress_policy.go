@@ -0,0 +1,100 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/egress"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/oci/caps"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// validateEgressPolicy validates the egress policy of hostConfig. The policy
+// is enforced in the network namespace of the container, so it can neither
+// be enforced on a namespace shared with the host or another container, nor
+// on a container allowed to change the rules enforcing it.
+func validateEgressPolicy(hostConfig *containertypes.HostConfig, platform string) error {
+	if hostConfig.EgressPolicy == nil {
+		return nil
+	}
+	if platform == "windows" {
+		return errors.New("egress policies are not supported on Windows")
+	}
+	if hostConfig.NetworkMode.IsHost() || hostConfig.NetworkMode.IsContainer() {
+		return errors.Errorf("egress policies are not supported with the %s network mode", hostConfig.NetworkMode.NetworkName())
+	}
+	if hostConfig.Privileged {
+		return errors.New("egress policies are not supported on privileged containers")
+	}
+	added, err := caps.NormalizeLegacyCapabilities(hostConfig.CapAdd)
+	if err != nil {
+		return err
+	}
+	for _, c := range added {
+		if c == "ALL" || c == "CAP_NET_ADMIN" {
+			return errors.New("egress policies are not supported on containers with the NET_ADMIN capability")
+		}
+	}
+	return egress.Validate(hostConfig.EgressPolicy)
+}
+
+// startEgressPolicy enforces the egress policy of container c in its network
+// namespace, before its process is started, and logs the connections it
+// denies as events. The DNS queries of the container are allowed.
+func (daemon *Daemon) startEgressPolicy(c *container.Container) error {
+	if c.HostConfig.EgressPolicy == nil || daemon.netController == nil {
+		return nil
+	}
+	sb := daemon.getNetworkSandbox(c)
+	if sb == nil {
+		// The container has no network.
+		return nil
+	}
+
+	e, err := egress.NewEnforcer(sb.Key(), c.HostConfig.EgressPolicy, daemon.egressDNSResolvers(c), func(d egress.Denial) {
+		daemon.LogContainerEventWithAttributes(c, "egress_denied", d.Attributes())
+	})
+	if err != nil {
+		return errdefs.System(err)
+	}
+
+	daemon.egressMu.Lock()
+	old := daemon.egressEnforcers[c.ID]
+	if daemon.egressEnforcers == nil {
+		daemon.egressEnforcers = make(map[string]*egress.Enforcer)
+	}
+	daemon.egressEnforcers[c.ID] = e
+	daemon.egressMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// stopEgressPolicy stops logging the connections denied by the egress policy
+// of container c once it stopped. It doesn't wait for the logging to stop,
+// as it is called with the container locked.
+func (daemon *Daemon) stopEgressPolicy(c *container.Container) {
+	daemon.egressMu.Lock()
+	e := daemon.egressEnforcers[c.ID]
+	delete(daemon.egressEnforcers, c.ID)
+	daemon.egressMu.Unlock()
+	if e != nil {
+		e.Close()
+	}
+}
+
+// restoreEgressPolicies enforces again the egress policies of the
+// containers kept running while the daemon restarted, in case their rules
+// were changed, and resumes logging the connections they deny.
+func (daemon *Daemon) restoreEgressPolicies() {
+	for _, c := range daemon.containers.List() {
+		if c.HostConfig.EgressPolicy == nil || !c.IsRunning() {
+			continue
+		}
+		if err := daemon.startEgressPolicy(c); err != nil {
+			logrus.WithError(err).WithField("container", c.ID).Error("failed to restore the egress policy")
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"io/ioutil"
+	"net"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/libnetwork/resolvconf"
+	"github.com/docker/libnetwork/types"
+	"github.com/sirupsen/logrus"
+)
+
+// embeddedDNSServer is the address of the DNS server embedded in the
+// daemon, which serves the containers on user-defined networks.
+const embeddedDNSServer = "127.0.0.11"
+
+// egressDNSResolvers returns the DNS servers container c queries from its
+// network namespace: the nameservers of its resolv.conf, and the servers
+// the embedded DNS server forwards its queries to if it uses it. Loopback
+// servers are left out: they are reached through the loopback interface,
+// or queried from the host namespace by the embedded DNS server.
+func (daemon *Daemon) egressDNSResolvers(c *container.Container) []net.IP {
+	content, err := ioutil.ReadFile(c.ResolvConfPath)
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.ID).Warn("failed to read the DNS servers to allow in the egress policy")
+		return nil
+	}
+	servers := resolvconf.GetNameservers(content, types.IP)
+	for _, s := range servers {
+		if s != embeddedDNSServer {
+			continue
+		}
+		// The embedded DNS server forwards the queries to the configured
+		// servers, or to the IPv4 servers of the resolv.conf of the host,
+		// from the network namespace of the container.
+		upstream := c.HostConfig.DNS
+		if len(upstream) == 0 {
+			upstream = daemon.configStore.DNS
+		}
+		if len(upstream) == 0 {
+			if rc, err := resolvconf.GetSpecific(daemon.configStore.GetResolvConf()); err == nil {
+				upstream = resolvconf.GetNameservers(rc.Content, types.IPv4)
+			}
+		}
+		servers = append(servers, upstream...)
+		break
+	}
+
+	var resolvers []net.IP
+	for _, s := range servers {
+		if ip := net.ParseIP(s); ip != nil && !ip.IsLoopback() {
+			resolvers = append(resolvers, ip)
+		}
+	}
+	return resolvers
+}
@@ -0,0 +1,57 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestEgressDNSResolvers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "egress-dns")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		assert.NilError(t, ioutil.WriteFile(p, []byte(content), 0644))
+		return p
+	}
+	hostResolvConf := writeFile("host", "nameserver 127.0.0.53\nnameserver 192.0.2.53\nnameserver 2001:db8::1\n")
+	d := &Daemon{configStore: &config.Config{}}
+	d.configStore.ResolvConf = hostResolvConf
+
+	resolvers := func(resolvConf string, dns ...string) []string {
+		c := &container.Container{
+			ResolvConfPath: writeFile("container", resolvConf),
+			HostConfig:     &containertypes.HostConfig{DNS: dns},
+		}
+		var servers []string
+		for _, ip := range d.egressDNSResolvers(c) {
+			servers = append(servers, ip.String())
+		}
+		return servers
+	}
+
+	// On the default bridge, the container queries the servers of its
+	// resolv.conf.
+	assert.Check(t, is.DeepEqual(resolvers("nameserver 192.0.2.1\nnameserver 2001:db8::53\n"), []string{"192.0.2.1", "2001:db8::53"}))
+
+	// On user-defined networks, the embedded DNS server forwards the
+	// queries to the IPv4 servers of the host, but for loopback ones.
+	assert.Check(t, is.DeepEqual(resolvers("nameserver 127.0.0.11\nnameserver 2001:db8::53\noptions ndots:0\n"), []string{"2001:db8::53", "192.0.2.53"}))
+
+	// The configured servers replace those of the host.
+	assert.Check(t, is.DeepEqual(resolvers("nameserver 127.0.0.11\n", "198.51.100.1"), []string{"198.51.100.1"}))
+	d.configStore.DNS = []string{"198.51.100.2"}
+	assert.Check(t, is.DeepEqual(resolvers("nameserver 127.0.0.11\n"), []string{"198.51.100.2"}))
+
+	c := &container.Container{ResolvConfPath: filepath.Join(dir, "missing"), HostConfig: &containertypes.HostConfig{}}
+	assert.Check(t, is.Len(d.egressDNSResolvers(c), 0))
+}
@@ -0,0 +1,79 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestValidateEgressPolicy(t *testing.T) {
+	policy := &containertypes.EgressPolicy{Rules: []containertypes.EgressRule{{Host: "example.com", Protocol: "tcp", Ports: "443"}}}
+	tests := []struct {
+		doc        string
+		hostConfig containertypes.HostConfig
+		platform   string
+		expected   string
+	}{
+		{
+			doc:        "no policy",
+			hostConfig: containertypes.HostConfig{NetworkMode: "host", Privileged: true},
+			platform:   "linux",
+		},
+		{
+			doc:        "bridge network",
+			hostConfig: containertypes.HostConfig{NetworkMode: "bridge", CapAdd: []string{"NET_RAW"}, EgressPolicy: policy},
+			platform:   "linux",
+		},
+		{
+			doc:        "host network",
+			hostConfig: containertypes.HostConfig{NetworkMode: "host", EgressPolicy: policy},
+			platform:   "linux",
+			expected:   "egress policies are not supported with the host network mode",
+		},
+		{
+			doc:        "privileged",
+			hostConfig: containertypes.HostConfig{NetworkMode: "bridge", Privileged: true, EgressPolicy: policy},
+			platform:   "linux",
+			expected:   "egress policies are not supported on privileged containers",
+		},
+		{
+			doc:        "NET_ADMIN",
+			hostConfig: containertypes.HostConfig{NetworkMode: "bridge", CapAdd: []string{"net_admin"}, EgressPolicy: policy},
+			platform:   "linux",
+			expected:   "egress policies are not supported on containers with the NET_ADMIN capability",
+		},
+		{
+			doc:        "all capabilities",
+			hostConfig: containertypes.HostConfig{NetworkMode: "bridge", CapAdd: []string{"ALL"}, EgressPolicy: policy},
+			platform:   "linux",
+			expected:   "egress policies are not supported on containers with the NET_ADMIN capability",
+		},
+		{
+			doc: "invalid rule",
+			hostConfig: containertypes.HostConfig{NetworkMode: "bridge", EgressPolicy: &containertypes.EgressPolicy{
+				Rules: []containertypes.EgressRule{{Ports: "443"}},
+			}},
+			platform: "linux",
+			expected: "invalid egress rule 0: ports require the tcp or udp protocol",
+		},
+		{
+			doc:        "windows",
+			hostConfig: containertypes.HostConfig{NetworkMode: "nat", EgressPolicy: policy},
+			platform:   "windows",
+			expected:   "egress policies are not supported on Windows",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.doc, func(t *testing.T) {
+			err := validateEgressPolicy(&tc.hostConfig, tc.platform)
+			if tc.expected == "" {
+				assert.Check(t, err)
+			} else {
+				assert.Check(t, is.Error(err, tc.expected))
+			}
+		})
+	}
+}
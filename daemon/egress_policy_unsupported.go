@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"net"
+
+	"github.com/docker/docker/container"
+)
+
+func (daemon *Daemon) egressDNSResolvers(c *container.Container) []net.IP {
+	return nil
+}
@@ -1,14 +1,19 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
 	daemonevents "github.com/docker/docker/daemon/events"
+	units "github.com/docker/go-units"
 	"github.com/docker/libnetwork"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // LogContainerEvent generates an event related to a container with only the default attributes.
@@ -55,6 +60,24 @@ func (daemon *Daemon) LogVolumeEvent(volumeID, action string, attributes map[str
 	daemon.EventsService.Log(action, events.VolumeEventType, actor)
 }
 
+// LogSecretEvent generates an event related to a secret.
+func (daemon *Daemon) LogSecretEvent(secretID, name, action string) {
+	actor := events.Actor{
+		ID:         secretID,
+		Attributes: map[string]string{"name": name},
+	}
+	daemon.EventsService.Log(action, events.SecretEventType, actor)
+}
+
+// LogConfigEvent generates an event related to a config.
+func (daemon *Daemon) LogConfigEvent(configID, name, action string) {
+	actor := events.Actor{
+		ID:         configID,
+		Attributes: map[string]string{"name": name},
+	}
+	daemon.EventsService.Log(action, events.ConfigEventType, actor)
+}
+
 // LogNetworkEvent generates an event related to a network with only the default attributes.
 func (daemon *Daemon) LogNetworkEvent(nw libnetwork.Network, action string) {
 	daemon.LogNetworkEventWithAttributes(nw, action, map[string]string{})
@@ -85,6 +108,102 @@ func (daemon *Daemon) LogDaemonEventWithAttributes(action string, attributes map
 	}
 }
 
+// initEventsJournal starts recording events in the journal under the
+// data-root, and records an unclean shutdown of the previous daemon.
+func (daemon *Daemon) initEventsJournal(cfg *config.Config) error {
+	maxSize := int64(daemonevents.DefaultJournalMaxSize)
+	if cfg.EventsJournal.MaxSize != "" {
+		size, err := units.RAMInBytes(cfg.EventsJournal.MaxSize)
+		if err != nil {
+			return err
+		}
+		maxSize = size
+	}
+	flushInterval := daemonevents.DefaultJournalFlushInterval
+	if cfg.EventsJournal.FlushInterval != "" {
+		d, err := time.ParseDuration(cfg.EventsJournal.FlushInterval)
+		if err != nil {
+			return err
+		}
+		flushInterval = d
+	}
+
+	j, err := daemonevents.NewJournal(filepath.Join(cfg.Root, "events"), maxSize, flushInterval)
+	if err != nil {
+		return err
+	}
+	daemon.eventsJournal = j
+	daemon.EventsService.SetJournal(j)
+
+	if unclean, lastEvent := j.UncleanShutdown(); unclean {
+		attributes := map[string]string{}
+		if !lastEvent.IsZero() {
+			attributes["lastEvent"] = lastEvent.UTC().Format(time.RFC3339Nano)
+		}
+		logrus.Warn("daemon was not shut down cleanly")
+		daemon.EventsService.Log("unclean_shutdown", events.DaemonEventType, events.Actor{
+			ID:         daemon.ID,
+			Attributes: attributes,
+		})
+	}
+	return nil
+}
+
+// closeEventsJournal writes the pending events to the journal and closes it.
+func (daemon *Daemon) closeEventsJournal() {
+	if daemon.eventsJournal == nil {
+		return
+	}
+	if err := daemon.eventsJournal.Close(); err != nil {
+		logrus.WithError(err).Error("failed to close events journal")
+	}
+}
+
+// startEventForwarders starts forwarding events to the given sinks. The last
+// event delivered to each sink is recorded under root, so that delivery
+// resumes from there when the events journal is enabled.
+// Called with daemon.eventForwardersMu held, or while the daemon starts.
+func (daemon *Daemon) startEventForwarders(root string, sinks []config.EventSinkConfig) error {
+	for _, s := range sinks {
+		sink, err := daemonevents.NewSink(s.Type, s.Address, s.Headers)
+		if err != nil {
+			daemon.stopEventForwarders()
+			return errors.Wrapf(err, "event sink %s", s.Name)
+		}
+		filter := filters.NewArgs()
+		for k, values := range s.Filters {
+			for _, v := range values {
+				filter.Add(k, v)
+			}
+		}
+		f, err := daemonevents.NewForwarder(daemon.EventsService, daemon.eventsJournal, sink, daemonevents.ForwarderConfig{
+			Name:          s.Name,
+			Filter:        filter,
+			QueueSize:     s.QueueSize,
+			MaxRetries:    s.MaxRetries,
+			CheckpointDir: filepath.Join(root, "events", "sinks"),
+		})
+		if err != nil {
+			sink.Close()
+			daemon.stopEventForwarders()
+			return errors.Wrapf(err, "event sink %s", s.Name)
+		}
+		daemon.eventForwarders = append(daemon.eventForwarders, f)
+	}
+	return nil
+}
+
+// stopEventForwarders stops forwarding events to sinks.
+// Called with daemon.eventForwardersMu held.
+func (daemon *Daemon) stopEventForwarders() {
+	for _, f := range daemon.eventForwarders {
+		if err := f.Close(); err != nil {
+			logrus.WithError(err).Warn("failed to close event sink")
+		}
+	}
+	daemon.eventForwarders = nil
+}
+
 // SubscribeToEvents returns the currently record of events, a channel to stream new events from, and a function to cancel the stream of events.
 func (daemon *Daemon) SubscribeToEvents(since, until time.Time, filter filters.Args) ([]events.Message, chan interface{}) {
 	ef := daemonevents.NewFilter(filter)
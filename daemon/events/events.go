@@ -6,6 +6,7 @@ import (
 
 	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/pubsub"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -15,19 +16,36 @@ const (
 
 // Events is pubsub channel for events generated by the engine.
 type Events struct {
-	mu     sync.Mutex
-	events []eventtypes.Message
-	pub    *pubsub.Publisher
+	mu      sync.Mutex
+	events  []eventtypes.Message
+	pub     *pubsub.Publisher
+	journal *Journal
+	// journalPub publishes the events with their sequence number in the
+	// journal, in order.
+	journalPub *pubsub.Publisher
+	// seq is the sequence number in the journal of the last event
+	// published.
+	seq uint64
 }
 
 // New returns new *Events instance
 func New() *Events {
 	return &Events{
-		events: make([]eventtypes.Message, 0, eventsLimit),
-		pub:    pubsub.NewPublisher(100*time.Millisecond, bufferSize),
+		events:     make([]eventtypes.Message, 0, eventsLimit),
+		pub:        pubsub.NewPublisher(100*time.Millisecond, bufferSize),
+		journalPub: pubsub.NewPublisher(100*time.Millisecond, bufferSize),
 	}
 }
 
+// SetJournal makes e record all published events in j, and read the events
+// for time-range queries from it instead of the in-memory buffer.
+func (e *Events) SetJournal(j *Journal) {
+	e.mu.Lock()
+	e.journal = j
+	e.seq = j.lastSeq()
+	e.mu.Unlock()
+}
+
 // Subscribe adds new listener to events, returns slice of 256 stored
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion), and a function to call
@@ -51,14 +69,40 @@ func (e *Events) Subscribe() ([]eventtypes.Message, chan interface{}, func()) {
 // of interface{}, so you need type assertion).
 func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtypes.Message, chan interface{}) {
 	eventSubscribers.Inc()
-	e.mu.Lock()
 
 	var topic func(m interface{}) bool
 	if ef != nil && ef.filter.Len() > 0 {
 		topic = func(m interface{}) bool { return ef.Include(m.(eventtypes.Message)) }
 	}
 
-	buffered := e.loadBufferedEvents(since, until, topic)
+	// The journal is read without holding the lock, so that publishing is
+	// not blocked meanwhile. The events published after it started being
+	// read are taken from the in-memory buffer.
+	e.mu.Lock()
+	j, seq := e.journal, e.seq
+	e.mu.Unlock()
+	var (
+		journaled   []journalEntry
+		journalRead bool
+	)
+	if j != nil && (!since.IsZero() || !until.IsZero()) {
+		var err error
+		journaled, err = j.readEntries(since, until, topic)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to read events journal, using in-memory events")
+		} else {
+			journalRead = true
+		}
+	}
+
+	e.mu.Lock()
+
+	var buffered []eventtypes.Message
+	if journalRead {
+		buffered = e.withPublishedSince(journaled, seq, since, until, topic)
+	} else {
+		buffered = e.loadBufferedEvents(since, until, topic)
+	}
 
 	var ch chan interface{}
 	if topic != nil {
@@ -72,12 +116,35 @@ func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtype
 	return buffered, ch
 }
 
+// subscribeJournal adds a listener to the events recorded in the journal,
+// accepted by topic if it is not nil, which receives them as journalEntry
+// values in the order of their sequence numbers. It returns the sequence
+// number of the last event published before the listener was added.
+func (e *Events) subscribeJournal(topic func(interface{}) bool) (chan interface{}, uint64) {
+	eventSubscribers.Inc()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if topic == nil {
+		return e.journalPub.Subscribe(), e.seq
+	}
+	return e.journalPub.SubscribeTopic(func(v interface{}) bool {
+		return topic(v.(journalEntry).Message)
+	}), e.seq
+}
+
 // Evict evicts listener from pubsub
 func (e *Events) Evict(l chan interface{}) {
 	eventSubscribers.Dec()
 	e.pub.Evict(l)
 }
 
+// evictJournal evicts a listener added by subscribeJournal.
+func (e *Events) evictJournal(l chan interface{}) {
+	eventSubscribers.Dec()
+	e.journalPub.Evict(l)
+}
+
 // Log creates a local scope message and publishes it
 func (e *Events) Log(action, eventType string, actor eventtypes.Actor) {
 	now := time.Now().UTC()
@@ -117,6 +184,13 @@ func (e *Events) PublishMessage(jm eventtypes.Message) {
 	} else {
 		e.events = append(e.events, jm)
 	}
+	if e.journal != nil {
+		e.seq = e.journal.Append(jm)
+		// Published with the lock held, so that the events are received
+		// in order. The only listeners are event forwarders, which do not
+		// block.
+		e.journalPub.Publish(journalEntry{Message: jm, Seq: e.seq})
+	}
 	e.mu.Unlock()
 	e.pub.Publish(jm)
 }
@@ -163,3 +237,33 @@ func (e *Events) loadBufferedEvents(since, until time.Time, topic func(interface
 	}
 	return buffered
 }
+
+// withPublishedSince returns the events of the journal entries up to the
+// sequence number seq, followed by the events in the buffer published after
+// it that were emitted between since and until and are accepted by topic if
+// it is not nil.
+func (e *Events) withPublishedSince(entries []journalEntry, seq uint64, since, until time.Time, topic func(interface{}) bool) []eventtypes.Message {
+	events := make([]eventtypes.Message, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Seq <= seq {
+			events = append(events, entry.Message)
+		}
+	}
+
+	published := int(e.seq - seq)
+	if published > len(e.events) {
+		published = len(e.events)
+	}
+	for _, ev := range e.events[len(e.events)-published:] {
+		if !since.IsZero() && ev.TimeNano < since.UnixNano() {
+			continue
+		}
+		if !until.IsZero() && ev.TimeNano > until.UnixNano() {
+			continue
+		}
+		if topic == nil || topic(ev) {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
@@ -0,0 +1,290 @@
+package events // import "github.com/docker/docker/daemon/events"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultForwarderQueueSize is the default maximum number of events
+	// waiting to be delivered to a sink.
+	DefaultForwarderQueueSize = 1024
+	// DefaultForwarderMaxRetries is the default number of times delivering
+	// an event is retried.
+	DefaultForwarderMaxRetries = 5
+
+	forwarderMaxBackoff       = 30 * time.Second
+	forwarderCheckpointPeriod = time.Second
+)
+
+// ForwarderConfig is the configuration of a Forwarder.
+type ForwarderConfig struct {
+	// Name identifies the forwarder in logs, metrics and checkpoints.
+	Name string
+	// Filter selects the forwarded events.
+	Filter filters.Args
+	// QueueSize is the maximum number of events waiting to be delivered.
+	QueueSize int
+	// MaxRetries is the number of times delivering an event is retried
+	// before it is dropped. A negative value retries indefinitely.
+	MaxRetries int
+	// CheckpointDir is the directory in which the sequence number in the
+	// journal of the last delivered event is recorded. It is only used
+	// along with a journal.
+	CheckpointDir string
+}
+
+// Forwarder delivers events accepted by a filter to a Sink.
+//
+// Events are queued in memory, and dropped when the queue is full. When a
+// journal is used, delivery is at-least-once: events dropped from the queue
+// are read back from the journal, and so are events emitted since the last
+// one delivered before the forwarder was closed, including by a previous
+// daemon.
+type Forwarder struct {
+	name       string
+	events     *Events
+	journal    *Journal
+	sink       Sink
+	filter     *Filter
+	maxRetries int
+	checkpoint string
+
+	l          chan interface{}
+	queue      chan journalEntry
+	overflowed int32
+
+	// lastSent is the sequence number in the journal of the last event
+	// delivered or dropped.
+	lastSent uint64
+	dirty    bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewForwarder starts forwarding the events published to e to sink. j may
+// be nil.
+func NewForwarder(e *Events, j *Journal, sink Sink, cfg ForwarderConfig) (*Forwarder, error) {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultForwarderQueueSize
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultForwarderMaxRetries
+	}
+	f := &Forwarder{
+		name:       cfg.Name,
+		events:     e,
+		journal:    j,
+		sink:       sink,
+		filter:     NewFilter(cfg.Filter),
+		maxRetries: cfg.MaxRetries,
+		queue:      make(chan journalEntry, cfg.QueueSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	var lastSent uint64
+	if j != nil && cfg.CheckpointDir != "" {
+		if err := os.MkdirAll(cfg.CheckpointDir, 0700); err != nil {
+			return nil, errors.Wrap(err, "failed to create event forwarder checkpoint directory")
+		}
+		f.checkpoint = filepath.Join(cfg.CheckpointDir, cfg.Name)
+		var err error
+		if lastSent, err = readCheckpoint(f.checkpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	if j == nil {
+		_, f.l = e.SubscribeTopic(time.Time{}, time.Time{}, f.filter)
+	} else {
+		var topic func(interface{}) bool
+		if f.filter.filter.Len() > 0 {
+			topic = func(m interface{}) bool { return f.filter.Include(m.(eventtypes.Message)) }
+		}
+		f.l, f.lastSent = e.subscribeJournal(topic)
+		switch {
+		case lastSent > f.lastSent:
+			logrus.WithField("sink", f.name).Warn("ignoring event forwarder checkpoint ahead of the events journal")
+			f.dirty = true
+		case lastSent > 0:
+			f.lastSent = lastSent
+			// Deliver the events missed while not forwarding.
+			f.overflowed = 1
+		default:
+			f.dirty = true
+		}
+	}
+	go f.receive()
+	go f.send()
+	return f, nil
+}
+
+// Close stops forwarding events, and records the last one delivered. Events
+// waiting to be delivered are discarded.
+func (f *Forwarder) Close() error {
+	select {
+	case <-f.stop:
+		return nil
+	default:
+	}
+	if f.journal == nil {
+		f.events.Evict(f.l)
+	} else {
+		f.events.evictJournal(f.l)
+	}
+	close(f.stop)
+	<-f.done
+	f.writeCheckpoint()
+	return f.sink.Close()
+}
+
+// receive moves the events from the subscription to the queue, so that the
+// publisher is never blocked by a slow sink.
+func (f *Forwarder) receive() {
+	for v := range f.l {
+		var ev journalEntry
+		switch v := v.(type) {
+		case journalEntry:
+			ev = v
+		case eventtypes.Message:
+			ev.Message = v
+		default:
+			continue
+		}
+		select {
+		case f.queue <- ev:
+		default:
+			if atomic.SwapInt32(&f.overflowed, 1) == 0 {
+				logrus.WithField("sink", f.name).Warn("event sink queue is full, dropping events")
+			}
+			if f.journal == nil {
+				eventsForwarded.WithValues(f.name, "dropped").Inc()
+			}
+		}
+	}
+}
+
+func (f *Forwarder) send() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(forwarderCheckpointPeriod)
+	defer ticker.Stop()
+
+	for {
+		if f.journal != nil && atomic.SwapInt32(&f.overflowed, 0) == 1 {
+			if !f.replay() {
+				return
+			}
+		}
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			f.writeCheckpoint()
+		case ev := <-f.queue:
+			if !f.deliver(ev) {
+				return
+			}
+		}
+	}
+}
+
+// replay delivers the events recorded in the journal since the last one
+// delivered. It returns false if the forwarder was closed.
+func (f *Forwarder) replay() bool {
+	var topic func(interface{}) bool
+	if f.filter.filter.Len() > 0 {
+		topic = func(m interface{}) bool { return f.filter.Include(m.(eventtypes.Message)) }
+	}
+	events, err := f.journal.readEntries(time.Time{}, time.Time{}, topic)
+	if err != nil {
+		logrus.WithError(err).WithField("sink", f.name).Error("failed to read events journal")
+		return true
+	}
+	for _, ev := range events {
+		if !f.deliver(ev) {
+			return false
+		}
+	}
+	return true
+}
+
+// deliver sends ev to the sink, retrying with an exponential backoff. It
+// returns false if the forwarder was closed before ev was delivered.
+func (f *Forwarder) deliver(ev journalEntry) bool {
+	if f.journal != nil && ev.Seq <= f.lastSent {
+		// Already delivered, or recorded before the events the
+		// forwarder delivers.
+		return true
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		err := f.sink.Send(ev.Message)
+		if err == nil {
+			eventsForwarded.WithValues(f.name, "delivered").Inc()
+			break
+		}
+		log := logrus.WithError(err).WithField("sink", f.name)
+		if f.maxRetries >= 0 && attempt >= f.maxRetries {
+			log.Errorf("failed to deliver %s %s event after %d attempts, dropping it", ev.Type, ev.Action, attempt+1)
+			eventsForwarded.WithValues(f.name, "dropped").Inc()
+			break
+		}
+		log.Debugf("failed to deliver %s %s event, retrying in %v", ev.Type, ev.Action, backoff)
+
+		select {
+		case <-f.stop:
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > forwarderMaxBackoff {
+			backoff = forwarderMaxBackoff
+		}
+	}
+
+	f.lastSent = ev.Seq
+	f.dirty = true
+	return true
+}
+
+func (f *Forwarder) writeCheckpoint() {
+	if f.checkpoint == "" || !f.dirty {
+		return
+	}
+	data := []byte(strconv.FormatUint(f.lastSent, 10))
+	if err := ioutils.AtomicWriteFile(f.checkpoint, data, 0600); err != nil {
+		logrus.WithError(err).WithField("sink", f.name).Warn("failed to write event forwarder checkpoint")
+		return
+	}
+	f.dirty = false
+}
+
+func readCheckpoint(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "failed to read event forwarder checkpoint")
+	}
+	lastSent, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		logrus.WithError(err).WithField("path", path).Warn("ignoring invalid event forwarder checkpoint")
+		return 0, nil
+	}
+	return lastSent, nil
+}
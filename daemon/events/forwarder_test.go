@@ -0,0 +1,187 @@
+package events // import "github.com/docker/docker/daemon/events"
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+)
+
+type recordingSink struct {
+	mu       sync.Mutex
+	received []events.Message
+	failures int
+	block    chan struct{}
+}
+
+func (s *recordingSink) Send(ev events.Message) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return os.ErrDeadlineExceeded
+	}
+	s.received = append(s.received, ev)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return journalActions(s.received)
+}
+
+func waitActions(s *recordingSink, expected ...string) func(poll.LogT) poll.Result {
+	return func(poll.LogT) poll.Result {
+		actions := s.actions()
+		if len(actions) < len(expected) {
+			return poll.Continue("received %v", actions)
+		}
+		if len(actions) > len(expected) {
+			return poll.Error(nil)
+		}
+		for i := range expected {
+			if actions[i] != expected[i] {
+				return poll.Continue("received %v", actions)
+			}
+		}
+		return poll.Success()
+	}
+}
+
+func TestForwarderFilter(t *testing.T) {
+	e := New()
+	sink := &recordingSink{failures: 1}
+	f, err := NewForwarder(e, nil, sink, ForwarderConfig{
+		Name:   "test",
+		Filter: filters.NewArgs(filters.Arg("event", "start")),
+	})
+	assert.NilError(t, err)
+	defer f.Close()
+
+	e.Log("create", events.ContainerEventType, events.Actor{ID: "cont"})
+	e.Log("start", events.ContainerEventType, events.Actor{ID: "cont"})
+	poll.WaitOn(t, waitActions(sink, "start"), poll.WithDelay(10*time.Millisecond))
+}
+
+func TestForwarderFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-forwarder")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events.log")
+	sink, err := NewSink(SinkFile, path, nil)
+	assert.NilError(t, err)
+
+	e := New()
+	f, err := NewForwarder(e, nil, sink, ForwarderConfig{Name: "file"})
+	assert.NilError(t, err)
+	e.Log("start", events.ContainerEventType, events.Actor{ID: "cont"})
+	e.Log("die", events.ContainerEventType, events.Actor{ID: "cont"})
+
+	var actions []string
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		fh, err := os.Open(path)
+		if err != nil {
+			return poll.Error(err)
+		}
+		defer fh.Close()
+		actions = nil
+		scanner := bufio.NewScanner(fh)
+		for scanner.Scan() {
+			var ev events.Message
+			if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+				return poll.Error(err)
+			}
+			actions = append(actions, ev.Action)
+		}
+		if len(actions) < 2 {
+			return poll.Continue("received %v", actions)
+		}
+		return poll.Success()
+	}, poll.WithDelay(10*time.Millisecond))
+	assert.NilError(t, f.Close())
+	assert.Check(t, is.DeepEqual(actions, []string{"start", "die"}))
+}
+
+func TestForwarderReplaysFromJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-forwarder")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	j, err := NewJournal(filepath.Join(dir, "journal"), 0, time.Hour)
+	assert.NilError(t, err)
+	defer j.Close()
+	e := New()
+	e.SetJournal(j)
+
+	cfg := ForwarderConfig{
+		Name:          "test",
+		QueueSize:     1,
+		CheckpointDir: filepath.Join(dir, "sinks"),
+	}
+
+	// Overflow the queue while the sink is blocked; the dropped events are
+	// read back from the journal.
+	sink := &recordingSink{block: make(chan struct{})}
+	f, err := NewForwarder(e, j, sink, cfg)
+	assert.NilError(t, err)
+	for _, action := range []string{"create", "start", "pause", "unpause"} {
+		e.Log(action, events.ContainerEventType, events.Actor{ID: "cont"})
+	}
+	close(sink.block)
+	poll.WaitOn(t, waitActions(sink, "create", "start", "pause", "unpause"), poll.WithDelay(10*time.Millisecond))
+	assert.NilError(t, f.Close())
+
+	// Events emitted while not forwarding are delivered on restart.
+	e.Log("die", events.ContainerEventType, events.Actor{ID: "cont"})
+	sink = &recordingSink{}
+	f, err = NewForwarder(e, j, sink, cfg)
+	assert.NilError(t, err)
+	defer f.Close()
+	e.Log("destroy", events.ContainerEventType, events.Actor{ID: "cont"})
+	poll.WaitOn(t, waitActions(sink, "die", "destroy"), poll.WithDelay(10*time.Millisecond))
+}
+
+func TestForwarderDeliversEventsWithEqualTimes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-forwarder")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	j, err := NewJournal(filepath.Join(dir, "journal"), 0, time.Hour)
+	assert.NilError(t, err)
+	defer j.Close()
+	e := New()
+	e.SetJournal(j)
+
+	sink := &recordingSink{block: make(chan struct{})}
+	f, err := NewForwarder(e, j, sink, ForwarderConfig{
+		Name:          "test",
+		QueueSize:     1,
+		CheckpointDir: filepath.Join(dir, "sinks"),
+	})
+	assert.NilError(t, err)
+	defer f.Close()
+
+	now := time.Now()
+	for _, action := range []string{"create", "start", "pause", "unpause"} {
+		e.PublishMessage(journalEvent(action, now))
+	}
+	close(sink.block)
+	poll.WaitOn(t, waitActions(sink, "create", "start", "pause", "unpause"), poll.WithDelay(10*time.Millisecond))
+}
@@ -0,0 +1,401 @@
+package events // import "github.com/docker/docker/daemon/events"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	journalFile     = "events.log"
+	journalMarker   = "running"
+	maxPendingFlush = 256
+	// maxPendingKept is the maximum number of events kept in memory while
+	// they cannot be written to disk.
+	maxPendingKept = 64 * maxPendingFlush
+
+	// DefaultJournalMaxSize is the default maximum size of the journal.
+	DefaultJournalMaxSize = 10 * 1024 * 1024
+	// DefaultJournalFlushInterval is the default maximum time events are
+	// kept in memory before being written to disk.
+	DefaultJournalFlushInterval = 10 * time.Second
+)
+
+// Journal is a size-capped on-disk record of events. Events are buffered in
+// memory and written in batches, each followed by a single fsync, to limit
+// the number of writes to the storage.
+//
+// The journal is made of two files, the current one and the previous one,
+// which is replaced when the current one reaches half of the maximum size.
+//
+// Each event is assigned a sequence number, increasing across daemon
+// restarts, which orders the events recorded in the journal.
+type Journal struct {
+	dir     string
+	maxSize int64
+
+	// mu protects the in-memory state, and is never held while accessing
+	// the files.
+	mu      sync.Mutex
+	seq     uint64
+	pending []journalEntry
+
+	// fileMu serializes the accesses to the files.
+	fileMu sync.Mutex
+	f      *os.File
+	size   int64
+
+	unclean   bool
+	lastEvent time.Time
+
+	flush  chan struct{}
+	closed chan struct{}
+	done   chan struct{}
+}
+
+// NewJournal opens the journal in dir, creating it if needed. Events are
+// written to disk every flushInterval, or earlier if many are pending.
+func NewJournal(dir string, maxSize int64, flushInterval time.Duration) (*Journal, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultJournalMaxSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultJournalFlushInterval
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create events journal directory")
+	}
+
+	j := &Journal{
+		dir:     dir,
+		maxSize: maxSize,
+		flush:   make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	entries, err := j.read()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		j.seq = entries[len(entries)-1].Seq
+	}
+
+	// The marker is removed on Close; finding it means that the previous
+	// daemon did not shut down cleanly.
+	marker := filepath.Join(dir, journalMarker)
+	if _, err := os.Stat(marker); err == nil {
+		j.unclean = true
+		if len(entries) > 0 {
+			j.lastEvent = time.Unix(0, entries[len(entries)-1].TimeNano)
+		}
+	}
+	if err := ioutil.WriteFile(marker, nil, 0600); err != nil {
+		return nil, errors.Wrap(err, "failed to create events journal marker")
+	}
+
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+
+	go j.run(flushInterval)
+	return j, nil
+}
+
+// UncleanShutdown reports whether the journal was not closed the last time
+// it was used, and the time of the last event it recorded then.
+func (j *Journal) UncleanShutdown() (bool, time.Time) {
+	return j.unclean, j.lastEvent
+}
+
+// journalEntry is an event recorded in the journal, with its sequence
+// number. Entries written before sequence numbers were recorded have none.
+type journalEntry struct {
+	eventtypes.Message
+	Seq uint64 `json:"journalSeq,omitempty"`
+}
+
+// Append queues an event to be written to the journal, and returns its
+// sequence number.
+func (j *Journal) Append(ev eventtypes.Message) uint64 {
+	j.mu.Lock()
+	j.seq++
+	seq := j.seq
+	j.pending = append(j.pending, journalEntry{Message: ev, Seq: seq})
+	n := len(j.pending)
+	j.mu.Unlock()
+
+	if n >= maxPendingFlush {
+		select {
+		case j.flush <- struct{}{}:
+		default:
+		}
+	}
+	return seq
+}
+
+// lastSeq returns the sequence number of the last event appended.
+func (j *Journal) lastSeq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// Read returns the recorded events emitted between since and until, which
+// are ignored when zero, and accepted by topic if it is not nil. Events
+// that were not written to disk yet are included.
+func (j *Journal) Read(since, until time.Time, topic func(interface{}) bool) ([]eventtypes.Message, error) {
+	entries, err := j.readEntries(since, until, topic)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]eventtypes.Message, 0, len(entries))
+	for _, e := range entries {
+		events = append(events, e.Message)
+	}
+	return events, nil
+}
+
+// readEntries is like Read, but returns the entries with their sequence
+// numbers.
+func (j *Journal) readEntries(since, until time.Time, topic func(interface{}) bool) ([]journalEntry, error) {
+	j.fileMu.Lock()
+	entries, err := j.read()
+	j.mu.Lock()
+	entries = append(entries, j.pending...)
+	j.mu.Unlock()
+	j.fileMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var sinceNano, untilNano int64
+	if !since.IsZero() {
+		sinceNano = since.UnixNano()
+	}
+	if !until.IsZero() {
+		untilNano = until.UnixNano()
+	}
+
+	var result []journalEntry
+	for _, e := range entries {
+		if e.TimeNano < sinceNano || (untilNano > 0 && e.TimeNano > untilNano) {
+			continue
+		}
+		if topic == nil || topic(e.Message) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// Close writes pending events to disk and closes the journal, marking it as
+// cleanly shut down.
+func (j *Journal) Close() error {
+	select {
+	case <-j.closed:
+		return nil
+	default:
+	}
+	close(j.closed)
+	<-j.done
+
+	j.fileMu.Lock()
+	defer j.fileMu.Unlock()
+	if j.f != nil {
+		if err := j.f.Close(); err != nil {
+			return err
+		}
+	}
+	return os.Remove(filepath.Join(j.dir, journalMarker))
+}
+
+func (j *Journal) run(flushInterval time.Duration) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-j.flush:
+		case <-j.closed:
+			j.writePending()
+			return
+		}
+		j.writePending()
+	}
+}
+
+// writePending writes the pending events to disk. The events stay pending
+// until they are written, so that they are neither lost nor missing from
+// reads if writing them fails.
+func (j *Journal) writePending() {
+	j.fileMu.Lock()
+	defer j.fileMu.Unlock()
+
+	// Events are only appended to the pending ones meanwhile, so the batch
+	// is still their beginning once written.
+	j.mu.Lock()
+	batch := j.pending
+	j.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range batch {
+		if err := enc.Encode(e); err != nil {
+			logrus.WithError(err).Warn("failed to encode event for journal")
+		}
+	}
+
+	if err := j.write(buf.Bytes()); err != nil {
+		logrus.WithError(err).Error("failed to write events journal")
+		j.mu.Lock()
+		if n := len(j.pending) - maxPendingKept; n > 0 {
+			logrus.Warnf("dropping %d events that could not be written to the journal", n)
+			j.pending = append(j.pending[:0:0], j.pending[n:]...)
+		}
+		j.mu.Unlock()
+		return
+	}
+
+	j.mu.Lock()
+	j.pending = append(j.pending[:0:0], j.pending[len(batch):]...)
+	j.mu.Unlock()
+}
+
+// write appends data to the journal, rotating it first if needed, and syncs
+// it to disk. Data partially written is removed on failure. The data is
+// written to the current file if it cannot be rotated.
+// Called with j.fileMu held.
+func (j *Journal) write(data []byte) error {
+	if j.f != nil && j.size > 0 && j.size+int64(len(data)) > j.maxSize/2 {
+		if err := j.rotate(); err != nil {
+			logrus.WithError(err).Error("failed to rotate events journal")
+		}
+	}
+	if j.f == nil {
+		// Reopening the journal failed when it was last rotated.
+		if err := j.open(); err != nil {
+			return err
+		}
+	}
+	n, err := j.f.Write(data)
+	if err != nil {
+		if n > 0 {
+			if err := j.f.Truncate(j.size); err != nil {
+				logrus.WithError(err).Error("failed to remove partially written events from the journal")
+				j.size += int64(n)
+			}
+		}
+		return err
+	}
+	j.size += int64(n)
+	if err := j.f.Sync(); err != nil {
+		// The events were written, even if they may not be on disk yet;
+		// writing them again would record them twice.
+		logrus.WithError(err).Error("failed to sync events journal")
+	}
+	return nil
+}
+
+func (j *Journal) open() error {
+	name := filepath.Join(j.dir, journalFile)
+	if err := truncatePartialEntry(name); err != nil {
+		return errors.Wrap(err, "failed to repair events journal")
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open events journal")
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to open events journal")
+	}
+	j.f = f
+	j.size = fi.Size()
+	return nil
+}
+
+// truncatePartialEntry removes an incomplete last line from the file, so
+// that new entries are not appended to it.
+func truncatePartialEntry(name string) error {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	return os.Truncate(name, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+// rotate replaces the previous file with the current one. The current file
+// is reopened if it cannot be renamed. j.f is nil if it cannot be reopened.
+// Called with j.fileMu held.
+func (j *Journal) rotate() error {
+	err := j.f.Close()
+	j.f = nil
+	if err == nil {
+		current := filepath.Join(j.dir, journalFile)
+		err = os.Rename(current, current+".1")
+	}
+	if openErr := j.open(); openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+// read returns all the entries written to disk, oldest first.
+// Called with j.fileMu held, or before the journal is in use.
+func (j *Journal) read() ([]journalEntry, error) {
+	var events []journalEntry
+	current := filepath.Join(j.dir, journalFile)
+	for _, name := range []string{current + ".1", current} {
+		f, err := os.Open(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "failed to read events journal")
+		}
+		events = readJournalFile(f, events)
+		f.Close()
+	}
+	return events, nil
+}
+
+// readJournalFile appends the entries in r to events. Reading stops at the
+// first malformed entry, such as one partially written before a crash.
+func readJournalFile(r io.Reader, events []journalEntry) []journalEntry {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var ev journalEntry
+		if err := dec.Decode(&ev); err != nil {
+			if err != io.EOF {
+				logrus.WithError(err).Warn("ignoring malformed events journal entry")
+			}
+			return events
+		}
+		events = append(events, ev)
+	}
+}
@@ -0,0 +1,219 @@
+package events // import "github.com/docker/docker/daemon/events"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func journalEvent(action string, t time.Time) events.Message {
+	return events.Message{
+		Action:   action,
+		Type:     events.ContainerEventType,
+		Time:     t.Unix(),
+		TimeNano: t.UnixNano(),
+	}
+}
+
+func journalActions(msgs []events.Message) []string {
+	var actions []string
+	for _, m := range msgs {
+		actions = append(actions, m.Action)
+	}
+	return actions
+}
+
+func TestJournalPersistsAcrossRestarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-journal")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	base := time.Unix(1000, 0)
+	j, err := NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	unclean, _ := j.UncleanShutdown()
+	assert.Check(t, !unclean)
+	for i, action := range []string{"create", "start", "die"} {
+		j.Append(journalEvent(action, base.Add(time.Duration(i)*time.Second)))
+	}
+
+	// Pending events are included before being written.
+	msgs, err := j.Read(base.Add(time.Second), time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(journalActions(msgs), []string{"start", "die"}))
+	assert.NilError(t, j.Close())
+
+	j, err = NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	defer j.Close()
+	unclean, _ = j.UncleanShutdown()
+	assert.Check(t, !unclean)
+
+	msgs, err = j.Read(base, base.Add(time.Second), nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(journalActions(msgs), []string{"create", "start"}))
+
+	msgs, err = j.Read(base, time.Time{}, func(m interface{}) bool { return m.(events.Message).Action == "die" })
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(journalActions(msgs), []string{"die"}))
+}
+
+func TestJournalUncleanShutdown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-journal")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	last := time.Unix(2000, 0)
+	j, err := NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	j.Append(journalEvent("start", last))
+	j.writePending()
+
+	// Simulate a crash in the middle of writing an entry.
+	f, err := os.OpenFile(filepath.Join(dir, journalFile), os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NilError(t, err)
+	_, err = f.WriteString(`{"Action":"di`)
+	assert.NilError(t, err)
+	f.Close()
+
+	j2, err := NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	defer j2.Close()
+	unclean, lastEvent := j2.UncleanShutdown()
+	assert.Check(t, unclean)
+	assert.Check(t, lastEvent.Equal(last))
+
+	j2.Append(journalEvent("restart", last.Add(time.Second)))
+	j2.writePending()
+	msgs, err := j2.Read(last, time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(journalActions(msgs), []string{"start", "restart"}))
+}
+
+func TestJournalRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-journal")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	j, err := NewJournal(dir, 1024, time.Hour)
+	assert.NilError(t, err)
+	defer j.Close()
+
+	base := time.Unix(3000, 0)
+	for i := 0; i < 100; i++ {
+		j.Append(journalEvent("start", base.Add(time.Duration(i)*time.Second)))
+		j.writePending()
+	}
+
+	var total int64
+	for _, name := range []string{journalFile, journalFile + ".1"} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		assert.NilError(t, err)
+		total += fi.Size()
+	}
+	assert.Check(t, total <= 1024, "journal size %d exceeds maximum", total)
+
+	msgs, err := j.Read(base, time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, len(msgs) > 0)
+	assert.Check(t, is.Equal(msgs[len(msgs)-1].TimeNano, base.Add(99*time.Second).UnixNano()))
+}
+
+func TestJournalRotationFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-journal")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	j, err := NewJournal(dir, 1024, time.Hour)
+	assert.NilError(t, err)
+	defer j.Close()
+
+	// Make renaming the current file fail.
+	previous := filepath.Join(dir, journalFile+".1")
+	assert.NilError(t, os.MkdirAll(filepath.Join(previous, "busy"), 0700))
+
+	base := time.Unix(5000, 0)
+	for i := 0; i < 20; i++ {
+		j.Append(journalEvent("start", base.Add(time.Duration(i)*time.Second)))
+		j.writePending()
+	}
+	assert.Check(t, is.Len(j.pending, 0))
+	msgs, err := j.Read(base, time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(msgs, 20))
+
+	// Rotating works again once the file can be renamed.
+	assert.NilError(t, os.RemoveAll(previous))
+	j.Append(journalEvent("die", base.Add(time.Minute)))
+	j.writePending()
+	assert.Check(t, is.Len(j.pending, 0))
+	_, err = os.Stat(previous)
+	assert.Check(t, err)
+	msgs, err = j.Read(base, time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, len(msgs) > 0)
+	assert.Check(t, is.Equal(msgs[len(msgs)-1].Action, "die"))
+}
+
+func TestSubscribeTopicReadsJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-journal")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	old := time.Now().Add(-time.Hour)
+	j, err := NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	j.Append(journalEvent("create", old))
+	assert.NilError(t, j.Close())
+
+	j, err = NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	defer j.Close()
+
+	e := New()
+	e.SetJournal(j)
+	e.Log("start", events.ContainerEventType, events.Actor{ID: "cont"})
+
+	msgs, l := e.SubscribeTopic(old, time.Time{}, nil)
+	defer e.Evict(l)
+	assert.Check(t, is.DeepEqual(journalActions(msgs), []string{"create", "start"}))
+}
+
+func TestJournalKeepsEventsFailingToBeWritten(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events-journal")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	base := time.Unix(4000, 0)
+	j, err := NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(j.Append(journalEvent("create", base)), uint64(1)))
+
+	// Make writing fail.
+	assert.NilError(t, j.f.Close())
+	j.writePending()
+	msgs, err := j.Read(base, time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(journalActions(msgs), []string{"create"}))
+
+	assert.NilError(t, j.open())
+	assert.Check(t, is.Equal(j.Append(journalEvent("start", base.Add(time.Second))), uint64(2)))
+	j.writePending()
+	assert.Check(t, is.Len(j.pending, 0))
+	assert.NilError(t, j.Close())
+
+	// Sequence numbers keep increasing across restarts.
+	j, err = NewJournal(dir, 0, time.Hour)
+	assert.NilError(t, err)
+	defer j.Close()
+	assert.Check(t, is.Equal(j.Append(journalEvent("die", base.Add(2*time.Second))), uint64(3)))
+	msgs, err = j.Read(base, time.Time{}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(journalActions(msgs), []string{"create", "start", "die"}))
+}
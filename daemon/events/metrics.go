@@ -5,11 +5,13 @@ import metrics "github.com/docker/go-metrics"
 var (
 	eventsCounter    metrics.Counter
 	eventSubscribers metrics.Gauge
+	eventsForwarded  metrics.LabeledCounter
 )
 
 func init() {
 	ns := metrics.NewNamespace("engine", "daemon", nil)
 	eventsCounter = ns.NewCounter("events", "The number of events logged")
 	eventSubscribers = ns.NewGauge("events_subscribers", "The number of current subscribers to events", metrics.Total)
+	eventsForwarded = ns.NewLabeledCounter("events_forwarded", "The number of events delivered to or dropped by event sinks", "sink", "result")
 	metrics.Register(ns)
 }
@@ -0,0 +1,174 @@
+package events // import "github.com/docker/docker/daemon/events"
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/pkg/errors"
+)
+
+// Sink types supported by NewSink.
+const (
+	SinkWebhook  = "webhook"
+	SinkUnixgram = "unixgram"
+	SinkFile     = "file"
+)
+
+// webhookTimeout is the maximum time a webhook has to accept an event.
+const webhookTimeout = 10 * time.Second
+
+// Sink is a destination events are forwarded to.
+type Sink interface {
+	// Send delivers an event, returning an error if it may not have been
+	// received.
+	Send(eventtypes.Message) error
+	Close() error
+}
+
+// NewSink returns a sink of the given type. headers are only used by
+// webhooks.
+func NewSink(sinkType, address string, headers map[string]string) (Sink, error) {
+	switch sinkType {
+	case SinkWebhook:
+		return newWebhookSink(address, headers), nil
+	case SinkUnixgram:
+		return &unixgramSink{address: address}, nil
+	case SinkFile:
+		return newFileSink(address)
+	default:
+		return nil, errors.Errorf("unsupported event sink type %q", sinkType)
+	}
+}
+
+// webhookSink POSTs each event as JSON to a URL.
+type webhookSink struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+func newWebhookSink(url string, headers map[string]string) *webhookSink {
+	h := http.Header{}
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	h.Set("Content-Type", "application/json")
+	return &webhookSink{
+		url:     url,
+		headers: h,
+		client:  &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (s *webhookSink) Send(ev eventtypes.Message) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.headers {
+		req.Header[k] = v
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// unixgramSink sends each event as a JSON datagram to a unix socket. The
+// socket is connected lazily, so that the receiver may be started after the
+// daemon.
+type unixgramSink struct {
+	address string
+	mu      sync.Mutex
+	conn    net.Conn
+}
+
+func (s *unixgramSink) Send(ev eventtypes.Message) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		conn, err := net.Dial("unixgram", s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if _, err := s.conn.Write(data); err != nil {
+		// The receiver may have been restarted; reconnect on the next
+		// attempt.
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *unixgramSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// fileSink appends each event as a line of JSON to a file.
+type fileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open event sink file")
+	}
+	return &fileSink{f: f}, nil
+}
+
+func (s *fileSink) Send(ev eventtypes.Message) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.f.Write(append(data, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
@@ -30,17 +30,37 @@ type mountOptions struct {
 	Type   string
 	Label  string
 	Flag   uint32
+
+	// NoSysResource drops CAP_SYS_RESOURCE before mounting.
+	NoSysResource bool
 }
 
 func mountFrom(dir, device, target, mType string, flags uintptr, label string) error {
-	options := &mountOptions{
+	return reexecMount(dir, &mountOptions{
 		Device: device,
 		Target: target,
 		Type:   mType,
 		Flag:   uint32(flags),
 		Label:  label,
-	}
+	})
+}
 
+// mountFromWithoutSysResource is mountFrom, with the mount made by a process
+// without CAP_SYS_RESOURCE. Overlayfs writes to the upper layer, copy-ups
+// included, with the credentials of the process that mounted it, and ext4
+// does not enforce project quotas on CAP_SYS_RESOURCE.
+func mountFromWithoutSysResource(dir, device, target, mType string, flags uintptr, label string) error {
+	return reexecMount(dir, &mountOptions{
+		Device:        device,
+		Target:        target,
+		Type:          mType,
+		Flag:          uint32(flags),
+		Label:         label,
+		NoSysResource: true,
+	})
+}
+
+func reexecMount(dir string, options *mountOptions) error {
 	cmd := reexec.Command("docker-mountfrom", dir)
 	w, err := cmd.StdinPipe()
 	if err != nil {
@@ -82,9 +102,31 @@ func mountFromMain() {
 		fatal(err)
 	}
 
+	if options.NoSysResource {
+		if err := dropSysResource(); err != nil {
+			fatal(err)
+		}
+	}
+
 	if err := unix.Mount(options.Device, options.Target, options.Type, uintptr(options.Flag), options.Label); err != nil {
 		fatal(err)
 	}
 
 	os.Exit(0)
 }
+
+// dropSysResource removes CAP_SYS_RESOURCE from the effective and permitted
+// capabilities of the calling thread.
+func dropSysResource() error {
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	if err := unix.Capget(&hdr, &data[0]); err != nil {
+		return fmt.Errorf("mountfrom capget failed: %v", err)
+	}
+	data[0].Effective &^= 1 << unix.CAP_SYS_RESOURCE
+	data[0].Permitted &^= 1 << unix.CAP_SYS_RESOURCE
+	if err := unix.Capset(&hdr, &data[0]); err != nil {
+		return fmt.Errorf("mountfrom capset failed: %v", err)
+	}
+	return nil
+}
@@ -105,6 +105,10 @@ type Driver struct {
 	naiveDiff     graphdriver.DiffDriver
 	supportsDType bool
 	locker        *locker.Locker
+
+	// mountNoSysResource makes the writable layers be mounted without
+	// CAP_SYS_RESOURCE, for their project quotas to be enforced.
+	mountNoSysResource bool
 }
 
 var (
@@ -197,16 +201,19 @@ func Init(home string, options []string, uidMaps, gidMaps []idtools.IDMap) (grap
 
 	d.naiveDiff = graphdriver.NewNaiveDiffDriver(d, uidMaps, gidMaps)
 
-	if backingFs == "xfs" {
-		// Try to enable project quota support over xfs.
+	if backingFs == "xfs" || backingFs == "extfs" {
+		// Try to enable project quota support over xfs or ext4.
 		if d.quotaCtl, err = quota.NewControl(home); err == nil {
 			projectQuotaSupported = true
+			// ext4 lets CAP_SYS_RESOURCE exceed the project quotas, so the
+			// layers are mounted without it.
+			d.mountNoSysResource = backingFs == "extfs"
 		} else if opts.quota.Size > 0 {
 			return nil, fmt.Errorf("Storage option overlay2.size not supported. Filesystem does not support Project Quota: %v", err)
 		}
 	} else if opts.quota.Size > 0 {
-		// if xfs is not the backing fs then error out if the storage-opt overlay2.size is used.
-		return nil, fmt.Errorf("Storage Option overlay2.size only supported for backingFS XFS. Found %v", backingFs)
+		// if xfs or ext4 is not the backing fs then error out if the storage-opt overlay2.size is used.
+		return nil, fmt.Errorf("Storage Option overlay2.size only supported for backingFS XFS and ext4. Found %v", backingFs)
 	}
 
 	// figure out whether "index=off" option is recognized by the kernel
@@ -340,7 +347,7 @@ func (d *Driver) CreateReadWrite(id, parent string, opts *graphdriver.CreateOpts
 	}
 
 	if _, ok := opts.StorageOpt["size"]; ok && !projectQuotaSupported {
-		return fmt.Errorf("--storage-opt is supported only for overlay over xfs with 'pquota' mount option")
+		return fmt.Errorf("--storage-opt is supported only for overlay over xfs with 'pquota' mount option, or ext4 with 'prjquota' mount option")
 	}
 
 	return d.create(id, parent, opts)
@@ -589,6 +596,16 @@ func (d *Driver) Get(id, mountLabel string) (_ containerfs.ContainerFS, retErr e
 	mount := unix.Mount
 	mountTarget := mergedDir
 
+	// The writable layers are mounted by a helper without CAP_SYS_RESOURCE,
+	// see mountFromWithoutSysResource.
+	mountFrom := mountFrom
+	if !readonly && d.mountNoSysResource {
+		mountFrom = mountFromWithoutSysResource
+		mount = func(source string, target string, mType string, flags uintptr, label string) error {
+			return mountFrom(d.home, source, target, mType, flags, label)
+		}
+	}
+
 	rootUID, rootGID, err := idtools.GetRootUIDGID(d.uidMaps, d.gidMaps)
 	if err != nil {
 		return nil, err
@@ -777,7 +794,8 @@ func (d *Driver) List() ([]string, error) {
 	}
 	ids := make([]string, 0, len(entries)-1)
 	for _, file := range entries {
-		if file.Name() != linkDir {
+		// Skip the device node made for project quotas.
+		if file.Name() != linkDir && file.IsDir() {
 			ids = append(ids, file.Name())
 		}
 	}
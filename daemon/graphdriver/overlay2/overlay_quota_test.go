@@ -0,0 +1,104 @@
+//go:build linux
+// +build linux
+
+package overlay2 // import "github.com/docker/docker/daemon/graphdriver/overlay2"
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/quota"
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/skip"
+)
+
+const quotaSize = 1024 * 1024
+
+func TestOverlayQuota(t *testing.T) {
+	for _, fsType := range []string{"xfs", "ext4"} {
+		fsType := fsType
+		t.Run(fsType, func(t *testing.T) {
+			if msg, ok := quota.CanTestQuota(fsType); !ok {
+				t.Skip(msg)
+			}
+			imageFileName, err := quota.PrepareQuotaTestImage(t, fsType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(imageFileName)
+
+			t.Run("testLayerWithQuota", quota.WrapMountTest(imageFileName, fsType, true, testLayerWithQuota))
+		})
+	}
+}
+
+// testLayerWithQuota checks that the quota of a layer is enforced on the
+// writes through the overlay mount, even those of the daemon, which has
+// CAP_SYS_RESOURCE, and on the files copied up from the lower layers.
+func testLayerWithQuota(t *testing.T, mountPoint, backingFsDev, testDir string) {
+	d, err := Init(filepath.Join(testDir, "overlay2"), nil, nil, nil)
+	if err == graphdriver.ErrNotSupported {
+		t.Skip("overlay is not supported")
+	}
+	assert.NilError(t, err)
+	defer d.Cleanup()
+
+	assert.NilError(t, d.Create("base", "", nil))
+	base, err := d.Get("base", "")
+	assert.NilError(t, err)
+	bigFile := filepath.Join(base.Path(), "big")
+	err = ioutil.WriteFile(bigFile, make([]byte, 2*quotaSize), 0644)
+	d.Put("base")
+	assert.NilError(t, err)
+
+	opts := &graphdriver.CreateOpts{StorageOpt: map[string]string{"size": "1M"}}
+	assert.NilError(t, d.CreateReadWrite("rw", "base", opts))
+	rw, err := d.Get("rw", "")
+	assert.NilError(t, err)
+	defer d.Put("rw")
+
+	testfile := filepath.Join(rw.Path(), "testfile")
+	assert.NilError(t, ioutil.WriteFile(testfile, make([]byte, quotaSize/2), 0644))
+	assert.NilError(t, os.Remove(testfile))
+
+	err = ioutil.WriteFile(testfile, make([]byte, quotaSize+1), 0644)
+	assert.Check(t, errors.Is(err, unix.EDQUOT), err)
+
+	_, err = os.OpenFile(filepath.Join(rw.Path(), "big"), os.O_WRONLY|os.O_APPEND, 0)
+	assert.Check(t, errors.Is(err, unix.EDQUOT), err)
+}
+
+// TestOverlayMountWithoutSysResource checks the writable layers still mount
+// and take writes when mounted without CAP_SYS_RESOURCE, as over ext4.
+func TestOverlayMountWithoutSysResource(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	home := fs.NewDir(t, "overlay2-no-sys-resource")
+	defer home.Remove()
+
+	driver, err := Init(home.Join("overlay2"), nil, nil, nil)
+	if err == graphdriver.ErrNotSupported {
+		t.Skip("overlay is not supported")
+	}
+	assert.NilError(t, err)
+	d := driver.(*Driver)
+	defer d.Cleanup()
+	d.mountNoSysResource = true
+
+	assert.NilError(t, d.Create("base", "", nil))
+	assert.NilError(t, d.CreateReadWrite("rw", "base", nil))
+	rw, err := d.Get("rw", "")
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(rw.Path(), "testfile"), []byte("test"), 0644))
+	assert.NilError(t, d.Put("rw"))
+
+	data, err := ioutil.ReadFile(filepath.Join(d.getDiffPath("rw"), "testfile"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "test")
+}
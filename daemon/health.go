@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -142,6 +143,7 @@ func handleProbeResult(d *Daemon, c *container.Container, result *types.Healthch
 
 	if result.ExitCode == exitStatusHealthy {
 		h.FailingStreak = 0
+		h.RestartCount = 0
 		h.SetStatus(types.Healthy)
 	} else { // Failure (including invalid exit code)
 		shouldIncrementStreak := true
@@ -179,25 +181,66 @@ func handleProbeResult(d *Daemon, c *container.Container, result *types.Healthch
 	current := h.Status()
 	if oldStatus != current {
 		d.LogContainerEvent(c, "health_status: "+current)
-
-		if d.HasExperimental() && current == types.Unhealthy {
-			restart, wait, err := c.RestartManager().ShouldRestart(0, false, time.Since(c.StartedAt), c.Health.Health)
-			if err == nil && restart {
-				logrus.Infof("Unhealthy container %v: restarting...", c.ID)
-				go func() {
-					err := <-wait
-					if err == nil {
-						d.stopHealthchecks(c)
-						if err := d.containerRestart(c, c.StopTimeout()); err != nil {
-							logrus.Debugf("failed to restart container: %+v", err)
-						}
-					} else if err != restartmanager.ErrRestartCanceled {
-						logrus.Errorf("restartmanger wait error: %+v", err)
+	}
+	if current == types.Unhealthy && c.HostConfig != nil && c.HostConfig.RestartPolicy.OnUnhealthy != nil {
+		// Checked on every failed probe, so that a container which became
+		// unhealthy within the grace period is restarted once it is over.
+		d.restartUnhealthy(c, oldStatus != current)
+	} else if oldStatus != current && d.HasExperimental() && current == types.Unhealthy {
+		restart, wait, err := c.RestartManager().ShouldRestart(0, false, time.Since(c.StartedAt), c.Health.Health)
+		if err == nil && restart {
+			logrus.Infof("Unhealthy container %v: restarting...", c.ID)
+			go func() {
+				err := <-wait
+				if err == nil {
+					d.stopHealthchecks(c)
+					if err := d.containerRestart(c, c.StopTimeout()); err != nil {
+						logrus.Debugf("failed to restart container: %+v", err)
 					}
-				}()
-			}
+				} else if err != restartmanager.ErrRestartCanceled {
+					logrus.Errorf("restartmanger wait error: %+v", err)
+				}
+			}()
+		}
+	}
+}
+
+// restartUnhealthy restarts an unhealthy container according to its
+// OnUnhealthy restart policy. changed is set if the container just became
+// unhealthy.
+// Called with c locked.
+func (d *Daemon) restartUnhealthy(c *container.Container, changed bool) {
+	policy := c.HostConfig.RestartPolicy.OnUnhealthy
+	h := c.State.Health
+
+	if uptime := time.Since(c.StartedAt); uptime < policy.GracePeriod {
+		logrus.Debugf("Unhealthy container %s: not restarting within grace period (%v < %v)", c.ID, uptime, policy.GracePeriod)
+		return
+	}
+	if max := policy.MaximumRestartCount; max > 0 && h.RestartCount >= max {
+		if changed {
+			logrus.Warnf("Unhealthy container %s: not restarting, maximum restart count (%d) reached", c.ID, max)
 		}
+		return
 	}
+
+	h.RestartCount++
+	if err := c.CheckpointTo(d.containersReplica); err != nil {
+		logrus.Errorf("Error replicating health state for container %s: %v", c.ID, err)
+	}
+	d.LogContainerEventWithAttributes(c, "health_restart", map[string]string{
+		"restartCount": strconv.Itoa(h.RestartCount),
+	})
+
+	logrus.Infof("Unhealthy container %s: restarting (%d)...", c.ID, h.RestartCount)
+	// Stop the probes right away, so that the results of the probes still
+	// running don't restart the container again.
+	d.stopHealthchecks(c)
+	go func() {
+		if err := d.containerRestart(c, c.StopTimeout()); err != nil {
+			logrus.Errorf("failed to restart unhealthy container %s: %v", c.ID, err)
+		}
+	}()
 }
 
 // Run the container's monitoring thread until notified via "stop".
@@ -283,6 +326,13 @@ func getProbe(c *container.Container) probe {
 		return &cmdProbe{shell: true}
 	case "NONE":
 		return nil
+	case "HTTP", "TCP", "GRPC":
+		p, err := parseNetworkProbe(config.Test)
+		if err != nil {
+			logrus.Warnf("Invalid healthcheck in container %s: %v", c.ID, err)
+			return nil
+		}
+		return p
 	default:
 		logrus.Warnf("Unknown healthcheck type '%s' (expected 'CMD') in container %s", config.Test[0], c.ID)
 		return nil
@@ -0,0 +1,257 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// Exit status code recorded for a failed HTTP, TCP or GRPC probe.
+	exitStatusUnhealthy = 1
+
+	// Default address probed in the container's network namespace.
+	defaultProbeHost = "127.0.0.1"
+)
+
+// probeOptions are the options accepted by each network probe type, as
+// "key=value" elements following the type in Healthcheck.Test.
+var probeOptions = map[string][]string{
+	"HTTP": {"port", "host", "path", "scheme", "status", "header"},
+	"TCP":  {"port", "host"},
+	"GRPC": {"port", "host", "service"},
+}
+
+// isNetworkProbe returns whether test is a probe run by the daemon itself
+// rather than a command exec'd in the container.
+func isNetworkProbe(test []string) bool {
+	if len(test) == 0 {
+		return false
+	}
+	_, ok := probeOptions[test[0]]
+	return ok
+}
+
+// parseNetworkProbe returns the probe described by an HTTP, TCP or GRPC
+// healthcheck test, such as:
+//
+//	{"HTTP", "port=8080", "path=/healthz", "status=200-299", "header=X-Probe: 1"}
+//	{"TCP", "port=5432"}
+//	{"GRPC", "port=50051", "service=my.Service"}
+func parseNetworkProbe(test []string) (probe, error) {
+	kind := test[0]
+	allowed := probeOptions[kind]
+
+	opts := map[string][]string{}
+	for _, arg := range test[1:] {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid %s healthcheck option %q: expected key=value", kind, arg)
+		}
+		if !containsString(allowed, kv[0]) {
+			return nil, errors.Errorf("unknown %s healthcheck option %q (allowed: %s)", kind, kv[0], strings.Join(allowed, ", "))
+		}
+		if kv[0] != "header" && len(opts[kv[0]]) > 0 {
+			return nil, errors.Errorf("%s healthcheck option %q specified more than once", kind, kv[0])
+		}
+		opts[kv[0]] = append(opts[kv[0]], kv[1])
+	}
+	get := func(key, def string) string {
+		if v := opts[key]; len(v) > 0 {
+			return v[0]
+		}
+		return def
+	}
+
+	port, err := strconv.ParseUint(get("port", ""), 10, 16)
+	if err != nil || port == 0 {
+		return nil, errors.Errorf("%s healthcheck requires a valid port option", kind)
+	}
+	host := get("host", defaultProbeHost)
+	if net.ParseIP(host) == nil {
+		return nil, errors.Errorf("invalid %s healthcheck host %q: must be an IP address", kind, host)
+	}
+	address := net.JoinHostPort(host, strconv.FormatUint(port, 10))
+
+	switch kind {
+	case "TCP":
+		return &tcpProbe{address: address}, nil
+	case "GRPC":
+		return &grpcProbe{address: address, service: get("service", "")}, nil
+	}
+
+	p := &httpProbe{
+		url:     get("scheme", "http") + "://" + address + get("path", "/"),
+		headers: http.Header{},
+	}
+	if scheme := get("scheme", "http"); scheme != "http" && scheme != "https" {
+		return nil, errors.Errorf("invalid HTTP healthcheck scheme %q: must be http or https", scheme)
+	}
+	if path := get("path", "/"); !strings.HasPrefix(path, "/") {
+		return nil, errors.Errorf("invalid HTTP healthcheck path %q: must start with /", path)
+	}
+	if p.statuses, err = parseStatusRanges(get("status", "200-399")); err != nil {
+		return nil, err
+	}
+	for _, h := range opts["header"] {
+		kv := strings.SplitN(h, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("invalid HTTP healthcheck header %q: expected Name: value", h)
+		}
+		p.headers.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+	return p, nil
+}
+
+// parseStatusRanges parses a comma-separated list of HTTP status codes and
+// ranges of status codes, such as "200,204" or "200-299".
+func parseStatusRanges(s string) ([][2]int, error) {
+	var ranges [][2]int
+	for _, r := range strings.Split(s, ",") {
+		bounds := strings.SplitN(r, "-", 2)
+		if len(bounds) == 1 {
+			bounds = append(bounds, bounds[0])
+		}
+		lo, err1 := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		hi, err2 := strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err1 != nil || err2 != nil || lo < 100 || hi > 599 || lo > hi {
+			return nil, errors.Errorf("invalid HTTP healthcheck status %q", r)
+		}
+		ranges = append(ranges, [2]int{lo, hi})
+	}
+	return ranges, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// probeResult returns a HealthcheckResult for a network probe, healthy if
+// err is nil.
+func probeResult(err error, output string) *types.HealthcheckResult {
+	result := &types.HealthcheckResult{
+		End:    time.Now(),
+		Output: output,
+	}
+	if err != nil {
+		result.ExitCode = exitStatusUnhealthy
+		result.Output = err.Error()
+	}
+	if len(result.Output) > maxOutputLen {
+		result.Output = result.Output[:maxOutputLen] + "..."
+	}
+	return result
+}
+
+// tcpProbe implements the "TCP" probe type, which succeeds if a connection
+// can be established.
+type tcpProbe struct {
+	address string
+}
+
+func (p *tcpProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
+	conn, err := dialInContainer(ctx, cntr, "tcp", p.address)
+	if err != nil {
+		return probeResult(err, ""), nil
+	}
+	conn.Close()
+	return probeResult(nil, "Connected to "+p.address), nil
+}
+
+// httpProbe implements the "HTTP" probe type, which succeeds if a GET
+// request returns one of the expected status codes.
+type httpProbe struct {
+	url      string
+	headers  http.Header
+	statuses [][2]int
+}
+
+func (p *httpProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
+	req, err := http.NewRequest(http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range p.headers {
+		req.Header[k] = v
+	}
+	if host := p.headers.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialInContainer(ctx, cntr, network, address)
+			},
+			// Probes commonly target self-signed endpoints.
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint: gosec
+			DisableKeepAlives: true,
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return probeResult(err, ""), nil
+	}
+	defer resp.Body.Close()
+
+	output := &limitedBuffer{}
+	fmt.Fprintf(output, "GET %s: %s\n", p.url, resp.Status)
+	io.Copy(output, io.LimitReader(resp.Body, maxOutputLen))
+
+	for _, r := range p.statuses {
+		if resp.StatusCode >= r[0] && resp.StatusCode <= r[1] {
+			return probeResult(nil, output.String()), nil
+		}
+	}
+	result := probeResult(nil, output.String())
+	result.ExitCode = exitStatusUnhealthy
+	return result, nil
+}
+
+// grpcProbe implements the "GRPC" probe type, which uses the standard gRPC
+// health checking protocol.
+type grpcProbe struct {
+	address string
+	service string
+}
+
+func (p *grpcProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
+	conn, err := grpc.DialContext(ctx, p.address,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dialInContainer(ctx, cntr, "tcp", address)
+		}),
+	)
+	if err != nil {
+		return probeResult(err, ""), nil
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.service})
+	if err != nil {
+		return probeResult(err, ""), nil
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return probeResult(errors.Errorf("service %q status: %s", p.service, resp.Status), ""), nil
+	}
+	return probeResult(nil, "Service status: "+resp.Status.String()), nil
+}
@@ -0,0 +1,60 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"net"
+	"runtime"
+
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netns"
+)
+
+// dialInContainer connects to address from within the network namespace of
+// the container, so that probes reach services bound to its loopback
+// interface without exec'ing anything in the container.
+func dialInContainer(ctx context.Context, cntr *container.Container, network, address string) (net.Conn, error) {
+	pid := cntr.State.GetPID()
+	if pid == 0 {
+		return nil, errors.Errorf("container %s is not running", cntr.ID)
+	}
+
+	// The socket is bound to the namespace of the thread creating it, so
+	// the thread has to stay in the container's namespace until the dial
+	// has created it.
+	runtime.LockOSThread()
+	origNS, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, errors.Wrap(err, "failed to get current network namespace")
+	}
+	defer origNS.Close()
+
+	targetNS, err := netns.GetFromPid(pid)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, errors.Wrapf(err, "failed to get network namespace of container %s", cntr.ID)
+	}
+	defer targetNS.Close()
+
+	if err := netns.Set(targetNS); err != nil {
+		runtime.UnlockOSThread()
+		return nil, errors.Wrapf(err, "failed to enter network namespace of container %s", cntr.ID)
+	}
+
+	var d net.Dialer
+	conn, dialErr := d.DialContext(ctx, network, address)
+
+	if err := netns.Set(origNS); err != nil {
+		// Leave the thread locked, so that it is terminated rather than
+		// reused by other goroutines while in the wrong namespace.
+		logrus.WithError(err).Error("failed to restore network namespace after healthcheck")
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, err
+	}
+	runtime.UnlockOSThread()
+	return conn, dialErr
+}
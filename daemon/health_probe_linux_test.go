@@ -0,0 +1,78 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+// probeInOwnNetns returns a container whose network namespace is the one of
+// the test, so that probes reach the servers the test listens with.
+func probeInOwnNetns() *container.Container {
+	c := &container.Container{ID: "probed", State: container.NewState()}
+	c.State.Pid = os.Getpid()
+	return c
+}
+
+func TestHTTPProbe(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || r.Header.Get("X-Probe") != "yes" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	assert.NilError(t, err)
+	c := probeInOwnNetns()
+
+	p, err := parseNetworkProbe([]string{"HTTP", "port=" + u.Port(), "path=/healthz", "header=X-Probe: yes"})
+	assert.NilError(t, err)
+	result, err := p.run(context.Background(), nil, c)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(result.ExitCode, 0))
+	assert.Check(t, is.Contains(result.Output, "200 OK"))
+	assert.Check(t, is.Contains(result.Output, "ok"))
+
+	p, err = parseNetworkProbe([]string{"HTTP", "port=" + u.Port(), "path=/healthz"})
+	assert.NilError(t, err)
+	result, err = p.run(context.Background(), nil, c)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(result.ExitCode, exitStatusUnhealthy))
+	assert.Check(t, is.Contains(result.Output, "503 Service Unavailable"))
+}
+
+func TestTCPProbe(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	assert.NilError(t, err)
+	c := probeInOwnNetns()
+
+	p, err := parseNetworkProbe([]string{"TCP", "port=" + port})
+	assert.NilError(t, err)
+	result, err := p.run(context.Background(), nil, c)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(result.ExitCode, 0))
+	assert.Check(t, is.Equal(result.Output, "Connected to 127.0.0.1:"+port))
+
+	l.Close()
+	result, err = p.run(context.Background(), nil, c)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(result.ExitCode, exitStatusUnhealthy))
+	assert.Check(t, is.Contains(result.Output, "connection refused"))
+}
@@ -0,0 +1,54 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestParseNetworkProbe(t *testing.T) {
+	p, err := parseNetworkProbe([]string{"HTTP", "port=8080", "path=/healthz", "status=200,204-206", "header=X-Probe: yes"})
+	assert.NilError(t, err)
+	hp := p.(*httpProbe)
+	assert.Check(t, is.Equal(hp.url, "http://127.0.0.1:8080/healthz"))
+	assert.Check(t, is.DeepEqual(hp.statuses, [][2]int{{200, 200}, {204, 206}}))
+	assert.Check(t, is.Equal(hp.headers.Get("X-Probe"), "yes"))
+
+	p, err = parseNetworkProbe([]string{"TCP", "port=5432", "host=::1"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(p.(*tcpProbe).address, "[::1]:5432"))
+
+	p, err = parseNetworkProbe([]string{"GRPC", "port=50051", "service=my.Service"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(p.(*grpcProbe).service, "my.Service"))
+}
+
+func TestParseNetworkProbeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		test     []string
+		expected string
+	}{
+		{test: []string{"TCP"}, expected: "TCP healthcheck requires a valid port option"},
+		{test: []string{"TCP", "port=70000"}, expected: "TCP healthcheck requires a valid port option"},
+		{test: []string{"TCP", "port=80", "path=/"}, expected: `unknown TCP healthcheck option "path" (allowed: port, host)`},
+		{test: []string{"TCP", "port"}, expected: `invalid TCP healthcheck option "port": expected key=value`},
+		{test: []string{"GRPC", "port=80", "port=81"}, expected: `GRPC healthcheck option "port" specified more than once`},
+		{test: []string{"HTTP", "port=80", "host=localhost"}, expected: `invalid HTTP healthcheck host "localhost": must be an IP address`},
+		{test: []string{"HTTP", "port=80", "scheme=ftp"}, expected: `invalid HTTP healthcheck scheme "ftp": must be http or https`},
+		{test: []string{"HTTP", "port=80", "path=healthz"}, expected: `invalid HTTP healthcheck path "healthz": must start with /`},
+		{test: []string{"HTTP", "port=80", "status=299-200"}, expected: `invalid HTTP healthcheck status "299-200"`},
+		{test: []string{"HTTP", "port=80", "header=X-Probe"}, expected: `invalid HTTP healthcheck header "X-Probe": expected Name: value`},
+	} {
+		_, err := parseNetworkProbe(tc.test)
+		assert.Check(t, is.Error(err, tc.expected), tc.test)
+	}
+}
+
+func TestValidateHealthCheckNetworkProbe(t *testing.T) {
+	err := validateHealthCheck(&containertypes.HealthConfig{Test: []string{"HTTP", "port=80"}})
+	assert.Check(t, is.Nil(err))
+	err = validateHealthCheck(&containertypes.HealthConfig{Test: []string{"HTTP"}})
+	assert.Check(t, is.Error(err, "HTTP healthcheck requires a valid port option"))
+}
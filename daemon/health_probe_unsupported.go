@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"net"
+	"runtime"
+
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+)
+
+func dialInContainer(ctx context.Context, cntr *container.Container, network, address string) (net.Conn, error) {
+	return nil, errors.Errorf("network healthchecks are not supported on %s", runtime.GOOS)
+}
@@ -153,3 +153,158 @@ func TestHealthStates(t *testing.T) {
 		t.Errorf("Expecting FailingStreak=0, but got %d\n", c.State.Health.FailingStreak)
 	}
 }
+
+func TestUnhealthyRestartLimits(t *testing.T) {
+	e := events.New()
+	_, l, _ := e.Subscribe()
+	defer e.Evict(l)
+
+	store, err := container.NewViewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon := &Daemon{
+		EventsService:     e,
+		containersReplica: store,
+	}
+	muteLogs()
+
+	c := &container.Container{
+		ID:   "container_id",
+		Name: "container_name",
+		Config: &containertypes.Config{
+			Image:       "image_name",
+			Healthcheck: &containertypes.HealthConfig{Retries: 1},
+		},
+		HostConfig: &containertypes.HostConfig{
+			RestartPolicy: containertypes.RestartPolicy{
+				OnUnhealthy: &containertypes.UnhealthyRestartPolicy{
+					GracePeriod:         time.Hour,
+					MaximumRestartCount: 2,
+				},
+			},
+		},
+	}
+
+	expectNoRestart := func() {
+		handleProbeResult(daemon, c, &types.HealthcheckResult{Start: time.Now(), ExitCode: 1}, nil)
+		if ev := (<-l).(eventtypes.Message); ev.Status != "health_status: unhealthy" {
+			t.Fatalf("Expecting event %#v, but got %#v", "health_status: unhealthy", ev.Status)
+		}
+		select {
+		case ev := <-l:
+			t.Fatalf("Expecting no event, but got %#v", ev.(eventtypes.Message).Status)
+		default:
+		}
+	}
+
+	// Within the grace period.
+	reset(c)
+	c.State.StartedAt = time.Now()
+	expectNoRestart()
+
+	// Maximum restart count reached.
+	reset(c)
+	c.HostConfig.RestartPolicy.OnUnhealthy.GracePeriod = 0
+	c.State.Health.RestartCount = 2
+	expectNoRestart()
+	if c.State.Health.RestartCount != 2 {
+		t.Errorf("Expecting RestartCount=2, but got %d", c.State.Health.RestartCount)
+	}
+
+	// Becoming healthy resets the restart count.
+	handleProbeResult(daemon, c, &types.HealthcheckResult{ExitCode: 0}, nil)
+	if c.State.Health.RestartCount != 0 {
+		t.Errorf("Expecting RestartCount=0, but got %d", c.State.Health.RestartCount)
+	}
+}
+
+func TestUnhealthyRestart(t *testing.T) {
+	e := events.New()
+	_, l, _ := e.Subscribe()
+	defer e.Evict(l)
+
+	store, err := container.NewViewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon := &Daemon{
+		EventsService:     e,
+		containersReplica: store,
+	}
+	muteLogs()
+
+	c := &container.Container{
+		ID:   "container_id",
+		Name: "container_name",
+		Config: &containertypes.Config{
+			Image:       "image_name",
+			Healthcheck: &containertypes.HealthConfig{Retries: 1},
+		},
+		HostConfig: &containertypes.HostConfig{
+			RestartPolicy: containertypes.RestartPolicy{
+				OnUnhealthy: &containertypes.UnhealthyRestartPolicy{
+					GracePeriod:         time.Hour,
+					MaximumRestartCount: 2,
+				},
+			},
+		},
+	}
+	reset(c)
+	// The container cannot actually be started again, so that the
+	// restart fails right away.
+	c.State.Dead = true
+
+	expect := func(expected string) {
+		t.Helper()
+		select {
+		case ev := <-l:
+			if ev := ev.(eventtypes.Message); ev.Status != expected {
+				t.Fatalf("Expecting event %#v, but got %#v", expected, ev.Status)
+			}
+		default:
+			t.Fatalf("Expecting event %#v, but got none", expected)
+		}
+	}
+	expectNone := func() {
+		t.Helper()
+		select {
+		case ev := <-l:
+			t.Fatalf("Expecting no event, but got %#v", ev.(eventtypes.Message).Status)
+		default:
+		}
+	}
+	fail := func() {
+		handleProbeResult(daemon, c, &types.HealthcheckResult{Start: time.Now(), ExitCode: 1}, nil)
+	}
+
+	// Becoming unhealthy within the grace period doesn't restart the
+	// container.
+	c.State.StartedAt = time.Now()
+	fail()
+	expect("health_status: unhealthy")
+	expectNone()
+
+	// The container is restarted by the first probe failing after the grace
+	// period, although its status doesn't change.
+	c.State.StartedAt = time.Now().Add(-2 * time.Hour)
+	fail()
+	expect("health_restart")
+	expectNone()
+	if c.State.Health.RestartCount != 1 {
+		t.Errorf("Expecting RestartCount=1, but got %d", c.State.Health.RestartCount)
+	}
+
+	fail()
+	expect("health_restart")
+	if c.State.Health.RestartCount != 2 {
+		t.Errorf("Expecting RestartCount=2, but got %d", c.State.Health.RestartCount)
+	}
+
+	// The maximum restart count is reached.
+	fail()
+	expectNone()
+	if c.State.Health.RestartCount != 2 {
+		t.Errorf("Expecting RestartCount=2, but got %d", c.State.Health.RestartCount)
+	}
+}
@@ -22,8 +22,9 @@ const (
 	conflictRunningContainer
 	conflictActiveReference
 	conflictStoppedContainer
+	conflictPinned
 	conflictHard = conflictDependentChild | conflictRunningContainer
-	conflictSoft = conflictActiveReference | conflictStoppedContainer
+	conflictSoft = conflictActiveReference | conflictStoppedContainer | conflictPinned
 )
 
 // ImageDelete deletes the image referenced by the given imageRef from this
@@ -52,6 +53,7 @@ const (
 // Soft Conflict:
 // 	- any stopped container using the image.
 // 	- any repository tag or digest references to the image.
+// 	- any pin on the image.
 //
 // The image cannot be removed if there are any hard conflicts and can be
 // removed if there are soft conflicts only if force is true.
@@ -94,6 +96,12 @@ func (i *ImageService) ImageDelete(imageRef string, force, prune bool) ([]types.
 				err := errors.Errorf("conflict: unable to remove repository reference %q (must force) - container %s is using its referenced image %s", imageRef, stringid.TruncateID(container.ID), stringid.TruncateID(imgID.String()))
 				return nil, errdefs.Conflict(err)
 			}
+			if pins, pinned := i.imagePins(imgID); pinned {
+				// Likewise, a pinned image must stay addressable
+				// by its name.
+				err := errors.Errorf("conflict: unable to remove repository reference %q (must force) - image %s is pinned by %s", imageRef, stringid.TruncateID(imgID.String()), pinOwners(pins))
+				return nil, errdefs.Conflict(err)
+			}
 		}
 
 		parsedRef, err := reference.ParseNormalizedNamed(imageRef)
@@ -289,8 +297,8 @@ func (idc *imageDeleteConflict) Conflict() {}
 // imageDeleteHelper attempts to delete the given image from this daemon. If
 // the image has any hard delete conflicts (child images or running containers
 // using the image) then it cannot be deleted. If the image has any soft delete
-// conflicts (any tags/digests referencing the image or any stopped container
-// using the image) then it can only be deleted if force is true. If the delete
+// conflicts (any tags/digests referencing the image, any stopped container
+// using the image or any pin) then it can only be deleted if force is true. If the delete
 // succeeds and prune is true, the parent images are also deleted if they do
 // not have any soft or hard delete conflicts themselves. Any deleted images
 // and untagged references are appended to the given records. If any error or
@@ -389,6 +397,17 @@ func (i *ImageService) checkImageDeleteConflict(imgID image.ID, mask conflictTyp
 		}
 	}
 
+	if mask&conflictPinned != 0 {
+		// Check if the image is kept on purpose, e.g. as a rollback target.
+		if pins, pinned := i.imagePins(imgID); pinned {
+			return &imageDeleteConflict{
+				imgID:   imgID,
+				used:    true,
+				message: fmt.Sprintf("image is pinned by %s", pinOwners(pins)),
+			}
+		}
+	}
+
 	if mask&conflictStoppedContainer != 0 {
 		// Check if any stopped containers reference this image.
 		stopped := func(c *container.Container) bool {
@@ -63,6 +63,11 @@ func (i *ImageService) LookupImage(name string) (*types.ImageInspect, error) {
 		return nil, err
 	}
 
+	pins, err := i.imageStore.GetPins(img.ID())
+	if err != nil {
+		return nil, err
+	}
+
 	imageInspect := &types.ImageInspect{
 		ID:              img.ID().String(),
 		RepoTags:        repoTags,
@@ -84,6 +89,7 @@ func (i *ImageService) LookupImage(name string) (*types.ImageInspect, error) {
 		RootFS:          rootFSToAPIType(img.RootFS),
 		Metadata: types.ImageMetadata{
 			LastTagTime: lastUpdated,
+			Pins:        pinsToAPIType(pins),
 		},
 	}
 
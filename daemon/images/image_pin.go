@@ -0,0 +1,137 @@
+package images // import "github.com/docker/docker/daemon/images"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/image"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// PinImage pins the image referenced by imageRef on behalf of owner. Pinned
+// images are skipped by prune and can only be deleted by forcing it. Pinning
+// an image again with the same owner replaces the reason of the existing pin.
+func (i *ImageService) PinImage(imageRef, owner, reason string) error {
+	if owner == "" {
+		return errdefs.InvalidParameter(errors.New("an owner is required to pin an image"))
+	}
+	img, err := i.GetImage(imageRef, nil)
+	if err != nil {
+		return err
+	}
+	imgID := img.ID()
+
+	i.pinMu.Lock()
+	defer i.pinMu.Unlock()
+
+	pins, err := i.imageStore.GetPins(imgID)
+	if err != nil {
+		return err
+	}
+	pin := image.Pin{
+		Owner:   owner,
+		Reason:  reason,
+		Created: time.Now().UTC(),
+	}
+	replaced := false
+	for n, p := range pins {
+		if p.Owner == owner {
+			pins[n] = pin
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		pins = append(pins, pin)
+	}
+	if err := i.imageStore.SetPins(imgID, pins); err != nil {
+		return err
+	}
+
+	i.LogImageEventWithAttributes(imgID.String(), imageRef, "pin", map[string]string{
+		"owner":  owner,
+		"reason": reason,
+	})
+	return nil
+}
+
+// UnpinImage removes the pin owner holds on the image referenced by imageRef.
+// If owner is empty, all pins are removed from the image.
+func (i *ImageService) UnpinImage(imageRef, owner string) error {
+	img, err := i.GetImage(imageRef, nil)
+	if err != nil {
+		return err
+	}
+	imgID := img.ID()
+
+	i.pinMu.Lock()
+	defer i.pinMu.Unlock()
+
+	pins, err := i.imageStore.GetPins(imgID)
+	if err != nil {
+		return err
+	}
+	var remaining []image.Pin
+	for _, p := range pins {
+		if owner != "" && p.Owner != owner {
+			remaining = append(remaining, p)
+		}
+	}
+	if len(remaining) == len(pins) {
+		if owner == "" {
+			return errdefs.NotFound(errors.Errorf("image %s is not pinned", imageRef))
+		}
+		return errdefs.NotFound(errors.Errorf("image %s is not pinned by %s", imageRef, owner))
+	}
+	if err := i.imageStore.SetPins(imgID, remaining); err != nil {
+		return err
+	}
+
+	i.LogImageEventWithAttributes(imgID.String(), imageRef, "unpin", map[string]string{
+		"owner": owner,
+	})
+	return nil
+}
+
+// imagePins returns the pins on the image. Errors reading them are logged,
+// and the image is treated as pinned, so that it is not removed by accident.
+func (i *ImageService) imagePins(imgID image.ID) ([]image.Pin, bool) {
+	pins, err := i.imageStore.GetPins(imgID)
+	if err != nil {
+		logrus.WithError(err).WithField("image", imgID.String()).Error("failed to read image pins")
+		return nil, true
+	}
+	return pins, len(pins) > 0
+}
+
+// pinOwners returns a human readable list of the owners of pins.
+func pinOwners(pins []image.Pin) string {
+	if len(pins) == 0 {
+		return "unknown owner"
+	}
+	owners := make([]string, 0, len(pins))
+	for _, p := range pins {
+		owners = append(owners, fmt.Sprintf("%q", p.Owner))
+	}
+	return strings.Join(owners, ", ")
+}
+
+// pinsToAPIType returns pins as the type of the API.
+func pinsToAPIType(pins []image.Pin) []types.ImagePin {
+	if len(pins) == 0 {
+		return nil
+	}
+	apiPins := make([]types.ImagePin, 0, len(pins))
+	for _, p := range pins {
+		apiPins = append(apiPins, types.ImagePin{
+			Owner:   p.Owner,
+			Reason:  p.Reason,
+			Created: p.Created,
+		})
+	}
+	return apiPins
+}
@@ -90,6 +90,9 @@ func (i *ImageService) ImagesPrune(ctx context.Context, pruneFilters filters.Arg
 			if img.Config != nil && !matchLabels(pruneFilters, img.Config.Labels) {
 				continue
 			}
+			if _, pinned := i.imagePins(id); pinned {
+				continue
+			}
 			topImages[id] = img
 		}
 	}
@@ -24,8 +24,10 @@ import (
 )
 
 // PullImage initiates a pull operation. image is the repository name to pull, and
-// tag may be either empty, or indicate a specific tag to pull.
-func (i *ImageService) PullImage(ctx context.Context, image, tag string, platform *specs.Platform, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+// tag may be either empty, or indicate a specific tag to pull. If
+// structuredProgress is set, types.PullProgress reports are written to
+// outStream along with the regular progress messages.
+func (i *ImageService) PullImage(ctx context.Context, image, tag string, platform *specs.Platform, metaHeaders map[string][]string, authConfig *types.AuthConfig, structuredProgress bool, outStream io.Writer) error {
 	start := time.Now()
 	// Special case: "pull -a" may send an image name with a
 	// trailing :. This is ugly, but let's not break API
@@ -51,7 +53,7 @@ func (i *ImageService) PullImage(ctx context.Context, image, tag string, platfor
 		}
 	}
 
-	err = i.pullImageWithReference(ctx, ref, platform, metaHeaders, authConfig, outStream)
+	err = i.pullImageWithReference(ctx, ref, platform, metaHeaders, authConfig, structuredProgress, outStream)
 	imageActions.WithValues("pull").UpdateSince(start)
 	if err != nil {
 		return err
@@ -78,7 +80,7 @@ func (i *ImageService) PullImage(ctx context.Context, image, tag string, platfor
 	return nil
 }
 
-func (i *ImageService) pullImageWithReference(ctx context.Context, ref reference.Named, platform *specs.Platform, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+func (i *ImageService) pullImageWithReference(ctx context.Context, ref reference.Named, platform *specs.Platform, metaHeaders map[string][]string, authConfig *types.AuthConfig, structuredProgress bool, outStream io.Writer) error {
 	// Include a buffer so that slow client connections don't affect
 	// transfer performance.
 	progressChan := make(chan progress.Progress, 100)
@@ -88,7 +90,11 @@ func (i *ImageService) pullImageWithReference(ctx context.Context, ref reference
 	ctx, cancelFunc := context.WithCancel(ctx)
 
 	go func() {
-		progressutils.WriteDistributionProgress(cancelFunc, outStream, progressChan)
+		if structuredProgress {
+			progressutils.WriteStructuredPullProgress(cancelFunc, outStream, progressChan)
+		} else {
+			progressutils.WriteDistributionProgress(cancelFunc, outStream, progressChan)
+		}
 		close(writesDone)
 	}()
 
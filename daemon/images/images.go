@@ -23,6 +23,7 @@ var acceptedImageFilterTags = map[string]bool{
 	"before":    true,
 	"since":     true,
 	"reference": true,
+	"pinned":    true,
 }
 
 // byCreated is a temporary type used to sort a list of images by creation
@@ -67,6 +68,17 @@ func (i *ImageService) Images(imageFilters filters.Args, all bool, withExtraAttr
 		allImages = i.imageStore.Map()
 	}
 
+	var pinnedFilter *bool
+	if imageFilters.Contains("pinned") {
+		var pinned bool
+		if imageFilters.ExactMatch("pinned", "true") {
+			pinned = true
+		} else if !imageFilters.ExactMatch("pinned", "false") {
+			return nil, invalidFilter{"pinned", imageFilters.Get("pinned")}
+		}
+		pinnedFilter = &pinned
+	}
+
 	var beforeFilter, sinceFilter *image.Image
 	err = imageFilters.WalkValues("before", func(value string) error {
 		beforeFilter, err = i.GetImage(value, nil)
@@ -114,6 +126,12 @@ func (i *ImageService) Images(imageFilters filters.Args, all bool, withExtraAttr
 			}
 		}
 
+		if pinnedFilter != nil {
+			if _, pinned := i.imagePins(id); pinned != *pinnedFilter {
+				continue
+			}
+		}
+
 		// Skip any images with an unsupported operating system to avoid a potential
 		// panic when indexing through the layerstore. Don't error as we want to list
 		// the other images. This should never happen, but here as a safety precaution.
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"runtime"
+	"sync"
 
 	"github.com/containerd/containerd/content"
 	"github.com/containerd/containerd/leases"
@@ -84,6 +85,7 @@ type ImageService struct {
 	imageStore                image.Store
 	layerStores               map[string]layer.Store // By operating system
 	deltaStore                image.Store
+	pinMu                     sync.Mutex // protects read-modify-write of image pins
 	pruneRunning              int32
 	referenceStore            dockerreference.Store
 	registryService           registry.Service
@@ -64,6 +64,7 @@ func (daemon *Daemon) ContainerInspectCurrent(name string, size bool) (*types.Co
 			SandboxKey:             ctr.NetworkSettings.SandboxKey,
 			SecondaryIPAddresses:   ctr.NetworkSettings.SecondaryIPAddresses,
 			SecondaryIPv6Addresses: ctr.NetworkSettings.SecondaryIPv6Addresses,
+			Bandwidth:              ctr.NetworkSettings.Bandwidth,
 		},
 		DefaultNetworkSettings: daemon.getDefaultNetworkSettings(ctr.NetworkSettings.Networks),
 		Networks:               apiNetworks,
@@ -83,11 +84,19 @@ func (daemon *Daemon) ContainerInspectCurrent(name string, size bool) (*types.Co
 		base.SizeRootFs = &sizeRootFs
 	}
 
+	var traffic *types.NetworkTraffic
+	if t := daemon.statsCollector.Traffic(); t != nil {
+		if nt, ok := t.Get(ctr); ok {
+			traffic = &nt
+		}
+	}
+
 	return &types.ContainerJSON{
 		ContainerJSONBase: base,
 		Mounts:            mountPoints,
 		Config:            ctr.Config,
 		NetworkSettings:   networkSettings,
+		NetworkTraffic:    traffic,
 	}, nil
 }
 
@@ -143,6 +152,7 @@ func (daemon *Daemon) getInspectData(container *container.Container) (*types.Con
 			Status:        container.State.Health.Status(),
 			FailingStreak: container.State.Health.FailingStreak,
 			Log:           append([]*types.HealthcheckResult{}, container.State.Health.Log...),
+			RestartCount:  container.State.Health.RestartCount,
 		}
 	}
 
@@ -161,6 +171,12 @@ func (daemon *Daemon) getInspectData(container *container.Container) (*types.Con
 		Health:     containerHealth,
 	}
 
+	backoff := container.RestartBackoff()
+	containerState.RestartDelay = backoff.Delay
+	if !backoff.NextRestart.IsZero() {
+		containerState.NextRestart = backoff.NextRestart.Format(time.RFC3339Nano)
+	}
+
 	contJSONBase := &types.ContainerJSONBase{
 		ID:           container.ID,
 		Created:      container.Created.Format(time.RFC3339Nano),
@@ -0,0 +1,71 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/daemon/logger/local"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	"github.com/sirupsen/logrus"
+)
+
+// containerLogPath returns the path of the log file of c written by the
+// daemon, or of its local cache if it uses another log driver.
+func containerLogPath(c *container.Container) (string, error) {
+	switch c.HostConfig.LogConfig.Type {
+	case jsonfilelog.Name:
+		return c.GetRootResourcePath(fmt.Sprintf("%s-json.log", c.ID))
+	case local.Name:
+		logDir, err := c.GetRootResourcePath("local-logs")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(logDir, "container.log"), nil
+	default:
+		return c.GetRootResourcePath("container-cached.log")
+	}
+}
+
+// initLogQuota sets the quota on the log files of all the containers, and
+// counts in it the log files of the containers that are not running, which
+// are not written to.
+func (daemon *Daemon) initLogQuota(cfg *config.Config) {
+	for _, c := range daemon.List() {
+		path, err := containerLogPath(c)
+		if err != nil {
+			continue
+		}
+		opts, err := loggerutils.ParseQuotaOptions(c.HostConfig.LogConfig.Config)
+		if err != nil {
+			logrus.WithError(err).WithField("container", c.ID).Warn("ignoring invalid log quota options")
+			opts = loggerutils.QuotaOptions{Weight: 1}
+		}
+		loggerutils.DefaultQuota.Add(path, opts)
+	}
+	loggerutils.DefaultQuota.SetLimit(cfg.LogQuota.Value())
+}
+
+// logsDiskUsage returns the disk space used by the log files of the
+// containers.
+func (daemon *Daemon) logsDiskUsage() *types.LogsDiskUsage {
+	usage := loggerutils.DefaultQuota.Usage()
+	du := &types.LogsDiskUsage{
+		Quota:      loggerutils.DefaultQuota.Limit(),
+		Containers: make(map[string]int64),
+	}
+	for _, c := range daemon.List() {
+		path, err := containerLogPath(c)
+		if err != nil {
+			continue
+		}
+		if size, ok := usage[path]; ok {
+			du.Containers[c.ID] = size
+			du.Size += size
+		}
+	}
+	return du
+}
@@ -6,4 +6,5 @@ import (
 	_ "github.com/docker/docker/daemon/logger/journald"
 	_ "github.com/docker/docker/daemon/logger/jsonfilelog"
 	_ "github.com/docker/docker/daemon/logger/local"
+	_ "github.com/docker/docker/daemon/logger/otlp"
 )
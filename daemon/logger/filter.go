@@ -0,0 +1,102 @@
+package logger // import "github.com/docker/docker/daemon/logger"
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/docker/docker/api/types/backend"
+)
+
+// MessageFilter selects the messages read from a LogReader. The zero value
+// matches all the messages.
+type MessageFilter struct {
+	// Source, if set, is the stream the messages must come from, such as
+	// "stdout" or "stderr".
+	Source string
+	// Contains, if set, is a substring the line must contain.
+	Contains string
+	// Pattern, if set, is a regular expression the line must match.
+	Pattern *regexp.Regexp
+	// Attrs are the attributes the message must have, with the same value.
+	Attrs map[string]string
+}
+
+// IsZero returns whether f matches all the messages.
+func (f *MessageFilter) IsZero() bool {
+	return f == nil || (f.Source == "" && f.Contains == "" && f.Pattern == nil && len(f.Attrs) == 0)
+}
+
+// Match returns whether msg is selected by f. A nil filter matches all the
+// messages.
+func (f *MessageFilter) Match(msg *Message) bool {
+	if f == nil {
+		return true
+	}
+	if f.Source != "" && msg.Source != f.Source {
+		return false
+	}
+	// Some drivers keep the newline ending the line, which is not matched
+	// so that patterns anchored at the end match with all the drivers.
+	line := bytes.TrimSuffix(msg.Line, []byte("\n"))
+	if f.Contains != "" && !bytes.Contains(line, []byte(f.Contains)) {
+		return false
+	}
+	if f.Pattern != nil && !f.Pattern.Match(line) {
+		return false
+	}
+	for k, v := range f.Attrs {
+		if !hasAttr(msg.Attrs, k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAttr(attrs []backend.LogAttr, key, value string) bool {
+	for _, a := range attrs {
+		if a.Key == key && a.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ByteBudget limits the size of the lines of the messages read. A nil
+// budget is unlimited. It is not safe for concurrent use.
+type ByteBudget struct {
+	left      int64
+	exhausted bool
+}
+
+// NewByteBudget returns a budget of max bytes, or nil if max is 0 or less.
+func NewByteBudget(max int64) *ByteBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &ByteBudget{left: max}
+}
+
+// Take counts the line of msg against the budget, and returns whether it
+// fits in it. Once a message does not fit, or the budget is used up, the
+// budget is exhausted and no further message fits, so that the messages
+// read are not missing any in between.
+func (b *ByteBudget) Take(msg *Message) bool {
+	if b == nil {
+		return true
+	}
+	if b.exhausted || int64(len(msg.Line)) > b.left {
+		b.exhausted = true
+		return false
+	}
+	b.left -= int64(len(msg.Line))
+	if b.left == 0 {
+		b.exhausted = true
+	}
+	return true
+}
+
+// Exhausted returns whether no further message fits in the budget, so that
+// reading, and following, the logs can stop.
+func (b *ByteBudget) Exhausted() bool {
+	return b != nil && b.exhausted
+}
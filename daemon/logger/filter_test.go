@@ -0,0 +1,62 @@
+package logger // import "github.com/docker/docker/daemon/logger"
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/docker/docker/api/types/backend"
+	"gotest.tools/v3/assert"
+)
+
+func TestMessageFilter(t *testing.T) {
+	msg := &Message{
+		Line:   []byte("GET /index.html 404\n"),
+		Source: "stdout",
+		Attrs:  []backend.LogAttr{{Key: "env", Value: "prod"}},
+	}
+
+	var f *MessageFilter
+	assert.Check(t, f.IsZero())
+	assert.Check(t, f.Match(msg))
+	assert.Check(t, (&MessageFilter{}).IsZero())
+
+	for _, tc := range []struct {
+		filter   MessageFilter
+		expected bool
+	}{
+		{filter: MessageFilter{Source: "stdout"}, expected: true},
+		{filter: MessageFilter{Source: "stderr"}, expected: false},
+		{filter: MessageFilter{Contains: "404"}, expected: true},
+		{filter: MessageFilter{Contains: "500"}, expected: false},
+		{filter: MessageFilter{Pattern: regexp.MustCompile(`^GET .* 4\d\d$`)}, expected: true},
+		{filter: MessageFilter{Pattern: regexp.MustCompile(`^POST`)}, expected: false},
+		{filter: MessageFilter{Attrs: map[string]string{"env": "prod"}}, expected: true},
+		{filter: MessageFilter{Attrs: map[string]string{"env": "dev"}}, expected: false},
+		{filter: MessageFilter{Attrs: map[string]string{"env": "prod", "region": "eu"}}, expected: false},
+		{filter: MessageFilter{Source: "stdout", Contains: "index", Attrs: map[string]string{"env": "prod"}}, expected: true},
+	} {
+		assert.Check(t, !tc.filter.IsZero())
+		assert.Check(t, tc.filter.Match(msg) == tc.expected, "%+v", tc.filter)
+	}
+}
+
+func TestByteBudget(t *testing.T) {
+	assert.Check(t, NewByteBudget(0) == nil)
+	var unlimited *ByteBudget
+	assert.Check(t, unlimited.Take(&Message{Line: make([]byte, 1<<20)}))
+
+	b := NewByteBudget(10)
+	assert.Check(t, b.Take(&Message{Line: []byte("12345")}))
+	assert.Check(t, !b.Take(&Message{Line: []byte("123456")}))
+	// A smaller message that would fit is not taken after one did not.
+	assert.Check(t, !b.Take(&Message{Line: []byte("1")}))
+	assert.Check(t, b.Exhausted())
+
+	// The budget is also exhausted once used up.
+	b = NewByteBudget(10)
+	assert.Check(t, b.Take(&Message{Line: []byte("12345")}))
+	assert.Check(t, !b.Exhausted())
+	assert.Check(t, b.Take(&Message{Line: []byte("12345")}))
+	assert.Check(t, b.Exhausted())
+	assert.Check(t, !unlimited.Exhausted())
+}
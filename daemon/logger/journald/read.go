@@ -132,14 +132,63 @@ func CErr(ret C.int) string {
 	return C.GoString(C.strerror(C.int(-ret)))
 }
 
-func (s *journald) drainJournal(logWatcher *logger.LogWatcher, j *C.sd_journal, oldCursor *C.char, untilUnixMicro uint64) (*C.char, bool, int) {
+// readEntry returns the message of the journal entry j is positioned at,
+// and its timestamp in microseconds. The message is nil if the entry has
+// none, and ok is false if its timestamp cannot be read.
+func readEntry(j *C.sd_journal) (m *logger.Message, stamp uint64, ok bool) {
 	var (
-		msg, data, cursor *C.char
+		msg, data         *C.char
 		length            C.size_t
-		stamp             C.uint64_t
+		realtime          C.uint64_t
 		priority, partial C.int
-		done              bool
-		shown             int
+	)
+	i := C.get_message(j, &msg, &length, &partial)
+	if i == -C.ENOENT || i == -C.EADDRNOTAVAIL {
+		return nil, 0, true
+	}
+	// Read the entry's timestamp.
+	if C.sd_journal_get_realtime_usec(j, &realtime) != 0 {
+		return nil, 0, false
+	}
+
+	// Set up the time and text of the entry.
+	timestamp := time.Unix(int64(realtime)/1000000, (int64(realtime)%1000000)*1000)
+	line := C.GoBytes(unsafe.Pointer(msg), C.int(length))
+	if partial == 0 {
+		line = append(line, "\n"...)
+	}
+	// Recover the stream name by mapping
+	// from the journal priority back to
+	// the stream that we would have
+	// assigned that value.
+	source := ""
+	if C.get_priority(j, &priority) == 0 {
+		if priority == C.int(journal.PriErr) {
+			source = "stderr"
+		} else if priority == C.int(journal.PriInfo) {
+			source = "stdout"
+		}
+	}
+	// Retrieve the values of any variables we're adding to the journal.
+	var attrs []backend.LogAttr
+	C.sd_journal_restart_data(j)
+	for C.get_attribute_field(j, &data, &length) > C.int(0) {
+		kv := strings.SplitN(C.GoStringN(data, C.int(length)), "=", 2)
+		attrs = append(attrs, backend.LogAttr{Key: kv[0], Value: kv[1]})
+	}
+	return &logger.Message{
+		Line:      line,
+		Source:    source,
+		Timestamp: timestamp.In(time.UTC),
+		Attrs:     attrs,
+	}, uint64(realtime), true
+}
+
+func (s *journald) drainJournal(logWatcher *logger.LogWatcher, j *C.sd_journal, oldCursor *C.char, untilUnixMicro uint64, filter *logger.MessageFilter, budget *logger.ByteBudget) (*C.char, bool, int) {
+	var (
+		cursor      *C.char
+		done        bool
+		shown, read int
 	)
 
 	// Walk the journal from here forward until we run out of new entries
@@ -155,60 +204,41 @@ drain:
 			}
 		}
 		// Read and send the logged message, if there is one to read.
-		i := C.get_message(j, &msg, &length, &partial)
-		if i != -C.ENOENT && i != -C.EADDRNOTAVAIL {
-			// Read the entry's timestamp.
-			if C.sd_journal_get_realtime_usec(j, &stamp) != 0 {
-				break
-			}
+		msg, stamp, ok := readEntry(j)
+		if !ok {
+			break
+		}
+		if msg != nil {
 			// Break if the timestamp exceeds any provided until flag.
-			if untilUnixMicro != 0 && untilUnixMicro < uint64(stamp) {
+			if untilUnixMicro != 0 && untilUnixMicro < stamp {
 				done = true
 				break
 			}
 
-			// Set up the time and text of the entry.
-			timestamp := time.Unix(int64(stamp)/1000000, (int64(stamp)%1000000)*1000)
-			line := C.GoBytes(unsafe.Pointer(msg), C.int(length))
-			if partial == 0 {
-				line = append(line, "\n"...)
-			}
-			// Recover the stream name by mapping
-			// from the journal priority back to
-			// the stream that we would have
-			// assigned that value.
-			source := ""
-			if C.get_priority(j, &priority) == 0 {
-				if priority == C.int(journal.PriErr) {
-					source = "stderr"
-				} else if priority == C.int(journal.PriInfo) {
-					source = "stdout"
+			if filter.Match(msg) {
+				// Stop once the size of the messages sent exceeds the budget.
+				if !budget.Take(msg) {
+					done = true
+					break
+				}
+				// Send the log message, unless the consumer is gone
+				select {
+				case <-logWatcher.WatchConsumerGone():
+					done = true // we won't be able to write anything anymore
+					break drain
+				case logWatcher.Msg <- msg:
+					shown++
+				}
+				// Do not wait for the next entry once the budget is used up.
+				if budget.Exhausted() {
+					done = true
+					break
 				}
 			}
-			// Retrieve the values of any variables we're adding to the journal.
-			var attrs []backend.LogAttr
-			C.sd_journal_restart_data(j)
-			for C.get_attribute_field(j, &data, &length) > C.int(0) {
-				kv := strings.SplitN(C.GoStringN(data, C.int(length)), "=", 2)
-				attrs = append(attrs, backend.LogAttr{Key: kv[0], Value: kv[1]})
-			}
-
-			// Send the log message, unless the consumer is gone
-			select {
-			case <-logWatcher.WatchConsumerGone():
-				done = true // we won't be able to write anything anymore
-				break drain
-			case logWatcher.Msg <- &logger.Message{
-				Line:      line,
-				Source:    source,
-				Timestamp: timestamp.In(time.UTC),
-				Attrs:     attrs,
-			}:
-				shown++
-			}
+			read++
 			// Call sd_journal_process() periodically during the processing loop
 			// to close any opened file descriptors for rotated (deleted) journal files.
-			if shown%1024 == 0 {
+			if read%1024 == 0 {
 				if ret := C.sd_journal_process(j); ret < 0 {
 					// log a warning but ignore it for now
 					logrus.WithField("container", s.vars["CONTAINER_ID_FULL"]).
@@ -232,7 +262,7 @@ drain:
 	return cursor, done, shown
 }
 
-func (s *journald) followJournal(logWatcher *logger.LogWatcher, j *C.sd_journal, cursor *C.char, untilUnixMicro uint64) *C.char {
+func (s *journald) followJournal(logWatcher *logger.LogWatcher, j *C.sd_journal, cursor *C.char, untilUnixMicro uint64, filter *logger.MessageFilter, budget *logger.ByteBudget) *C.char {
 	s.mu.Lock()
 	s.readers[logWatcher] = struct{}{}
 	s.mu.Unlock()
@@ -257,7 +287,7 @@ func (s *journald) followJournal(logWatcher *logger.LogWatcher, j *C.sd_journal,
 				continue
 			}
 		}
-		newCursor, done, recv := s.drainJournal(logWatcher, j, cursor, untilUnixMicro)
+		newCursor, done, recv := s.drainJournal(logWatcher, j, cursor, untilUnixMicro, filter, budget)
 		cursor = newCursor
 		if done || (status == C.SD_JOURNAL_NOP && recv == 0) {
 			break
@@ -279,7 +309,9 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 		stamp          C.uint64_t
 		sinceUnixMicro uint64
 		untilUnixMicro uint64
+		done           bool
 	)
+	budget := logger.NewByteBudget(config.MaxBytes)
 
 	// Get a handle to the journal.
 	if rc := C.sd_journal_open(&j, C.int(0)); rc != 0 {
@@ -341,7 +373,26 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 			return
 		}
 		// (Try to) skip backwards by the requested number of lines...
-		if C.sd_journal_previous_skip(j, C.uint64_t(config.Tail)) >= 0 {
+		if !config.Filter.IsZero() {
+			// ...counting only the lines matching the filter.
+			for n := 0; n < config.Tail && C.sd_journal_previous(j) > 0; {
+				msg, entryStamp, ok := readEntry(j)
+				if !ok || msg == nil {
+					continue
+				}
+				if sinceUnixMicro != 0 && entryStamp < sinceUnixMicro {
+					break
+				}
+				if config.Filter.Match(msg) {
+					n++
+				}
+			}
+			if sinceUnixMicro != 0 &&
+				C.sd_journal_get_realtime_usec(j, &stamp) == 0 &&
+				uint64(stamp) < sinceUnixMicro {
+				C.sd_journal_seek_realtime_usec(j, C.uint64_t(sinceUnixMicro))
+			}
+		} else if C.sd_journal_previous_skip(j, C.uint64_t(config.Tail)) >= 0 {
 			// ...but not before "since"
 			if sinceUnixMicro != 0 &&
 				C.sd_journal_get_realtime_usec(j, &stamp) == 0 &&
@@ -368,10 +419,10 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 		}
 	}
 	if config.Tail != 0 { // special case for --tail 0
-		cursor, _, _ = s.drainJournal(logWatcher, j, nil, untilUnixMicro)
+		cursor, done, _ = s.drainJournal(logWatcher, j, nil, untilUnixMicro, config.Filter, budget)
 	}
-	if config.Follow {
-		cursor = s.followJournal(logWatcher, j, cursor, untilUnixMicro)
+	if config.Follow && !done {
+		cursor = s.followJournal(logWatcher, j, cursor, untilUnixMicro, config.Filter, budget)
 		// Let followJournal handle freeing the journal context
 		// object and closing the channel.
 		following = true
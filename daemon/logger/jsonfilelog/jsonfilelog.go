@@ -77,6 +77,15 @@ func New(info logger.Info) (logger.Logger, error) {
 		}
 	}
 
+	quotaOpts, err := loggerutils.ParseQuotaOptions(info.Config)
+	if err != nil {
+		return nil, err
+	}
+	bufferOpts, err := loggerutils.ParseBufferOptions(info.Config)
+	if err != nil {
+		return nil, err
+	}
+
 	attrs, err := info.ExtraAttributes(nil)
 	if err != nil {
 		return nil, err
@@ -114,6 +123,8 @@ func New(info logger.Info) (logger.Logger, error) {
 	if err != nil {
 		return nil, err
 	}
+	writer.SetQuota(loggerutils.DefaultQuota, quotaOpts)
+	writer.SetBuffer(bufferOpts)
 
 	return &JSONFileLogger{
 		writer:  writer,
@@ -160,6 +171,10 @@ func ValidateLogOpt(cfg map[string]string) error {
 		case "env":
 		case "env-regex":
 		case "tag":
+		case loggerutils.QuotaWeightOpt:
+		case loggerutils.QuotaMinOpt:
+		case loggerutils.BufferMaxSizeOpt:
+		case loggerutils.BufferMaxAgeOpt:
 		default:
 			return fmt.Errorf("unknown log opt '%s' for json-file log driver", key)
 		}
@@ -1,6 +1,7 @@
 package local
 
 import (
+	"github.com/docker/docker/daemon/logger/loggerutils"
 	"github.com/pkg/errors"
 )
 
@@ -9,6 +10,8 @@ type CreateConfig struct {
 	DisableCompression bool
 	MaxFileSize        int64
 	MaxFileCount       int
+	Quota              loggerutils.QuotaOptions
+	Buffer             loggerutils.BufferOptions
 }
 
 func newDefaultConfig() *CreateConfig {
@@ -16,6 +19,7 @@ func newDefaultConfig() *CreateConfig {
 		MaxFileSize:        defaultMaxFileSize,
 		MaxFileCount:       defaultMaxFileCount,
 		DisableCompression: !defaultCompressLogs,
+		Quota:              loggerutils.QuotaOptions{Weight: 1},
 	}
 }
 
@@ -35,6 +35,12 @@ var LogOptKeys = map[string]bool{
 	"max-file": true,
 	"max-size": true,
 	"compress": true,
+
+	loggerutils.QuotaWeightOpt: true,
+	loggerutils.QuotaMinOpt:    true,
+
+	loggerutils.BufferMaxSizeOpt: true,
+	loggerutils.BufferMaxAgeOpt:  true,
 }
 
 // ValidateLogOpt looks for log driver specific options.
@@ -94,6 +100,16 @@ func New(info logger.Info) (logger.Logger, error) {
 		}
 		cfg.DisableCompression = !compressLogs
 	}
+
+	var err error
+	cfg.Quota, err = loggerutils.ParseQuotaOptions(info.Config)
+	if err != nil {
+		return nil, errdefs.InvalidParameter(err)
+	}
+	cfg.Buffer, err = loggerutils.ParseBufferOptions(info.Config)
+	if err != nil {
+		return nil, errdefs.InvalidParameter(err)
+	}
 	return newDriver(info.LogPath, cfg)
 }
 
@@ -144,6 +160,8 @@ func newDriver(logPath string, cfg *CreateConfig) (logger.Logger, error) {
 	if err != nil {
 		return nil, err
 	}
+	lf.SetQuota(loggerutils.DefaultQuota, cfg.Quota)
+	lf.SetBuffer(cfg.Buffer)
 	return &driver{
 		logfile: lf,
 		readers: make(map[*logger.LogWatcher]struct{}),
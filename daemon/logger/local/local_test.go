@@ -82,13 +82,23 @@ func TestWriteLog(t *testing.T) {
 
 func TestReadLog(t *testing.T) {
 	t.Parallel()
+	testReadLog(t, nil)
+}
 
+func TestReadLogBuffered(t *testing.T) {
+	t.Parallel()
+	// The messages are read from the buffer, as they are not written to
+	// disk yet.
+	testReadLog(t, map[string]string{"buffer-max-size": "1m", "buffer-max-age": "1h"})
+}
+
+func testReadLog(t *testing.T, config map[string]string) {
 	dir, err := ioutil.TempDir("", t.Name())
 	assert.NilError(t, err)
 	defer os.RemoveAll(dir)
 
 	logPath := filepath.Join(dir, "test.log")
-	l, err := New(logger.Info{LogPath: logPath})
+	l, err := New(logger.Info{LogPath: logPath, Config: config})
 	assert.NilError(t, err)
 	defer l.Close()
 
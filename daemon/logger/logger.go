@@ -91,6 +91,12 @@ type ReadConfig struct {
 	Until  time.Time
 	Tail   int
 	Follow bool
+	// Filter, if set, selects the messages read. Tail counts only the
+	// messages it matches.
+	Filter *MessageFilter
+	// MaxBytes, if positive, is the most bytes of log lines read. Reading
+	// stops at the first message that would exceed it.
+	MaxBytes int64
 }
 
 // LogReader is the interface for reading log messages for loggers that support reading.
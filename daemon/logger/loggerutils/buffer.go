@@ -0,0 +1,320 @@
+package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutils"
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/pubsub"
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Log options enabling buffering. They set the maximum amount of logs lost
+// on power failure.
+const (
+	BufferMaxSizeOpt = "buffer-max-size"
+	BufferMaxAgeOpt  = "buffer-max-age"
+)
+
+const (
+	// DefaultBufferMaxSize is the size of the buffer when only
+	// buffer-max-age is set.
+	DefaultBufferMaxSize = 1024 * 1024
+	// DefaultBufferMaxAge is the age of the buffer when only
+	// buffer-max-size is set.
+	DefaultBufferMaxAge = 10 * time.Second
+
+	// bufferFollowBacklog is the number of entries queued for each follower
+	// of a buffered log file. Writing to the log never waits for followers:
+	// the entries written while the queue of a follower is full are not
+	// sent to it, and following the log fails with errFollowerLagging.
+	bufferFollowBacklog = 4096
+)
+
+// BufferOptions set how the entries of a log file are buffered in memory
+// before they are written to disk, to write to disk less often and in
+// larger batches. They are the most logs lost if the buffer cannot be
+// written to disk, such as on power failure.
+type BufferOptions struct {
+	// MaxSize is the size at which the buffer is written to disk.
+	MaxSize int64
+	// MaxAge is the time after which entries are written to disk.
+	MaxAge time.Duration
+}
+
+// Enabled returns whether the entries are buffered.
+func (o BufferOptions) Enabled() bool {
+	return o.MaxSize > 0
+}
+
+// ParseBufferOptions parses the buffer-max-size and buffer-max-age log
+// options. Buffering is disabled unless one of them is set.
+func ParseBufferOptions(cfg map[string]string) (BufferOptions, error) {
+	var opts BufferOptions
+	s, sizeSet := cfg[BufferMaxSizeOpt]
+	if sizeSet {
+		size, err := units.RAMInBytes(s)
+		if err != nil || size <= 0 {
+			return opts, errors.Errorf("invalid value for %s: %s: must be a positive size", BufferMaxSizeOpt, s)
+		}
+		opts.MaxSize = size
+	}
+	a, ageSet := cfg[BufferMaxAgeOpt]
+	if ageSet {
+		age, err := time.ParseDuration(a)
+		if err != nil || age <= 0 {
+			return opts, errors.Errorf("invalid value for %s: %s: must be a positive duration", BufferMaxAgeOpt, a)
+		}
+		opts.MaxAge = age
+	}
+	if !sizeSet && !ageSet {
+		return opts, nil
+	}
+	if !sizeSet {
+		opts.MaxSize = DefaultBufferMaxSize
+	}
+	if !ageSet {
+		opts.MaxAge = DefaultBufferMaxAge
+	}
+	return opts, nil
+}
+
+// logBuffer holds the entries of a log file not yet written to disk.
+type logBuffer struct {
+	opts    BufferOptions
+	data    []byte
+	entries []bufferedEntry
+	timer   *time.Timer
+	// followers receive the entries as they are written to the buffer, as
+	// followedEntry values, unless they lag bufferFollowBacklog entries
+	// behind.
+	followers *pubsub.Publisher
+	// published is the sequence number of the last entry sent to the
+	// followers.
+	published uint64
+}
+
+type bufferedEntry struct {
+	end       int
+	timestamp time.Time
+}
+
+// followedEntry is an entry sent to the followers of a buffered log file.
+// The sequence numbers of the entries tell the followers whether they
+// missed some.
+type followedEntry struct {
+	seq  uint64
+	data []byte
+}
+
+// errFollowerLagging is returned to the followers of a buffered log file
+// that missed entries.
+var errFollowerLagging = errors.New("log entries were dropped: the logs were written faster than they were read")
+
+// bufferedFiles are the log files with a buffer, flushed by FlushBuffers.
+var bufferedFiles = struct {
+	sync.Mutex
+	files map[*LogFile]struct{}
+}{files: make(map[*LogFile]struct{})}
+
+// FlushBuffers writes the buffered entries of all the log files to disk.
+func FlushBuffers() {
+	bufferedFiles.Lock()
+	files := make([]*LogFile, 0, len(bufferedFiles.files))
+	for w := range bufferedFiles.files {
+		files = append(files, w)
+	}
+	bufferedFiles.Unlock()
+
+	for _, w := range files {
+		w.mu.Lock()
+		if err := w.flush(); err != nil {
+			logrus.WithError(err).WithField("file", w.f.Name()).Error("failed to flush log buffer")
+		}
+		w.mu.Unlock()
+	}
+}
+
+// SetBuffer makes w buffer the entries in memory, as set by opts. The
+// buffer is written to disk when it reaches its maximum size or age, when
+// w is closed, and by FlushBuffers. It must be called before any entry is
+// written.
+func (w *LogFile) SetBuffer(opts BufferOptions) {
+	if !opts.Enabled() {
+		return
+	}
+	w.mu.Lock()
+	w.buffer = &logBuffer{
+		opts:      opts,
+		followers: pubsub.NewPublisher(0, bufferFollowBacklog),
+	}
+	w.mu.Unlock()
+
+	bufferedFiles.Lock()
+	bufferedFiles.files[w] = struct{}{}
+	bufferedFiles.Unlock()
+}
+
+// bufferEntry adds an entry to the buffer, writing the buffer to disk if it
+// is full. w.mu must be held.
+func (w *LogFile) bufferEntry(b []byte, timestamp time.Time) error {
+	buf := w.buffer
+	if buf.timer == nil {
+		buf.timer = time.AfterFunc(buf.opts.MaxAge, w.flushExpired)
+	}
+	buf.data = append(buf.data, b...)
+	buf.entries = append(buf.entries, bufferedEntry{end: len(buf.data), timestamp: timestamp})
+	buf.published++
+	buf.followers.Publish(followedEntry{seq: buf.published, data: append([]byte(nil), b...)})
+
+	if int64(len(buf.data)) >= buf.opts.MaxSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *LogFile) flushExpired() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	if err := w.flush(); err != nil {
+		logrus.WithError(err).WithField("file", w.f.Name()).Error("failed to flush log buffer")
+	}
+}
+
+// flush writes the buffered entries to disk, writing as many entries at once
+// as fit in the current file before it is rotated. w.mu must be held.
+func (w *LogFile) flush() error {
+	buf := w.buffer
+	if buf == nil || len(buf.entries) == 0 {
+		return nil
+	}
+	if buf.timer != nil {
+		buf.timer.Stop()
+		buf.timer = nil
+	}
+
+	var start int
+	for len(buf.entries) > 0 {
+		if err := w.checkCapacityAndRotate(); err != nil {
+			return errors.Wrap(err, "error rotating log file")
+		}
+
+		n := 0
+		for n < len(buf.entries) {
+			n++
+			if w.capacity != -1 && w.currentSize+int64(buf.entries[n-1].end-start) >= w.capacity {
+				break
+			}
+		}
+		end := buf.entries[n-1].end
+		written, err := w.f.Write(buf.data[start:end])
+		w.currentSize += int64(written)
+		w.growQuota(int64(written))
+		if err != nil {
+			// The entries that could not be written are lost, as when
+			// writing them unbuffered; keep the following ones.
+			buf.entries = buf.entries[n:]
+			for i := range buf.entries {
+				buf.entries[i].end -= end
+			}
+			buf.data = append(buf.data[:0], buf.data[end:]...)
+			return errors.Wrap(err, "error writing log buffer")
+		}
+		w.lastTimestamp = buf.entries[n-1].timestamp
+		buf.entries = buf.entries[n:]
+		start = end
+	}
+	buf.data = buf.data[:0]
+	return nil
+}
+
+// pending returns a reader of the buffered entries, and subscribes to the
+// following ones if follow is true, along with the sequence number of the
+// next entry. w.mu must be held.
+func (w *LogFile) pending(follow bool) (SizeReaderAt, chan interface{}, uint64) {
+	if w.buffer == nil {
+		return nil, nil, 0
+	}
+	var entries chan interface{}
+	if follow && !w.closed {
+		entries = w.buffer.followers.Subscribe()
+	}
+	return bytes.NewReader(append([]byte(nil), w.buffer.data...)), entries, w.buffer.published + 1
+}
+
+// followBuffer sends the entries written to the buffer to watcher, starting
+// with the entry numbered next, until w is closed or the consumer is gone.
+// It fails if entries were dropped because the consumer lags behind, rather
+// than leaving a gap in the logs.
+func (w *LogFile) followBuffer(entries chan interface{}, next uint64, watcher *logger.LogWatcher, dec Decoder, config logger.ReadConfig, budget *logger.ByteBudget) {
+	for {
+		// Entries dropped after the last one received are only noticed
+		// here, as no entry may be written after them.
+		if len(entries) == 0 && w.missedEntries(entries, next) {
+			watcher.Err <- errFollowerLagging
+			return
+		}
+
+		var e followedEntry
+		select {
+		case v, ok := <-entries:
+			if !ok {
+				return
+			}
+			e = v.(followedEntry)
+		case <-watcher.WatchConsumerGone():
+			return
+		}
+		if e.seq != next {
+			watcher.Err <- errFollowerLagging
+			return
+		}
+		next++
+
+		dec.Reset(bytes.NewReader(e.data))
+		msg, err := dec.Decode()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				watcher.Err <- err
+			}
+			return
+		}
+		if !config.Since.IsZero() && msg.Timestamp.Before(config.Since) {
+			continue
+		}
+		if !config.Until.IsZero() && msg.Timestamp.After(config.Until) {
+			return
+		}
+		if !config.Filter.Match(msg) {
+			continue
+		}
+		if !budget.Take(msg) {
+			return
+		}
+		select {
+		case watcher.Msg <- msg:
+		case <-watcher.WatchConsumerGone():
+			return
+		}
+		// Do not wait for the next entry once the budget is used up.
+		if budget.Exhausted() {
+			return
+		}
+	}
+}
+
+// missedEntries returns whether entries were published since the entry
+// numbered next that are not queued in entries, the subscription of a
+// follower.
+func (w *LogFile) missedEntries(entries chan interface{}, next uint64) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(entries) == 0 && w.buffer.published >= next
+}
@@ -0,0 +1,252 @@
+package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutils"
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+)
+
+// lineDecoder decodes log files of one entry per line.
+type lineDecoder struct {
+	rdr *bufio.Reader
+}
+
+func (d *lineDecoder) Decode() (*logger.Message, error) {
+	if d.rdr == nil {
+		return nil, io.EOF
+	}
+	line, err := d.rdr.ReadBytes('\n')
+	if err != nil {
+		// Partial lines are not decoded.
+		return nil, io.EOF
+	}
+	return &logger.Message{Line: line[:len(line)-1], Timestamp: time.Now()}, nil
+}
+
+func (d *lineDecoder) Reset(rdr io.Reader) {
+	d.rdr = bufio.NewReader(rdr)
+}
+
+func (d *lineDecoder) Close() {}
+
+func newBufferedLogFile(t *testing.T, capacity int64, maxFiles int, opts BufferOptions) (*LogFile, string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "buffered-log")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "log")
+	marshal := func(msg *logger.Message) ([]byte, error) {
+		return append(msg.Line, '\n'), nil
+	}
+	decode := func(rdr io.Reader) Decoder {
+		return &lineDecoder{}
+	}
+	l, err := NewLogFile(path, capacity, maxFiles, false, marshal, decode, 0600, nil)
+	assert.NilError(t, err)
+	l.SetBuffer(opts)
+	return l, path
+}
+
+func writeLines(t *testing.T, l *LogFile, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		assert.NilError(t, l.WriteLogEntry(&logger.Message{Line: []byte(line), Timestamp: time.Now()}))
+	}
+}
+
+func readLines(t *testing.T, lw *logger.LogWatcher, n int) []string {
+	t.Helper()
+	var lines []string
+	for len(lines) < n {
+		select {
+		case msg := <-lw.Msg:
+			lines = append(lines, string(msg.Line))
+		case err := <-lw.Err:
+			t.Fatal(err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout reading logs, got %v", lines)
+		}
+	}
+	return lines
+}
+
+func fileContent(t *testing.T, path string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	return string(b)
+}
+
+func TestParseBufferOptions(t *testing.T) {
+	opts, err := ParseBufferOptions(nil)
+	assert.NilError(t, err)
+	assert.Check(t, !opts.Enabled())
+
+	opts, err = ParseBufferOptions(map[string]string{BufferMaxSizeOpt: "64k"})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(opts, BufferOptions{MaxSize: 64 * 1024, MaxAge: DefaultBufferMaxAge}))
+
+	opts, err = ParseBufferOptions(map[string]string{BufferMaxAgeOpt: "1m"})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(opts, BufferOptions{MaxSize: DefaultBufferMaxSize, MaxAge: time.Minute}))
+
+	_, err = ParseBufferOptions(map[string]string{BufferMaxSizeOpt: "0"})
+	assert.Check(t, is.ErrorContains(err, "invalid value for buffer-max-size"))
+	_, err = ParseBufferOptions(map[string]string{BufferMaxAgeOpt: "-1s"})
+	assert.Check(t, is.ErrorContains(err, "invalid value for buffer-max-age"))
+}
+
+func TestBufferedLogFileFlush(t *testing.T) {
+	l, path := newBufferedLogFile(t, -1, 1, BufferOptions{MaxSize: 12, MaxAge: time.Hour})
+
+	writeLines(t, l, "one", "two")
+	assert.Check(t, is.Equal(fileContent(t, path), ""))
+
+	// Reaching the maximum size writes the buffer.
+	writeLines(t, l, "three")
+	assert.Check(t, is.Equal(fileContent(t, path), "one\ntwo\nthree\n"))
+
+	writeLines(t, l, "four")
+	FlushBuffers()
+	assert.Check(t, is.Equal(fileContent(t, path), "one\ntwo\nthree\nfour\n"))
+
+	writeLines(t, l, "five")
+	assert.NilError(t, l.Close())
+	assert.Check(t, is.Equal(fileContent(t, path), "one\ntwo\nthree\nfour\nfive\n"))
+}
+
+func TestBufferedLogFileMaxAge(t *testing.T) {
+	l, path := newBufferedLogFile(t, -1, 1, BufferOptions{MaxSize: 1024, MaxAge: 10 * time.Millisecond})
+	defer l.Close()
+
+	writeLines(t, l, "one")
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if content := fileContent(t, path); content != "one\n" {
+			return poll.Continue("log file contains %q", content)
+		}
+		return poll.Success()
+	}, poll.WithDelay(10*time.Millisecond))
+}
+
+func TestBufferedLogFileRotation(t *testing.T) {
+	l, path := newBufferedLogFile(t, 8, 3, BufferOptions{MaxSize: 1024, MaxAge: time.Hour})
+	defer l.Close()
+
+	writeLines(t, l, "aaa", "bbb", "ccc", "ddd", "eee", "fff")
+	FlushBuffers()
+	assert.Check(t, is.Equal(fileContent(t, path+".2"), "aaa\nbbb\n"))
+	assert.Check(t, is.Equal(fileContent(t, path+".1"), "ccc\nddd\n"))
+	assert.Check(t, is.Equal(fileContent(t, path), "eee\nfff\n"))
+}
+
+func TestBufferedLogFileReadLogs(t *testing.T) {
+	l, _ := newBufferedLogFile(t, -1, 1, BufferOptions{MaxSize: 1024, MaxAge: time.Hour})
+	defer l.Close()
+
+	writeLines(t, l, "one", "two")
+	FlushBuffers()
+	writeLines(t, l, "three")
+
+	// Entries on disk and in the buffer are read.
+	lw := logger.NewLogWatcher()
+	go l.ReadLogs(logger.ReadConfig{Tail: -1}, lw)
+	assert.Check(t, is.DeepEqual(readLines(t, lw, 3), []string{"one", "two", "three"}))
+	lw.ConsumerGone()
+
+	// Entries written to the buffer are followed.
+	lw = logger.NewLogWatcher()
+	defer lw.ConsumerGone()
+	go l.ReadLogs(logger.ReadConfig{Tail: -1, Follow: true}, lw)
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if l.buffer.followers.Len() == 0 {
+			return poll.Continue("waiting for follower")
+		}
+		return poll.Success()
+	}, poll.WithDelay(time.Millisecond))
+	writeLines(t, l, "four", "five")
+	assert.Check(t, is.DeepEqual(readLines(t, lw, 5), []string{"one", "two", "three", "four", "five"}))
+}
+
+func TestBufferedLogFileFollowerLagging(t *testing.T) {
+	l, _ := newBufferedLogFile(t, -1, 1, BufferOptions{MaxSize: 1024 * 1024, MaxAge: time.Hour})
+	defer l.Close()
+
+	lw := logger.NewLogWatcher()
+	defer lw.ConsumerGone()
+	go l.ReadLogs(logger.ReadConfig{Follow: true}, lw)
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if l.buffer.followers.Len() == 0 {
+			return poll.Continue("waiting for follower")
+		}
+		return poll.Success()
+	}, poll.WithDelay(time.Millisecond))
+
+	// The follower can queue fewer entries than written while it doesn't
+	// read them.
+	const n = 3 * bufferFollowBacklog
+	for i := 0; i < n; i++ {
+		writeLines(t, l, strconv.Itoa(i))
+	}
+
+	// The entries are followed without gaps until the follower fails.
+	for i := 0; ; i++ {
+		select {
+		case msg := <-lw.Msg:
+			assert.Assert(t, is.Equal(string(msg.Line), strconv.Itoa(i)))
+		case err := <-lw.Err:
+			assert.Check(t, is.ErrorContains(err, "log entries were dropped"))
+			assert.Check(t, i < n, "read %d entries", i)
+			return
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout reading logs after %d entries", i)
+		}
+	}
+}
+
+func TestFollowLogsMaxBytes(t *testing.T) {
+	for _, buffered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("buffered=%v", buffered), func(t *testing.T) {
+			l, _ := newBufferedLogFile(t, -1, 1, BufferOptions{MaxSize: 1024, MaxAge: time.Hour})
+			defer l.Close()
+			if !buffered {
+				l.buffer = nil
+			}
+			writeLines(t, l, "one")
+
+			// Following stops as soon as the budget is used up, without
+			// waiting for the next entry.
+			lw := logger.NewLogWatcher()
+			defer lw.ConsumerGone()
+			done := make(chan struct{})
+			go func() {
+				l.ReadLogs(logger.ReadConfig{Tail: -1, Follow: true, MaxBytes: 6}, lw)
+				close(done)
+			}()
+			poll.WaitOn(t, func(poll.LogT) poll.Result {
+				if buffered && l.buffer.followers.Len() == 0 {
+					return poll.Continue("waiting for follower")
+				}
+				return poll.Success()
+			}, poll.WithDelay(time.Millisecond))
+			writeLines(t, l, "two")
+			assert.Check(t, is.DeepEqual(readLines(t, lw, 2), []string{"one", "two"}))
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("following did not stop once the budget was used up")
+			}
+		})
+	}
+}
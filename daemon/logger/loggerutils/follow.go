@@ -3,7 +3,6 @@ package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutil
 import (
 	"io"
 	"os"
-	"time"
 
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/pkg/filenotify"
@@ -136,7 +135,7 @@ func (fl *follow) handleDecodeErr(err error) error {
 	return nil
 }
 
-func (fl *follow) mainLoop(since, until time.Time) {
+func (fl *follow) mainLoop(config logger.ReadConfig, budget *logger.ByteBudget) {
 	for {
 		select {
 		case err := <-fl.notifyEvict:
@@ -161,10 +160,16 @@ func (fl *follow) mainLoop(since, until time.Time) {
 		}
 
 		fl.retries = 0 // reset retries since we've succeeded
-		if !since.IsZero() && msg.Timestamp.Before(since) {
+		if !config.Since.IsZero() && msg.Timestamp.Before(config.Since) {
 			continue
 		}
-		if !until.IsZero() && msg.Timestamp.After(until) {
+		if !config.Until.IsZero() && msg.Timestamp.After(config.Until) {
+			return
+		}
+		if !config.Filter.Match(msg) {
+			continue
+		}
+		if !budget.Take(msg) {
 			return
 		}
 		// send the message, unless the consumer is gone
@@ -180,10 +185,14 @@ func (fl *follow) mainLoop(since, until time.Time) {
 		case <-fl.logWatcher.WatchConsumerGone():
 			return
 		}
+		// Do not wait for the next message once the budget is used up.
+		if budget.Exhausted() {
+			return
+		}
 	}
 }
 
-func followLogs(f *os.File, logWatcher *logger.LogWatcher, notifyRotate, notifyEvict chan interface{}, dec Decoder, since, until time.Time) {
+func followLogs(f *os.File, logWatcher *logger.LogWatcher, notifyRotate, notifyEvict chan interface{}, dec Decoder, config logger.ReadConfig, budget *logger.ByteBudget) {
 	dec.Reset(f)
 
 	name := f.Name()
@@ -207,5 +216,5 @@ func followLogs(f *os.File, logWatcher *logger.LogWatcher, notifyRotate, notifyE
 		notifyEvict:  notifyEvict,
 		dec:          dec,
 	}
-	fl.mainLoop(since, until)
+	fl.mainLoop(config, budget)
 }
@@ -91,6 +91,9 @@ type LogFile struct {
 	createDecoder   MakeDecoderFn
 	getTailReader   GetTailReaderFunc
 	perms           os.FileMode
+	quota           *Quota
+	quotaLog        *quotaLog
+	buffer          *logBuffer // entries not yet written to disk, if buffered
 }
 
 // MakeDecoderFn creates a decoder
@@ -156,6 +159,7 @@ func (w *LogFile) WriteLogEntry(msg *logger.Message) error {
 		return errors.Wrap(err, "error marshalling log message")
 	}
 
+	timestamp := msg.Timestamp
 	logger.PutMessage(msg)
 
 	w.mu.Lock()
@@ -164,6 +168,12 @@ func (w *LogFile) WriteLogEntry(msg *logger.Message) error {
 		return errors.New("cannot write because the output file was closed")
 	}
 
+	if w.buffer != nil {
+		err := w.bufferEntry(b, timestamp)
+		w.mu.Unlock()
+		return err
+	}
+
 	if err := w.checkCapacityAndRotate(); err != nil {
 		w.mu.Unlock()
 		return errors.Wrap(err, "error rotating log file")
@@ -172,8 +182,9 @@ func (w *LogFile) WriteLogEntry(msg *logger.Message) error {
 	n, err := w.f.Write(b)
 	if err == nil {
 		w.currentSize += int64(n)
-		w.lastTimestamp = msg.Timestamp
+		w.lastTimestamp = timestamp
 	}
+	w.growQuota(int64(n))
 
 	w.mu.Unlock()
 	return errors.Wrap(err, "error writing log entry")
@@ -233,6 +244,7 @@ func (w *LogFile) checkCapacityAndRotate() (retErr error) {
 	w.notifyReaders.Publish(struct{}{})
 
 	if noCompress {
+		w.notifyQuota()
 		return nil
 	}
 
@@ -243,6 +255,7 @@ func (w *LogFile) checkCapacityAndRotate() (retErr error) {
 			logrus.WithError(err).Error("Error compressing log file after rotation")
 		}
 		w.rotateMu.Unlock()
+		w.notifyQuota()
 	}()
 
 	return nil
@@ -328,6 +341,32 @@ func compressFile(fileName string, lastTimestamp time.Time) (retErr error) {
 	return nil
 }
 
+// SetQuota counts the files of w in quota q. Rotated files of w may then be
+// evicted to stay within the quota, as set by opts.
+func (w *LogFile) SetQuota(q *Quota, opts QuotaOptions) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.quota = q
+	// Rotated files are not evicted while they are renamed or compressed.
+	w.quotaLog = q.add(w.f.Name(), opts, &w.rotateMu)
+	q.notify()
+}
+
+// notifyQuota checks the quota of w, if any, after a rotation.
+func (w *LogFile) notifyQuota() {
+	if w.quota != nil {
+		w.quota.notify()
+	}
+}
+
+// growQuota counts n bytes written to w in its quota, if any. w.mu must be
+// held.
+func (w *LogFile) growQuota(n int64) {
+	if w.quota != nil && n > 0 {
+		w.quota.grow(n)
+	}
+}
+
 // MaxFiles return maximum number of files
 func (w *LogFile) MaxFiles() int {
 	return w.maxFiles
@@ -340,6 +379,15 @@ func (w *LogFile) Close() error {
 	if w.closed {
 		return nil
 	}
+	if w.buffer != nil {
+		if err := w.flush(); err != nil {
+			logrus.WithError(err).WithField("file", w.f.Name()).Error("failed to flush log buffer")
+		}
+		w.buffer.followers.Close()
+		bufferedFiles.Lock()
+		delete(bufferedFiles.files, w)
+		bufferedFiles.Unlock()
+	}
 	if err := w.f.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
 		return err
 	}
@@ -371,12 +419,21 @@ func (w *LogFile) ReadLogs(config logger.ReadConfig, watcher *logger.LogWatcher)
 		return
 	}
 
+	// The entries not yet written to disk are read from the buffer, and
+	// followed as they are written to it.
+	pending, bufferedEntries, nextEntry := w.pending(config.Follow)
+	if bufferedEntries != nil {
+		defer w.buffer.followers.Evict(bufferedEntries)
+	}
+
 	notifyEvict := w.notifyReaders.SubscribeTopicWithBuffer(func(i interface{}) bool {
 		_, ok := i.(error)
 		return ok
 	}, 1)
 	defer w.notifyReaders.Evict(notifyEvict)
 
+	budget := logger.NewByteBudget(config.MaxBytes)
+
 	if config.Tail != 0 {
 		// TODO(@cpuguy83): Instead of opening every file, only get the files which
 		// are needed to tail.
@@ -414,10 +471,13 @@ func (w *LogFile) ReadLogs(config logger.ReadConfig, watcher *logger.LogWatcher)
 		if currentChunk.Size() > 0 {
 			readers = append(readers, currentChunk)
 		}
+		if pending != nil && pending.Size() > 0 {
+			readers = append(readers, pending)
+		}
 
-		ok := tailFiles(readers, watcher, dec, w.getTailReader, config, notifyEvict)
+		ok := tailFiles(readers, watcher, dec, w.getTailReader, config, budget, notifyEvict)
 		closeFiles()
-		if !ok {
+		if !ok || budget.Exhausted() {
 			return
 		}
 		w.mu.RLock()
@@ -429,13 +489,18 @@ func (w *LogFile) ReadLogs(config logger.ReadConfig, watcher *logger.LogWatcher)
 	}
 	w.mu.RUnlock()
 
+	if bufferedEntries != nil {
+		w.followBuffer(bufferedEntries, nextEntry, watcher, dec, config, budget)
+		return
+	}
+
 	notifyRotate := w.notifyReaders.SubscribeTopic(func(i interface{}) bool {
 		_, ok := i.(struct{})
 		return ok
 	})
 	defer w.notifyReaders.Evict(notifyRotate)
 
-	followLogs(currentFile, watcher, notifyRotate, notifyEvict, dec, config.Since, config.Until)
+	followLogs(currentFile, watcher, notifyRotate, notifyEvict, dec, config, budget)
 }
 
 func (w *LogFile) openRotatedFiles(config logger.ReadConfig) (files []*os.File, err error) {
@@ -541,7 +606,7 @@ func newSectionReader(f *os.File) (*io.SectionReader, error) {
 	return io.NewSectionReader(f, 0, size), nil
 }
 
-func tailFiles(files []SizeReaderAt, watcher *logger.LogWatcher, dec Decoder, getTailReader GetTailReaderFunc, config logger.ReadConfig, notifyEvict <-chan interface{}) (cont bool) {
+func tailFiles(files []SizeReaderAt, watcher *logger.LogWatcher, dec Decoder, getTailReader GetTailReaderFunc, config logger.ReadConfig, budget *logger.ByteBudget, notifyEvict <-chan interface{}) (cont bool) {
 	nLines := config.Tail
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -564,9 +629,14 @@ func tailFiles(files []SizeReaderAt, watcher *logger.LogWatcher, dec Decoder, ge
 		}
 	}()
 
+	// Tail counts only the messages matching the filter, which cannot be
+	// told from the end of the files, so the files are read in full and
+	// the last matching messages kept.
+	filterTail := config.Tail > 0 && !config.Filter.IsZero()
+
 	readers := make([]io.Reader, 0, len(files))
 
-	if config.Tail > 0 {
+	if config.Tail > 0 && !filterTail {
 		for i := len(files) - 1; i >= 0 && nLines > 0; i-- {
 			tail, n, err := getTailReader(ctx, files[i], nLines)
 			if err != nil {
@@ -585,26 +655,58 @@ func tailFiles(files []SizeReaderAt, watcher *logger.LogWatcher, dec Decoder, ge
 	rdr := io.MultiReader(readers...)
 	dec.Reset(rdr)
 
+	send := func(msg *logger.Message) bool {
+		if !budget.Take(msg) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case watcher.Msg <- msg:
+			return true
+		}
+	}
+
+	var tail []*logger.Message
 	for {
 		msg, err := dec.Decode()
 		if err != nil {
 			if !errors.Is(err, io.EOF) {
 				watcher.Err <- err
+				return
 			}
-			return
+			break
 		}
 		if !config.Since.IsZero() && msg.Timestamp.Before(config.Since) {
 			continue
 		}
 		if !config.Until.IsZero() && msg.Timestamp.After(config.Until) {
+			if !filterTail {
+				return
+			}
+			break
+		}
+		if !config.Filter.Match(msg) {
+			continue
+		}
+		if filterTail {
+			tail = append(tail, msg)
+			if len(tail) > config.Tail {
+				tail = tail[1:]
+			}
+			continue
+		}
+		if !send(msg) {
 			return
 		}
-		select {
-		case <-ctx.Done():
+	}
+
+	for _, msg := range tail {
+		if !send(msg) {
 			return
-		case watcher.Msg <- msg:
 		}
 	}
+	return
 }
 
 func watchFile(name string) (filenotify.FileWatcher, error) {
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -69,7 +70,7 @@ func TestTailFiles(t *testing.T) {
 			started := make(chan struct{})
 			go func() {
 				close(started)
-				tailFiles(files, watcher, dec, tailReader, config, make(chan interface{}))
+				tailFiles(files, watcher, dec, tailReader, config, nil, make(chan interface{}))
 			}()
 			<-started
 		})
@@ -79,7 +80,7 @@ func TestTailFiles(t *testing.T) {
 	started := make(chan struct{})
 	go func() {
 		close(started)
-		tailFiles(files, watcher, dec, tailReader, config, make(chan interface{}))
+		tailFiles(files, watcher, dec, tailReader, config, nil, make(chan interface{}))
 	}()
 	<-started
 
@@ -104,6 +105,69 @@ func TestTailFiles(t *testing.T) {
 	}
 }
 
+func TestTailFilesFilter(t *testing.T) {
+	s1 := strings.NewReader("error: one\ninfo: two\nerror: three\n")
+	s2 := strings.NewReader("info: four\nerror: five\ninfo: six\n")
+	files := []SizeReaderAt{s1, s2}
+
+	tailReader := func(ctx context.Context, r SizeReaderAt, lines int) (io.Reader, int, error) {
+		return tailfile.NewTailReader(ctx, r, lines)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		config   logger.ReadConfig
+		expected []string
+	}{
+		{
+			desc:     "contains",
+			config:   logger.ReadConfig{Tail: -1, Filter: &logger.MessageFilter{Contains: "error"}},
+			expected: []string{"error: one", "error: three", "error: five"},
+		},
+		{
+			desc:     "pattern",
+			config:   logger.ReadConfig{Tail: -1, Filter: &logger.MessageFilter{Pattern: regexp.MustCompile(`^info: (two|six)$`)}},
+			expected: []string{"info: two", "info: six"},
+		},
+		{
+			desc:     "tail counts matching lines",
+			config:   logger.ReadConfig{Tail: 2, Filter: &logger.MessageFilter{Contains: "error"}},
+			expected: []string{"error: three", "error: five"},
+		},
+		{
+			desc:     "max bytes",
+			config:   logger.ReadConfig{Tail: -1, MaxBytes: 20},
+			expected: []string{"error: one", "info: two"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			for _, f := range files {
+				f.(*strings.Reader).Seek(0, io.SeekStart)
+			}
+			watcher := logger.NewLogWatcher()
+			defer watcher.ConsumerGone()
+
+			done := make(chan struct{})
+			go func() {
+				tailFiles(files, watcher, &lineDecoder{}, tailReader, tc.config, logger.NewByteBudget(tc.config.MaxBytes), make(chan interface{}))
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("timeout waiting for tail lines")
+			}
+			assert.Equal(t, len(watcher.Err), 0)
+			var lines []string
+			for len(watcher.Msg) > 0 {
+				lines = append(lines, string((<-watcher.Msg).Line))
+			}
+			assert.DeepEqual(t, lines, tc.expected)
+		})
+	}
+}
+
 type dummyDecoder struct{}
 
 func (dummyDecoder) Decode() (*logger.Message, error) {
@@ -128,7 +192,7 @@ func TestFollowLogsConsumerGone(t *testing.T) {
 	followLogsDone := make(chan struct{})
 	var since, until time.Time
 	go func() {
-		followLogs(f, lw, make(chan interface{}), make(chan interface{}), dec, since, until)
+		followLogs(f, lw, make(chan interface{}), make(chan interface{}), dec, logger.ReadConfig{Since: since, Until: until}, nil)
 		close(followLogsDone)
 	}()
 
@@ -189,7 +253,7 @@ func TestFollowLogsProducerGone(t *testing.T) {
 
 	followLogsDone := make(chan struct{})
 	go func() {
-		followLogs(f, lw, make(chan interface{}), make(chan interface{}), dec, since, until)
+		followLogs(f, lw, make(chan interface{}), make(chan interface{}), dec, logger.ReadConfig{Since: since, Until: until}, nil)
 		close(followLogsDone)
 	}()
 
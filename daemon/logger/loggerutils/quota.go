@@ -0,0 +1,359 @@
+package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutils"
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Log options setting how the log files of a container are evicted to stay
+// within the quota.
+const (
+	QuotaWeightOpt = "quota-weight"
+	QuotaMinOpt    = "quota-min"
+)
+
+// rotatedSuffix matches the suffix of rotated log files, such as ".3" or
+// ".3.gz".
+var rotatedSuffix = regexp.MustCompile(`^\.[0-9]+(\.gz)?$`)
+
+// QuotaOptions set how the log files of a container are evicted to stay
+// within the quota.
+type QuotaOptions struct {
+	// Weight makes the rotated files evicted as if they were Weight times
+	// more recent. The default weight is 1.
+	Weight float64
+	// Min is the size the log files are not evicted below.
+	Min int64
+}
+
+// ParseQuotaOptions parses the quota-weight and quota-min log options.
+func ParseQuotaOptions(cfg map[string]string) (QuotaOptions, error) {
+	opts := QuotaOptions{Weight: 1}
+	if s, ok := cfg[QuotaWeightOpt]; ok {
+		w, err := strconv.ParseFloat(s, 64)
+		if err != nil || w <= 0 {
+			return opts, errors.Errorf("invalid value for %s: %s: must be a positive number", QuotaWeightOpt, s)
+		}
+		opts.Weight = w
+	}
+	if s, ok := cfg[QuotaMinOpt]; ok {
+		m, err := units.FromHumanSize(s)
+		if err != nil || m < 0 {
+			return opts, errors.Errorf("invalid value for %s: %s", QuotaMinOpt, s)
+		}
+		opts.Min = m
+	}
+	return opts, nil
+}
+
+// DefaultQuota is the quota shared by the log files of all the containers.
+// It is unlimited unless a limit is set.
+var DefaultQuota = NewQuota(0)
+
+// Quota is a limit on the disk space used by a set of log files. When it is
+// exceeded, rotated files are evicted, oldest first, until the log files
+// fit within the limit again. The current file of a log is never evicted.
+//
+// The quota is checked whenever a log file is rotated, and whenever the
+// log files written with a LogFile grew by quotaCheckStep, or by a
+// hundredth of the limit if larger, so that it is also enforced when the
+// files of some logs are not rotated.
+type Quota struct {
+	growth int64 // bytes written since the quota was last checked, accessed atomically
+
+	mu     sync.Mutex
+	limit  int64
+	logs   map[string]*quotaLog
+	warned bool
+
+	enforceMu sync.Mutex
+	start     sync.Once
+	trigger   chan struct{}
+}
+
+// quotaCheckStep is the minimum growth of the log files after which the
+// quota is checked.
+const quotaCheckStep = 1024 * 1024
+
+// quotaLog is a log file, and its rotated files, counted in a quota.
+type quotaLog struct {
+	mu   sync.Mutex
+	path string
+	// rotateMu is held while the files are rotated or compressed. It is the
+	// rotateMu of the LogFile writing the log, or mu.
+	rotateMu *sync.Mutex  // protected by Quota.mu
+	opts     QuotaOptions // protected by Quota.mu
+	// adds counts the times the log was added to the quota, so that it is
+	// not forgotten if it is added again while its files are scanned.
+	adds int // protected by Quota.mu
+}
+
+type rotatedFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// NewQuota returns a quota of limit bytes. A limit of 0 or less is
+// unlimited.
+func NewQuota(limit int64) *Quota {
+	return &Quota{
+		limit:   limit,
+		logs:    make(map[string]*quotaLog),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// SetLimit changes the limit of the quota, evicting rotated files if the
+// new limit is exceeded.
+func (q *Quota) SetLimit(limit int64) {
+	q.mu.Lock()
+	q.limit = limit
+	q.mu.Unlock()
+	q.notify()
+}
+
+// Limit returns the limit of the quota.
+func (q *Quota) Limit() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.limit
+}
+
+// Add counts the log file at path, and its rotated files, in the quota. It
+// is not needed for the log files written with a LogFile using the quota,
+// but for the files of the logs not currently written to. The log is
+// removed from the quota once all its files are removed.
+func (q *Quota) Add(path string, opts QuotaOptions) {
+	q.add(path, opts, nil)
+	q.notify()
+}
+
+// add counts the log file at path in the quota. rotateMu is held while the
+// files of the log are rotated, or nil if they are not written to.
+func (q *Quota) add(path string, opts QuotaOptions, rotateMu *sync.Mutex) *quotaLog {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	l, ok := q.logs[path]
+	if !ok {
+		l = &quotaLog{path: path}
+		l.rotateMu = &l.mu
+		q.logs[path] = l
+	}
+	if rotateMu != nil {
+		l.rotateMu = rotateMu
+	}
+	l.opts = opts
+	l.adds++
+	return l
+}
+
+// grow records that n bytes were written to the log files in the quota,
+// and enforces the quota in the background once they grew enough.
+func (q *Quota) grow(n int64) {
+	step := int64(quotaCheckStep)
+	if limit := q.Limit(); limit <= 0 {
+		return
+	} else if limit/100 > step {
+		step = limit / 100
+	}
+	if atomic.AddInt64(&q.growth, n) < step {
+		return
+	}
+	atomic.StoreInt64(&q.growth, 0)
+	q.notify()
+}
+
+// Usage returns the size of the files of each log in the quota, by path.
+func (q *Quota) Usage() map[string]int64 {
+	usage := make(map[string]int64)
+	for _, l := range q.list() {
+		size, _, exists := l.scan()
+		if !exists {
+			q.forget(l)
+			continue
+		}
+		usage[l.path] = size
+	}
+	return usage
+}
+
+// Enforce evicts rotated files until the log files fit within the quota.
+// The rotated files are evicted by age divided by the weight of their log,
+// skipping the logs that would be left smaller than their minimum size.
+func (q *Quota) Enforce() {
+	q.enforceMu.Lock()
+	defer q.enforceMu.Unlock()
+
+	limit := q.Limit()
+	if limit <= 0 {
+		return
+	}
+	atomic.StoreInt64(&q.growth, 0)
+
+	type logState struct {
+		log     *quotaLog
+		opts    QuotaOptions
+		size    int64
+		rotated []rotatedFile
+	}
+	var (
+		logs  []*logState
+		total int64
+	)
+	for _, l := range q.list() {
+		size, rotated, exists := l.scan()
+		if !exists {
+			q.forget(l)
+			continue
+		}
+		q.mu.Lock()
+		opts := l.opts
+		q.mu.Unlock()
+		logs = append(logs, &logState{log: l, opts: opts, size: size, rotated: rotated})
+		total += size
+	}
+
+	now := time.Now()
+	for total > limit {
+		var (
+			victim *logState
+			score  float64
+		)
+		for _, s := range logs {
+			if len(s.rotated) == 0 || s.size-s.rotated[0].size < s.opts.Min {
+				continue
+			}
+			weight := s.opts.Weight
+			if weight <= 0 {
+				weight = 1
+			}
+			if sc := float64(now.Sub(s.rotated[0].modTime)) / weight; victim == nil || sc > score {
+				victim, score = s, sc
+			}
+		}
+		if victim == nil {
+			q.mu.Lock()
+			if !q.warned {
+				logrus.WithField("limit", units.BytesSize(float64(limit))).Warn("log quota exceeded, but no rotated log file can be evicted")
+				q.warned = true
+			}
+			q.mu.Unlock()
+			return
+		}
+
+		q.mu.Lock()
+		rotateMu := victim.log.rotateMu
+		q.mu.Unlock()
+		if err := victim.log.evictOldest(rotateMu); err != nil {
+			logrus.WithError(err).WithField("log", victim.log.path).Warn("failed to evict rotated log file")
+			victim.rotated = nil
+			continue
+		}
+		size, rotated, _ := victim.log.scan()
+		total += size - victim.size
+		victim.size, victim.rotated = size, rotated
+	}
+
+	q.mu.Lock()
+	q.warned = false
+	q.mu.Unlock()
+}
+
+// notify enforces the quota in the background.
+func (q *Quota) notify() {
+	q.start.Do(func() {
+		go func() {
+			for range q.trigger {
+				q.Enforce()
+			}
+		}()
+	})
+	select {
+	case q.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Quota) list() []*quotaLog {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	logs := make([]*quotaLog, 0, len(q.logs))
+	for _, l := range q.logs {
+		logs = append(logs, l)
+	}
+	return logs
+}
+
+// forget removes l from the quota, unless its files were created again
+// since they were scanned, or it was added again meanwhile.
+func (q *Quota) forget(l *quotaLog) {
+	q.mu.Lock()
+	adds := l.adds
+	q.mu.Unlock()
+
+	if _, _, exists := l.scan(); exists {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.logs[l.path] == l && l.adds == adds {
+		delete(q.logs, l.path)
+	}
+}
+
+// scan returns the size of the files of the log, its rotated files, oldest
+// first, and whether any of its files exist.
+func (l *quotaLog) scan() (int64, []rotatedFile, bool) {
+	var (
+		size   int64
+		exists bool
+	)
+	if fi, err := os.Stat(l.path); err == nil {
+		size, exists = fi.Size(), true
+	}
+	matches, _ := filepath.Glob(l.path + ".*")
+	var rotated []rotatedFile
+	for _, m := range matches {
+		if !rotatedSuffix.MatchString(m[len(l.path):]) {
+			continue
+		}
+		fi, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		size += fi.Size()
+		rotated = append(rotated, rotatedFile{name: m, size: fi.Size(), modTime: fi.ModTime()})
+	}
+	sort.Slice(rotated, func(i, j int) bool {
+		return rotated[i].modTime.Before(rotated[j].modTime)
+	})
+	return size, rotated, exists || len(rotated) > 0
+}
+
+// evictOldest removes the oldest rotated file of the log, holding rotateMu
+// so that the files are not rotated or compressed meanwhile.
+func (l *quotaLog) evictOldest(rotateMu *sync.Mutex) error {
+	rotateMu.Lock()
+	defer rotateMu.Unlock()
+	_, rotated, _ := l.scan()
+	if len(rotated) == 0 {
+		return nil
+	}
+	logrus.WithField("file", rotated[0].name).Debug("evicting rotated log file to stay within the log quota")
+	if err := os.Remove(rotated[0].name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
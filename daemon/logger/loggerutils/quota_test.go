@@ -0,0 +1,197 @@
+package loggerutils // import "github.com/docker/docker/daemon/logger/loggerutils"
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+)
+
+// writeLogFiles creates the files of a log of 10 bytes each, the first one
+// being the current file, and the following ones rotated files, one more
+// hour older each.
+func writeLogFiles(t *testing.T, path string, names ...string) {
+	t.Helper()
+	now := time.Now()
+	for i, name := range names {
+		p := path + name
+		assert.NilError(t, ioutil.WriteFile(p, []byte("0123456789"), 0600))
+		mtime := now.Add(-time.Duration(i) * time.Hour)
+		assert.NilError(t, os.Chtimes(p, mtime, mtime))
+	}
+}
+
+func listLogFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := ioutil.ReadDir(dir)
+	assert.NilError(t, err)
+	var names []string
+	for _, fi := range files {
+		names = append(names, fi.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestParseQuotaOptions(t *testing.T) {
+	opts, err := ParseQuotaOptions(nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(opts, QuotaOptions{Weight: 1}))
+
+	opts, err = ParseQuotaOptions(map[string]string{QuotaWeightOpt: "2.5", QuotaMinOpt: "1kb"})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(opts, QuotaOptions{Weight: 2.5, Min: 1000}))
+
+	_, err = ParseQuotaOptions(map[string]string{QuotaWeightOpt: "0"})
+	assert.Check(t, is.ErrorContains(err, "invalid value for quota-weight"))
+	_, err = ParseQuotaOptions(map[string]string{QuotaMinOpt: "lots"})
+	assert.Check(t, is.ErrorContains(err, "invalid value for quota-min"))
+}
+
+func TestQuotaEvictsOldestFirst(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	a, b := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+	writeLogFiles(t, a, "", ".1", ".2.gz", ".3.gz")
+	writeLogFiles(t, b, "", ".1")
+
+	q := NewQuota(40)
+	q.Add(a, QuotaOptions{Weight: 1})
+	q.Add(b, QuotaOptions{Weight: 1})
+	q.Enforce()
+
+	assert.Check(t, is.DeepEqual(listLogFiles(t, dir), []string{"a.log", "a.log.1", "b.log", "b.log.1"}))
+	assert.Check(t, is.DeepEqual(q.Usage(), map[string]int64{a: 20, b: 20}))
+
+	// The current files are never evicted.
+	q.SetLimit(5)
+	q.Enforce()
+	assert.Check(t, is.DeepEqual(listLogFiles(t, dir), []string{"a.log", "b.log"}))
+}
+
+func TestQuotaWeightAndMin(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	a, b, c := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log"), filepath.Join(dir, "c.log")
+	writeLogFiles(t, a, "", ".1", ".2")
+	writeLogFiles(t, b, "", ".1", ".2")
+	writeLogFiles(t, c, "", ".1", ".2")
+
+	q := NewQuota(60)
+	// The files of a are evicted as if they were 4 times more recent, and
+	// c is not evicted below 30 bytes.
+	q.Add(a, QuotaOptions{Weight: 4})
+	q.Add(b, QuotaOptions{Weight: 1})
+	q.Add(c, QuotaOptions{Weight: 1, Min: 30})
+	q.Enforce()
+
+	assert.Check(t, is.DeepEqual(listLogFiles(t, dir), []string{
+		"a.log", "a.log.1",
+		"b.log",
+		"c.log", "c.log.1", "c.log.2",
+	}))
+}
+
+func TestQuotaForgetsRemovedLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	a := filepath.Join(dir, "a.log")
+	writeLogFiles(t, a, "", ".1")
+
+	q := NewQuota(0)
+	q.Add(a, QuotaOptions{Weight: 1})
+	assert.Check(t, is.DeepEqual(q.Usage(), map[string]int64{a: 20}))
+
+	assert.NilError(t, os.Remove(a))
+	assert.NilError(t, os.Remove(a+".1"))
+	assert.Check(t, is.Len(q.Usage(), 0))
+	assert.Check(t, is.Len(q.list(), 0))
+}
+
+func TestLogFileQuota(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	marshal := func(msg *logger.Message) ([]byte, error) {
+		return msg.Line, nil
+	}
+	decode := func(io.Reader) Decoder { return dummyDecoder{} }
+
+	q := NewQuota(25)
+	var files []*LogFile
+	for _, name := range []string{"a.log", "b.log"} {
+		l, err := NewLogFile(filepath.Join(dir, name), 10, 5, false, marshal, decode, 0600, nil)
+		assert.NilError(t, err)
+		defer l.Close()
+		l.SetQuota(q, QuotaOptions{Weight: 1})
+		files = append(files, l)
+	}
+
+	for i := 0; i < 4; i++ {
+		for _, l := range files {
+			assert.NilError(t, l.WriteLogEntry(&logger.Message{Line: []byte(strings.Repeat("x", 10))}))
+		}
+	}
+
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		var total int64
+		for _, size := range q.Usage() {
+			total += size
+		}
+		if total > 25 {
+			return poll.Continue("log files use %d bytes: %v", total, listLogFiles(t, dir))
+		}
+		return poll.Success()
+	}, poll.WithDelay(10*time.Millisecond))
+}
+
+func TestLogFileQuotaWithoutRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	marshal := func(msg *logger.Message) ([]byte, error) {
+		return msg.Line, nil
+	}
+	decode := func(io.Reader) Decoder { return dummyDecoder{} }
+
+	// The files of a stopped container, and a log that is never rotated.
+	a := filepath.Join(dir, "a.log")
+	writeLogFiles(t, a, "", ".1", ".2")
+	q := NewQuota(quotaCheckStep)
+	q.Add(a, QuotaOptions{Weight: 1})
+
+	l, err := NewLogFile(filepath.Join(dir, "b.log"), -1, 1, false, marshal, decode, 0600, nil)
+	assert.NilError(t, err)
+	defer l.Close()
+	l.SetQuota(q, QuotaOptions{Weight: 1})
+
+	line := []byte(strings.Repeat("x", quotaCheckStep/16))
+	for i := 0; i < 16; i++ {
+		assert.NilError(t, l.WriteLogEntry(&logger.Message{Line: line}))
+	}
+
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if files := listLogFiles(t, dir); len(files) > 2 {
+			return poll.Continue("rotated files not evicted: %v", files)
+		}
+		return poll.Success()
+	}, poll.WithDelay(10*time.Millisecond))
+	assert.Check(t, is.DeepEqual(listLogFiles(t, dir), []string{"a.log", "b.log"}))
+}
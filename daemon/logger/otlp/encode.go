@@ -0,0 +1,129 @@
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/docker/docker/daemon/logger"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// The Go types of the OTLP log messages are not vendored, so they are
+// encoded by hand with the field numbers of
+// opentelemetry/proto/logs/v1/logs.proto and
+// opentelemetry/proto/collector/logs/v1/logs_service.proto. The resource
+// and attributes use the vendored common and resource types.
+const (
+	// ExportLogsServiceRequest
+	requestResourceLogsField protowire.Number = 1
+
+	// ResourceLogs
+	resourceLogsResourceField  protowire.Number = 1
+	resourceLogsScopeLogsField protowire.Number = 2
+
+	// ScopeLogs
+	scopeLogsLogRecordsField protowire.Number = 2
+
+	// LogRecord
+	logRecordTimeUnixNanoField         protowire.Number = 1
+	logRecordBodyField                 protowire.Number = 5
+	logRecordAttributesField           protowire.Number = 6
+	logRecordObservedTimeUnixNanoField protowire.Number = 11
+)
+
+// Attribute keys, from the OpenTelemetry semantic conventions.
+const (
+	attrServiceName        = "service.name"
+	attrHostName           = "host.name"
+	attrContainerID        = "container.id"
+	attrContainerName      = "container.name"
+	attrContainerImageName = "container.image.name"
+	attrContainerImageID   = "container.image.id"
+	attrLogIOStream        = "log.iostream"
+)
+
+// encodeResource encodes a Resource with attrs, sorted by key.
+func encodeResource(attrs map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	resource := &resourcepb.Resource{}
+	for _, k := range keys {
+		resource.Attributes = append(resource.Attributes, stringKeyValue(k, attrs[k]))
+	}
+	return proto.Marshal(resource)
+}
+
+func stringKeyValue(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// encodeLogRecord encodes msg as a LogRecord. The line is a string body,
+// unless it is not valid UTF-8, which strings must be in protobuf, in which
+// case it is a bytes body.
+func encodeLogRecord(msg *logger.Message, observed int64) ([]byte, error) {
+	body := &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: string(msg.Line)}}
+	if !utf8.Valid(msg.Line) {
+		body = &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: append([]byte(nil), msg.Line...)}}
+	}
+	b, err := proto.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var record []byte
+	record = protowire.AppendTag(record, logRecordTimeUnixNanoField, protowire.Fixed64Type)
+	record = protowire.AppendFixed64(record, uint64(msg.Timestamp.UnixNano()))
+	record = protowire.AppendTag(record, logRecordBodyField, protowire.BytesType)
+	record = protowire.AppendBytes(record, b)
+
+	attrs := make([]*commonpb.KeyValue, 0, len(msg.Attrs)+1)
+	if msg.Source != "" {
+		attrs = append(attrs, stringKeyValue(attrLogIOStream, msg.Source))
+	}
+	for _, a := range msg.Attrs {
+		attrs = append(attrs, stringKeyValue(a.Key, a.Value))
+	}
+	for _, kv := range attrs {
+		b, err := proto.Marshal(kv)
+		if err != nil {
+			return nil, err
+		}
+		record = protowire.AppendTag(record, logRecordAttributesField, protowire.BytesType)
+		record = protowire.AppendBytes(record, b)
+	}
+
+	record = protowire.AppendTag(record, logRecordObservedTimeUnixNanoField, protowire.Fixed64Type)
+	record = protowire.AppendFixed64(record, uint64(observed))
+	return record, nil
+}
+
+// encodeRequest encodes an ExportLogsServiceRequest of the log records of
+// a resource.
+func encodeRequest(resource []byte, records [][]byte) []byte {
+	var scopeLogs []byte
+	for _, r := range records {
+		scopeLogs = protowire.AppendTag(scopeLogs, scopeLogsLogRecordsField, protowire.BytesType)
+		scopeLogs = protowire.AppendBytes(scopeLogs, r)
+	}
+
+	var resourceLogs []byte
+	resourceLogs = protowire.AppendTag(resourceLogs, resourceLogsResourceField, protowire.BytesType)
+	resourceLogs = protowire.AppendBytes(resourceLogs, resource)
+	resourceLogs = protowire.AppendTag(resourceLogs, resourceLogsScopeLogsField, protowire.BytesType)
+	resourceLogs = protowire.AppendBytes(resourceLogs, scopeLogs)
+
+	var req []byte
+	req = protowire.AppendTag(req, requestResourceLogsField, protowire.BytesType)
+	req = protowire.AppendBytes(req, resourceLogs)
+	return req
+}
@@ -0,0 +1,161 @@
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// exportMethod is the gRPC method exporting logs.
+const exportMethod = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+
+// exporter sends encoded ExportLogsServiceRequests to an OTLP receiver.
+type exporter interface {
+	export(ctx context.Context, req []byte) error
+	close() error
+}
+
+// permanentError is an error exporting logs that retrying does not fix,
+// such as the receiver rejecting them.
+type permanentError struct {
+	error
+}
+
+func (e permanentError) Unwrap() error {
+	return e.error
+}
+
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// rawCodec passes the requests, encoded by hand, through to gRPC.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, errors.Errorf("otlp: cannot marshal %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return errors.Errorf("otlp: cannot unmarshal into %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	// The requests are protobuf, only not marshaled by gRPC.
+	return "proto"
+}
+
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	headers metadata.MD
+}
+
+func newGRPCExporter(endpoint string, insecure bool, headers map[string]string) (*grpcExporter, error) {
+	opts := []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{}))}
+	if insecure {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	}
+	// The connection is made lazily, so that the containers start while
+	// the receiver is unavailable.
+	conn, err := grpc.Dial(endpoint, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "otlp: cannot connect to %s", endpoint)
+	}
+	return &grpcExporter{conn: conn, headers: metadata.New(headers)}, nil
+}
+
+func (e *grpcExporter) export(ctx context.Context, req []byte) error {
+	if len(e.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	}
+	var resp []byte
+	err := e.conn.Invoke(ctx, exportMethod, &req, &resp)
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return err
+	default:
+		return permanentError{err}
+	}
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
+
+type httpExporter struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+func newHTTPExporter(endpoint string, headers map[string]string) *httpExporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/logs") {
+		url += "/v1/logs"
+	}
+	return &httpExporter{
+		client:  &http.Client{},
+		url:     url,
+		headers: headers,
+	}
+}
+
+func (e *httpExporter) export(ctx context.Context, req []byte) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(req))
+	if err != nil {
+		return permanentError{err}
+	}
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.headers {
+		r.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Read the response so that the connection is reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = errors.Errorf("otlp: receiver returned %s", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return err
+	default:
+		return permanentError{err}
+	}
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,466 @@
+// Package otlp provides the log driver for forwarding container logs to
+// OpenTelemetry receivers, as OTLP log records over gRPC or HTTP/protobuf.
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Name is the name of the driver.
+	Name = "otlp"
+
+	endpointKey      = "otlp-endpoint"
+	protocolKey      = "otlp-protocol"
+	insecureKey      = "otlp-insecure"
+	headersKey       = "otlp-headers"
+	timeoutKey       = "otlp-timeout"
+	batchSizeKey     = "otlp-batch-size"
+	batchTimeoutKey  = "otlp-batch-timeout"
+	maxRetriesKey    = "otlp-max-retries"
+	bufferMaxSizeKey = "otlp-buffer-max-size"
+
+	protocolGRPC = "grpc"
+	protocolHTTP = "http/protobuf"
+
+	defaultGRPCEndpoint = "localhost:4317"
+	defaultHTTPEndpoint = "http://localhost:4318"
+	defaultTimeout      = 10 * time.Second
+	defaultBatchSize    = 512
+	defaultBatchTimeout = time.Second
+	defaultMaxRetries   = 5
+
+	// maxQueuedBatches is the number of batches waiting in memory while
+	// another one is sent, above which they are buffered to disk.
+	maxQueuedBatches = 4
+
+	initialRetryWait = 500 * time.Millisecond
+	maxRetryWait     = 30 * time.Second
+
+	// closeTimeout bounds the time Close waits for the logs being sent and
+	// sends the pending ones, so that stopping containers and shutting the
+	// daemon down are not delayed by an unavailable receiver. The logs not
+	// sent by then are buffered on disk, if enabled.
+	closeTimeout = 2 * time.Second
+)
+
+type options struct {
+	protocol      string
+	endpoint      string
+	insecure      bool
+	headers       map[string]string
+	timeout       time.Duration
+	batchSize     int
+	batchTimeout  time.Duration
+	maxRetries    int
+	bufferMaxSize int64
+}
+
+type otlpLogger struct {
+	opts     options
+	exporter exporter
+	resource []byte
+	// spool buffers on disk the logs that could not be sent. It is nil if
+	// they are dropped.
+	spool *spool
+
+	mu      sync.Mutex
+	pending [][]byte // log records of the next batch
+	closed  bool
+
+	batches chan [][]byte
+	closing chan struct{}
+	done    chan struct{}
+	// ctx is cancelled to interrupt the logs being sent when closing takes
+	// too long.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+// New creates an otlp logger using the configuration passed in on the
+// context. The logs are sent in batches, retried while the receiver is
+// unavailable, and buffered to info.LogPath if otlp-buffer-max-size is set.
+func New(info logger.Info) (logger.Logger, error) {
+	opts, err := parseOptions(info.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := resourceAttributes(info)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := encodeResource(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &otlpLogger{
+		opts:     opts,
+		resource: resource,
+		batches:  make(chan [][]byte, maxQueuedBatches),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	if opts.bufferMaxSize > 0 {
+		if info.LogPath == "" {
+			return nil, errors.Errorf("otlp: %s is not supported for this container", bufferMaxSizeKey)
+		}
+		if l.spool, err = newSpool(info.LogPath, opts.bufferMaxSize); err != nil {
+			return nil, err
+		}
+	}
+
+	switch opts.protocol {
+	case protocolHTTP:
+		l.exporter = newHTTPExporter(opts.endpoint, opts.headers)
+	default:
+		if l.exporter, err = newGRPCExporter(opts.endpoint, opts.insecure, opts.headers); err != nil {
+			return nil, err
+		}
+	}
+
+	go l.run()
+	return l, nil
+}
+
+// resourceAttributes returns the attributes of the resource the logs of the
+// container are from. The service name is set by the tag log option, and
+// the labels and environment variables selected by the labels and env log
+// options are added.
+func resourceAttributes(info logger.Info) (map[string]string, error) {
+	tag, err := loggerutils.ParseLogTag(info, "{{.Name}}")
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := info.ExtraAttributes(nil)
+	if err != nil {
+		return nil, err
+	}
+	attrs[attrServiceName] = tag
+	attrs[attrContainerID] = info.ContainerID
+	attrs[attrContainerName] = info.Name()
+	attrs[attrContainerImageName] = info.ContainerImageName
+	attrs[attrContainerImageID] = info.ContainerImageID
+	if hostname, err := info.Hostname(); err == nil {
+		attrs[attrHostName] = hostname
+	}
+	return attrs, nil
+}
+
+func (l *otlpLogger) Log(msg *logger.Message) error {
+	record, err := encodeLogRecord(msg, time.Now().UnixNano())
+	logger.PutMessage(msg)
+	if err != nil {
+		return errors.Wrap(err, "otlp: cannot encode log record")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("otlp: logger is closed")
+	}
+	l.pending = append(l.pending, record)
+	if len(l.pending) >= l.opts.batchSize {
+		batch := l.pending
+		l.pending = nil
+		select {
+		case l.batches <- batch:
+		default:
+			// The receiver is behind.
+			l.store(encodeRequest(l.resource, batch), len(batch))
+		}
+	}
+	return nil
+}
+
+// run sends the batches until the logger is closed.
+func (l *otlpLogger) run() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.opts.batchTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case batch := <-l.batches:
+			l.send(batch)
+		case <-ticker.C:
+			l.mu.Lock()
+			batch := l.pending
+			l.pending = nil
+			l.mu.Unlock()
+			// The buffered logs are retried even if there is no new one.
+			l.send(batch)
+		case <-l.closing:
+			return
+		}
+	}
+}
+
+// send exports batch, after the logs buffered on disk, so that the logs are
+// received in order. The logs that cannot be sent are buffered.
+func (l *otlpLogger) send(batch [][]byte) {
+	var req []byte
+	if len(batch) > 0 {
+		req = encodeRequest(l.resource, batch)
+	}
+	if l.spool != nil {
+		for {
+			buffered, seq, ok := l.spool.oldest()
+			if !ok {
+				break
+			}
+			if err := l.export(l.ctx, buffered, true); err != nil && !isPermanent(err) {
+				if req != nil {
+					l.store(req, len(batch))
+				}
+				return
+			}
+			l.spool.remove(seq)
+		}
+	}
+	if req == nil {
+		return
+	}
+	if err := l.export(l.ctx, req, true); err != nil && !isPermanent(err) {
+		l.store(req, len(batch))
+	}
+}
+
+// export sends req, retrying with an exponential backoff if retry is set
+// and the error is not permanent, until ctx is done. The requests rejected
+// by the receiver are dropped.
+func (l *otlpLogger) export(ctx context.Context, req []byte, retry bool) error {
+	wait := initialRetryWait
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, l.opts.timeout)
+		err := l.exporter.export(attemptCtx, req)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if isPermanent(err) {
+			logrus.WithError(err).WithField("endpoint", l.opts.endpoint).Error("otlp: logs rejected by the receiver, dropping them")
+			return err
+		}
+		if !retry || attempt >= l.opts.maxRetries {
+			logrus.WithError(err).WithField("endpoint", l.opts.endpoint).Warn("otlp: cannot send logs")
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-l.closing:
+			return err
+		case <-ctx.Done():
+			return err
+		}
+		if wait *= 2; wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+	}
+}
+
+// store buffers req, of n log records, on disk, or drops it if the logger
+// has no buffer.
+func (l *otlpLogger) store(req []byte, n int) {
+	if l.spool == nil {
+		logrus.WithField("endpoint", l.opts.endpoint).Warnf("otlp: dropping %d log records", n)
+		return
+	}
+	if err := l.spool.write(req); err != nil {
+		logrus.WithError(err).Warnf("otlp: dropping %d log records", n)
+	}
+}
+
+// Close sends the pending logs, once, buffering them on disk if that fails
+// or takes longer than closeTimeout.
+func (l *otlpLogger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	last := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	defer l.cancel()
+
+	close(l.closing)
+	select {
+	case <-l.done:
+	case <-ctx.Done():
+		l.cancel()
+		<-l.done
+	}
+
+	var batches [][][]byte
+	for len(l.batches) > 0 {
+		batches = append(batches, <-l.batches)
+	}
+	if len(last) > 0 {
+		batches = append(batches, last)
+	}
+	failed := false
+	for _, batch := range batches {
+		req := encodeRequest(l.resource, batch)
+		if !failed {
+			err := l.export(ctx, req, false)
+			if err == nil || isPermanent(err) {
+				continue
+			}
+			failed = true
+		}
+		l.store(req, len(batch))
+	}
+	return l.exporter.close()
+}
+
+func (l *otlpLogger) Name() string {
+	return Name
+}
+
+func parseOptions(cfg map[string]string) (options, error) {
+	opts := options{
+		protocol:     protocolGRPC,
+		timeout:      defaultTimeout,
+		batchSize:    defaultBatchSize,
+		batchTimeout: defaultBatchTimeout,
+		maxRetries:   defaultMaxRetries,
+	}
+	if v, ok := cfg[protocolKey]; ok {
+		switch v {
+		case protocolGRPC, protocolHTTP:
+			opts.protocol = v
+		default:
+			return opts, errors.Errorf("invalid value for %s: %q: must be %q or %q", protocolKey, v, protocolGRPC, protocolHTTP)
+		}
+	}
+
+	opts.endpoint = cfg[endpointKey]
+	if opts.protocol == protocolHTTP {
+		if opts.endpoint == "" {
+			opts.endpoint = defaultHTTPEndpoint
+		}
+		u, err := url.Parse(opts.endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return opts, errors.Errorf("invalid value for %s: %q: must be a http or https URL", endpointKey, opts.endpoint)
+		}
+		if _, ok := cfg[insecureKey]; ok {
+			return opts, errors.Errorf("%s is only valid with the %s protocol, use a http URL instead", insecureKey, protocolGRPC)
+		}
+	} else {
+		if opts.endpoint == "" {
+			opts.endpoint = defaultGRPCEndpoint
+		}
+		// A URL scheme sets whether TLS is used, as otlp-insecure does.
+		if u, err := url.Parse(opts.endpoint); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			opts.endpoint = u.Host
+			opts.insecure = u.Scheme == "http"
+		}
+		if v, ok := cfg[insecureKey]; ok {
+			insecure, err := strconv.ParseBool(v)
+			if err != nil {
+				return opts, errors.Errorf("invalid value for %s: %q", insecureKey, v)
+			}
+			opts.insecure = insecure
+		}
+	}
+
+	if v, ok := cfg[headersKey]; ok && v != "" {
+		opts.headers = make(map[string]string)
+		for _, h := range strings.Split(v, ",") {
+			kv := strings.SplitN(h, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+				return opts, errors.Errorf("invalid value for %s: %q: must be a list of key=value", headersKey, v)
+			}
+			opts.headers[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	var err error
+	if opts.timeout, err = parseDuration(cfg, timeoutKey, opts.timeout); err != nil {
+		return opts, err
+	}
+	if opts.batchTimeout, err = parseDuration(cfg, batchTimeoutKey, opts.batchTimeout); err != nil {
+		return opts, err
+	}
+	if v, ok := cfg[batchSizeKey]; ok {
+		if opts.batchSize, err = strconv.Atoi(v); err != nil || opts.batchSize <= 0 {
+			return opts, errors.Errorf("invalid value for %s: %q: must be a positive integer", batchSizeKey, v)
+		}
+	}
+	if v, ok := cfg[maxRetriesKey]; ok {
+		if opts.maxRetries, err = strconv.Atoi(v); err != nil || opts.maxRetries < 0 {
+			return opts, errors.Errorf("invalid value for %s: %q: must be a non-negative integer", maxRetriesKey, v)
+		}
+	}
+	if v, ok := cfg[bufferMaxSizeKey]; ok {
+		if opts.bufferMaxSize, err = units.RAMInBytes(v); err != nil || opts.bufferMaxSize < 0 {
+			return opts, errors.Errorf("invalid value for %s: %q: must be a size", bufferMaxSizeKey, v)
+		}
+	}
+	return opts, nil
+}
+
+func parseDuration(cfg map[string]string, key string, def time.Duration) (time.Duration, error) {
+	v, ok := cfg[key]
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, errors.Errorf("invalid value for %s: %q: must be a positive duration", key, v)
+	}
+	return d, nil
+}
+
+// ValidateLogOpt looks for otlp specific log options.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case endpointKey:
+		case protocolKey:
+		case insecureKey:
+		case headersKey:
+		case timeoutKey:
+		case batchSizeKey:
+		case batchTimeoutKey:
+		case maxRetriesKey:
+		case bufferMaxSizeKey:
+		case "tag":
+		case "labels":
+		case "labels-regex":
+		case "env":
+		case "env-regex":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	_, err := parseOptions(cfg)
+	return err
+}
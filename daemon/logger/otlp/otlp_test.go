@@ -0,0 +1,398 @@
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+)
+
+// receivedLogs are the logs decoded from the requests of a receiver stub.
+type receivedLogs struct {
+	mu       sync.Mutex
+	resource map[string]string
+	lines    []string
+	attrs    []map[string]string
+	headers  []string
+}
+
+func (r *receivedLogs) add(t *testing.T, req []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	forEachField(t, req, func(num protowire.Number, resourceLogs []byte) {
+		assert.Check(t, is.Equal(num, requestResourceLogsField))
+		forEachField(t, resourceLogs, func(num protowire.Number, b []byte) {
+			switch num {
+			case resourceLogsResourceField:
+				var resource resourcepb.Resource
+				assert.NilError(t, proto.Unmarshal(b, &resource))
+				r.resource = keyValues(resource.Attributes)
+			case resourceLogsScopeLogsField:
+				forEachField(t, b, func(_ protowire.Number, record []byte) {
+					r.addRecord(t, record)
+				})
+			}
+		})
+	})
+}
+
+func (r *receivedLogs) addRecord(t *testing.T, record []byte) {
+	var attrs []*commonpb.KeyValue
+	for len(record) > 0 {
+		num, typ, n := protowire.ConsumeTag(record)
+		assert.Assert(t, n > 0)
+		record = record[n:]
+		switch typ {
+		case protowire.Fixed64Type:
+			_, n = protowire.ConsumeFixed64(record)
+		case protowire.BytesType:
+			var b []byte
+			b, n = protowire.ConsumeBytes(record)
+			switch num {
+			case logRecordBodyField:
+				var body commonpb.AnyValue
+				assert.NilError(t, proto.Unmarshal(b, &body))
+				if v, ok := body.Value.(*commonpb.AnyValue_BytesValue); ok {
+					r.lines = append(r.lines, string(v.BytesValue))
+				} else {
+					r.lines = append(r.lines, body.GetStringValue())
+				}
+			case logRecordAttributesField:
+				var kv commonpb.KeyValue
+				assert.NilError(t, proto.Unmarshal(b, &kv))
+				attrs = append(attrs, &kv)
+			}
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		assert.Assert(t, n > 0)
+		record = record[n:]
+	}
+	r.attrs = append(r.attrs, keyValues(attrs))
+}
+
+func (r *receivedLogs) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lines...)
+}
+
+func forEachField(t *testing.T, b []byte, fn func(protowire.Number, []byte)) {
+	t.Helper()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		assert.Assert(t, n > 0)
+		assert.Assert(t, is.Equal(typ, protowire.BytesType))
+		b = b[n:]
+		v, n := protowire.ConsumeBytes(b)
+		assert.Assert(t, n > 0)
+		b = b[n:]
+		fn(num, v)
+	}
+}
+
+func keyValues(kvs []*commonpb.KeyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.GetStringValue()
+	}
+	return m
+}
+
+// httpReceiver starts a receiver stub answering the requests with the
+// status codes returned by respond.
+func httpReceiver(t *testing.T, respond func() int) (*receivedLogs, string) {
+	logs := &receivedLogs{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Check(t, is.Equal(r.URL.Path, "/v1/logs"))
+		assert.Check(t, is.Equal(r.Header.Get("Content-Type"), "application/x-protobuf"))
+		code := respond()
+		if code == http.StatusOK {
+			req, err := ioutil.ReadAll(r.Body)
+			assert.Check(t, err)
+			logs.add(t, req)
+			logs.mu.Lock()
+			logs.headers = append(logs.headers, r.Header.Get("X-Tenant"))
+			logs.mu.Unlock()
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	return logs, srv.URL
+}
+
+func newTestLogger(t *testing.T, cfg map[string]string, logPath string) logger.Logger {
+	t.Helper()
+	assert.NilError(t, ValidateLogOpt(cfg))
+	l, err := New(logger.Info{
+		Config:             cfg,
+		ContainerID:        "0123456789ab",
+		ContainerName:      "/web",
+		ContainerImageName: "nginx:latest",
+		ContainerImageID:   "sha256:abcdef",
+		ContainerLabels:    map[string]string{"app": "shop"},
+		LogPath:            logPath,
+	})
+	assert.NilError(t, err)
+	return l
+}
+
+func logLine(t *testing.T, l logger.Logger, line, source string) {
+	t.Helper()
+	msg := logger.NewMessage()
+	msg.Line = []byte(line)
+	msg.Source = source
+	msg.Timestamp = time.Now()
+	assert.NilError(t, l.Log(msg))
+}
+
+func TestHTTP(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	logs, endpoint := httpReceiver(t, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		// The first request is retried.
+		if calls == 1 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+
+	l := newTestLogger(t, map[string]string{
+		protocolKey:  protocolHTTP,
+		endpointKey:  endpoint,
+		headersKey:   "X-Tenant=acme",
+		batchSizeKey: "2",
+		"labels":     "app",
+	}, "")
+	logLine(t, l, "one", "stdout")
+	logLine(t, l, "two\xff", "stderr")
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if len(logs.received()) < 2 {
+			return poll.Continue("waiting for the logs")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(10*time.Second))
+	logLine(t, l, "three", "stdout")
+	assert.NilError(t, l.Close())
+
+	assert.Check(t, is.DeepEqual(logs.received(), []string{"one", "two\xff", "three"}))
+	assert.Check(t, is.DeepEqual(logs.attrs[0], map[string]string{attrLogIOStream: "stdout"}))
+	assert.Check(t, is.DeepEqual(logs.resource, map[string]string{
+		attrServiceName:        "web",
+		attrContainerID:        "0123456789ab",
+		attrContainerName:      "web",
+		attrContainerImageName: "nginx:latest",
+		attrContainerImageID:   "sha256:abcdef",
+		attrHostName:           logs.resource[attrHostName],
+		"app":                  "shop",
+	}))
+	assert.Check(t, is.DeepEqual(logs.headers, []string{"acme", "acme"}))
+}
+
+func TestHTTPRejected(t *testing.T) {
+	logs, endpoint := httpReceiver(t, func() int { return http.StatusBadRequest })
+
+	dir := t.TempDir()
+	l := newTestLogger(t, map[string]string{
+		protocolKey:      protocolHTTP,
+		endpointKey:      endpoint,
+		bufferMaxSizeKey: "1m",
+	}, dir)
+	logLine(t, l, "one", "stdout")
+	assert.NilError(t, l.Close())
+
+	// The logs rejected by the receiver are not buffered.
+	assert.Check(t, is.Len(logs.received(), 0))
+	files, err := ioutil.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(files, 0))
+}
+
+func TestBuffer(t *testing.T) {
+	var mu sync.Mutex
+	up := false
+	logs, endpoint := httpReceiver(t, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		if !up {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	cfg := map[string]string{
+		protocolKey:      protocolHTTP,
+		endpointKey:      endpoint,
+		batchSizeKey:     "1",
+		maxRetriesKey:    "0",
+		bufferMaxSizeKey: "1m",
+	}
+
+	dir := t.TempDir()
+	l := newTestLogger(t, cfg, dir)
+	logLine(t, l, "one", "stdout")
+	logLine(t, l, "two", "stdout")
+	assert.NilError(t, l.Close())
+	assert.Check(t, is.Len(logs.received(), 0))
+
+	// The buffered logs are sent, before the new ones, once the receiver is
+	// up, and by the next logger of the container.
+	mu.Lock()
+	up = true
+	mu.Unlock()
+	l = newTestLogger(t, cfg, dir)
+	logLine(t, l, "three", "stdout")
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if len(logs.received()) < 3 {
+			return poll.Continue("waiting for the logs")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(10*time.Second))
+	assert.NilError(t, l.Close())
+	assert.Check(t, is.DeepEqual(logs.received(), []string{"one", "two", "three"}))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(files, 0))
+}
+
+func TestCloseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	_, endpoint := httpReceiver(t, func() int {
+		<-release
+		return http.StatusOK
+	})
+	t.Cleanup(func() { close(release) })
+
+	dir := t.TempDir()
+	l := newTestLogger(t, map[string]string{
+		protocolKey:      protocolHTTP,
+		endpointKey:      endpoint,
+		batchSizeKey:     "1",
+		bufferMaxSizeKey: "1m",
+	}, dir)
+	logLine(t, l, "one", "stdout")
+	logLine(t, l, "two", "stdout")
+
+	// Closing does not wait for the unresponsive receiver, and buffers the
+	// logs that could not be sent.
+	start := time.Now()
+	assert.NilError(t, l.Close())
+	assert.Check(t, time.Since(start) < closeTimeout+time.Second, "closing took %s", time.Since(start))
+	files, err := ioutil.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Check(t, len(files) > 0)
+}
+
+func TestGRPC(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+
+	logs := &receivedLogs{}
+	var mu sync.Mutex
+	calls := 0
+	srv := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			if method != exportMethod {
+				return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+			}
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			mu.Lock()
+			calls++
+			first := calls == 1
+			mu.Unlock()
+			if first {
+				return status.Error(codes.Unavailable, "try again")
+			}
+			md, _ := metadata.FromIncomingContext(stream.Context())
+			logs.add(t, req)
+			logs.mu.Lock()
+			logs.headers = append(logs.headers, md.Get("x-tenant")...)
+			logs.mu.Unlock()
+			return stream.SendMsg(&[]byte{})
+		}),
+	)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	l := newTestLogger(t, map[string]string{
+		endpointKey: "http://" + ln.Addr().String(),
+		headersKey:  "X-Tenant=acme",
+		"tag":       "{{.ImageName}}",
+	}, "")
+	logLine(t, l, "one", "stdout")
+	logLine(t, l, "two", "stdout")
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if len(logs.received()) < 2 {
+			return poll.Continue("waiting for the logs")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(10*time.Second))
+	assert.NilError(t, l.Close())
+
+	assert.Check(t, is.DeepEqual(logs.received(), []string{"one", "two"}))
+	assert.Check(t, is.Equal(logs.resource[attrServiceName], "nginx:latest"))
+	assert.Check(t, is.DeepEqual(logs.headers, []string{"acme"}))
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	for _, tc := range []struct {
+		cfg map[string]string
+		err string
+	}{
+		{cfg: map[string]string{endpointKey: "collector:4317", insecureKey: "true", batchSizeKey: "100", bufferMaxSizeKey: "10m"}},
+		{cfg: map[string]string{protocolKey: protocolHTTP, endpointKey: "https://collector:4318", timeoutKey: "5s"}},
+		{cfg: map[string]string{"otlp-foo": "bar"}, err: "unknown log opt 'otlp-foo' for otlp log driver"},
+		{cfg: map[string]string{protocolKey: "thrift"}, err: `invalid value for otlp-protocol: "thrift"`},
+		{cfg: map[string]string{protocolKey: protocolHTTP, endpointKey: "collector:4318"}, err: "must be a http or https URL"},
+		{cfg: map[string]string{protocolKey: protocolHTTP, insecureKey: "true"}, err: "otlp-insecure is only valid with the grpc protocol"},
+		{cfg: map[string]string{insecureKey: "maybe"}, err: `invalid value for otlp-insecure: "maybe"`},
+		{cfg: map[string]string{headersKey: "a=b,c"}, err: "must be a list of key=value"},
+		{cfg: map[string]string{timeoutKey: "0s"}, err: "must be a positive duration"},
+		{cfg: map[string]string{batchSizeKey: "0"}, err: "must be a positive integer"},
+		{cfg: map[string]string{maxRetriesKey: "-1"}, err: "must be a non-negative integer"},
+		{cfg: map[string]string{bufferMaxSizeKey: "lots"}, err: "must be a size"},
+	} {
+		err := ValidateLogOpt(tc.cfg)
+		if tc.err == "" {
+			assert.Check(t, err, tc.cfg)
+		} else {
+			assert.Check(t, is.ErrorContains(err, tc.err), tc.cfg)
+		}
+	}
+}
+
+func TestParseOptionsGRPCScheme(t *testing.T) {
+	opts, err := parseOptions(map[string]string{endpointKey: "http://collector:4317"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(opts.endpoint, "collector:4317"))
+	assert.Check(t, opts.insecure)
+
+	opts, err = parseOptions(map[string]string{endpointKey: "https://collector:4317"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(opts.endpoint, "collector:4317"))
+	assert.Check(t, !opts.insecure)
+}
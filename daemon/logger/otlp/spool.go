@@ -0,0 +1,129 @@
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// spool buffers on disk the export requests that could not be sent, one
+// file each, up to maxSize bytes. The oldest requests are dropped to stay
+// within it. The requests are kept across restarts of the daemon.
+type spool struct {
+	mu      sync.Mutex
+	dir     string
+	maxSize int64
+	size    int64
+	files   []spoolFile // oldest first
+	seq     uint64
+}
+
+type spoolFile struct {
+	seq  uint64
+	size int64
+}
+
+func newSpool(dir string, maxSize int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "otlp: error creating buffer directory")
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "otlp: error reading buffer directory")
+	}
+	s := &spool{dir: dir, maxSize: maxSize}
+	for _, fi := range entries {
+		seq, err := strconv.ParseUint(fi.Name(), 10, 64)
+		if err != nil || !fi.Mode().IsRegular() {
+			// Leftover temporary files of interrupted writes.
+			os.Remove(filepath.Join(dir, fi.Name()))
+			continue
+		}
+		s.files = append(s.files, spoolFile{seq: seq, size: fi.Size()})
+		s.size += fi.Size()
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].seq < s.files[j].seq })
+	if len(s.files) > 0 {
+		s.seq = s.files[len(s.files)-1].seq + 1
+	}
+	s.mu.Lock()
+	s.evict()
+	s.mu.Unlock()
+	return s, nil
+}
+
+func (s *spool) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d", seq))
+}
+
+// write buffers req, dropping the oldest requests if the buffer is full.
+func (s *spool) write(req []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if int64(len(req)) > s.maxSize {
+		return errors.Errorf("otlp: request of %d bytes exceeds the buffer size", len(req))
+	}
+	if err := ioutils.AtomicWriteFile(s.path(s.seq), req, 0600); err != nil {
+		return errors.Wrap(err, "otlp: error buffering logs")
+	}
+	s.files = append(s.files, spoolFile{seq: s.seq, size: int64(len(req))})
+	s.size += int64(len(req))
+	s.seq++
+	s.evict()
+	return nil
+}
+
+// evict drops the oldest requests until the buffer is within its maximum
+// size. s.mu must be held.
+func (s *spool) evict() {
+	for s.size > s.maxSize && len(s.files) > 0 {
+		logrus.WithField("dir", s.dir).Warn("otlp: log buffer is full, dropping the oldest logs")
+		s.removeLocked(s.files[0].seq)
+	}
+}
+
+// oldest returns the oldest request buffered, and its sequence number to
+// remove it once it is sent.
+func (s *spool) oldest() (req []byte, seq uint64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.files) > 0 {
+		seq := s.files[0].seq
+		req, err := ioutil.ReadFile(s.path(seq))
+		if err == nil {
+			return req, seq, true
+		}
+		logrus.WithError(err).WithField("dir", s.dir).Warn("otlp: dropping unreadable buffered logs")
+		s.removeLocked(seq)
+	}
+	return nil, 0, false
+}
+
+// remove drops the request with sequence number seq.
+func (s *spool) remove(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(seq)
+}
+
+func (s *spool) removeLocked(seq uint64) {
+	for i, f := range s.files {
+		if f.seq != seq {
+			continue
+		}
+		if err := os.Remove(s.path(seq)); err != nil && !os.IsNotExist(err) {
+			logrus.WithError(err).WithField("dir", s.dir).Warn("otlp: error removing buffered logs")
+		}
+		s.size -= f.size
+		s.files = append(s.files[:i], s.files[i+1:]...)
+		return
+	}
+}
@@ -0,0 +1,46 @@
+package otlp // import "github.com/docker/docker/daemon/logger/otlp"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(dir, 10)
+	assert.NilError(t, err)
+
+	assert.NilError(t, s.write([]byte("aaaa")))
+	assert.NilError(t, s.write([]byte("bbbb")))
+	// The oldest request is dropped to stay within the maximum size.
+	assert.NilError(t, s.write([]byte("cccc")))
+	assert.Check(t, is.ErrorContains(s.write([]byte("dddddddddddd")), "exceeds the buffer size"))
+
+	req, seq, ok := s.oldest()
+	assert.Assert(t, ok)
+	assert.Check(t, is.Equal(string(req), "bbbb"))
+
+	// The requests are kept across restarts, and temporary files removed.
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, ".tmp-123"), []byte("x"), 0600))
+	s, err = newSpool(dir, 10)
+	assert.NilError(t, err)
+	req, seq2, ok := s.oldest()
+	assert.Assert(t, ok)
+	assert.Check(t, is.Equal(string(req), "bbbb"))
+	assert.Check(t, is.Equal(seq2, seq))
+
+	s.remove(seq)
+	req, _, ok = s.oldest()
+	assert.Assert(t, ok)
+	assert.Check(t, is.Equal(string(req), "cccc"))
+	assert.NilError(t, s.write([]byte("eeee")))
+
+	files, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(files, 2))
+}
@@ -2,6 +2,7 @@ package daemon // import "github.com/docker/docker/daemon"
 
 import (
 	"context"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -89,11 +90,33 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 		until = time.Unix(s, n)
 	}
 
+	filter := &logger.MessageFilter{
+		Contains: config.Contains,
+		Attrs:    config.Attrs,
+	}
+	if config.Grep != "" {
+		if filter.Pattern, err = regexp.Compile(config.Grep); err != nil {
+			return nil, false, errdefs.InvalidParameter(errors.Wrap(err, "invalid grep pattern"))
+		}
+	}
+	// Select the stream here too, so that tail counts only the lines of
+	// the stream shown.
+	if !config.ShowStdout {
+		filter.Source = "stderr"
+	} else if !config.ShowStderr {
+		filter.Source = "stdout"
+	}
+	if filter.IsZero() {
+		filter = nil
+	}
+
 	readConfig := logger.ReadConfig{
-		Since:  since,
-		Until:  until,
-		Tail:   tailLines,
-		Follow: follow,
+		Since:    since,
+		Until:    until,
+		Tail:     tailLines,
+		Follow:   follow,
+		Filter:   filter,
+		MaxBytes: config.MaxBytes,
 	}
 
 	logs := logReader.ReadLogs(readConfig)
@@ -103,6 +126,10 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 	// (if the caller wants to give up on logs, they have to cancel the context)
 	// this goroutine functions as a shim between the logger and the caller.
 	messageChan := make(chan *backend.LogMessage, 1)
+	// The filter and budget are applied again for the log readers that do
+	// not support them, which has no effect on the messages of those that
+	// do.
+	budget := logger.NewByteBudget(readConfig.MaxBytes)
 	go func() {
 		if cLogCreated {
 			defer func() {
@@ -143,6 +170,12 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 				if !ok {
 					return
 				}
+				if !filter.Match(msg) {
+					continue
+				}
+				if !budget.Take(msg) {
+					return
+				}
 				m := msg.AsLogMessage() // just a pointer conversion, does not copy data
 
 				// there could be a case where the reader stops accepting
@@ -0,0 +1,312 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// mergedLogsReorderWindow is how long a log message is held back for the
+	// older messages of the other containers to arrive.
+	mergedLogsReorderWindow = 500 * time.Millisecond
+	// mergedLogsMaxPending is the number of log messages held back above
+	// which the oldest one is sent without waiting.
+	mergedLogsMaxPending = 4096
+)
+
+// ContainersLogs returns the logs of the containers matching filter, merged
+// in the order of their timestamps. Each log stream is held back for up to
+// mergedLogsReorderWindow for the other ones to catch up. If config.Follow
+// is set, the containers matching filter that start while the logs are read
+// are joined, until ctx is canceled.
+func (daemon *Daemon) ContainersLogs(ctx context.Context, filter filters.Args, config *types.ContainerLogsOptions) (<-chan *types.ContainerLogMessage, error) {
+	var since time.Time
+	if config.Since != "" {
+		s, n, err := timetypes.ParseTimestamps(config.Since, 0)
+		if err != nil {
+			return nil, err
+		}
+		since = time.Unix(s, n)
+	}
+
+	// Subscribe to the start events before listing the containers, so that
+	// no container starting in between is missed.
+	var started chan interface{}
+	if config.Follow {
+		_, started = daemon.SubscribeToEvents(time.Time{}, time.Time{}, filters.NewArgs(
+			filters.Arg("type", events.ContainerEventType),
+			filters.Arg("event", "start"),
+		))
+	}
+	opened := time.Now()
+	containers, err := daemon.Containers(&types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		if started != nil {
+			daemon.UnsubscribeFromEvents(started)
+		}
+		return nil, err
+	}
+
+	m := newLogsMerger(*config)
+	if since.After(opened) {
+		opened = since
+	}
+	go func() {
+		defer close(m.out)
+		if started != nil {
+			defer daemon.UnsubscribeFromEvents(started)
+		}
+
+		for _, c := range containers {
+			daemon.joinMergedLogs(ctx, m, c, time.Time{})
+		}
+		for {
+			if !config.Follow && m.done() {
+				return
+			}
+
+			msg, wait := m.next(time.Now())
+			if msg != nil {
+				m.pop(msg)
+				if !m.budget.Take(&logger.Message{Line: []byte(msg.Line)}) {
+					return
+				}
+				select {
+				case m.out <- msg:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			var timer *time.Timer
+			var timeout <-chan time.Time
+			if wait > 0 {
+				timer = time.NewTimer(wait)
+				timeout = timer.C
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-m.in:
+				m.add(e, time.Now())
+			case <-timeout:
+			case ev := <-started:
+				jev, ok := ev.(events.Message)
+				if !ok {
+					break
+				}
+				// The containers starting again are read from when their
+				// logs ended, and the new ones from when the logs were
+				// opened.
+				joinSince := opened
+				if s, ok := m.streams[jev.Actor.ID]; ok {
+					if s.live {
+						break
+					}
+					if s.ended.After(joinSince) {
+						joinSince = s.ended
+					}
+				}
+				f := filter.Clone()
+				f.Add("id", jev.Actor.ID)
+				list, err := daemon.Containers(&types.ContainerListOptions{All: true, Filters: f})
+				if err != nil || len(list) == 0 {
+					break
+				}
+				daemon.joinMergedLogs(ctx, m, list[0], joinSince)
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+	}()
+	return m.out, nil
+}
+
+// joinMergedLogs adds the logs of c to m, from since if it is set.
+func (daemon *Daemon) joinMergedLogs(ctx context.Context, m *logsMerger, c *types.Container, since time.Time) {
+	config := m.config
+	if !since.IsZero() {
+		config.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+		config.Tail = "all"
+	}
+	var name string
+	if len(c.Names) > 0 {
+		name = strings.TrimPrefix(c.Names[0], "/")
+	}
+	s := m.stream(c.ID, name)
+
+	msgs, _, err := daemon.ContainerLogs(ctx, c.ID, &config)
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.ID).Debug("cannot read the logs of the container to merge")
+		m.add(mergedLogsEntry{stream: s, msg: &backend.LogMessage{Err: err}}, time.Now())
+		s.ended = time.Now()
+		return
+	}
+	s.live = true
+	go func() {
+		for msg := range msgs {
+			select {
+			case m.in <- mergedLogsEntry{stream: s, msg: msg}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case m.in <- mergedLogsEntry{stream: s}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// logsMerger merges the log streams of containers by timestamp. It is not
+// safe for concurrent use, except for sending to in.
+type logsMerger struct {
+	config  types.ContainerLogsOptions
+	in      chan mergedLogsEntry
+	out     chan *types.ContainerLogMessage
+	streams map[string]*mergedLogsStream
+	pending int
+	// budget is the size of the lines left to send, of all the streams.
+	budget *logger.ByteBudget
+}
+
+// mergedLogsStream is the log stream of a container.
+type mergedLogsStream struct {
+	id, name string
+	// live is set while messages are received from the stream.
+	live bool
+	// ended is when the stream ended.
+	ended   time.Time
+	pending []pendingLogMessage
+}
+
+type pendingLogMessage struct {
+	msg     *types.ContainerLogMessage
+	arrived time.Time
+}
+
+// mergedLogsEntry is a message of a log stream, or the end of the stream if
+// msg is nil.
+type mergedLogsEntry struct {
+	stream *mergedLogsStream
+	msg    *backend.LogMessage
+}
+
+func newLogsMerger(config types.ContainerLogsOptions) *logsMerger {
+	return &logsMerger{
+		config:  config,
+		in:      make(chan mergedLogsEntry),
+		out:     make(chan *types.ContainerLogMessage),
+		streams: make(map[string]*mergedLogsStream),
+		budget:  logger.NewByteBudget(config.MaxBytes),
+	}
+}
+
+func (m *logsMerger) stream(id, name string) *mergedLogsStream {
+	s, ok := m.streams[id]
+	if !ok {
+		s = &mergedLogsStream{id: id}
+		m.streams[id] = s
+	}
+	s.name = name
+	return s
+}
+
+// add holds back the message of e, or marks its stream as ended.
+func (m *logsMerger) add(e mergedLogsEntry, now time.Time) {
+	s := e.stream
+	if e.msg == nil {
+		s.live = false
+		s.ended = now
+		return
+	}
+	msg := &types.ContainerLogMessage{
+		ContainerID:   s.id,
+		ContainerName: s.name,
+		Stream:        e.msg.Source,
+		Timestamp:     e.msg.Timestamp,
+		Line:          string(e.msg.Line),
+	}
+	if e.msg.Err != nil {
+		msg.Error = e.msg.Err.Error()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = now
+	}
+	if m.config.Details && len(e.msg.Attrs) > 0 {
+		msg.Attrs = make(map[string]string, len(e.msg.Attrs))
+		for _, a := range e.msg.Attrs {
+			msg.Attrs[a.Key] = a.Value
+		}
+	}
+	s.pending = append(s.pending, pendingLogMessage{msg: msg, arrived: now})
+	m.pending++
+}
+
+// next returns the oldest message held back if it can be sent, which is
+// when every live stream has a message held back, when one was held back
+// for the whole reorder window, or when too many are held back. Otherwise,
+// it returns how long to wait for the window to pass, or 0 if no message is
+// held back.
+func (m *logsMerger) next(now time.Time) (*types.ContainerLogMessage, time.Duration) {
+	var (
+		oldest  *types.ContainerLogMessage
+		arrived time.Time
+		waiting bool
+	)
+	for _, s := range m.streams {
+		if len(s.pending) == 0 {
+			if s.live {
+				waiting = true
+			}
+			continue
+		}
+		head := s.pending[0]
+		if oldest == nil || head.msg.Timestamp.Before(oldest.Timestamp) {
+			oldest = head.msg
+		}
+		if arrived.IsZero() || head.arrived.Before(arrived) {
+			arrived = head.arrived
+		}
+	}
+	if oldest == nil {
+		return nil, 0
+	}
+	if !waiting || m.pending > mergedLogsMaxPending {
+		return oldest, 0
+	}
+	if wait := arrived.Add(mergedLogsReorderWindow).Sub(now); wait > 0 {
+		return nil, wait
+	}
+	return oldest, 0
+}
+
+// pop removes msg, returned by next, from the messages held back.
+func (m *logsMerger) pop(msg *types.ContainerLogMessage) {
+	s := m.streams[msg.ContainerID]
+	s.pending = s.pending[1:]
+	m.pending--
+}
+
+// done returns whether all the streams ended and all their messages were
+// sent.
+func (m *logsMerger) done() bool {
+	for _, s := range m.streams {
+		if s.live || len(s.pending) > 0 {
+			return false
+		}
+	}
+	return true
+}
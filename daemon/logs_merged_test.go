@@ -0,0 +1,104 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/backend"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestLogsMergerOrder(t *testing.T) {
+	m := newLogsMerger(types.ContainerLogsOptions{})
+	a, b := m.stream("a", "web"), m.stream("b", "db")
+	a.live, b.live = true, true
+
+	now := time.Now()
+	logAt := func(s *mergedLogsStream, line string, sec int) {
+		m.add(mergedLogsEntry{stream: s, msg: &backend.LogMessage{Line: []byte(line), Timestamp: time.Unix(int64(sec), 0)}}, now)
+	}
+	next := func(now time.Time) string {
+		t.Helper()
+		msg, _ := m.next(now)
+		if msg == nil {
+			return ""
+		}
+		m.pop(msg)
+		return msg.ContainerName + ": " + msg.Line
+	}
+
+	logAt(a, "one", 1)
+	logAt(a, "three", 3)
+
+	// The messages are held back while a live stream has none.
+	msg, wait := m.next(now)
+	assert.Check(t, msg == nil)
+	assert.Check(t, is.Equal(wait, mergedLogsReorderWindow))
+
+	logAt(b, "two", 2)
+	assert.Check(t, is.Equal(next(now), "web: one"))
+	assert.Check(t, is.Equal(next(now), "db: two"))
+	assert.Check(t, is.Equal(next(now), ""))
+
+	// Until they were held back for the whole reorder window.
+	assert.Check(t, is.Equal(next(now.Add(mergedLogsReorderWindow)), "web: three"))
+	assert.Check(t, !m.done())
+
+	// The streams that ended are not waited for.
+	logAt(a, "four", 4)
+	m.add(mergedLogsEntry{stream: b}, now)
+	assert.Check(t, is.Equal(next(now), "web: four"))
+	m.add(mergedLogsEntry{stream: a}, now)
+	assert.Check(t, m.done())
+}
+
+func TestLogsMergerMaxPending(t *testing.T) {
+	m := newLogsMerger(types.ContainerLogsOptions{})
+	a, b := m.stream("a", "web"), m.stream("b", "db")
+	a.live, b.live = true, true
+
+	now := time.Now()
+	for i := 0; i <= mergedLogsMaxPending; i++ {
+		msg, _ := m.next(now)
+		assert.Assert(t, msg == nil)
+		m.add(mergedLogsEntry{stream: a, msg: &backend.LogMessage{Timestamp: now}}, now)
+	}
+	msg, _ := m.next(now)
+	assert.Check(t, msg != nil)
+}
+
+func TestLogsMergerMessage(t *testing.T) {
+	m := newLogsMerger(types.ContainerLogsOptions{Details: true})
+	a := m.stream("a", "web")
+
+	now := time.Now()
+	m.add(mergedLogsEntry{stream: a, msg: &backend.LogMessage{
+		Line:      []byte("hello\n"),
+		Source:    "stderr",
+		Timestamp: now,
+		Attrs:     []backend.LogAttr{{Key: "env", Value: "prod"}},
+	}}, now)
+	m.add(mergedLogsEntry{stream: a, msg: &backend.LogMessage{Err: errors.New("boom")}}, now)
+
+	msg, _ := m.next(now)
+	assert.Check(t, is.DeepEqual(msg, &types.ContainerLogMessage{
+		ContainerID:   "a",
+		ContainerName: "web",
+		Stream:        "stderr",
+		Timestamp:     now,
+		Line:          "hello\n",
+		Attrs:         map[string]string{"env": "prod"},
+	}))
+	m.pop(msg)
+
+	msg, _ = m.next(now)
+	assert.Check(t, is.DeepEqual(msg, &types.ContainerLogMessage{
+		ContainerID:   "a",
+		ContainerName: "web",
+		Timestamp:     now,
+		Error:         "boom",
+	}))
+}
@@ -3,6 +3,7 @@ package daemon // import "github.com/docker/docker/daemon"
 import (
 	"sync"
 
+	"github.com/docker/docker/daemon/stats"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/plugingetter"
 	"github.com/docker/docker/pkg/plugins"
@@ -24,7 +25,9 @@ var (
 	healthChecksCounter       metrics.Counter
 	healthChecksFailedCounter metrics.Counter
 
-	stateCtr *stateCounter
+	stateCtr     *stateCounter
+	containerCtr *containerCollector
+	trafficCtr   *trafficCollector
 )
 
 func init() {
@@ -61,6 +64,14 @@ func init() {
 	stateCtr = newStateCounter(ns.NewDesc("container_states", "The count of containers in various states", metrics.Unit("containers"), "state"))
 	ns.Add(stateCtr)
 
+	containerCtr = newContainerCollector(ns)
+	ns.Add(containerCtr)
+
+	trafficCtr = &trafficCollector{
+		desc: ns.NewDesc("container_network_traffic_bytes", "The network traffic accounted under each key across restarts and recreations of the containers", metrics.Total, "key", "direction"),
+	}
+	ns.Add(trafficCtr)
+
 	metrics.Register(ns)
 }
 
@@ -115,6 +126,36 @@ func (ctr *stateCounter) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(ctr.desc, prometheus.GaugeValue, float64(stopped), "stopped")
 }
 
+// trafficCollector exports the network traffic accounted under each key.
+type trafficCollector struct {
+	mu      sync.RWMutex
+	traffic *stats.Traffic
+	desc    *prometheus.Desc
+}
+
+func (ctr *trafficCollector) setTraffic(t *stats.Traffic) {
+	ctr.mu.Lock()
+	ctr.traffic = t
+	ctr.mu.Unlock()
+}
+
+func (ctr *trafficCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ctr.desc
+}
+
+func (ctr *trafficCollector) Collect(ch chan<- prometheus.Metric) {
+	ctr.mu.RLock()
+	t := ctr.traffic
+	ctr.mu.RUnlock()
+	if t == nil {
+		return
+	}
+	for _, nt := range t.List() {
+		ch <- prometheus.MustNewConstMetric(ctr.desc, prometheus.CounterValue, float64(nt.RxBytes), nt.Key, "receive")
+		ch <- prometheus.MustNewConstMetric(ctr.desc, prometheus.CounterValue, float64(nt.TxBytes), nt.Key, "transmit")
+	}
+}
+
 func (daemon *Daemon) cleanupMetricsPlugins() {
 	ls := daemon.PluginStore.GetAllManagedPluginsByCap(metricsPluginType)
 	var wg sync.WaitGroup
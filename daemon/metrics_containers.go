@@ -0,0 +1,220 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/daemon/stats"
+	metrics "github.com/docker/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// healthStatuses are the values of the status label of the health status
+// metric.
+var healthStatuses = []string{types.Starting, types.Healthy, types.Unhealthy}
+
+// containerCollector exports the metrics of each running container. The
+// resource usage is taken from the latest stats sampled by the stats
+// collector, so that scrapes don't read the stats of the containers.
+type containerCollector struct {
+	ns *metrics.Namespace
+
+	mu     sync.RWMutex
+	latest *stats.Latest
+	daemon *Daemon
+	// labels are the container labels exported as metric labels.
+	labels []string
+
+	cpuUsage        *prometheus.Desc
+	cpuThrottled    *prometheus.Desc
+	memoryUsage     *prometheus.Desc
+	memoryLimit     *prometheus.Desc
+	networkBytes    *prometheus.Desc
+	networkPackets  *prometheus.Desc
+	networkErrors   *prometheus.Desc
+	networkDropped  *prometheus.Desc
+	blkioBytes      *prometheus.Desc
+	blkioOperations *prometheus.Desc
+	restarts        *prometheus.Desc
+	health          *prometheus.Desc
+	oomKills        *prometheus.Desc
+	pressure        *prometheus.Desc
+	stalled         *prometheus.Desc
+	memoryEvents    *prometheus.Desc
+}
+
+func newContainerCollector(ns *metrics.Namespace) *containerCollector {
+	return &containerCollector{ns: ns}
+}
+
+// configure enables the metrics of the containers, with the labels of the
+// containers in labels added to them.
+func (ctr *containerCollector) configure(d *Daemon, latest *stats.Latest, labels []string) {
+	names := []string{"id", "name"}
+	for _, l := range labels {
+		names = append(names, config.ContainerMetricsLabel(l))
+	}
+	desc := func(name, help string, unit metrics.Unit, extra ...string) *prometheus.Desc {
+		return ctr.ns.NewDesc(name, help, unit, append(append([]string{}, names...), extra...)...)
+	}
+
+	ctr.mu.Lock()
+	defer ctr.mu.Unlock()
+	ctr.daemon = d
+	ctr.latest = latest
+	ctr.labels = labels
+	ctr.cpuUsage = desc("container_cpu_usage_seconds", "The total CPU time consumed by the container", metrics.Total)
+	ctr.cpuThrottled = desc("container_cpu_throttled_seconds", "The total time the container was throttled for", metrics.Total)
+	ctr.memoryUsage = desc("container_memory_usage", "The memory usage of the container", metrics.Bytes)
+	ctr.memoryLimit = desc("container_memory_limit", "The memory limit of the container", metrics.Bytes)
+	ctr.networkBytes = desc("container_network_bytes", "The number of bytes received and transmitted by the container", metrics.Total, "interface", "direction")
+	ctr.networkPackets = desc("container_network_packets", "The number of packets received and transmitted by the container", metrics.Total, "interface", "direction")
+	ctr.networkErrors = desc("container_network_errors", "The number of errors receiving and transmitting packets", metrics.Total, "interface", "direction")
+	ctr.networkDropped = desc("container_network_dropped", "The number of received and transmitted packets dropped", metrics.Total, "interface", "direction")
+	ctr.blkioBytes = desc("container_blkio_bytes", "The number of bytes read and written by the container", metrics.Total, "device", "operation")
+	ctr.blkioOperations = desc("container_blkio_operations", "The number of read and write operations of the container", metrics.Total, "device", "operation")
+	ctr.restarts = desc("container_restarts", "The number of times the container was restarted by its restart policy", metrics.Total)
+	ctr.health = desc("container_health_status", "The health status of the container, 1 for the current status", metrics.Unit(""), "status")
+	ctr.oomKills = desc("container_oom_kills", "The number of times a process of the container was killed for lack of memory (cgroup v2 only)", metrics.Total)
+	ctr.pressure = desc("container_pressure", "The share of time tasks of the container were stalled waiting for a resource (cgroup v2 only)", metrics.Unit("percent"), "resource", "kind", "window")
+	ctr.stalled = desc("container_pressure_stalled_seconds", "The total time tasks of the container were stalled waiting for a resource (cgroup v2 only)", metrics.Total, "resource", "kind")
+	ctr.memoryEvents = desc("container_memory_events", "The number of memory events of the container (cgroup v2 only)", metrics.Total, "event")
+}
+
+func (ctr *containerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ctr.mu.RLock()
+	defer ctr.mu.RUnlock()
+	if ctr.daemon == nil {
+		return
+	}
+	for _, desc := range []*prometheus.Desc{
+		ctr.cpuUsage, ctr.cpuThrottled, ctr.memoryUsage, ctr.memoryLimit,
+		ctr.networkBytes, ctr.networkPackets, ctr.networkErrors, ctr.networkDropped,
+		ctr.blkioBytes, ctr.blkioOperations, ctr.restarts, ctr.health, ctr.oomKills,
+		ctr.pressure, ctr.stalled, ctr.memoryEvents,
+	} {
+		ch <- desc
+	}
+}
+
+func (ctr *containerCollector) Collect(ch chan<- prometheus.Metric) {
+	ctr.mu.RLock()
+	defer ctr.mu.RUnlock()
+	if ctr.daemon == nil {
+		return
+	}
+
+	for _, c := range ctr.daemon.List() {
+		c.Lock()
+		running := c.Running
+		name := strings.TrimPrefix(c.Name, "/")
+		restarts := c.RestartCount
+		var health string
+		if c.Health != nil {
+			health = c.Health.Status()
+		}
+		var containerLabels map[string]string
+		if c.Config != nil {
+			containerLabels = c.Config.Labels
+		}
+		c.Unlock()
+		if !running {
+			continue
+		}
+
+		values := []string{c.ID, name}
+		for _, l := range ctr.labels {
+			values = append(values, containerLabels[l])
+		}
+		counter := func(desc *prometheus.Desc, v float64, extra ...string) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, v, append(values, extra...)...)
+		}
+		gauge := func(desc *prometheus.Desc, v float64, extra ...string) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, append(values, extra...)...)
+		}
+
+		counter(ctr.restarts, float64(restarts))
+		if health != "" {
+			for _, status := range healthStatuses {
+				v := 0.0
+				if status == health {
+					v = 1
+				}
+				gauge(ctr.health, v, status)
+			}
+		}
+
+		if ctr.latest == nil {
+			continue
+		}
+		s, ok := ctr.latest.Get(c.ID)
+		if !ok {
+			continue
+		}
+		counter(ctr.cpuUsage, float64(s.CPUStats.CPUUsage.TotalUsage)/1e9)
+		counter(ctr.cpuThrottled, float64(s.CPUStats.ThrottlingData.ThrottledTime)/1e9)
+		gauge(ctr.memoryUsage, float64(s.MemoryStats.Usage))
+		if s.MemoryStats.Limit > 0 {
+			gauge(ctr.memoryLimit, float64(s.MemoryStats.Limit))
+		}
+		for iface, n := range s.Networks {
+			counter(ctr.networkBytes, float64(n.RxBytes), iface, "receive")
+			counter(ctr.networkBytes, float64(n.TxBytes), iface, "transmit")
+			counter(ctr.networkPackets, float64(n.RxPackets), iface, "receive")
+			counter(ctr.networkPackets, float64(n.TxPackets), iface, "transmit")
+			counter(ctr.networkErrors, float64(n.RxErrors), iface, "receive")
+			counter(ctr.networkErrors, float64(n.TxErrors), iface, "transmit")
+			counter(ctr.networkDropped, float64(n.RxDropped), iface, "receive")
+			counter(ctr.networkDropped, float64(n.TxDropped), iface, "transmit")
+		}
+		collectBlkio(ctr.blkioBytes, s.BlkioStats.IoServiceBytesRecursive, counter)
+		collectBlkio(ctr.blkioOperations, s.BlkioStats.IoServicedRecursive, counter)
+
+		// The memory events are read from the cgroup, so that they are
+		// not lost when the daemon restarts.
+		if ev := s.MemoryStats.Events; ev != nil {
+			counter(ctr.oomKills, float64(ev.OomKill))
+			for event, v := range map[string]uint64{"low": ev.Low, "high": ev.High, "max": ev.Max, "oom": ev.Oom, "oom_kill": ev.OomKill} {
+				counter(ctr.memoryEvents, float64(v), event)
+			}
+		}
+		if ps := s.PressureStats; ps != nil {
+			for resource, p := range map[string]*types.Pressure{"cpu": ps.CPU, "memory": ps.Memory, "io": ps.IO} {
+				if p == nil {
+					continue
+				}
+				ctr.collectPressure(counter, gauge, resource, "some", p.Some)
+				if p.Full != nil {
+					ctr.collectPressure(counter, gauge, resource, "full", *p.Full)
+				}
+			}
+		}
+	}
+}
+
+func (ctr *containerCollector) collectPressure(counter, gauge func(*prometheus.Desc, float64, ...string), resource, kind string, data types.PressureData) {
+	gauge(ctr.pressure, data.Avg10, resource, kind, "10s")
+	gauge(ctr.pressure, data.Avg60, resource, kind, "60s")
+	gauge(ctr.pressure, data.Avg300, resource, kind, "300s")
+	counter(ctr.stalled, float64(data.Total)/1e6, resource, kind)
+}
+
+// collectBlkio exports the reads and writes of each device in entries,
+// ignoring the other operations, which are the same I/O broken down
+// differently.
+func collectBlkio(desc *prometheus.Desc, entries []types.BlkioStatEntry, counter func(*prometheus.Desc, float64, ...string)) {
+	totals := make(map[[2]string]uint64)
+	for _, e := range entries {
+		op := strings.ToLower(e.Op)
+		if op != "read" && op != "write" {
+			continue
+		}
+		totals[[2]string{fmt.Sprintf("%d:%d", e.Major, e.Minor), op}] += e.Value
+	}
+	for k, v := range totals {
+		counter(desc, float64(v), k[0], k[1])
+	}
+}
@@ -0,0 +1,145 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/stats"
+	metrics "github.com/docker/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestContainerCollector(t *testing.T) {
+	running := &container.Container{
+		ID:           "running",
+		Name:         "/web",
+		State:        &container.State{Running: true, Health: &container.Health{Health: types.Health{Status: types.Healthy}}},
+		Config:       &containertypes.Config{Labels: map[string]string{"com.example.team": "frontend", "other": "ignored"}},
+		RestartCount: 2,
+	}
+	stopped := &container.Container{
+		ID:     "stopped",
+		Name:   "/db",
+		State:  &container.State{},
+		Config: &containertypes.Config{},
+	}
+	d := &Daemon{containers: container.NewMemoryStore()}
+	d.containers.Add(running.ID, running)
+	d.containers.Add(stopped.ID, stopped)
+
+	latest := stats.NewLatest(10 * time.Second)
+	var s types.StatsJSON
+	s.CPUStats.CPUUsage.TotalUsage = 1500000000
+	s.MemoryStats.Usage = 1024
+	s.Networks = map[string]types.NetworkStats{"eth0": {RxBytes: 100, TxBytes: 200}}
+	s.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 4096},
+		{Major: 8, Minor: 0, Op: "Write", Value: 512},
+		{Major: 8, Minor: 0, Op: "Total", Value: 4608},
+	}
+	s.MemoryStats.Events = &types.MemoryEvents{Max: 3, Oom: 2, OomKill: 1}
+	s.PressureStats = &types.PressureStats{
+		Memory: &types.Pressure{Some: types.PressureData{Avg10: 1.5, Total: 2000000}},
+	}
+	latest.Add(running.ID, s)
+
+	ctr := newContainerCollector(metrics.NewNamespace("engine", "daemon", nil))
+	ctr.configure(d, latest, []string{"com.example.team"})
+
+	reg := prometheus.NewPedanticRegistry()
+	assert.NilError(t, reg.Register(ctr))
+	families, err := reg.Gather()
+	assert.NilError(t, err)
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		for _, m := range f.Metric {
+			labels := make(map[string]string)
+			for _, l := range m.Label {
+				labels[l.GetName()] = l.GetValue()
+			}
+			assert.Check(t, is.Equal(labels["id"], "running"), f.GetName())
+			assert.Check(t, is.Equal(labels["name"], "web"), f.GetName())
+			assert.Check(t, is.Equal(labels["container_label_com_example_team"], "frontend"), f.GetName())
+			assert.Check(t, is.Len(labels, 3+extraLabels(f.GetName())), f.GetName())
+
+			key := f.GetName()
+			for _, extra := range []string{"interface", "direction", "device", "operation", "status", "event", "resource", "kind", "window"} {
+				if v, ok := labels[extra]; ok {
+					key += "/" + v
+				}
+			}
+			values[key] = metricValue(m)
+		}
+	}
+
+	assert.Check(t, is.DeepEqual(values, map[string]float64{
+		"engine_daemon_container_cpu_usage_seconds_total":                    1.5,
+		"engine_daemon_container_cpu_throttled_seconds_total":                0,
+		"engine_daemon_container_memory_usage_bytes":                         1024,
+		"engine_daemon_container_network_bytes_total/eth0/receive":           100,
+		"engine_daemon_container_network_bytes_total/eth0/transmit":          200,
+		"engine_daemon_container_network_packets_total/eth0/receive":         0,
+		"engine_daemon_container_network_packets_total/eth0/transmit":        0,
+		"engine_daemon_container_network_errors_total/eth0/receive":          0,
+		"engine_daemon_container_network_errors_total/eth0/transmit":         0,
+		"engine_daemon_container_network_dropped_total/eth0/receive":         0,
+		"engine_daemon_container_network_dropped_total/eth0/transmit":        0,
+		"engine_daemon_container_blkio_bytes_total/8:0/read":                 4096,
+		"engine_daemon_container_blkio_bytes_total/8:0/write":                512,
+		"engine_daemon_container_restarts_total":                             2,
+		"engine_daemon_container_oom_kills_total":                            1,
+		"engine_daemon_container_health_status/starting":                     0,
+		"engine_daemon_container_health_status/healthy":                      1,
+		"engine_daemon_container_health_status/unhealthy":                    0,
+		"engine_daemon_container_memory_events_total/low":                    0,
+		"engine_daemon_container_memory_events_total/high":                   0,
+		"engine_daemon_container_memory_events_total/max":                    3,
+		"engine_daemon_container_memory_events_total/oom":                    2,
+		"engine_daemon_container_memory_events_total/oom_kill":               1,
+		"engine_daemon_container_pressure_percent/memory/some/10s":           1.5,
+		"engine_daemon_container_pressure_percent/memory/some/60s":           0,
+		"engine_daemon_container_pressure_percent/memory/some/300s":          0,
+		"engine_daemon_container_pressure_stalled_seconds_total/memory/some": 2,
+	}))
+}
+
+func extraLabels(name string) int {
+	switch name {
+	case "engine_daemon_container_health_status",
+		"engine_daemon_container_memory_events_total":
+		return 1
+	case "engine_daemon_container_pressure_percent":
+		return 3
+	case "engine_daemon_container_cpu_usage_seconds_total",
+		"engine_daemon_container_cpu_throttled_seconds_total",
+		"engine_daemon_container_memory_usage_bytes",
+		"engine_daemon_container_restarts_total",
+		"engine_daemon_container_oom_kills_total":
+		return 0
+	default:
+		return 2
+	}
+}
+
+func metricValue(m *dto.Metric) float64 {
+	if m.Counter != nil {
+		return m.Counter.GetValue()
+	}
+	return m.Gauge.GetValue()
+}
+
+func TestContainerCollectorDisabled(t *testing.T) {
+	ctr := newContainerCollector(metrics.NewNamespace("engine", "daemon", nil))
+	reg := prometheus.NewPedanticRegistry()
+	assert.NilError(t, reg.Register(ctr))
+	families, err := reg.Gather()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(families, 0))
+}
@@ -86,6 +86,12 @@ func (daemon *Daemon) handleContainerExit(c *container.Container, e *libcontaine
 				// But containerStart will use daemon.netController segment.
 				// So to avoid panic at startup process, here must wait util daemon restore done.
 				daemon.waitForStartupDone()
+				daemon.waitDependencies(c, nil)
+				if !c.IsRestarting() {
+					// The container was stopped while waiting for its
+					// dependencies.
+					return
+				}
 				if err = daemon.containerStart(c, "", "", false); err != nil {
 					logrus.Debugf("failed to restart container: %+v", err)
 				}
@@ -211,9 +217,11 @@ func (daemon *Daemon) ProcessEvent(id string, e libcontainerdtypes.EventType, ei
 	return nil
 }
 
+// autoRemove removes the exited container c if it has AutoRemove set, unless
+// it is claimed by a removal or an image update, which decide its fate.
 func (daemon *Daemon) autoRemove(c *container.Container) {
 	c.Lock()
-	ar := c.HostConfig.AutoRemove
+	ar := c.HostConfig.AutoRemove && !c.RemovalInProgress
 	c.Unlock()
 	if !ar {
 		return
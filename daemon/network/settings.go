@@ -4,6 +4,7 @@ import (
 	"net"
 	"sync"
 
+	"github.com/docker/docker/api/types"
 	networktypes "github.com/docker/docker/api/types/network"
 	clustertypes "github.com/docker/docker/daemon/cluster/provider"
 	"github.com/docker/go-connections/nat"
@@ -26,6 +27,9 @@ type Settings struct {
 	SecondaryIPv6Addresses []networktypes.Address
 	IsAnonymousEndpoint    bool
 	HasSwarmEndpoint       bool
+	// Bandwidth is the shaping applied to the network interfaces of the
+	// container, indexed by interface name.
+	Bandwidth map[string]types.NetworkBandwidth
 }
 
 // EndpointSettings is a package local wrapper for
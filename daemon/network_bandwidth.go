@@ -0,0 +1,69 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+)
+
+// minNetworkBurst is the smallest burst used when none is configured. It
+// is larger than the MTU of jumbo frames, which could never be sent with a
+// smaller burst.
+const minNetworkBurst = 16 * 1024
+
+// validateNetworkBandwidth validates the shaping of the network traffic of a
+// container using networkMode. A rate or burst of -1 removes it on update.
+func validateNetworkBandwidth(resources *containertypes.Resources, networkMode containertypes.NetworkMode, platform string) error {
+	for _, v := range []struct {
+		name  string
+		value int64
+	}{
+		{"egress rate", resources.NetworkEgressRate},
+		{"egress burst", resources.NetworkEgressBurst},
+		{"ingress rate", resources.NetworkIngressRate},
+		{"ingress burst", resources.NetworkIngressBurst},
+	} {
+		if v.value < -1 {
+			return errors.Errorf("invalid network %s %d: must be positive, or -1 to remove it", v.name, v.value)
+		}
+	}
+	if resources.NetworkEgressRate <= 0 && resources.NetworkIngressRate <= 0 {
+		return nil
+	}
+	if platform == "windows" {
+		return errors.New("network bandwidth shaping is not supported on Windows")
+	}
+	if networkMode.IsHost() || networkMode.IsContainer() {
+		return errors.Errorf("network bandwidth shaping is not supported with the %s network mode", networkMode.NetworkName())
+	}
+	return nil
+}
+
+// networkBurst returns the burst of traffic shaped at rate, 100ms of traffic
+// at the rate if it isn't configured.
+func networkBurst(rate, burst int64) int64 {
+	if burst > 0 {
+		return burst
+	}
+	if burst = rate / 10; burst < minNetworkBurst {
+		burst = minNetworkBurst
+	}
+	return burst
+}
+
+// updateNetworkBandwidth applies the shaping of the network traffic of the
+// running container ctr after its HostConfig was updated.
+func (daemon *Daemon) updateNetworkBandwidth(ctr *container.Container) error {
+	if daemon.netController == nil {
+		return nil
+	}
+	sb := daemon.getNetworkSandbox(ctr)
+
+	ctr.Lock()
+	defer ctr.Unlock()
+	if err := daemon.applyNetworkBandwidth(ctr, sb); err != nil {
+		return errdefs.System(err)
+	}
+	return ctr.CheckpointTo(daemon.containersReplica)
+}
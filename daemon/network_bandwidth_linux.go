@@ -0,0 +1,175 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"math"
+	"net"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/docker/libnetwork"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// networkShapingLatency is the longest time a packet is queued before being
+// dropped when the traffic exceeds its rate.
+const networkShapingLatency = 25 * time.Millisecond
+
+// networkShapingHandle is the handle of the root qdiscs shaping the traffic
+// of the containers, so that they are told apart from qdiscs set up by
+// others.
+var networkShapingHandle = netlink.MakeHandle(1, 0)
+
+// applyNetworkBandwidth shapes the traffic of the network interfaces in the
+// sandbox of container c to the rates of its HostConfig, and records the
+// applied shaping in its NetworkSettings. The egress traffic is shaped on
+// the interfaces of the container, and the ingress traffic on their host
+// end, which only veth interfaces have. If shaping an interface fails, the
+// interfaces already shaped are shaped back as recorded in NetworkSettings.
+func (daemon *Daemon) applyNetworkBandwidth(c *container.Container, sb libnetwork.Sandbox) error {
+	if sb == nil || c.HostConfig.NetworkMode.IsHost() || c.HostConfig.NetworkMode.IsContainer() {
+		return nil
+	}
+	resources := c.HostConfig.Resources
+	if resources.NetworkEgressRate <= 0 && resources.NetworkIngressRate <= 0 && len(c.NetworkSettings.Bandwidth) == 0 {
+		return nil
+	}
+
+	ns, err := netns.GetFromPath(sb.Key())
+	if err != nil {
+		return errors.Wrap(err, "failed to open the network namespace of the container")
+	}
+	defer ns.Close()
+	nh, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return errors.Wrap(err, "failed to open the network namespace of the container")
+	}
+	defer nh.Delete()
+	hh, err := netlink.NewHandle()
+	if err != nil {
+		return err
+	}
+	defer hh.Delete()
+
+	links, err := nh.LinkList()
+	if err != nil {
+		return errors.Wrap(err, "failed to list the network interfaces of the container")
+	}
+	bandwidth := make(map[string]types.NetworkBandwidth)
+	for i, link := range links {
+		attrs := link.Attrs()
+		if attrs.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		var bw types.NetworkBandwidth
+		if resources.NetworkEgressRate > 0 {
+			bw.EgressRate = resources.NetworkEgressRate
+			bw.EgressBurst = networkBurst(resources.NetworkEgressRate, resources.NetworkEgressBurst)
+		}
+		if err := shapeLink(nh, link, bw.EgressRate, bw.EgressBurst); err != nil {
+			restoreNetworkBandwidth(c, nh, hh, links[:i+1])
+			return errors.Wrapf(err, "failed to shape the egress traffic of %s", attrs.Name)
+		}
+
+		if peer := vethPeer(hh, link); peer != nil {
+			if resources.NetworkIngressRate > 0 {
+				bw.IngressRate = resources.NetworkIngressRate
+				bw.IngressBurst = networkBurst(resources.NetworkIngressRate, resources.NetworkIngressBurst)
+			}
+			if err := shapeLink(hh, peer, bw.IngressRate, bw.IngressBurst); err != nil {
+				restoreNetworkBandwidth(c, nh, hh, links[:i+1])
+				return errors.Wrapf(err, "failed to shape the ingress traffic of %s", attrs.Name)
+			}
+		} else if resources.NetworkIngressRate > 0 {
+			logrus.WithField("container", c.ID).Warnf("not shaping the ingress traffic of %s: it has no host end", attrs.Name)
+		}
+
+		if bw != (types.NetworkBandwidth{}) {
+			bandwidth[attrs.Name] = bw
+		}
+	}
+
+	if len(bandwidth) == 0 {
+		bandwidth = nil
+	}
+	// The map is replaced, so that the stats can read it while it changes.
+	c.NetworkSettings.Bandwidth = bandwidth
+	return nil
+}
+
+// restoreNetworkBandwidth shapes the traffic of links in the sandbox of
+// container c back to the shaping recorded in its NetworkSettings.
+func restoreNetworkBandwidth(c *container.Container, nh, hh *netlink.Handle, links []netlink.Link) {
+	for _, link := range links {
+		attrs := link.Attrs()
+		if attrs.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		bw := c.NetworkSettings.Bandwidth[attrs.Name]
+		if err := shapeLink(nh, link, bw.EgressRate, bw.EgressBurst); err != nil {
+			logrus.WithError(err).WithField("container", c.ID).Warnf("failed to restore the shaping of the egress traffic of %s", attrs.Name)
+		}
+		if peer := vethPeer(hh, link); peer != nil {
+			if err := shapeLink(hh, peer, bw.IngressRate, bw.IngressBurst); err != nil {
+				logrus.WithError(err).WithField("container", c.ID).Warnf("failed to restore the shaping of the ingress traffic of %s", attrs.Name)
+			}
+		}
+	}
+}
+
+// vethPeer returns the host end of link, or nil if it is not a veth
+// interface.
+func vethPeer(hh *netlink.Handle, link netlink.Link) netlink.Link {
+	if link.Type() != "veth" || link.Attrs().ParentIndex == 0 {
+		return nil
+	}
+	peer, err := hh.LinkByIndex(link.Attrs().ParentIndex)
+	if err != nil || peer.Type() != "veth" || peer.Attrs().ParentIndex != link.Attrs().Index {
+		return nil
+	}
+	return peer
+}
+
+// shapeLink limits the traffic sent on link to rate, or removes the limit if
+// rate isn't positive.
+func shapeLink(h *netlink.Handle, link netlink.Link, rate, burst int64) error {
+	if rate > 0 {
+		return h.QdiscReplace(newShapingQdisc(link.Attrs().Index, rate, burst))
+	}
+	qdiscs, err := h.QdiscList(link)
+	if err != nil {
+		return err
+	}
+	for _, q := range qdiscs {
+		if _, ok := q.(*netlink.Tbf); ok && q.Attrs().Parent == netlink.HANDLE_ROOT && q.Attrs().Handle == networkShapingHandle {
+			return h.QdiscDel(q)
+		}
+	}
+	return nil
+}
+
+// newShapingQdisc returns the token bucket filter limiting the traffic of
+// link index to rate, in bytes per second, with bursts of burst bytes.
+func newShapingQdisc(index int, rate, burst int64) *netlink.Tbf {
+	limit := rate*int64(networkShapingLatency/time.Millisecond)/1000 + burst
+	if limit > math.MaxUint32 {
+		limit = math.MaxUint32
+	}
+	if burst > math.MaxUint32 {
+		burst = math.MaxUint32
+	}
+	return &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: index,
+			Handle:    networkShapingHandle,
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		Rate:   uint64(rate),
+		Limit:  uint32(limit),
+		Buffer: netlink.Xmittime(uint64(rate), uint32(burst)),
+	}
+}
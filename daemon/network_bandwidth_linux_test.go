@@ -0,0 +1,24 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"math"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestNewShapingQdisc(t *testing.T) {
+	q := newShapingQdisc(3, 1<<20, 1<<17)
+	assert.Check(t, is.Equal(q.LinkIndex, 3))
+	assert.Check(t, is.Equal(q.Parent, uint32(netlink.HANDLE_ROOT)))
+	assert.Check(t, is.Equal(q.Handle, networkShapingHandle))
+	assert.Check(t, is.Equal(q.Rate, uint64(1<<20)))
+	// 25ms of traffic at the rate are queued on top of the burst.
+	assert.Check(t, is.Equal(q.Limit, uint32(1<<20/40+1<<17)))
+	assert.Check(t, is.Equal(q.Buffer, netlink.Xmittime(1<<20, 1<<17)))
+
+	q = newShapingQdisc(3, math.MaxInt64/1000, 1<<40)
+	assert.Check(t, is.Equal(q.Limit, uint32(math.MaxUint32)))
+}
@@ -0,0 +1,77 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestValidateNetworkBandwidth(t *testing.T) {
+	tests := []struct {
+		doc       string
+		resources containertypes.Resources
+		mode      containertypes.NetworkMode
+		platform  string
+		expected  string
+	}{
+		{
+			doc:       "rates on a bridge network",
+			resources: containertypes.Resources{NetworkEgressRate: 1 << 20, NetworkIngressRate: 2 << 20, NetworkIngressBurst: 1 << 16},
+			mode:      "bridge",
+			platform:  "linux",
+		},
+		{
+			doc:       "removal on update",
+			resources: containertypes.Resources{NetworkEgressRate: -1, NetworkIngressRate: -1},
+			mode:      "host",
+			platform:  "linux",
+		},
+		{
+			doc:       "negative burst",
+			resources: containertypes.Resources{NetworkEgressRate: 1 << 20, NetworkEgressBurst: -2},
+			mode:      "bridge",
+			platform:  "linux",
+			expected:  "invalid network egress burst -2: must be positive, or -1 to remove it",
+		},
+		{
+			doc:       "host network",
+			resources: containertypes.Resources{NetworkIngressRate: 1 << 20},
+			mode:      "host",
+			platform:  "linux",
+			expected:  "network bandwidth shaping is not supported with the host network mode",
+		},
+		{
+			doc:       "container network",
+			resources: containertypes.Resources{NetworkEgressRate: 1 << 20},
+			mode:      "container:abc",
+			platform:  "linux",
+			expected:  "network bandwidth shaping is not supported with the container network mode",
+		},
+		{
+			doc:       "windows",
+			resources: containertypes.Resources{NetworkEgressRate: 1 << 20},
+			mode:      "nat",
+			platform:  "windows",
+			expected:  "network bandwidth shaping is not supported on Windows",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.doc, func(t *testing.T) {
+			err := validateNetworkBandwidth(&tc.resources, tc.mode, tc.platform)
+			if tc.expected == "" {
+				assert.Check(t, err)
+			} else {
+				assert.Check(t, is.Error(err, tc.expected))
+			}
+		})
+	}
+}
+
+func TestNetworkBurst(t *testing.T) {
+	assert.Check(t, is.Equal(networkBurst(1<<20, 0), int64(1<<20/10)))
+	assert.Check(t, is.Equal(networkBurst(1<<20, 4096), int64(4096)))
+	assert.Check(t, is.Equal(networkBurst(1000, 0), int64(minNetworkBurst)))
+}
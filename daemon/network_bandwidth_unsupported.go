@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"github.com/docker/docker/container"
+	"github.com/docker/libnetwork"
+)
+
+func (daemon *Daemon) applyNetworkBandwidth(c *container.Container, sb libnetwork.Sandbox) error {
+	return nil
+}
@@ -1,18 +1,23 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 
 	containertypes "github.com/docker/docker/api/types/container"
+	swarmtypes "github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/daemon/secretstore"
 	"github.com/docker/docker/pkg/containerfs"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/libnetwork"
+	"golang.org/x/sys/unix"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/skip"
@@ -203,3 +208,60 @@ func TestGetSourceMount(t *testing.T) {
 	_, _, err = getSourceMount(cwd)
 	assert.NilError(t, err)
 }
+
+func TestSecretMounts(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+	c := &container.Container{
+		HostConfig: &containertypes.HostConfig{
+			Secrets: []containertypes.FileReference{{Name: "token", UID: "1000", Mode: 0400}},
+			Configs: []containertypes.FileReference{{Name: "app.conf", Target: "/etc/app.conf"}},
+		},
+	}
+	d := setupFakeDaemon(t, c)
+	defer cleanupFakeContainer(c)
+
+	store, err := secretstore.New(filepath.Join(c.Root, "store"), bytes.Repeat([]byte{1}, 32))
+	assert.NilError(t, err)
+	d.secrets = store
+	_, err = store.CreateSecret(swarmtypes.SecretSpec{Annotations: swarmtypes.Annotations{Name: "token"}, Data: []byte("s3cr3t")})
+	assert.NilError(t, err)
+	_, err = store.CreateConfig(swarmtypes.ConfigSpec{Annotations: swarmtypes.Annotations{Name: "app.conf"}, Data: []byte("debug=1")})
+	assert.NilError(t, err)
+
+	s, err := d.createSpec(c)
+	assert.NilError(t, err)
+	defer d.cleanupSecretDir(c)
+
+	sources := map[string]string{}
+	for _, m := range s.Mounts {
+		sources[m.Destination] = m.Source
+	}
+	for _, tc := range []struct {
+		target, data string
+		uid          uint32
+		mode         os.FileMode
+	}{
+		{target: "/run/secrets/token", data: "s3cr3t", uid: 1000, mode: 0400},
+		{target: "/etc/app.conf", data: "debug=1", uid: 0, mode: 0444},
+	} {
+		src, ok := sources[tc.target]
+		if !assert.Check(t, ok, "no mount for %s", tc.target) {
+			continue
+		}
+		data, err := ioutil.ReadFile(src)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(string(data), tc.data))
+		fi, err := os.Stat(src)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(fi.Mode(), tc.mode))
+		assert.Check(t, is.Equal(fi.Sys().(*syscall.Stat_t).Uid, tc.uid))
+	}
+
+	// The secrets are on a read-only tmpfs.
+	dir, err := c.SecretMountPath()
+	assert.NilError(t, err)
+	var st unix.Statfs_t
+	assert.NilError(t, unix.Statfs(dir, &st))
+	assert.Check(t, is.Equal(st.Type, int64(unix.TMPFS_MAGIC)))
+	assert.Check(t, st.Flags&unix.ST_RDONLY != 0)
+}
@@ -3,9 +3,12 @@ package daemon // import "github.com/docker/docker/daemon"
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/discovery"
+	"github.com/docker/docker/daemon/logger/loggerutils"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,7 +26,16 @@ import (
 // - Insecure registries
 // - Registry mirrors
 // - Daemon live restore
+// - Event sinks
+// - Log quota
+// - Stats history tiers
 func (daemon *Daemon) Reload(conf *config.Config) (err error) {
+	// Stopping the event forwarders waits for their ongoing deliveries, so
+	// they are restarted without holding the configuration lock.
+	if err := daemon.reloadEventSinks(conf); err != nil {
+		return err
+	}
+
 	daemon.configStore.Lock()
 	attributes := map[string]string{}
 
@@ -53,6 +65,11 @@ func (daemon *Daemon) Reload(conf *config.Config) (err error) {
 	}
 	daemon.reloadShutdownTimeout(conf, attributes)
 	daemon.reloadFeatures(conf, attributes)
+	attributes["event-sinks"] = eventSinkNames(daemon.configStore.EventSinks)
+	daemon.reloadLogQuota(conf, attributes)
+	if err := daemon.reloadStatsHistoryTiers(conf, attributes); err != nil {
+		return err
+	}
 
 	if err := daemon.reloadClusterDiscovery(conf, attributes); err != nil {
 		return err
@@ -172,6 +189,38 @@ func (daemon *Daemon) reloadShutdownTimeout(conf *config.Config, attributes map[
 	attributes["shutdown-timeout"] = fmt.Sprintf("%d", daemon.configStore.ShutdownTimeout)
 }
 
+// reloadLogQuota updates the quota on the log files of all the containers,
+// and updates the passed attributes
+func (daemon *Daemon) reloadLogQuota(conf *config.Config, attributes map[string]string) {
+	// An unset quota is unlimited, so that removing the option from the
+	// configuration lifts the quota.
+	daemon.configStore.LogQuota = conf.LogQuota
+	loggerutils.DefaultQuota.SetLimit(conf.LogQuota.Value())
+
+	attributes["log-quota"] = fmt.Sprintf("%d", daemon.configStore.LogQuota.Value())
+}
+
+// reloadStatsHistoryTiers updates the tiers of the stats history, if it is
+// enabled, and updates the passed attributes
+func (daemon *Daemon) reloadStatsHistoryTiers(conf *config.Config, attributes map[string]string) error {
+	if daemon.statsCollector == nil {
+		return nil
+	}
+	h := daemon.statsCollector.History()
+	if h == nil {
+		return nil
+	}
+	tiers, err := statsHistoryTiers(conf)
+	if err != nil {
+		return err
+	}
+	h.SetTiers(tiers)
+	daemon.configStore.StatsHistory.Tiers = conf.StatsHistory.Tiers
+
+	attributes["stats-history-tiers"] = strings.Join(daemon.configStore.StatsHistory.Tiers, ",")
+	return nil
+}
+
 // reloadClusterDiscovery updates configuration with cluster discovery options
 // and updates the passed attributes
 func (daemon *Daemon) reloadClusterDiscovery(conf *config.Config, attributes map[string]string) (err error) {
@@ -383,3 +432,41 @@ func (daemon *Daemon) reloadFeatures(conf *config.Config, attributes map[string]
 	// prepare reload event attributes with updatable configurations
 	attributes["features"] = fmt.Sprintf("%v", daemon.configStore.Features)
 }
+
+// reloadEventSinks restarts forwarding events to sinks if their
+// configuration changed. It is called without holding the configuration
+// lock.
+func (daemon *Daemon) reloadEventSinks(conf *config.Config) error {
+	daemon.eventForwardersMu.Lock()
+	defer daemon.eventForwardersMu.Unlock()
+
+	daemon.configStore.Lock()
+	root, current := daemon.configStore.Root, daemon.configStore.EventSinks
+	daemon.configStore.Unlock()
+	if reflect.DeepEqual(current, conf.EventSinks) {
+		return nil
+	}
+
+	daemon.stopEventForwarders()
+	if err := daemon.startEventForwarders(root, conf.EventSinks); err != nil {
+		// Keep forwarding to the previously configured sinks.
+		if err := daemon.startEventForwarders(root, current); err != nil {
+			logrus.WithError(err).Error("failed to restart event sinks")
+		}
+		return err
+	}
+
+	daemon.configStore.Lock()
+	daemon.configStore.EventSinks = conf.EventSinks
+	daemon.configStore.Unlock()
+	return nil
+}
+
+// eventSinkNames returns the comma-separated names of sinks.
+func eventSinkNames(sinks []config.EventSinkConfig) string {
+	names := make([]string, 0, len(sinks))
+	for _, s := range sinks {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ",")
+}
@@ -12,8 +12,14 @@ import (
 
 // ContainerRename changes the name of a container, using the oldName
 // to find the container. An error is returned if newName is already
-// reserved.
+// reserved, or if the container is being removed.
 func (daemon *Daemon) ContainerRename(oldName, newName string) error {
+	return daemon.containerRename(oldName, newName, false)
+}
+
+// containerRename is ContainerRename. claimed is set by the operations
+// renaming a container they claimed with SetRemovalInProgress.
+func (daemon *Daemon) containerRename(oldName, newName string, claimed bool) error {
 	var (
 		sid string
 		sb  libnetwork.Sandbox
@@ -35,6 +41,10 @@ func (daemon *Daemon) ContainerRename(oldName, newName string) error {
 	container.Lock()
 	defer container.Unlock()
 
+	if container.RemovalInProgress && !claimed {
+		return errdefs.Conflict(errors.New("container is marked for removal and cannot be renamed"))
+	}
+
 	oldName = container.Name
 	oldIsAnonymousEndpoint := container.NetworkSettings.IsAnonymousEndpoint
 
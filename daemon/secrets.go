@@ -1,10 +1,29 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
 	swarmtypes "github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// containerSecretMountPath and containerConfigMountPath are the directories
+// relative secret and config targets are resolved in.
+const (
+	containerSecretMountPath = "/run/secrets"
+	containerConfigMountPath = "/"
+)
+
+var errSecretStoreNotConfigured = errdefs.Unavailable(errors.New("secrets and configs are not available: the daemon has no secret-store-key configured"))
+
 // SetContainerSecretReferences sets the container secret references needed
 func (daemon *Daemon) SetContainerSecretReferences(name string, refs []*swarmtypes.SecretReference) error {
 	if !secretsSupported() && len(refs) > 0 {
@@ -21,3 +40,224 @@ func (daemon *Daemon) SetContainerSecretReferences(name string, refs []*swarmtyp
 
 	return nil
 }
+
+// GetSecrets returns the secrets stored by the daemon matching the filters.
+func (daemon *Daemon) GetSecrets(opts types.SecretListOptions) ([]swarmtypes.Secret, error) {
+	if daemon.secrets == nil {
+		return nil, errSecretStoreNotConfigured
+	}
+	return daemon.secrets.ListSecrets(opts.Filters)
+}
+
+// CreateSecret stores a new secret and returns its ID.
+func (daemon *Daemon) CreateSecret(spec swarmtypes.SecretSpec) (string, error) {
+	if daemon.secrets == nil {
+		return "", errSecretStoreNotConfigured
+	}
+	id, err := daemon.secrets.CreateSecret(spec)
+	if err != nil {
+		return "", err
+	}
+	daemon.LogSecretEvent(id, spec.Name, "create")
+	return id, nil
+}
+
+// GetSecret returns the secret with the given ID or name.
+func (daemon *Daemon) GetSecret(idOrName string) (swarmtypes.Secret, error) {
+	if daemon.secrets == nil {
+		return swarmtypes.Secret{}, errSecretStoreNotConfigured
+	}
+	return daemon.secrets.GetSecret(idOrName)
+}
+
+// UpdateSecret updates the labels of a secret.
+func (daemon *Daemon) UpdateSecret(idOrName string, version uint64, spec swarmtypes.SecretSpec) error {
+	if daemon.secrets == nil {
+		return errSecretStoreNotConfigured
+	}
+	secret, err := daemon.secrets.GetSecret(idOrName)
+	if err != nil {
+		return err
+	}
+	if err := daemon.secrets.UpdateSecret(secret.ID, version, spec); err != nil {
+		return err
+	}
+	daemon.LogSecretEvent(secret.ID, secret.Spec.Name, "update")
+	return nil
+}
+
+// RemoveSecret removes a secret which is not referenced by any container.
+func (daemon *Daemon) RemoveSecret(idOrName string) error {
+	if daemon.secrets == nil {
+		return errSecretStoreNotConfigured
+	}
+	secret, err := daemon.secrets.GetSecret(idOrName)
+	if err != nil {
+		return err
+	}
+	if users := daemon.fileReferenceUsers(secret.ID, secret.Spec.Name, func(hc *containertypes.HostConfig) []containertypes.FileReference {
+		return hc.Secrets
+	}); len(users) > 0 {
+		return errdefs.Conflict(errors.Errorf("secret %s is in use by the following containers: %v", secret.Spec.Name, users))
+	}
+	if err := daemon.secrets.RemoveSecret(secret.ID); err != nil {
+		return err
+	}
+	daemon.LogSecretEvent(secret.ID, secret.Spec.Name, "remove")
+	return nil
+}
+
+// fileReferenceUsers returns the names of the containers referencing the
+// secret or config with the given ID and name.
+func (daemon *Daemon) fileReferenceUsers(id, name string, refs func(*containertypes.HostConfig) []containertypes.FileReference) []string {
+	var users []string
+	for _, c := range daemon.containers.List() {
+		for _, r := range refs(c.HostConfig) {
+			if r.Name == id || r.Name == name {
+				users = append(users, strings.TrimPrefix(c.Name, "/"))
+				break
+			}
+		}
+	}
+	return users
+}
+
+// validateFileReferences validates the secrets and configs referenced by
+// the host config of a container being created.
+func (daemon *Daemon) validateFileReferences(hostConfig *containertypes.HostConfig) error {
+	if len(hostConfig.Secrets) == 0 && len(hostConfig.Configs) == 0 {
+		return nil
+	}
+	if isWindows {
+		return errors.New("secrets and configs are not supported for standalone containers on Windows")
+	}
+	if daemon.secrets == nil {
+		return errSecretStoreNotConfigured
+	}
+
+	targets := make(map[string]string)
+	validate := func(kind string, r containertypes.FileReference, defaultDir string, get func(string) error) error {
+		if r.Name == "" {
+			return errors.Errorf("%s name must not be empty", kind)
+		}
+		if err := get(r.Name); err != nil {
+			return err
+		}
+		for _, id := range []string{r.UID, r.GID} {
+			if id == "" {
+				continue
+			}
+			if n, err := strconv.Atoi(id); err != nil || n < 0 {
+				return errors.Errorf("invalid owner %q for %s %s", id, kind, r.Name)
+			}
+		}
+		if r.Mode&^0777 != 0 {
+			return errors.Errorf("invalid mode %o for %s %s", r.Mode, kind, r.Name)
+		}
+		target := fileReferenceTarget(r, defaultDir)
+		if target == "/" {
+			return errors.Errorf("invalid target for %s %s", kind, r.Name)
+		}
+		if other, ok := targets[target]; ok {
+			return errors.Errorf("%s %s and %s are both mounted at %s", kind, r.Name, other, target)
+		}
+		targets[target] = r.Name
+		return nil
+	}
+	for _, r := range hostConfig.Secrets {
+		if err := validate("secret", r, containerSecretMountPath, func(name string) error {
+			_, err := daemon.secrets.GetSecret(name)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, r := range hostConfig.Configs {
+		if err := validate("config", r, containerConfigMountPath, func(name string) error {
+			_, err := daemon.secrets.GetConfig(name)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileReferenceTarget returns the absolute path a secret or config is
+// mounted at in a container.
+func fileReferenceTarget(r containertypes.FileReference, defaultDir string) string {
+	target := r.Target
+	if target == "" {
+		target = r.Name
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(defaultDir, target)
+	}
+	return filepath.Clean(target)
+}
+
+// resolveFileReferences sets the secret and config references of a
+// container from the secrets and configs in its host config, so that they
+// are mounted the same way as the secrets and configs of swarm tasks.
+func (daemon *Daemon) resolveFileReferences(c *container.Container) error {
+	if len(c.HostConfig.Secrets) == 0 && len(c.HostConfig.Configs) == 0 {
+		return nil
+	}
+	if daemon.secrets == nil {
+		return errSecretStoreNotConfigured
+	}
+
+	var secretRefs []*swarmtypes.SecretReference
+	for _, r := range c.HostConfig.Secrets {
+		secret, err := daemon.secrets.GetSecret(r.Name)
+		if err != nil {
+			return err
+		}
+		uid, gid, mode := fileReferenceOwnership(r)
+		secretRefs = append(secretRefs, &swarmtypes.SecretReference{
+			File: &swarmtypes.SecretReferenceFileTarget{
+				Name: fileReferenceTarget(r, containerSecretMountPath),
+				UID:  uid,
+				GID:  gid,
+				Mode: mode,
+			},
+			SecretID:   secret.ID,
+			SecretName: secret.Spec.Name,
+		})
+	}
+	var configRefs []*swarmtypes.ConfigReference
+	for _, r := range c.HostConfig.Configs {
+		config, err := daemon.secrets.GetConfig(r.Name)
+		if err != nil {
+			return err
+		}
+		uid, gid, mode := fileReferenceOwnership(r)
+		configRefs = append(configRefs, &swarmtypes.ConfigReference{
+			File: &swarmtypes.ConfigReferenceFileTarget{
+				Name: fileReferenceTarget(r, containerConfigMountPath),
+				UID:  uid,
+				GID:  gid,
+				Mode: mode,
+			},
+			ConfigID:   config.ID,
+			ConfigName: config.Spec.Name,
+		})
+	}
+	c.SecretReferences = secretRefs
+	c.ConfigReferences = configRefs
+	return nil
+}
+
+func fileReferenceOwnership(r containertypes.FileReference) (uid, gid string, mode os.FileMode) {
+	uid, gid, mode = r.UID, r.GID, r.Mode
+	if uid == "" {
+		uid = "0"
+	}
+	if gid == "" {
+		gid = "0"
+	}
+	if mode == 0 {
+		mode = 0444
+	}
+	return uid, gid, mode
+}
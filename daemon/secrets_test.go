@@ -0,0 +1,164 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	swarmtypes "github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/secretstore"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+func newSecretsTestDaemon(t *testing.T, containers ...*container.Container) *Daemon {
+	t.Helper()
+	store, err := secretstore.New(t.TempDir(), bytes.Repeat([]byte{1}, 32))
+	assert.NilError(t, err)
+	d := &Daemon{
+		containers:    container.NewMemoryStore(),
+		secrets:       store,
+		EventsService: events.New(),
+	}
+	for _, c := range containers {
+		d.containers.Add(c.ID, c)
+	}
+	return d
+}
+
+func TestValidateFileReferences(t *testing.T) {
+	skip.If(t, isWindows, "secrets and configs are not supported for standalone containers on Windows")
+	d := newSecretsTestDaemon(t)
+	_, err := d.CreateSecret(swarmtypes.SecretSpec{Annotations: swarmtypes.Annotations{Name: "token"}, Data: []byte("s3cr3t")})
+	assert.NilError(t, err)
+	_, err = d.CreateConfig(swarmtypes.ConfigSpec{Annotations: swarmtypes.Annotations{Name: "app.conf"}, Data: []byte("debug=1")})
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name        string
+		hostConfig  containertypes.HostConfig
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			hostConfig: containertypes.HostConfig{
+				Secrets: []containertypes.FileReference{{Name: "token"}, {Name: "token", Target: "/etc/token", UID: "1000", Mode: 0400}},
+				Configs: []containertypes.FileReference{{Name: "app.conf", Target: "/etc/app.conf"}},
+			},
+		},
+		{
+			name:        "missing secret",
+			hostConfig:  containertypes.HostConfig{Secrets: []containertypes.FileReference{{Name: "password"}}},
+			expectedErr: "secret password not found",
+		},
+		{
+			name:        "missing config",
+			hostConfig:  containertypes.HostConfig{Configs: []containertypes.FileReference{{Name: "token"}}},
+			expectedErr: "config token not found",
+		},
+		{
+			name:        "invalid owner",
+			hostConfig:  containertypes.HostConfig{Secrets: []containertypes.FileReference{{Name: "token", GID: "wheel"}}},
+			expectedErr: `invalid owner "wheel" for secret token`,
+		},
+		{
+			name:        "invalid mode",
+			hostConfig:  containertypes.HostConfig{Secrets: []containertypes.FileReference{{Name: "token", Mode: 04755}}},
+			expectedErr: "invalid mode 4755 for secret token",
+		},
+		{
+			name: "duplicate target",
+			hostConfig: containertypes.HostConfig{
+				Secrets: []containertypes.FileReference{{Name: "token", Target: "/etc/app.conf"}},
+				Configs: []containertypes.FileReference{{Name: "app.conf", Target: "/etc/app.conf"}},
+			},
+			expectedErr: "config app.conf and token are both mounted at /etc/app.conf",
+		},
+		{
+			name:        "root target",
+			hostConfig:  containertypes.HostConfig{Configs: []containertypes.FileReference{{Name: "app.conf", Target: "/"}}},
+			expectedErr: "invalid target for config app.conf",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := d.validateFileReferences(&tc.hostConfig)
+			if tc.expectedErr == "" {
+				assert.Check(t, err)
+			} else {
+				assert.Check(t, is.Error(err, tc.expectedErr))
+			}
+		})
+	}
+
+	err = (&Daemon{}).validateFileReferences(&containertypes.HostConfig{Secrets: []containertypes.FileReference{{Name: "token"}}})
+	assert.Check(t, errdefs.IsUnavailable(err), "got: %v", err)
+	assert.Check(t, (&Daemon{}).validateFileReferences(&containertypes.HostConfig{}))
+}
+
+func TestResolveFileReferences(t *testing.T) {
+	c := &container.Container{
+		ID:   "1111111111",
+		Name: "/app",
+		HostConfig: &containertypes.HostConfig{
+			Secrets: []containertypes.FileReference{{Name: "token"}, {Name: "token", Target: "/etc/token", UID: "1000", GID: "1000", Mode: 0400}},
+			Configs: []containertypes.FileReference{{Name: "app.conf"}},
+		},
+	}
+	d := newSecretsTestDaemon(t, c)
+	secretID, err := d.CreateSecret(swarmtypes.SecretSpec{Annotations: swarmtypes.Annotations{Name: "token"}, Data: []byte("s3cr3t")})
+	assert.NilError(t, err)
+	configID, err := d.CreateConfig(swarmtypes.ConfigSpec{Annotations: swarmtypes.Annotations{Name: "app.conf"}, Data: []byte("debug=1")})
+	assert.NilError(t, err)
+
+	assert.NilError(t, d.resolveFileReferences(c))
+	assert.Check(t, is.DeepEqual(c.SecretReferences, []*swarmtypes.SecretReference{
+		{
+			File:       &swarmtypes.SecretReferenceFileTarget{Name: "/run/secrets/token", UID: "0", GID: "0", Mode: 0444},
+			SecretID:   secretID,
+			SecretName: "token",
+		},
+		{
+			File:       &swarmtypes.SecretReferenceFileTarget{Name: "/etc/token", UID: "1000", GID: "1000", Mode: 0400},
+			SecretID:   secretID,
+			SecretName: "token",
+		},
+	}))
+	assert.Check(t, is.DeepEqual(c.ConfigReferences, []*swarmtypes.ConfigReference{
+		{
+			File:       &swarmtypes.ConfigReferenceFileTarget{Name: "/app.conf", UID: "0", GID: "0", Mode: 0444},
+			ConfigID:   configID,
+			ConfigName: "app.conf",
+		},
+	}))
+
+	// Secrets and configs can't be removed while containers reference them.
+	err = d.RemoveSecret("token")
+	assert.Check(t, errdefs.IsConflict(err), "got: %v", err)
+	assert.Check(t, is.ErrorContains(err, "secret token is in use by the following containers: [app]"))
+	err = d.RemoveConfig(configID)
+	assert.Check(t, errdefs.IsConflict(err), "got: %v", err)
+
+	d.containers.Delete(c.ID)
+	assert.NilError(t, d.RemoveSecret("token"))
+	assert.NilError(t, d.RemoveConfig(configID))
+
+	// References to removed secrets fail to resolve.
+	err = d.resolveFileReferences(c)
+	assert.Check(t, errdefs.IsNotFound(err), "got: %v", err)
+}
+
+func TestSecretStoreNotConfigured(t *testing.T) {
+	d := &Daemon{}
+	_, err := d.GetSecrets(types.SecretListOptions{})
+	assert.Check(t, errdefs.IsUnavailable(err), "got: %v", err)
+	_, err = d.CreateConfig(swarmtypes.ConfigSpec{})
+	assert.Check(t, errdefs.IsUnavailable(err), "got: %v", err)
+	err = d.RemoveSecret("token")
+	assert.Check(t, errdefs.IsUnavailable(err), "got: %v", err)
+}
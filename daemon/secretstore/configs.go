@@ -0,0 +1,72 @@
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+)
+
+func (o *object) config() swarm.Config {
+	return swarm.Config{
+		ID: o.ID,
+		Meta: swarm.Meta{
+			Version:   swarm.Version{Index: o.Version},
+			CreatedAt: o.CreatedAt,
+			UpdatedAt: o.UpdatedAt,
+		},
+		Spec: swarm.ConfigSpec{
+			Annotations: swarm.Annotations{Name: o.Name, Labels: o.Labels},
+		},
+	}
+}
+
+// CreateConfig stores a new config and returns its ID.
+func (s *Store) CreateConfig(spec swarm.ConfigSpec) (string, error) {
+	if spec.Templating != nil {
+		return "", errdefs.InvalidParameter(errors.New("config templating is not supported"))
+	}
+	return s.create(kindConfig, spec.Name, spec.Labels, spec.Data, maxConfigSize)
+}
+
+// GetConfig returns the config with the given ID or name, including its
+// data.
+func (s *Store) GetConfig(idOrName string) (swarm.Config, error) {
+	o, err := s.get(kindConfig, idOrName)
+	if err != nil {
+		return swarm.Config{}, err
+	}
+	c := o.config()
+	if c.Spec.Data, err = s.open(kindConfig, &o); err != nil {
+		return swarm.Config{}, err
+	}
+	return c, nil
+}
+
+// ListConfigs returns the configs matching the filters, without their data.
+func (s *Store) ListConfigs(filter filters.Args) ([]swarm.Config, error) {
+	objects, err := s.list(kindConfig, filter)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]swarm.Config, 0, len(objects))
+	for _, o := range objects {
+		configs = append(configs, o.config())
+	}
+	return configs, nil
+}
+
+// UpdateConfig updates the labels of a config.
+func (s *Store) UpdateConfig(idOrName string, version uint64, spec swarm.ConfigSpec) error {
+	return s.update(kindConfig, idOrName, version, spec.Name, spec.Labels, spec.Data)
+}
+
+// RemoveConfig removes a config.
+func (s *Store) RemoveConfig(idOrName string) error {
+	return s.remove(kindConfig, idOrName)
+}
+
+// ConfigData returns the decrypted data of the config with the given ID.
+func (s *Store) ConfigData(id string) ([]byte, error) {
+	return s.data(kindConfig, id)
+}
@@ -0,0 +1,43 @@
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	keyFilePrefix    = "file:"
+	keyKeyringPrefix = "keyring:"
+)
+
+// LoadKey returns the encryption key configured by spec, which is either
+// "file:<path>" to read the key from a file, or "keyring:<description>" to
+// read it from a "user" key of the kernel keyring. The key material, which
+// should be at least 32 random bytes, is hashed into an AES-256 key.
+func LoadKey(spec string) ([]byte, error) {
+	var (
+		material []byte
+		err      error
+	)
+	switch {
+	case strings.HasPrefix(spec, keyFilePrefix):
+		material, err = ioutil.ReadFile(strings.TrimPrefix(spec, keyFilePrefix))
+	case strings.HasPrefix(spec, keyKeyringPrefix):
+		material, err = readKeyring(strings.TrimPrefix(spec, keyKeyringPrefix))
+	default:
+		return nil, errors.Errorf("invalid secret store key %q: must be file:<path> or keyring:<description>", spec)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read secret store key")
+	}
+	material = bytes.TrimSpace(material)
+	if len(material) == 0 {
+		return nil, errors.New("secret store key is empty")
+	}
+	key := sha256.Sum256(material)
+	return key[:], nil
+}
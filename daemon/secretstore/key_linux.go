@@ -0,0 +1,31 @@
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// readKeyring reads the payload of the "user" key with the given description
+// in the user keyring of the daemon, or in its session keyring.
+func readKeyring(description string) ([]byte, error) {
+	id, err := unix.KeyctlSearch(unix.KEY_SPEC_USER_KEYRING, "user", description, 0)
+	if err != nil {
+		id, err = unix.KeyctlSearch(unix.KEY_SPEC_SESSION_KEYRING, "user", description, 0)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "key %q not found in keyring", description)
+	}
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	if n < size {
+		buf = buf[:n]
+	}
+	return buf, nil
+}
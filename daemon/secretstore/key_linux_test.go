@@ -0,0 +1,25 @@
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestLoadKeyKeyring(t *testing.T) {
+	const description = "docker-secretstore-test"
+	id, err := unix.AddKey("user", description, []byte("0123456789abcdef0123456789abcdef"), unix.KEY_SPEC_SESSION_KEYRING)
+	if err != nil {
+		t.Skipf("cannot add key to the session keyring: %v", err)
+	}
+	defer unix.KeyctlInt(unix.KEYCTL_UNLINK, id, unix.KEY_SPEC_SESSION_KEYRING, 0, 0)
+
+	key, err := LoadKey("keyring:" + description)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(key, 32))
+
+	_, err = LoadKey("keyring:docker-secretstore-missing")
+	assert.Check(t, is.ErrorContains(err, "not found in keyring"))
+}
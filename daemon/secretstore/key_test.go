@@ -0,0 +1,42 @@
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestLoadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	assert.NilError(t, ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef\n"), 0600))
+
+	key, err := LoadKey("file:" + keyFile)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(key, 32))
+
+	// Trailing whitespace is not part of the key.
+	assert.NilError(t, ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600))
+	key2, err := LoadKey("file:" + keyFile)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(key, key2))
+
+	_, err = New(dir, key)
+	assert.NilError(t, err)
+}
+
+func TestLoadKeyErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	assert.NilError(t, ioutil.WriteFile(empty, []byte("\n"), 0600))
+
+	_, err := LoadKey(filepath.Join(dir, "key"))
+	assert.Check(t, is.ErrorContains(err, "must be file:<path> or keyring:<description>"))
+	_, err = LoadKey("file:" + filepath.Join(dir, "missing"))
+	assert.Check(t, is.ErrorContains(err, "failed to read secret store key"))
+	_, err = LoadKey("file:" + empty)
+	assert.Check(t, is.Error(err, "secret store key is empty"))
+}
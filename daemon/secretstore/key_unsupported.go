@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import "github.com/pkg/errors"
+
+func readKeyring(description string) ([]byte, error) {
+	return nil, errors.New("the kernel keyring is not supported on this platform")
+}
@@ -0,0 +1,71 @@
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+)
+
+func (o *object) secret() swarm.Secret {
+	return swarm.Secret{
+		ID: o.ID,
+		Meta: swarm.Meta{
+			Version:   swarm.Version{Index: o.Version},
+			CreatedAt: o.CreatedAt,
+			UpdatedAt: o.UpdatedAt,
+		},
+		Spec: swarm.SecretSpec{
+			Annotations: swarm.Annotations{Name: o.Name, Labels: o.Labels},
+		},
+	}
+}
+
+// CreateSecret stores a new secret and returns its ID.
+func (s *Store) CreateSecret(spec swarm.SecretSpec) (string, error) {
+	if spec.Driver != nil {
+		return "", errdefs.InvalidParameter(errors.New("secret drivers are not supported"))
+	}
+	if spec.Templating != nil {
+		return "", errdefs.InvalidParameter(errors.New("secret templating is not supported"))
+	}
+	return s.create(kindSecret, spec.Name, spec.Labels, spec.Data, maxSecretSize)
+}
+
+// GetSecret returns the secret with the given ID or name. As with swarm, the
+// data of the secret is not returned.
+func (s *Store) GetSecret(idOrName string) (swarm.Secret, error) {
+	o, err := s.get(kindSecret, idOrName)
+	if err != nil {
+		return swarm.Secret{}, err
+	}
+	return o.secret(), nil
+}
+
+// ListSecrets returns the secrets matching the filters, without their data.
+func (s *Store) ListSecrets(filter filters.Args) ([]swarm.Secret, error) {
+	objects, err := s.list(kindSecret, filter)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]swarm.Secret, 0, len(objects))
+	for _, o := range objects {
+		secrets = append(secrets, o.secret())
+	}
+	return secrets, nil
+}
+
+// UpdateSecret updates the labels of a secret.
+func (s *Store) UpdateSecret(idOrName string, version uint64, spec swarm.SecretSpec) error {
+	return s.update(kindSecret, idOrName, version, spec.Name, spec.Labels, spec.Data)
+}
+
+// RemoveSecret removes a secret.
+func (s *Store) RemoveSecret(idOrName string) error {
+	return s.remove(kindSecret, idOrName)
+}
+
+// SecretData returns the decrypted data of the secret with the given ID.
+func (s *Store) SecretData(id string) ([]byte, error) {
+	return s.data(kindSecret, id)
+}
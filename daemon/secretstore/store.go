@@ -0,0 +1,300 @@
+// Package secretstore stores the secrets and configs of standalone
+// containers on disk, encrypted with a key from a file or the kernel keyring.
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	kindSecret = "secret"
+	kindConfig = "config"
+
+	// maxSecretSize and maxConfigSize are the sizes swarm limits secrets
+	// and configs to.
+	maxSecretSize = 500 * 1024
+	maxConfigSize = 1000 * 1024
+)
+
+var (
+	validName = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[a-zA-Z0-9-_.]*[a-zA-Z0-9])?$`)
+
+	acceptedFilters = map[string]bool{
+		"id":    true,
+		"label": true,
+		"name":  true,
+		"names": true,
+	}
+)
+
+// object is a secret or a config, as stored on disk.
+type object struct {
+	ID        string
+	Version   uint64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string
+	Labels    map[string]string `json:",omitempty"`
+	// Data is the nonce followed by the encrypted data.
+	Data []byte
+}
+
+// Store stores secrets and configs. Each object is a file in the directory
+// of its kind, with its data encrypted with AES-GCM.
+type Store struct {
+	root string
+	aead cipher.AEAD
+
+	mu      sync.Mutex
+	objects map[string]map[string]*object // kind -> ID -> object
+}
+
+// New creates a store in root using the given AES key, and loads the objects
+// it already contains.
+func New(root string, key []byte) (*Store, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid secret store key")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{
+		root:    root,
+		aead:    aead,
+		objects: make(map[string]map[string]*object),
+	}
+	for _, kind := range []string{kindSecret, kindConfig} {
+		if err := s.load(kind); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) dir(kind string) string {
+	return filepath.Join(s.root, kind+"s")
+}
+
+func (s *Store) load(kind string) error {
+	dir := s.dir(kind)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	s.objects[kind] = make(map[string]*object)
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return err
+		}
+		var o object
+		if err := json.Unmarshal(b, &o); err != nil || o.ID+".json" != f.Name() {
+			logrus.WithError(err).WithField("file", f.Name()).Warnf("ignoring invalid %s", kind)
+			continue
+		}
+		s.objects[kind][o.ID] = &o
+	}
+	return nil
+}
+
+func (s *Store) save(kind string, o *object) error {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	return ioutils.AtomicWriteFile(filepath.Join(s.dir(kind), o.ID+".json"), b, 0600)
+}
+
+// seal encrypts data, authenticating the kind and ID of the object so that
+// the data of an object can't be swapped with the data of another one.
+func (s *Store) seal(kind, id string, data []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(data)+s.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, data, []byte(kind+"/"+id)), nil
+}
+
+func (s *Store) open(kind string, o *object) ([]byte, error) {
+	n := s.aead.NonceSize()
+	if len(o.Data) < n {
+		return nil, errors.Errorf("%s %s is corrupted", kind, o.ID)
+	}
+	data, err := s.aead.Open(nil, o.Data[:n], o.Data[n:], []byte(kind+"/"+o.ID))
+	if err != nil {
+		return nil, errors.Errorf("failed to decrypt %s %s: the secret store key may have changed", kind, o.ID)
+	}
+	return data, nil
+}
+
+// find returns the object with the given ID or name. The caller must hold
+// s.mu.
+func (s *Store) find(kind, idOrName string) (*object, error) {
+	objects := s.objects[kind]
+	if o, ok := objects[idOrName]; ok {
+		return o, nil
+	}
+	for _, o := range objects {
+		if o.Name == idOrName {
+			return o, nil
+		}
+	}
+	return nil, errdefs.NotFound(errors.Errorf("%s %s not found", kind, idOrName))
+}
+
+func (s *Store) create(kind, name string, labels map[string]string, data []byte, maxSize int) (string, error) {
+	if !validName.MatchString(name) || len(name) > 64 {
+		return "", errdefs.InvalidParameter(errors.Errorf("invalid %s name %q", kind, name))
+	}
+	if len(data) == 0 || len(data) > maxSize {
+		return "", errdefs.InvalidParameter(errors.Errorf("%s data must be larger than 0 and less than %d bytes", kind, maxSize))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.find(kind, name); err == nil {
+		return "", errdefs.Conflict(errors.Errorf("%s %s already exists", kind, name))
+	}
+	now := time.Now().UTC()
+	o := &object{
+		ID:        stringid.GenerateRandomID(),
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      name,
+		Labels:    labels,
+	}
+	var err error
+	if o.Data, err = s.seal(kind, o.ID, data); err != nil {
+		return "", err
+	}
+	if err := s.save(kind, o); err != nil {
+		return "", err
+	}
+	s.objects[kind][o.ID] = o
+	return o.ID, nil
+}
+
+func (s *Store) get(kind, idOrName string) (object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.find(kind, idOrName)
+	if err != nil {
+		return object{}, err
+	}
+	return *o, nil
+}
+
+func (s *Store) list(kind string, filter filters.Args) ([]object, error) {
+	if err := filter.Validate(acceptedFilters); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []object
+	for _, o := range s.objects[kind] {
+		if !filter.ExactMatch("names", o.Name) ||
+			!filter.FuzzyMatch("name", o.Name) ||
+			!filter.FuzzyMatch("id", o.ID) ||
+			!filter.MatchKVList("label", o.Labels) {
+			continue
+		}
+		list = append(list, *o)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// update changes the labels of an object. As with swarm, the name and the
+// data of an object can't be changed.
+func (s *Store) update(kind, idOrName string, version uint64, name string, labels map[string]string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, err := s.find(kind, idOrName)
+	if err != nil {
+		return err
+	}
+	if version != o.Version {
+		return errdefs.Conflict(errors.Errorf("update out of sequence: %s %s is at version %d", kind, o.ID, o.Version))
+	}
+	if name != o.Name {
+		return errdefs.InvalidParameter(errors.Errorf("only updates to the labels of a %s are allowed", kind))
+	}
+	if len(data) > 0 {
+		current, err := s.open(kind, o)
+		if err != nil {
+			return err
+		}
+		if string(current) != string(data) {
+			return errdefs.InvalidParameter(errors.Errorf("only updates to the labels of a %s are allowed", kind))
+		}
+	}
+
+	updated := *o
+	updated.Version++
+	updated.UpdatedAt = time.Now().UTC()
+	updated.Labels = labels
+	if err := s.save(kind, &updated); err != nil {
+		return err
+	}
+	s.objects[kind][o.ID] = &updated
+	return nil
+}
+
+func (s *Store) remove(kind, idOrName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, err := s.find(kind, idOrName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.dir(kind), o.ID+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(s.objects[kind], o.ID)
+	return nil
+}
+
+func (s *Store) data(kind, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.objects[kind][id]
+	if !ok {
+		return nil, errdefs.NotFound(errors.Errorf("%s %s not found", kind, id))
+	}
+	return s.open(kind, o)
+}
@@ -0,0 +1,192 @@
+package secretstore // import "github.com/docker/docker/daemon/secretstore"
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestStore(t *testing.T, root string, key byte) *Store {
+	t.Helper()
+	s, err := New(root, bytes.Repeat([]byte{key}, 32))
+	assert.NilError(t, err)
+	return s
+}
+
+func TestSecrets(t *testing.T) {
+	root := t.TempDir()
+	s := newTestStore(t, root, 1)
+
+	id, err := s.CreateSecret(swarm.SecretSpec{
+		Annotations: swarm.Annotations{Name: "db-password", Labels: map[string]string{"app": "db"}},
+		Data:        []byte("hunter2"),
+	})
+	assert.NilError(t, err)
+
+	_, err = s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "db-password"}, Data: []byte("x")})
+	assert.Check(t, errdefs.IsConflict(err), "got: %v", err)
+	_, err = s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "-invalid"}, Data: []byte("x")})
+	assert.Check(t, errdefs.IsInvalidParameter(err), "got: %v", err)
+	_, err = s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "empty"}})
+	assert.Check(t, errdefs.IsInvalidParameter(err), "got: %v", err)
+	_, err = s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "driver"}, Driver: &swarm.Driver{Name: "vault"}})
+	assert.Check(t, errdefs.IsInvalidParameter(err), "got: %v", err)
+
+	secret, err := s.GetSecret("db-password")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(secret.ID, id))
+	assert.Check(t, is.Equal(secret.Version.Index, uint64(1)))
+	assert.Check(t, is.DeepEqual(secret.Spec.Labels, map[string]string{"app": "db"}))
+	assert.Check(t, is.Len(secret.Spec.Data, 0))
+
+	data, err := s.SecretData(id)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(data), "hunter2"))
+
+	// The data is encrypted on disk.
+	b, err := ioutil.ReadFile(filepath.Join(root, "secrets", id+".json"))
+	assert.NilError(t, err)
+	assert.Check(t, !bytes.Contains(b, []byte("hunter2")))
+	assert.Check(t, !bytes.Contains(b, []byte("aHVudGVyMg"))) // base64
+	fi, err := os.Stat(filepath.Join(root, "secrets", id+".json"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(fi.Mode().Perm(), os.FileMode(0600)))
+
+	// Only the labels can be updated.
+	spec := secret.Spec
+	spec.Labels = map[string]string{"app": "db", "env": "prod"}
+	err = s.UpdateSecret(id, 2, spec)
+	assert.Check(t, errdefs.IsConflict(err), "got: %v", err)
+	spec.Data = []byte("changed")
+	err = s.UpdateSecret(id, 1, spec)
+	assert.Check(t, errdefs.IsInvalidParameter(err), "got: %v", err)
+	spec.Data = []byte("hunter2")
+	assert.NilError(t, s.UpdateSecret(id, 1, spec))
+
+	secret, err = s.GetSecret(id)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(secret.Version.Index, uint64(2)))
+	assert.Check(t, is.DeepEqual(secret.Spec.Labels, map[string]string{"app": "db", "env": "prod"}))
+
+	assert.NilError(t, s.RemoveSecret("db-password"))
+	_, err = s.GetSecret(id)
+	assert.Check(t, errdefs.IsNotFound(err), "got: %v", err)
+	_, err = os.Stat(filepath.Join(root, "secrets", id+".json"))
+	assert.Check(t, os.IsNotExist(err))
+	err = s.RemoveSecret(id)
+	assert.Check(t, errdefs.IsNotFound(err), "got: %v", err)
+}
+
+func TestConfigs(t *testing.T) {
+	s := newTestStore(t, t.TempDir(), 1)
+
+	id, err := s.CreateConfig(swarm.ConfigSpec{
+		Annotations: swarm.Annotations{Name: "nginx.conf"},
+		Data:        []byte("worker_processes 1;"),
+	})
+	assert.NilError(t, err)
+
+	// Configs and secrets have separate namespaces.
+	_, err = s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "nginx.conf"}, Data: []byte("x")})
+	assert.NilError(t, err)
+	_, err = s.SecretData(id)
+	assert.Check(t, errdefs.IsNotFound(err), "got: %v", err)
+
+	config, err := s.GetConfig("nginx.conf")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(config.ID, id))
+	assert.Check(t, is.Equal(string(config.Spec.Data), "worker_processes 1;"))
+
+	configs, err := s.ListConfigs(filters.NewArgs())
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(configs, 1))
+
+	assert.NilError(t, s.RemoveConfig(id))
+	_, err = s.ConfigData(id)
+	assert.Check(t, errdefs.IsNotFound(err), "got: %v", err)
+}
+
+func TestListFilters(t *testing.T) {
+	s := newTestStore(t, t.TempDir(), 1)
+
+	var ids []string
+	for _, spec := range []swarm.SecretSpec{
+		{Annotations: swarm.Annotations{Name: "foo", Labels: map[string]string{"env": "prod"}}, Data: []byte("1")},
+		{Annotations: swarm.Annotations{Name: "foobar", Labels: map[string]string{"env": "dev"}}, Data: []byte("2")},
+		{Annotations: swarm.Annotations{Name: "bar"}, Data: []byte("3")},
+	} {
+		id, err := s.CreateSecret(spec)
+		assert.NilError(t, err)
+		ids = append(ids, id)
+	}
+
+	testCases := []struct {
+		filter   filters.Args
+		expected []string
+	}{
+		{filters.NewArgs(), []string{"foo", "foobar", "bar"}},
+		{filters.NewArgs(filters.Arg("name", "foo")), []string{"foo", "foobar"}},
+		{filters.NewArgs(filters.Arg("names", "foo")), []string{"foo"}},
+		{filters.NewArgs(filters.Arg("label", "env")), []string{"foo", "foobar"}},
+		{filters.NewArgs(filters.Arg("label", "env=dev")), []string{"foobar"}},
+		{filters.NewArgs(filters.Arg("id", ids[2][:12])), []string{"bar"}},
+	}
+	for _, tc := range testCases {
+		secrets, err := s.ListSecrets(tc.filter)
+		assert.NilError(t, err)
+		var names []string
+		for _, secret := range secrets {
+			names = append(names, secret.Spec.Name)
+		}
+		assert.Check(t, is.DeepEqual(names, tc.expected))
+	}
+
+	_, err := s.ListSecrets(filters.NewArgs(filters.Arg("driver", "vault")))
+	assert.Check(t, errdefs.IsInvalidParameter(err), "got: %v", err)
+}
+
+func TestReload(t *testing.T) {
+	root := t.TempDir()
+	s := newTestStore(t, root, 1)
+	id, err := s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "token"}, Data: []byte("s3cr3t")})
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(root, "secrets", "garbage.json"), []byte("{"), 0600))
+
+	s = newTestStore(t, root, 1)
+	secrets, err := s.ListSecrets(filters.NewArgs())
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(secrets, 1))
+	data, err := s.SecretData(id)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(data), "s3cr3t"))
+
+	// The metadata is readable with another key, but the data is not.
+	s = newTestStore(t, root, 2)
+	_, err = s.GetSecret("token")
+	assert.NilError(t, err)
+	_, err = s.SecretData(id)
+	assert.Check(t, is.ErrorContains(err, "the secret store key may have changed"))
+}
+
+func TestSwappedData(t *testing.T) {
+	root := t.TempDir()
+	s := newTestStore(t, root, 1)
+	id1, err := s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "one"}, Data: []byte("1")})
+	assert.NilError(t, err)
+	id2, err := s.CreateSecret(swarm.SecretSpec{Annotations: swarm.Annotations{Name: "two"}, Data: []byte("2")})
+	assert.NilError(t, err)
+
+	s.mu.Lock()
+	s.objects[kindSecret][id1].Data = s.objects[kindSecret][id2].Data
+	s.mu.Unlock()
+	_, err = s.SecretData(id1)
+	assert.Check(t, is.ErrorContains(err, "failed to decrypt secret"))
+}
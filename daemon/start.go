@@ -150,6 +150,10 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 		return err
 	}
 
+	if err := daemon.startEgressPolicy(container); err != nil {
+		return err
+	}
+
 	spec, err := daemon.createSpec(container)
 	if err != nil {
 		return errdefs.System(err)
@@ -211,6 +215,12 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 	daemon.setStateCounter(container)
 
 	daemon.initHealthMonitor(container)
+	if t := daemon.statsCollector.Traffic(); t != nil {
+		// The interfaces of the container were just created, and their
+		// counters start from zero.
+		t.Forget(container.ID)
+	}
+	daemon.statsCollector.Track(container)
 
 	if err := container.CheckpointTo(daemon.containersReplica); err != nil {
 		logrus.WithError(err).WithField("container", container.ID).
@@ -226,6 +236,8 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 // Cleanup releases any network resources allocated to the container along with any rules
 // around how containers are linked together.  It also unmounts the container's root filesystem.
 func (daemon *Daemon) Cleanup(container *container.Container) {
+	daemon.stopEgressPolicy(container)
+	daemon.forgetHotplugDevices(container)
 	daemon.releaseNetwork(container)
 
 	if err := container.UnmountIpcMount(); err != nil {
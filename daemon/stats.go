@@ -136,6 +136,43 @@ func (daemon *Daemon) ContainerStats(ctx context.Context, prefixOrName string, c
 	}
 }
 
+// ContainerStatsHistory returns the recorded history of the stats of a
+// container.
+func (daemon *Daemon) ContainerStatsHistory(prefixOrName string, config *backend.ContainerStatsHistoryConfig) (*types.StatsHistory, error) {
+	ctr, err := daemon.GetContainer(prefixOrName)
+	if err != nil {
+		return nil, err
+	}
+	h := daemon.statsCollector.History()
+	if h == nil {
+		return nil, errdefs.Unavailable(errors.New("stats history is not enabled"))
+	}
+	history := h.Query(ctr.ID, config.Since, config.Until, config.Resolution)
+	return &history, nil
+}
+
+// ContainersNetworkTraffic returns the network traffic accounted under each
+// key.
+func (daemon *Daemon) ContainersNetworkTraffic() ([]types.NetworkTraffic, error) {
+	t := daemon.statsCollector.Traffic()
+	if t == nil {
+		return nil, errNetworkTrafficNotEnabled
+	}
+	return t.List(), nil
+}
+
+// ContainersNetworkTrafficReset discards the network traffic accounted under
+// key, or under all keys if key is empty.
+func (daemon *Daemon) ContainersNetworkTrafficReset(key string) error {
+	t := daemon.statsCollector.Traffic()
+	if t == nil {
+		return errNetworkTrafficNotEnabled
+	}
+	return t.Reset(key)
+}
+
+var errNetworkTrafficNotEnabled = errdefs.Unavailable(errors.New("network traffic accounting is not enabled"))
+
 func (daemon *Daemon) subscribeToContainerStats(c *container.Container) chan interface{} {
 	return daemon.statsCollector.Collect(c)
 }
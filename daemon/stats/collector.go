@@ -19,6 +19,16 @@ type Collector struct {
 	interval   time.Duration
 	publishers map[*container.Container]*pubsub.Publisher
 	bufReader  *bufio.Reader
+
+	history *History
+	latest  *Latest
+	traffic *Traffic
+	// tracked are the containers recorded in the history, the latest
+	// samples or the network traffic, with the time of their last sample.
+	tracked map[*container.Container]time.Time
+	// wake interrupts the wait for the next collection when a subscriber
+	// or a tracked container is added.
+	wake chan struct{}
 }
 
 // NewCollector creates a stats collector that will poll the supervisor with the specified interval
@@ -28,6 +38,8 @@ func NewCollector(supervisor supervisor, interval time.Duration) *Collector {
 		supervisor: supervisor,
 		publishers: make(map[*container.Container]*pubsub.Publisher),
 		bufReader:  bufio.NewReaderSize(nil, 128),
+		tracked:    make(map[*container.Container]time.Time),
+		wake:       make(chan struct{}, 1),
 	}
 	s.cond = sync.NewCond(&s.m)
 	return s
@@ -52,18 +64,120 @@ func (s *Collector) Collect(c *container.Container) chan interface{} {
 	}
 
 	s.cond.Broadcast()
+	s.wakeUp()
 	return publisher.Subscribe()
 }
 
+// SetHistory makes the collector record the stats of the containers added
+// with Track in h.
+func (s *Collector) SetHistory(h *History) {
+	s.m.Lock()
+	s.history = h
+	s.m.Unlock()
+}
+
+// History returns the history the stats are recorded in, or nil if the
+// history is not enabled.
+func (s *Collector) History() *History {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.history
+}
+
+// SetLatest makes the collector keep the latest stats of the containers
+// added with Track in l.
+func (s *Collector) SetLatest(l *Latest) {
+	s.m.Lock()
+	s.latest = l
+	s.m.Unlock()
+}
+
+// Latest returns the latest stats of the tracked containers, or nil if
+// they are not kept.
+func (s *Collector) Latest() *Latest {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.latest
+}
+
+// SetTraffic makes the collector account the network traffic of the
+// containers added with Track in t.
+func (s *Collector) SetTraffic(t *Traffic) {
+	s.m.Lock()
+	s.traffic = t
+	s.m.Unlock()
+}
+
+// Traffic returns the network traffic accounting, or nil if it is not
+// enabled.
+func (s *Collector) Traffic() *Traffic {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.traffic
+}
+
+// Track adds a running container to the history, the latest samples and
+// the network traffic accounting, until it stops. It does nothing if none
+// of them is enabled.
+func (s *Collector) Track(c *container.Container) {
+	s.cond.L.Lock()
+	defer s.cond.L.Unlock()
+	if s.history == nil && s.latest == nil && s.traffic == nil {
+		return
+	}
+	if _, exists := s.tracked[c]; !exists {
+		s.tracked[c] = time.Time{}
+	}
+	s.cond.Broadcast()
+	s.wakeUp()
+}
+
+// wakeUp makes Run collect the stats without waiting for the next interval.
+func (s *Collector) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
 // StopCollection closes the channels for all subscribers and removes
-// the container from metrics collection.
+// the container from metrics collection, from the history and from the
+// latest samples. The traffic it accounted is kept.
 func (s *Collector) StopCollection(c *container.Container) {
 	s.m.Lock()
 	if publisher, exists := s.publishers[c]; exists {
 		publisher.Close()
 		delete(s.publishers, c)
 	}
+	delete(s.tracked, c)
+	history, latest, traffic := s.history, s.latest, s.traffic
 	s.m.Unlock()
+
+	if history != nil {
+		history.Remove(c.ID)
+	}
+	if latest != nil {
+		latest.Remove(c.ID)
+	}
+	if traffic != nil {
+		traffic.Forget(c.ID)
+	}
+}
+
+// trackInterval returns the interval at which the tracked containers are
+// sampled. The caller must hold s.m.
+func (s *Collector) trackInterval() time.Duration {
+	var interval time.Duration
+	if s.history != nil {
+		interval = s.history.Interval()
+	}
+	if s.latest != nil && (interval == 0 || s.latest.Interval() < interval) {
+		interval = s.latest.Interval()
+	}
+	if s.traffic != nil && (interval == 0 || s.traffic.Interval() < interval) {
+		interval = s.traffic.Interval()
+	}
+	return interval
 }
 
 // Unsubscribe removes a specific subscriber from receiving updates for a container's stats.
@@ -84,6 +198,9 @@ func (s *Collector) Run() {
 	type publishersPair struct {
 		container *container.Container
 		publisher *pubsub.Publisher
+		// record is set if the stats must be added to the history, the
+		// latest samples and the network traffic.
+		record bool
 	}
 	// we cannot determine the capacity here.
 	// it will grow enough in first iteration
@@ -91,7 +208,7 @@ func (s *Collector) Run() {
 
 	for {
 		s.cond.L.Lock()
-		for len(s.publishers) == 0 {
+		for len(s.publishers) == 0 && len(s.tracked) == 0 {
 			s.cond.Wait()
 		}
 
@@ -101,7 +218,42 @@ func (s *Collector) Run() {
 
 		for container, publisher := range s.publishers {
 			// copy pointers here to release the lock ASAP
-			pairs = append(pairs, publishersPair{container, publisher})
+			pairs = append(pairs, publishersPair{container: container, publisher: publisher})
+		}
+
+		// Subscribers get the stats every interval, while the tracked
+		// containers are only sampled when due, so that the collector
+		// does not wake up every interval for them alone.
+		wait := time.Duration(-1)
+		if len(pairs) > 0 {
+			wait = s.interval
+		}
+		history, latest, traffic := s.history, s.latest, s.traffic
+		if interval := s.trackInterval(); interval > 0 {
+			now := time.Now()
+			for container, last := range s.tracked {
+				if d := now.Sub(last); d < interval {
+					if wait < 0 || interval-d < wait {
+						wait = interval - d
+					}
+					continue
+				}
+				if wait < 0 || interval < wait {
+					wait = interval
+				}
+				s.tracked[container] = now
+				found := false
+				for i := range pairs {
+					if pairs[i].container == container {
+						pairs[i].record = true
+						found = true
+						break
+					}
+				}
+				if !found {
+					pairs = append(pairs, publishersPair{container: container, record: true})
+				}
+			}
 		}
 
 		s.cond.L.Unlock()
@@ -129,9 +281,39 @@ func (s *Collector) Run() {
 				stats.CPUStats.SystemUsage = systemUsage
 				stats.CPUStats.OnlineCPUs = onlineCPUs
 
-				pair.publisher.Publish(*stats)
+				if pair.publisher != nil {
+					pair.publisher.Publish(*stats)
+				}
+				if pair.record {
+					stats.Name = pair.container.Name
+					stats.ID = pair.container.ID
+					if history != nil {
+						history.Add(pair.container.ID, *stats)
+					}
+					if latest != nil {
+						latest.Add(pair.container.ID, *stats)
+					}
+					if traffic != nil {
+						traffic.Add(pair.container, *stats)
+					}
+				}
 
 			case notRunningErr, notFoundErr:
+				if pair.record {
+					// Stop sampling the container until it is started again.
+					s.m.Lock()
+					delete(s.tracked, pair.container)
+					s.m.Unlock()
+					if latest != nil {
+						latest.Remove(pair.container.ID)
+					}
+					if traffic != nil {
+						traffic.Forget(pair.container.ID)
+					}
+				}
+				if pair.publisher == nil {
+					continue
+				}
 				// publish empty stats containing only name and ID if not running or not found
 				pair.publisher.Publish(types.StatsJSON{
 					Name: pair.container.Name,
@@ -140,6 +322,9 @@ func (s *Collector) Run() {
 
 			default:
 				logrus.Errorf("collecting stats for %s: %v", pair.container.ID, err)
+				if pair.publisher == nil {
+					continue
+				}
 				pair.publisher.Publish(types.StatsJSON{
 					Name: pair.container.Name,
 					ID:   pair.container.ID,
@@ -147,7 +332,15 @@ func (s *Collector) Run() {
 			}
 		}
 
-		time.Sleep(s.interval)
+		if wait < s.interval {
+			wait = s.interval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		}
 	}
 }
 
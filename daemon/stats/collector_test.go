@@ -0,0 +1,77 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+	"gotest.tools/v3/skip"
+)
+
+type fakeSupervisor struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func (s *fakeSupervisor) setRunning(id string, running bool) {
+	s.mu.Lock()
+	s.running[id] = running
+	s.mu.Unlock()
+}
+
+func (s *fakeSupervisor) GetContainerStats(c *container.Container) (*types.StatsJSON, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running[c.ID] {
+		return nil, errdefs.Conflict(errors.Errorf("container %s is not running", c.ID))
+	}
+	var st types.StatsJSON
+	st.Read = time.Now()
+	st.MemoryStats.Usage = 42
+	return &st, nil
+}
+
+func TestCollectorLatest(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "the system CPU usage is not available on Windows")
+	c := &container.Container{ID: "abc", Name: "/abc"}
+	supervisor := &fakeSupervisor{running: map[string]bool{"abc": true}}
+	s := NewCollector(supervisor, 10*time.Millisecond)
+
+	// Containers are not tracked if nothing records their stats.
+	s.Track(c)
+	assert.Check(t, is.Len(s.tracked, 0))
+
+	latest := NewLatest(10 * time.Millisecond)
+	s.SetLatest(latest)
+	assert.Check(t, s.Latest() == latest)
+	s.Track(c)
+	go s.Run()
+
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if _, ok := latest.Get(c.ID); ok {
+			return poll.Success()
+		}
+		return poll.Continue("no stats sampled yet")
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+
+	st, _ := latest.Get(c.ID)
+	assert.Check(t, is.Equal(st.ID, c.ID))
+	assert.Check(t, is.Equal(st.MemoryStats.Usage, uint64(42)))
+
+	// The sample is discarded once the container stops.
+	supervisor.setRunning(c.ID, false)
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if _, ok := latest.Get(c.ID); !ok {
+			return poll.Success()
+		}
+		return poll.Continue("stats of the stopped container are still kept")
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+}
@@ -0,0 +1,557 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultHistoryPersistInterval is the default interval at which the
+// history is saved to disk.
+const DefaultHistoryPersistInterval = 5 * time.Minute
+
+// HistoryTier is a level of downsampling of the stats history: samples are
+// kept Resolution apart, for Retention.
+type HistoryTier struct {
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+// DefaultHistoryTiers keeps samples 10 seconds apart for an hour, and a
+// minute apart for a day.
+var DefaultHistoryTiers = []HistoryTier{
+	{Resolution: 10 * time.Second, Retention: time.Hour},
+	{Resolution: time.Minute, Retention: 24 * time.Hour},
+}
+
+// History is a bounded in-memory record of the stats of containers,
+// downsampled over time, and optionally saved to disk.
+//
+// Only the CPU, memory, block I/O, pids and network counters of the stats
+// are kept. Each container is saved to a file the new samples are appended
+// to, which is rewritten once it holds about twice as many samples as the
+// history keeps.
+type History struct {
+	mu         sync.Mutex
+	tiers      []HistoryTier
+	containers map[string][]*ring
+	dir        string
+	// pending are the samples of each container not saved yet, and saved
+	// the number of samples in the file of each container.
+	pending map[string][]historySample
+	saved   map[string]int
+
+	// fileMu serializes the writes and removals of the files.
+	fileMu sync.Mutex
+
+	closed chan struct{}
+	done   chan struct{}
+}
+
+// NewHistory returns a history keeping samples for each of the given tiers,
+// ordered from the finest to the coarsest resolution. If dir is not empty,
+// the history is loaded from it, and saved to it every persistInterval and
+// on Close.
+func NewHistory(tiers []HistoryTier, dir string, persistInterval time.Duration) (*History, error) {
+	if len(tiers) == 0 {
+		tiers = DefaultHistoryTiers
+	}
+	h := &History{
+		tiers:      tiers,
+		containers: make(map[string][]*ring),
+		dir:        dir,
+		pending:    make(map[string][]historySample),
+		saved:      make(map[string]int),
+		closed:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if dir == "" {
+		close(h.done)
+		return h, nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create stats history directory")
+	}
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	if persistInterval <= 0 {
+		persistInterval = DefaultHistoryPersistInterval
+	}
+	go h.run(persistInterval)
+	return h, nil
+}
+
+// Interval returns the interval at which samples should be added.
+func (h *History) Interval() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.tiers[0].Resolution
+}
+
+// SetTiers changes the tiers of the history. The samples already recorded
+// are downsampled again to the new tiers, and those beyond the retention of
+// the coarsest tier are discarded.
+func (h *History) SetTiers(tiers []HistoryTier) {
+	if len(tiers) == 0 {
+		tiers = DefaultHistoryTiers
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tiers = tiers
+	for id, rings := range h.containers {
+		samples := merge(rings)
+		rings = h.newRings()
+		for _, s := range samples {
+			for _, r := range rings {
+				r.add(s)
+			}
+		}
+		h.containers[id] = rings
+	}
+}
+
+// Add records a sample of the stats of container id.
+func (h *History) Add(id string, stats types.StatsJSON) {
+	s := newSample(stats)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rings, ok := h.containers[id]
+	if !ok {
+		rings = h.newRings()
+		h.containers[id] = rings
+	}
+	for _, r := range rings {
+		r.add(s)
+	}
+	if h.dir != "" {
+		h.pending[id] = append(h.pending[id], s)
+	}
+}
+
+// Remove discards the history of container id.
+func (h *History) Remove(id string) {
+	h.mu.Lock()
+	delete(h.containers, id)
+	delete(h.pending, id)
+	delete(h.saved, id)
+	h.mu.Unlock()
+
+	if h.dir != "" {
+		h.fileMu.Lock()
+		defer h.fileMu.Unlock()
+		if err := os.Remove(h.path(id)); err != nil && !os.IsNotExist(err) {
+			logrus.WithError(err).WithField("container", id).Warn("failed to remove stats history")
+		}
+	}
+}
+
+// RemoveExcept discards the history of the containers for which keep
+// returns false, such as containers removed while the daemon was stopped.
+func (h *History) RemoveExcept(keep func(id string) bool) {
+	h.mu.Lock()
+	var ids []string
+	for id := range h.containers {
+		if !keep(id) {
+			ids = append(ids, id)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, id := range ids {
+		h.Remove(id)
+	}
+}
+
+// Query returns the samples of container id taken between since and until,
+// which are ignored when zero. The samples are taken from the finest tier
+// that goes back to since, and downsampled further if resolution is
+// coarser than the resolution of that tier.
+func (h *History) Query(id string, since, until time.Time, resolution time.Duration) types.StatsHistory {
+	h.mu.Lock()
+	rings := h.containers[id]
+	var (
+		r       *ring
+		samples []historySample
+		now     = time.Now()
+	)
+	for i, candidate := range rings {
+		list := candidate.list(now.Add(-h.tiers[i].Retention))
+		r, samples = candidate, list
+		if !since.IsZero() && len(list) > 0 && !list[0].Read.After(since) {
+			break
+		}
+	}
+	result := types.StatsHistory{Resolution: h.tiers[len(h.tiers)-1].Resolution}
+	h.mu.Unlock()
+
+	if r != nil {
+		result.Resolution = r.resolution
+	}
+	if resolution > result.Resolution {
+		result.Resolution = resolution
+	}
+
+	var kept []historySample
+	for _, s := range samples {
+		if (!since.IsZero() && s.Read.Before(since)) || (!until.IsZero() && s.Read.After(until)) {
+			continue
+		}
+		if n := len(kept); n > 0 && sameBucket(kept[n-1].Read, s.Read, result.Resolution) {
+			kept[n-1] = s
+			continue
+		}
+		kept = append(kept, s)
+	}
+	for i, s := range kept {
+		stats := s.stats()
+		if i > 0 {
+			stats.PreCPUStats = result.Samples[i-1].CPUStats
+			stats.PreRead = result.Samples[i-1].Read
+		}
+		result.Samples = append(result.Samples, stats)
+	}
+	return result
+}
+
+// Close saves the history to disk, if it is persisted.
+func (h *History) Close() error {
+	select {
+	case <-h.closed:
+		return nil
+	default:
+	}
+	close(h.closed)
+	<-h.done
+	if h.dir == "" {
+		return nil
+	}
+	return h.save()
+}
+
+func (h *History) run(persistInterval time.Duration) {
+	defer close(h.done)
+
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := h.save(); err != nil {
+				logrus.WithError(err).Warn("failed to save stats history")
+			}
+		case <-h.closed:
+			return
+		}
+	}
+}
+
+// newRings returns the rings of a container for the current tiers. The
+// caller must hold h.mu.
+func (h *History) newRings() []*ring {
+	rings := make([]*ring, len(h.tiers))
+	for i, t := range h.tiers {
+		rings[i] = newRing(t.Resolution, int(t.Retention/t.Resolution)+1)
+	}
+	return rings
+}
+
+// capacity returns the number of samples kept for each container. The
+// caller must hold h.mu.
+func (h *History) capacity() int {
+	var n int
+	for _, t := range h.tiers {
+		n += int(t.Retention/t.Resolution) + 1
+	}
+	return n
+}
+
+func (h *History) path(id string) string {
+	return filepath.Join(h.dir, id+".jsonl")
+}
+
+// historyWrite is the samples to save for a container, and whether they
+// replace the content of its file.
+type historyWrite struct {
+	samples []historySample
+	rewrite bool
+}
+
+// save appends the samples added since the history was last saved to the
+// file of each container, rewriting the files that grew too large.
+func (h *History) save() error {
+	h.fileMu.Lock()
+	defer h.fileMu.Unlock()
+
+	h.mu.Lock()
+	writes := make(map[string]historyWrite, len(h.pending))
+	maxSaved := 2 * h.capacity()
+	for id, samples := range h.pending {
+		if h.saved[id]+len(samples) > maxSaved {
+			samples = merge(h.containers[id])
+			writes[id] = historyWrite{samples: samples, rewrite: true}
+			h.saved[id] = len(samples)
+		} else {
+			writes[id] = historyWrite{samples: samples}
+			h.saved[id] += len(samples)
+		}
+	}
+	h.pending = make(map[string][]historySample)
+	h.mu.Unlock()
+
+	var saveErr error
+	for id, w := range writes {
+		if err := h.write(id, w); err != nil {
+			saveErr = errors.Wrap(err, "failed to save stats history")
+			// Rewrite the whole file the next time instead of appending
+			// to a file that may be missing samples or be truncated.
+			h.mu.Lock()
+			if _, ok := h.containers[id]; ok {
+				h.saved[id] = maxSaved
+				if len(h.pending[id]) == 0 {
+					h.pending[id] = w.samples[len(w.samples)-1:]
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+	return saveErr
+}
+
+func (h *History) write(id string, w historyWrite) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range w.samples {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+	if w.rewrite {
+		return ioutils.AtomicWriteFile(h.path(id), buf.Bytes(), 0600)
+	}
+	f, err := os.OpenFile(h.path(id), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// load reads the history saved in h.dir. The samples are downsampled to the
+// current tiers, regardless of the tiers they were recorded with.
+func (h *History) load() error {
+	files, err := ioutil.ReadDir(h.dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read stats history")
+	}
+	for _, fi := range files {
+		id := strings.TrimSuffix(fi.Name(), ".jsonl")
+		if id == fi.Name() {
+			continue
+		}
+		f, err := os.Open(h.path(id))
+		if err != nil {
+			return errors.Wrap(err, "failed to read stats history")
+		}
+		rings := h.newRings()
+		var n int
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var s historySample
+			if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+				// The last line is truncated if the daemon crashed while
+				// saving the history.
+				logrus.WithError(err).WithField("container", id).Warn("ignoring invalid stats history sample")
+				continue
+			}
+			for _, r := range rings {
+				r.add(s)
+			}
+			n++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			logrus.WithError(err).WithField("container", id).Warn("failed to read stats history")
+		}
+		h.containers[id] = rings
+		h.saved[id] = n
+	}
+	return nil
+}
+
+// historySample is the part of the stats of a container kept in the history.
+type historySample struct {
+	Read       time.Time       `json:"read"`
+	CPU        uint64          `json:"cpu,omitempty"`
+	CPUKernel  uint64          `json:"cpu_kernel,omitempty"`
+	CPUUser    uint64          `json:"cpu_user,omitempty"`
+	SystemCPU  uint64          `json:"system_cpu,omitempty"`
+	OnlineCPUs uint32          `json:"online_cpus,omitempty"`
+	Memory     uint64          `json:"memory,omitempty"`
+	MemoryMax  uint64          `json:"memory_limit,omitempty"`
+	BlkioRead  uint64          `json:"blkio_read,omitempty"`
+	BlkioWrite uint64          `json:"blkio_write,omitempty"`
+	Pids       uint64          `json:"pids,omitempty"`
+	Networks   []networkSample `json:"networks,omitempty"`
+}
+
+type networkSample struct {
+	Name      string `json:"name"`
+	RxBytes   uint64 `json:"rx_bytes,omitempty"`
+	RxPackets uint64 `json:"rx_packets,omitempty"`
+	TxBytes   uint64 `json:"tx_bytes,omitempty"`
+	TxPackets uint64 `json:"tx_packets,omitempty"`
+}
+
+func newSample(stats types.StatsJSON) historySample {
+	s := historySample{
+		Read:       stats.Read,
+		CPU:        stats.CPUStats.CPUUsage.TotalUsage,
+		CPUKernel:  stats.CPUStats.CPUUsage.UsageInKernelmode,
+		CPUUser:    stats.CPUStats.CPUUsage.UsageInUsermode,
+		SystemCPU:  stats.CPUStats.SystemUsage,
+		OnlineCPUs: stats.CPUStats.OnlineCPUs,
+		Memory:     stats.MemoryStats.Usage,
+		MemoryMax:  stats.MemoryStats.Limit,
+		Pids:       stats.PidsStats.Current,
+	}
+	for _, e := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			s.BlkioRead += e.Value
+		case "write":
+			s.BlkioWrite += e.Value
+		}
+	}
+	for name, n := range stats.Networks {
+		s.Networks = append(s.Networks, networkSample{
+			Name:      name,
+			RxBytes:   n.RxBytes,
+			RxPackets: n.RxPackets,
+			TxBytes:   n.TxBytes,
+			TxPackets: n.TxPackets,
+		})
+	}
+	sort.Slice(s.Networks, func(i, j int) bool { return s.Networks[i].Name < s.Networks[j].Name })
+	return s
+}
+
+// stats returns the sample as container stats, with the block I/O of all
+// the devices added up.
+func (s historySample) stats() types.StatsJSON {
+	var stats types.StatsJSON
+	stats.Read = s.Read
+	stats.CPUStats.CPUUsage.TotalUsage = s.CPU
+	stats.CPUStats.CPUUsage.UsageInKernelmode = s.CPUKernel
+	stats.CPUStats.CPUUsage.UsageInUsermode = s.CPUUser
+	stats.CPUStats.SystemUsage = s.SystemCPU
+	stats.CPUStats.OnlineCPUs = s.OnlineCPUs
+	stats.MemoryStats.Usage = s.Memory
+	stats.MemoryStats.Limit = s.MemoryMax
+	stats.PidsStats.Current = s.Pids
+	stats.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Op: "Read", Value: s.BlkioRead},
+		{Op: "Write", Value: s.BlkioWrite},
+	}
+	if len(s.Networks) > 0 {
+		stats.Networks = make(map[string]types.NetworkStats, len(s.Networks))
+		for _, n := range s.Networks {
+			stats.Networks[n.Name] = types.NetworkStats{
+				RxBytes:   n.RxBytes,
+				RxPackets: n.RxPackets,
+				TxBytes:   n.TxBytes,
+				TxPackets: n.TxPackets,
+			}
+		}
+	}
+	return stats
+}
+
+// merge returns the samples of all the tiers of a container, oldest first.
+func merge(rings []*ring) []historySample {
+	var samples []historySample
+	for _, r := range rings {
+		samples = append(samples, r.list(time.Time{})...)
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Read.Before(samples[j].Read) })
+	merged := samples[:0]
+	for _, s := range samples {
+		if n := len(merged); n > 0 && merged[n-1].Read.Equal(s.Read) {
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// ring keeps the most recent sample of each resolution interval, up to a
+// maximum number of samples.
+type ring struct {
+	resolution time.Duration
+	samples    []historySample
+	start, n   int
+}
+
+func newRing(resolution time.Duration, size int) *ring {
+	return &ring{
+		resolution: resolution,
+		samples:    make([]historySample, 0, size),
+	}
+}
+
+func (r *ring) add(s historySample) {
+	if r.n > 0 {
+		last := &r.samples[(r.start+r.n-1)%len(r.samples)]
+		if s.Read.Before(last.Read) {
+			return
+		}
+		if sameBucket(last.Read, s.Read, r.resolution) {
+			*last = s
+			return
+		}
+	}
+	if r.n < cap(r.samples) {
+		// The ring only grows as samples are added, so that short-lived
+		// containers do not allocate the whole retention.
+		r.samples = append(r.samples, s)
+		r.n++
+		return
+	}
+	r.samples[r.start] = s
+	r.start = (r.start + 1) % len(r.samples)
+}
+
+// list returns the samples taken after notBefore, oldest first.
+func (r *ring) list(notBefore time.Time) []historySample {
+	samples := make([]historySample, 0, r.n)
+	for i := 0; i < r.n; i++ {
+		s := r.samples[(r.start+i)%len(r.samples)]
+		if s.Read.Before(notBefore) {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+func sameBucket(a, b time.Time, resolution time.Duration) bool {
+	return a.Truncate(resolution).Equal(b.Truncate(resolution))
+}
@@ -0,0 +1,178 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func sample(t time.Time, cpu uint64) types.StatsJSON {
+	var s types.StatsJSON
+	s.Read = t
+	s.CPUStats.CPUUsage.TotalUsage = cpu
+	return s
+}
+
+func sampleTimes(h types.StatsHistory) []time.Time {
+	var times []time.Time
+	for _, s := range h.Samples {
+		times = append(times, s.Read)
+	}
+	return times
+}
+
+func TestHistoryDownsampling(t *testing.T) {
+	h, err := NewHistory([]HistoryTier{
+		{Resolution: 10 * time.Second, Retention: 2 * time.Minute},
+		{Resolution: time.Minute, Retention: 10 * time.Minute},
+	}, "", 0)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(h.Interval(), 10*time.Second))
+
+	start := time.Now().Truncate(2 * time.Minute).Add(-4 * time.Minute)
+	for i := 0; i < 30; i++ {
+		h.Add("c", sample(start.Add(time.Duration(i)*10*time.Second), uint64(i)))
+	}
+	last := start.Add(290 * time.Second)
+
+	// The finest tier only keeps the last two minutes.
+	recent := h.Query("c", last.Add(-30*time.Second), time.Time{}, 0)
+	assert.Check(t, is.Equal(recent.Resolution, 10*time.Second))
+	assert.Check(t, is.Len(recent.Samples, 4))
+	assert.Check(t, is.Equal(recent.Samples[3].PreCPUStats.CPUUsage.TotalUsage, uint64(28)))
+	assert.Check(t, recent.Samples[3].PreRead.Equal(last.Add(-10*time.Second)))
+
+	// Older samples come from the coarser tier, keeping the last sample of
+	// each minute.
+	all := h.Query("c", start, time.Time{}, 0)
+	assert.Check(t, is.Equal(all.Resolution, time.Minute))
+	assert.Check(t, is.DeepEqual(sampleTimes(all), []time.Time{
+		start.Add(50 * time.Second),
+		start.Add(110 * time.Second),
+		start.Add(170 * time.Second),
+		start.Add(230 * time.Second),
+		last,
+	}))
+	assert.Check(t, all.Samples[0].PreRead.IsZero())
+
+	// Coarser resolutions than the tier's are downsampled further.
+	coarse := h.Query("c", start, start.Add(3*time.Minute), 2*time.Minute)
+	assert.Check(t, is.Equal(coarse.Resolution, 2*time.Minute))
+	assert.Check(t, is.DeepEqual(sampleTimes(coarse), []time.Time{
+		start.Add(110 * time.Second),
+		start.Add(170 * time.Second),
+	}))
+
+	h.Remove("c")
+	assert.Check(t, is.Len(h.Query("c", time.Time{}, time.Time{}, 0).Samples, 0))
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats-history")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	tiers := []HistoryTier{{Resolution: 10 * time.Second, Retention: time.Hour}}
+	h, err := NewHistory(tiers, dir, time.Hour)
+	assert.NilError(t, err)
+	now := time.Now().Truncate(10 * time.Second)
+	h.Add("a", sample(now.Add(-20*time.Second), 1))
+	h.Add("a", sample(now.Add(-10*time.Second), 2))
+	h.Add("b", sample(now, 3))
+	assert.NilError(t, h.Close())
+
+	h, err = NewHistory(tiers, dir, time.Hour)
+	assert.NilError(t, err)
+	defer h.Close()
+	assert.Check(t, is.Len(h.Query("a", time.Time{}, time.Time{}, 0).Samples, 2))
+
+	h.RemoveExcept(func(id string) bool { return id == "a" })
+	assert.Check(t, is.Len(h.Query("b", time.Time{}, time.Time{}, 0).Samples, 0))
+	_, err = os.Stat(h.path("b"))
+	assert.Check(t, os.IsNotExist(err))
+
+	// New samples are appended to the file.
+	h.Add("a", sample(now, 3))
+	assert.NilError(t, h.save())
+	assert.Check(t, is.Equal(lines(t, h.path("a")), 3))
+}
+
+func TestHistoryPersistenceRewritesLargeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats-history")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The history keeps 3 samples, and the file is rewritten above 6.
+	h, err := NewHistory([]HistoryTier{{Resolution: 10 * time.Second, Retention: 20 * time.Second}}, dir, time.Hour)
+	assert.NilError(t, err)
+	defer h.Close()
+	now := time.Now().Truncate(10 * time.Second)
+	for i := 0; i < 6; i++ {
+		h.Add("a", sample(now.Add(time.Duration(i-10)*10*time.Second), uint64(i)))
+	}
+	assert.NilError(t, h.save())
+	assert.Check(t, is.Equal(lines(t, h.path("a")), 6))
+
+	h.Add("a", sample(now, 10))
+	assert.NilError(t, h.save())
+	assert.Check(t, is.Equal(lines(t, h.path("a")), 3))
+}
+
+func lines(t *testing.T, path string) int {
+	b, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	return bytes.Count(b, []byte("\n"))
+}
+
+func TestHistorySamples(t *testing.T) {
+	h, err := NewHistory(nil, "", 0)
+	assert.NilError(t, err)
+
+	s := sample(time.Now(), 100)
+	s.MemoryStats.Usage = 1024
+	s.MemoryStats.Stats = map[string]uint64{"cache": 512}
+	s.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 4096},
+		{Major: 8, Minor: 16, Op: "read", Value: 1024},
+		{Major: 8, Minor: 0, Op: "Write", Value: 512},
+		{Major: 8, Minor: 0, Op: "Total", Value: 4608},
+	}
+	s.Networks = map[string]types.NetworkStats{"eth0": {RxBytes: 100, TxBytes: 200, RxErrors: 1}}
+	h.Add("c", s)
+
+	samples := h.Query("c", time.Time{}, time.Time{}, 0).Samples
+	assert.Assert(t, is.Len(samples, 1))
+	assert.Check(t, is.Equal(samples[0].CPUStats.CPUUsage.TotalUsage, uint64(100)))
+	assert.Check(t, is.Equal(samples[0].MemoryStats.Usage, uint64(1024)))
+	assert.Check(t, is.Len(samples[0].MemoryStats.Stats, 0))
+	assert.Check(t, is.DeepEqual(samples[0].BlkioStats.IoServiceBytesRecursive, []types.BlkioStatEntry{
+		{Op: "Read", Value: 5120},
+		{Op: "Write", Value: 512},
+	}))
+	assert.Check(t, is.DeepEqual(samples[0].Networks, map[string]types.NetworkStats{"eth0": {RxBytes: 100, TxBytes: 200}}))
+}
+
+func TestHistorySetTiers(t *testing.T) {
+	h, err := NewHistory([]HistoryTier{{Resolution: 10 * time.Second, Retention: 10 * time.Minute}}, "", 0)
+	assert.NilError(t, err)
+
+	start := time.Now().Truncate(time.Minute).Add(-2 * time.Minute)
+	for i := 0; i < 12; i++ {
+		h.Add("c", sample(start.Add(time.Duration(i)*10*time.Second), uint64(i)))
+	}
+
+	h.SetTiers([]HistoryTier{{Resolution: time.Minute, Retention: time.Hour}})
+	assert.Check(t, is.Equal(h.Interval(), time.Minute))
+	all := h.Query("c", time.Time{}, time.Time{}, 0)
+	assert.Check(t, is.Equal(all.Resolution, time.Minute))
+	assert.Check(t, is.DeepEqual(sampleTimes(all), []time.Time{
+		start.Add(50 * time.Second),
+		start.Add(110 * time.Second),
+	}))
+}
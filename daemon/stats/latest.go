@@ -0,0 +1,53 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Latest keeps the most recent stats sample of each container, such as to
+// export them as metrics without reading the stats on each scrape.
+type Latest struct {
+	mu       sync.RWMutex
+	interval time.Duration
+	samples  map[string]types.StatsJSON
+}
+
+// NewLatest returns a Latest to which samples should be added every
+// interval.
+func NewLatest(interval time.Duration) *Latest {
+	return &Latest{
+		interval: interval,
+		samples:  make(map[string]types.StatsJSON),
+	}
+}
+
+// Interval returns the interval at which samples should be added.
+func (l *Latest) Interval() time.Duration {
+	return l.interval
+}
+
+// Add records a sample of the stats of container id, replacing the previous
+// one.
+func (l *Latest) Add(id string, s types.StatsJSON) {
+	l.mu.Lock()
+	l.samples[id] = s
+	l.mu.Unlock()
+}
+
+// Remove discards the sample of container id.
+func (l *Latest) Remove(id string) {
+	l.mu.Lock()
+	delete(l.samples, id)
+	l.mu.Unlock()
+}
+
+// Get returns the most recent sample of container id, if any.
+func (l *Latest) Get(id string) (types.StatsJSON, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	s, ok := l.samples[id]
+	return s, ok
+}
@@ -0,0 +1,285 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTrafficPersistInterval is the default interval at which the
+// network traffic totals are saved to disk.
+const DefaultTrafficPersistInterval = time.Minute
+
+// Traffic accumulates the network traffic of containers under accounting
+// keys, such as their name, so that the totals survive the restarts and the
+// recreations of the containers, which reset their interface counters.
+type Traffic struct {
+	mu       sync.Mutex
+	interval time.Duration
+	key      func(*container.Container) string
+	path     string
+	totals   map[string]*trafficTotal
+	// counters are the last counters of the interfaces of each running
+	// container, from which the traffic of the next sample is computed.
+	counters map[string]map[string]trafficCounters
+	dirty    bool
+
+	closed chan struct{}
+	done   chan struct{}
+}
+
+type trafficTotal struct {
+	RxBytes   uint64
+	TxBytes   uint64
+	Since     time.Time
+	UpdatedAt time.Time
+}
+
+type trafficCounters struct {
+	RxBytes uint64
+	TxBytes uint64
+}
+
+// persistedTraffic is the state of the accounting saved to disk. The
+// counters are saved with the totals so that the traffic of containers that
+// keep running while the daemon restarts isn't accounted twice.
+type persistedTraffic struct {
+	Totals   map[string]*trafficTotal
+	Counters map[string]map[string]trafficCounters
+}
+
+// NewTraffic returns a Traffic to which samples should be added every
+// interval, accounting the traffic of each container under key(c). If path
+// is not empty, the totals are loaded from it, and saved to it every
+// persistInterval and on Close.
+func NewTraffic(interval time.Duration, key func(*container.Container) string, path string, persistInterval time.Duration) (*Traffic, error) {
+	t := &Traffic{
+		interval: interval,
+		key:      key,
+		path:     path,
+		totals:   make(map[string]*trafficTotal),
+		counters: make(map[string]map[string]trafficCounters),
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if path == "" {
+		close(t.done)
+		return t, nil
+	}
+
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	if persistInterval <= 0 {
+		persistInterval = DefaultTrafficPersistInterval
+	}
+	go t.run(persistInterval)
+	return t, nil
+}
+
+// Interval returns the interval at which samples should be added.
+func (t *Traffic) Interval() time.Duration {
+	return t.interval
+}
+
+// Add accounts the traffic of container c since its previous sample. An
+// interface counter lower than in the previous sample means the interface
+// was recreated, and its whole value is accounted.
+func (t *Traffic) Add(c *container.Container, s types.StatsJSON) {
+	key := t.key(c)
+	if key == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	total, ok := t.totals[key]
+	if !ok {
+		total = &trafficTotal{Since: s.Read}
+		t.totals[key] = total
+	}
+	last := t.counters[c.ID]
+	counters := make(map[string]trafficCounters, len(s.Networks))
+	for iface, n := range s.Networks {
+		prev := last[iface]
+		total.RxBytes += delta(prev.RxBytes, n.RxBytes)
+		total.TxBytes += delta(prev.TxBytes, n.TxBytes)
+		counters[iface] = trafficCounters{RxBytes: n.RxBytes, TxBytes: n.TxBytes}
+	}
+	total.UpdatedAt = s.Read
+	t.counters[c.ID] = counters
+	t.dirty = true
+}
+
+func delta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// Forget discards the counters of container id once it stopped, so that the
+// traffic of its next run is accounted from zero. Its traffic is kept in
+// the total of its key.
+func (t *Traffic) Forget(id string) {
+	t.mu.Lock()
+	if _, ok := t.counters[id]; ok {
+		delete(t.counters, id)
+		t.dirty = true
+	}
+	t.mu.Unlock()
+}
+
+// ForgetExcept discards the counters of the containers for which keep
+// returns false, such as containers stopped while the daemon was down.
+func (t *Traffic) ForgetExcept(keep func(id string) bool) {
+	t.mu.Lock()
+	for id := range t.counters {
+		if !keep(id) {
+			delete(t.counters, id)
+			t.dirty = true
+		}
+	}
+	t.mu.Unlock()
+}
+
+// Get returns the traffic accounted under the key of container c.
+func (t *Traffic) Get(c *container.Container) (types.NetworkTraffic, bool) {
+	key := t.key(c)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	total, ok := t.totals[key]
+	if !ok {
+		return types.NetworkTraffic{}, false
+	}
+	return total.export(key), true
+}
+
+// List returns the traffic accounted under each key, ordered by key.
+func (t *Traffic) List() []types.NetworkTraffic {
+	t.mu.Lock()
+	list := make([]types.NetworkTraffic, 0, len(t.totals))
+	for key, total := range t.totals {
+		list = append(list, total.export(key))
+	}
+	t.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+func (total *trafficTotal) export(key string) types.NetworkTraffic {
+	return types.NetworkTraffic{
+		Key:       key,
+		RxBytes:   total.RxBytes,
+		TxBytes:   total.TxBytes,
+		Since:     total.Since,
+		UpdatedAt: total.UpdatedAt,
+	}
+}
+
+// Reset discards the traffic accounted under key, or under all keys if key
+// is empty. The accounting starts over from the next sample.
+func (t *Traffic) Reset(key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if key == "" {
+		t.totals = make(map[string]*trafficTotal)
+	} else {
+		if _, ok := t.totals[key]; !ok {
+			return errdefs.NotFound(errors.Errorf("no network traffic accounted for %s", key))
+		}
+		delete(t.totals, key)
+	}
+	t.dirty = true
+	return nil
+}
+
+// Close saves the totals to disk, if they are persisted.
+func (t *Traffic) Close() error {
+	select {
+	case <-t.closed:
+		return nil
+	default:
+	}
+	close(t.closed)
+	<-t.done
+	if t.path == "" {
+		return nil
+	}
+	return t.save()
+}
+
+func (t *Traffic) run(persistInterval time.Duration) {
+	defer close(t.done)
+
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.save(); err != nil {
+				logrus.WithError(err).Warn("failed to save network traffic accounting")
+			}
+		case <-t.closed:
+			return
+		}
+	}
+}
+
+// save writes the totals and the counters, if they changed since they were
+// last saved.
+func (t *Traffic) save() error {
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	b, err := json.Marshal(persistedTraffic{Totals: t.totals, Counters: t.counters})
+	t.dirty = false
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := ioutils.AtomicWriteFile(t.path, b, 0600); err != nil {
+		return errors.Wrap(err, "failed to save network traffic accounting")
+	}
+	return nil
+}
+
+func (t *Traffic) load() error {
+	b, err := ioutil.ReadFile(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to read network traffic accounting")
+	}
+	var p persistedTraffic
+	if err := json.Unmarshal(b, &p); err != nil {
+		logrus.WithError(err).Warn("ignoring invalid network traffic accounting")
+		return nil
+	}
+	if p.Totals != nil {
+		t.totals = p.Totals
+	}
+	if p.Counters != nil {
+		t.counters = p.Counters
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func trafficSample(t time.Time, rx, tx uint64) types.StatsJSON {
+	var s types.StatsJSON
+	s.Read = t
+	s.Networks = map[string]types.NetworkStats{"eth0": {RxBytes: rx, TxBytes: tx}}
+	return s
+}
+
+func trafficKey(c *container.Container) string {
+	if app := c.Config.Labels["app"]; app != "" {
+		return app
+	}
+	return strings.TrimPrefix(c.Name, "/")
+}
+
+func TestTrafficAccounting(t *testing.T) {
+	tr, err := NewTraffic(time.Second, trafficKey, "", 0)
+	assert.NilError(t, err)
+
+	c1 := &container.Container{ID: "c1", Name: "/web-1", Config: &containertypes.Config{Labels: map[string]string{"app": "web"}}}
+	c2 := &container.Container{ID: "c2", Name: "/web-2", Config: &containertypes.Config{Labels: map[string]string{"app": "web"}}}
+	db := &container.Container{ID: "db", Name: "/db", Config: &containertypes.Config{}}
+
+	start := time.Now()
+	tr.Add(c1, trafficSample(start, 100, 10))
+	tr.Add(c1, trafficSample(start.Add(time.Second), 150, 30))
+	// The interface was recreated, and its counters started over.
+	tr.Add(c1, trafficSample(start.Add(2*time.Second), 20, 5))
+	// The container stopped, and was recreated.
+	tr.Forget(c1.ID)
+	tr.Add(c2, trafficSample(start.Add(3*time.Second), 30, 40))
+	tr.Add(db, trafficSample(start.Add(3*time.Second), 7, 8))
+
+	web, ok := tr.Get(c1)
+	assert.Assert(t, ok)
+	assert.Check(t, is.DeepEqual(web, types.NetworkTraffic{
+		Key:       "web",
+		RxBytes:   150 + 20 + 30,
+		TxBytes:   30 + 5 + 40,
+		Since:     start,
+		UpdatedAt: start.Add(3 * time.Second),
+	}))
+
+	list := tr.List()
+	assert.Assert(t, is.Len(list, 2))
+	assert.Check(t, is.Equal(list[0].Key, "db"))
+	assert.Check(t, is.Equal(list[1].Key, "web"))
+
+	assert.NilError(t, tr.Reset("web"))
+	_, ok = tr.Get(c2)
+	assert.Check(t, !ok)
+	err = tr.Reset("web")
+	assert.Check(t, errdefs.IsNotFound(err), "got: %v", err)
+
+	// The accounting starts over from the counters of the last sample.
+	tr.Add(c2, trafficSample(start.Add(4*time.Second), 35, 40))
+	web, _ = tr.Get(c2)
+	assert.Check(t, is.Equal(web.RxBytes, uint64(5)))
+	assert.Check(t, is.Equal(web.TxBytes, uint64(0)))
+
+	assert.NilError(t, tr.Reset(""))
+	assert.Check(t, is.Len(tr.List(), 0))
+}
+
+func TestTrafficPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "network-traffic.json")
+	tr, err := NewTraffic(time.Second, trafficKey, path, time.Hour)
+	assert.NilError(t, err)
+
+	c := &container.Container{ID: "c", Name: "/app", Config: &containertypes.Config{}}
+	start := time.Now().UTC().Truncate(time.Second)
+	tr.Add(c, trafficSample(start, 100, 200))
+	assert.NilError(t, tr.Close())
+
+	// The container kept running while the daemon restarted: its traffic
+	// isn't accounted twice.
+	tr, err = NewTraffic(time.Second, trafficKey, path, time.Hour)
+	assert.NilError(t, err)
+	tr.Add(c, trafficSample(start.Add(time.Second), 110, 200))
+	app, ok := tr.Get(c)
+	assert.Assert(t, ok)
+	assert.Check(t, is.Equal(app.RxBytes, uint64(110)))
+	assert.Check(t, is.Equal(app.TxBytes, uint64(200)))
+	assert.Check(t, app.Since.Equal(start))
+
+	// The container was stopped while the daemon was down.
+	tr.ForgetExcept(func(string) bool { return false })
+	tr.Add(c, trafficSample(start.Add(2*time.Second), 50, 50))
+	app, _ = tr.Get(c)
+	assert.Check(t, is.Equal(app.RxBytes, uint64(160)))
+	assert.Check(t, is.Equal(app.TxBytes, uint64(250)))
+	assert.NilError(t, tr.Close())
+}
@@ -0,0 +1,166 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/cgroups"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/sirupsen/logrus"
+)
+
+const cgroup2Root = "/sys/fs/cgroup"
+
+// addCgroup2Stats adds to s the statistics of the cgroup v2 of c that are
+// not reported by containerd: the pressure stall information and all the
+// io.stat counters.
+func addCgroup2Stats(c *container.Container, s *types.StatsJSON) {
+	dir, err := cgroup2Dir(c.GetPID())
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.ID).Debug("failed to find cgroup of container")
+		return
+	}
+	s.PressureStats = readPressureStats(dir)
+	if ioStat, err := readIOStat(filepath.Join(dir, "io.stat")); err == nil {
+		s.BlkioStats.IoStat = ioStat
+	}
+}
+
+// cgroup2Dir returns the directory of the cgroup v2 of the process pid.
+func cgroup2Dir(pid int) (string, error) {
+	if pid == 0 {
+		return "", fmt.Errorf("container has no process")
+	}
+	groups, err := cgroups.ParseCgroupFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	g, ok := groups[""]
+	if !ok {
+		return "", fmt.Errorf("process %d is not in a cgroup v2", pid)
+	}
+	return filepath.Join(cgroup2Root, g), nil
+}
+
+// readPressureStats returns the pressure stall information in the cgroup
+// directory dir, or nil if the kernel does not support it.
+func readPressureStats(dir string) *types.PressureStats {
+	var ps types.PressureStats
+	for name, dst := range map[string]**types.Pressure{
+		"cpu.pressure":    &ps.CPU,
+		"memory.pressure": &ps.Memory,
+		"io.pressure":     &ps.IO,
+	} {
+		p, err := readPressure(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		*dst = p
+	}
+	if ps.CPU == nil && ps.Memory == nil && ps.IO == nil {
+		return nil
+	}
+	return &ps
+}
+
+// readPressure parses a PSI file, such as:
+//
+//	some avg10=0.00 avg60=0.12 avg300=0.04 total=123456
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=7890
+func readPressure(path string) (*types.Pressure, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p types.Pressure
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var data types.PressureData
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid pressure field %q in %s", field, path)
+			}
+			switch kv[0] {
+			case "avg10", "avg60", "avg300":
+				v, err := strconv.ParseFloat(kv[1], 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pressure field %q in %s", field, path)
+				}
+				switch kv[0] {
+				case "avg10":
+					data.Avg10 = v
+				case "avg60":
+					data.Avg60 = v
+				default:
+					data.Avg300 = v
+				}
+			case "total":
+				v, err := strconv.ParseUint(kv[1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pressure field %q in %s", field, path)
+				}
+				data.Total = v
+			}
+		}
+		switch fields[0] {
+		case "some":
+			p.Some = data
+		case "full":
+			p.Full = &data
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// readIOStat parses an io.stat file, such as:
+//
+//	8:0 rbytes=90112 wbytes=0 rios=22 wios=0 dbytes=0 dios=0
+func readIOStat(path string) ([]types.IOStatEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []types.IOStatEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var entry types.IOStatEntry
+		if _, err := fmt.Sscanf(fields[0], "%d:%d", &entry.Major, &entry.Minor); err != nil {
+			return nil, fmt.Errorf("invalid device %q in %s", fields[0], path)
+		}
+		entry.Stats = make(map[string]uint64, len(fields)-1)
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			// Some controllers report non-integer values, such as
+			// "cost.usage"; only keep counters.
+			if v, err := strconv.ParseUint(kv[1], 10, 64); err == nil {
+				entry.Stats[kv[0]] = v
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,52 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestReadCgroup2Stats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup2-stats")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"cpu.pressure": "some avg10=1.50 avg60=0.75 avg300=0.10 total=123456\n",
+		"io.pressure": "some avg10=0.00 avg60=0.00 avg300=0.00 total=10\n" +
+			"full avg10=0.00 avg60=0.00 avg300=0.00 total=5\n",
+		"io.stat": "8:0 rbytes=90112 wbytes=4096 rios=22 wios=1 dbytes=0 dios=0\n" +
+			"253:1 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=5 dios=6 cost.usage=1.5\n",
+	}
+	for name, content := range files {
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	ps := readPressureStats(dir)
+	assert.Assert(t, ps != nil)
+	assert.Check(t, is.DeepEqual(ps.CPU, &types.Pressure{
+		Some: types.PressureData{Avg10: 1.5, Avg60: 0.75, Avg300: 0.1, Total: 123456},
+	}))
+	assert.Check(t, is.Nil(ps.Memory))
+	assert.Check(t, is.DeepEqual(ps.IO, &types.Pressure{
+		Some: types.PressureData{Total: 10},
+		Full: &types.PressureData{Total: 5},
+	}))
+
+	ioStat, err := readIOStat(filepath.Join(dir, "io.stat"))
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(ioStat, []types.IOStatEntry{
+		{Major: 8, Minor: 0, Stats: map[string]uint64{"rbytes": 90112, "wbytes": 4096, "rios": 22, "wios": 1, "dbytes": 0, "dios": 0}},
+		{Major: 253, Minor: 1, Stats: map[string]uint64{"rbytes": 1, "wbytes": 2, "rios": 3, "wios": 4, "dbytes": 5, "dios": 6}},
+	}))
+
+	empty, err := ioutil.TempDir("", "cgroup2-stats")
+	assert.NilError(t, err)
+	defer os.RemoveAll(empty)
+	assert.Check(t, is.Nil(readPressureStats(empty)))
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+)
+
+func addCgroup2Stats(c *container.Container, s *types.StatsJSON) {
+}
@@ -1,11 +1,16 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/stats"
 	"github.com/docker/docker/pkg/system"
+	"github.com/sirupsen/logrus"
 )
 
 // newStatsCollector returns a new statsCollector that collections
@@ -24,3 +29,148 @@ func (daemon *Daemon) newStatsCollector(interval time.Duration) *stats.Collector
 	go s.Run()
 	return s
 }
+
+// initStatsHistory enables recording the stats of running containers in a
+// downsampled history, saved under the data-root if persistence is enabled.
+func (daemon *Daemon) initStatsHistory(cfg *config.Config) error {
+	tiers, err := statsHistoryTiers(cfg)
+	if err != nil {
+		return err
+	}
+	var dir string
+	var persistInterval time.Duration
+	if cfg.StatsHistory.Persist {
+		dir = filepath.Join(cfg.Root, "stats-history")
+		if cfg.StatsHistory.PersistInterval != "" {
+			d, err := time.ParseDuration(cfg.StatsHistory.PersistInterval)
+			if err != nil {
+				return err
+			}
+			persistInterval = d
+		}
+	}
+
+	h, err := stats.NewHistory(tiers, dir, persistInterval)
+	if err != nil {
+		return err
+	}
+	daemon.statsCollector.SetHistory(h)
+	return nil
+}
+
+// statsHistoryTiers returns the configured tiers of the stats history, or
+// nil for the default tiers.
+func statsHistoryTiers(cfg *config.Config) ([]stats.HistoryTier, error) {
+	var tiers []stats.HistoryTier
+	for _, t := range cfg.StatsHistory.Tiers {
+		resolution, retention, err := config.ParseStatsHistoryTier(t)
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, stats.HistoryTier{Resolution: resolution, Retention: retention})
+	}
+	return tiers, nil
+}
+
+// initContainerMetrics enables exporting the metrics of each running
+// container, from their latest stats.
+func (daemon *Daemon) initContainerMetrics(cfg *config.Config) error {
+	interval := config.DefaultContainerMetricsInterval
+	if cfg.ContainerMetrics.Interval != "" {
+		d, err := time.ParseDuration(cfg.ContainerMetrics.Interval)
+		if err != nil {
+			return err
+		}
+		interval = d
+	}
+	latest := stats.NewLatest(interval)
+	daemon.statsCollector.SetLatest(latest)
+	containerCtr.configure(daemon, latest, cfg.ContainerMetrics.Labels)
+	return nil
+}
+
+// initNetworkTraffic enables accounting the network traffic of running
+// containers under their name or the value of the configured label, saved
+// under the data-root.
+func (daemon *Daemon) initNetworkTraffic(cfg *config.Config) error {
+	interval := config.DefaultNetworkTrafficInterval
+	if cfg.NetworkTraffic.Interval != "" {
+		d, err := time.ParseDuration(cfg.NetworkTraffic.Interval)
+		if err != nil {
+			return err
+		}
+		interval = d
+	}
+	var persistInterval time.Duration
+	if cfg.NetworkTraffic.PersistInterval != "" {
+		d, err := time.ParseDuration(cfg.NetworkTraffic.PersistInterval)
+		if err != nil {
+			return err
+		}
+		persistInterval = d
+	}
+
+	label := cfg.NetworkTraffic.KeyLabel
+	key := func(c *container.Container) string {
+		if label != "" && c.Config != nil {
+			if v := c.Config.Labels[label]; v != "" {
+				return v
+			}
+		}
+		return strings.TrimPrefix(c.Name, "/")
+	}
+	t, err := stats.NewTraffic(interval, key, filepath.Join(cfg.Root, "network-traffic.json"), persistInterval)
+	if err != nil {
+		return err
+	}
+	daemon.statsCollector.SetTraffic(t)
+	trafficCtr.setTraffic(t)
+	return nil
+}
+
+// trackRestoredContainers adds the containers that were running when the
+// daemon started to the stats history, the latest stats and the network
+// traffic accounting, and discards the history of the containers that no
+// longer exist and the traffic counters of the containers no longer
+// running.
+func (daemon *Daemon) trackRestoredContainers() {
+	h := daemon.statsCollector.History()
+	t := daemon.statsCollector.Traffic()
+	if h == nil && t == nil && daemon.statsCollector.Latest() == nil {
+		return
+	}
+	if h != nil {
+		h.RemoveExcept(func(id string) bool {
+			return daemon.containers.Get(id) != nil
+		})
+	}
+	if t != nil {
+		t.ForgetExcept(func(id string) bool {
+			c := daemon.containers.Get(id)
+			return c != nil && c.IsRunning()
+		})
+	}
+	for _, c := range daemon.containers.List() {
+		if c.IsRunning() {
+			daemon.statsCollector.Track(c)
+		}
+	}
+}
+
+// closeStatsHistory saves the stats history, if it is persisted, and the
+// network traffic accounting.
+func (daemon *Daemon) closeStatsHistory() {
+	if daemon.statsCollector == nil {
+		return
+	}
+	if h := daemon.statsCollector.History(); h != nil {
+		if err := h.Close(); err != nil {
+			logrus.WithError(err).Error("failed to save stats history")
+		}
+	}
+	if t := daemon.statsCollector.Traffic(); t != nil {
+		if err := t.Close(); err != nil {
+			logrus.WithError(err).Error("failed to save network traffic accounting")
+		}
+	}
+}
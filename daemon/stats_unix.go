@@ -39,10 +39,13 @@ func (daemon *Daemon) getNetworkStats(c *container.Container) (map[string]types.
 		return nil, err
 	}
 
+	// The map is replaced, not modified, when the shaping changes.
+	bandwidth := c.NetworkSettings.Bandwidth
+
 	stats := make(map[string]types.NetworkStats)
 	// Convert libnetwork nw stats into api stats
 	for ifName, ifStats := range lnstats {
-		stats[ifName] = types.NetworkStats{
+		s := types.NetworkStats{
 			RxBytes:   ifStats.RxBytes,
 			RxPackets: ifStats.RxPackets,
 			RxErrors:  ifStats.RxErrors,
@@ -52,6 +55,10 @@ func (daemon *Daemon) getNetworkStats(c *container.Container) (map[string]types.
 			TxErrors:  ifStats.TxErrors,
 			TxDropped: ifStats.TxDropped,
 		}
+		if bw, ok := bandwidth[ifName]; ok {
+			s.Bandwidth = &bw
+		}
+		stats[ifName] = s
 	}
 
 	return stats, nil
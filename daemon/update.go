@@ -22,6 +22,11 @@ func (daemon *Daemon) ContainerUpdate(name string, hostConfig *container.HostCon
 	if err != nil {
 		return container.ContainerUpdateOKBody{Warnings: warnings}, errdefs.InvalidParameter(err)
 	}
+	if hostConfig != nil {
+		if err := validateNetworkBandwidth(&hostConfig.Resources, c.HostConfig.NetworkMode, c.OS); err != nil {
+			return container.ContainerUpdateOKBody{Warnings: warnings}, errdefs.InvalidParameter(err)
+		}
+	}
 
 	if err := daemon.update(name, hostConfig); err != nil {
 		return container.ContainerUpdateOKBody{Warnings: warnings}, err
@@ -89,6 +94,10 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 			// TODO: it would be nice if containerd responded with better errors here so we can classify this better.
 			return errCannotUpdate(ctr.ID, errdefs.System(err))
 		}
+		if err := daemon.updateNetworkBandwidth(ctr); err != nil {
+			restoreConfig = true
+			return errCannotUpdate(ctr.ID, err)
+		}
 	}
 
 	daemon.LogContainerEvent(ctr, "update")
@@ -0,0 +1,469 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	networktypes "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stringid"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultUpdateImageHealthTimeout is the time given to the new container
+	// to report healthy before the update is rolled back.
+	defaultUpdateImageHealthTimeout = 60 * time.Second
+
+	// updateImageHealthPollInterval is how often the health status of the
+	// new container is checked while waiting for it to become healthy.
+	updateImageHealthPollInterval = 500 * time.Millisecond
+)
+
+// ContainerUpdateImage replaces the container identified by name with a new
+// container created from the given image. The new container keeps the name,
+// configuration, networks, volumes and restart policy of the old one.
+//
+// The operation is transactional: if the new container cannot be created, fails
+// to start, exits, or does not become healthy within the health timeout, it is
+// removed and the old container is restored to its previous name and state.
+// The old container is only removed once the new one is known to be good.
+func (daemon *Daemon) ContainerUpdateImage(name string, config *types.ContainerUpdateImageConfig) (containertypes.ContainerCreateCreatedBody, error) {
+	start := time.Now()
+	if config.Image == "" {
+		return containertypes.ContainerCreateCreatedBody{}, errdefs.InvalidParameter(errors.New("image cannot be empty"))
+	}
+
+	oldCtr, err := daemon.GetContainer(name)
+	if err != nil {
+		return containertypes.ContainerCreateCreatedBody{}, err
+	}
+	if err := daemon.checkUpdateImageDependents(oldCtr); err != nil {
+		return containertypes.ContainerCreateCreatedBody{}, err
+	}
+
+	// Claim the container for the duration of the update, so that it can
+	// neither be removed nor updated concurrently.
+	if inProgress := oldCtr.SetRemovalInProgress(); inProgress {
+		return containertypes.ContainerCreateCreatedBody{}, errdefs.Conflict(fmt.Errorf("removal or update of container %s is already in progress", name))
+	}
+	defer oldCtr.ResetRemovalInProgress()
+
+	if _, err := daemon.imageService.GetImage(config.Image, nil); err != nil {
+		return containertypes.ContainerCreateCreatedBody{}, err
+	}
+
+	params, err := daemon.updateImageCreateConfig(oldCtr, config.Image)
+	if err != nil {
+		return containertypes.ContainerCreateCreatedBody{}, err
+	}
+
+	// The new container goes through the same validation as any other
+	// container created through the API.
+	created, err := daemon.containerCreate(createOpts{params: params})
+	warnings := created.Warnings
+	if err != nil {
+		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, err
+	}
+	newCtr, err := daemon.GetContainer(created.ID)
+	if err != nil {
+		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, err
+	}
+
+	stopTimeout := oldCtr.StopTimeout()
+	if config.StopTimeout != nil {
+		stopTimeout = *config.StopTimeout
+	}
+	healthTimeout := config.HealthTimeout
+	if healthTimeout <= 0 {
+		healthTimeout = defaultUpdateImageHealthTimeout
+	}
+
+	u := &imageUpdate{
+		daemon:     daemon,
+		oldCtr:     oldCtr,
+		newCtr:     newCtr,
+		oldName:    oldCtr.Name,
+		wasRunning: oldCtr.IsRunning(),
+	}
+	daemon.LogContainerEventWithAttributes(oldCtr, "update_image_start", map[string]string{
+		"newID":    newCtr.ID,
+		"newImage": config.Image,
+	})
+
+	if err := u.apply(stopTimeout, healthTimeout); err != nil {
+		logrus.WithError(err).WithField("container", oldCtr.ID).Warn("image update failed, rolling back")
+		rollbackErr := u.rollback()
+		daemon.LogContainerEventWithAttributes(oldCtr, "update_image_rollback", map[string]string{
+			"newID":    newCtr.ID,
+			"newImage": config.Image,
+			"error":    err.Error(),
+		})
+		if rollbackErr != nil {
+			return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, errdefs.System(errors.Wrapf(rollbackErr, "failed to roll back image update (%v)", err))
+		}
+		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, errdefs.System(errors.Wrap(err, "image update rolled back"))
+	}
+
+	if err := daemon.cleanupContainer(oldCtr, true, true); err != nil {
+		// The new container is running fine at this point, so a failure to
+		// remove the old one is not a reason to fail the update.
+		logrus.WithError(err).WithField("container", oldCtr.ID).Error("failed to remove container after image update")
+		warnings = append(warnings, fmt.Sprintf("failed to remove old container %s: %v", stringid.TruncateID(oldCtr.ID), err))
+	}
+
+	daemon.LogContainerEventWithAttributes(newCtr, "update_image", map[string]string{
+		"oldID":    oldCtr.ID,
+		"oldImage": oldCtr.Config.Image,
+	})
+	containerActions.WithValues("update-image").UpdateSince(start)
+
+	return containertypes.ContainerCreateCreatedBody{ID: newCtr.ID, Warnings: warnings}, nil
+}
+
+// checkUpdateImageDependents returns an error if other containers depend on
+// the identity of the container, as those would be left pointing at the old
+// container after it has been replaced.
+func (daemon *Daemon) checkUpdateImageDependents(ctr *container.Container) error {
+	if parents := daemon.linkIndex.parents(ctr); len(parents) > 0 {
+		return errdefs.Conflict(fmt.Errorf("cannot update image of container %s: it is linked to by other containers", ctr.ID))
+	}
+	isSelf := func(ref string) bool {
+		return ref != "" && (ref == ctr.ID || ref == strings.TrimPrefix(ctr.Name, "/"))
+	}
+	for _, c := range daemon.containers.List() {
+		if c.ID == ctr.ID || c.HostConfig == nil {
+			continue
+		}
+		if isSelf(c.HostConfig.NetworkMode.ConnectedContainer()) || isSelf(c.HostConfig.IpcMode.Container()) || isSelf(c.HostConfig.PidMode.Container()) {
+			return errdefs.Conflict(fmt.Errorf("cannot update image of container %s: container %s shares its namespaces", ctr.ID, c.ID))
+		}
+	}
+	return nil
+}
+
+// updateImageCreateConfig builds the configuration for a container that
+// replaces ctr and runs imageRef instead of the container's current image.
+func (daemon *Daemon) updateImageCreateConfig(ctr *container.Container, imageRef string) (types.ContainerCreateConfig, error) {
+	ctr.Lock()
+	defer ctr.Unlock()
+
+	var (
+		config     containertypes.Config
+		hostConfig containertypes.HostConfig
+	)
+	if err := deepCopyJSON(ctr.Config, &config); err != nil {
+		return types.ContainerCreateConfig{}, err
+	}
+	if err := deepCopyJSON(ctr.HostConfig, &hostConfig); err != nil {
+		return types.ContainerCreateConfig{}, err
+	}
+
+	// The container configuration has the defaults of the old image merged
+	// into it. Remove them, so that the defaults of the new image apply.
+	if oldImg, err := daemon.imageService.GetImage(ctr.ImageID.String(), nil); err == nil && oldImg.Config != nil {
+		resetImageDefaults(&config, oldImg.Config)
+	} else if err != nil {
+		logrus.WithError(err).WithField("container", ctr.ID).Warn("could not find image of container; keeping its configuration as is")
+	}
+	config.Image = imageRef
+
+	// The container ID environment variable is added again at create.
+	if cidenv := hostConfig.ContainerIDEnv; cidenv != "" {
+		config.Env = removeEnv(config.Env, cidenv)
+	}
+
+	// The hostname defaults to the short ID of the container.
+	shortID := stringid.TruncateID(ctr.ID)
+	defaultHostname := config.Hostname == shortID
+	if defaultHostname {
+		config.Hostname = ""
+	}
+
+	if err := keepAnonymousVolumes(ctr, &hostConfig); err != nil {
+		return types.ContainerCreateConfig{}, err
+	}
+
+	endpoints := make(map[string]*networktypes.EndpointSettings)
+	if ctr.NetworkSettings != nil {
+		for name, ep := range ctr.NetworkSettings.Networks {
+			if ep == nil || ep.EndpointSettings == nil {
+				continue
+			}
+			settings := &networktypes.EndpointSettings{
+				Links:      ep.Links,
+				DriverOpts: ep.DriverOpts,
+			}
+			if ep.IPAMConfig != nil {
+				ipam := *ep.IPAMConfig
+				settings.IPAMConfig = &ipam
+			}
+			for _, alias := range ep.Aliases {
+				if alias == shortID || (defaultHostname && alias == ctr.Config.Hostname) {
+					continue
+				}
+				settings.Aliases = append(settings.Aliases, alias)
+			}
+			endpoints[name] = settings
+		}
+	}
+
+	return types.ContainerCreateConfig{
+		Config:           &config,
+		HostConfig:       &hostConfig,
+		NetworkingConfig: &networktypes.NetworkingConfig{EndpointsConfig: endpoints},
+	}, nil
+}
+
+// resetImageDefaults removes the values that were merged into config from
+// the image configuration imgConfig when the container was created. It is
+// the inverse of merge for every value that the user did not override.
+func resetImageDefaults(config, imgConfig *containertypes.Config) {
+	if config.User == imgConfig.User {
+		config.User = ""
+	}
+	for port := range imgConfig.ExposedPorts {
+		delete(config.ExposedPorts, port)
+	}
+	var env []string
+	for _, e := range config.Env {
+		found := false
+		for _, imgEnv := range imgConfig.Env {
+			if e == imgEnv {
+				found = true
+				break
+			}
+		}
+		if !found {
+			env = append(env, e)
+		}
+	}
+	config.Env = env
+	for l, v := range imgConfig.Labels {
+		if config.Labels[l] == v {
+			delete(config.Labels, l)
+		}
+	}
+	if reflect.DeepEqual(config.Entrypoint, imgConfig.Entrypoint) {
+		config.Entrypoint = nil
+		if reflect.DeepEqual(config.Cmd, imgConfig.Cmd) {
+			config.Cmd = nil
+		}
+	}
+	if reflect.DeepEqual(config.Healthcheck, imgConfig.Healthcheck) {
+		config.Healthcheck = nil
+	}
+	if config.WorkingDir == imgConfig.WorkingDir {
+		config.WorkingDir = ""
+	}
+	for v := range imgConfig.Volumes {
+		delete(config.Volumes, v)
+	}
+	if config.StopSignal == imgConfig.StopSignal {
+		config.StopSignal = ""
+	}
+}
+
+// keepAnonymousVolumes replaces the anonymous volumes in hostConfig with
+// mounts of the volumes that are currently used by ctr, so that the data in
+// them is carried over to the new container.
+func keepAnonymousVolumes(ctr *container.Container, hostConfig *containertypes.HostConfig) error {
+	parser := volumemounts.NewParser(ctr.OS)
+
+	var binds []string
+	for _, b := range hostConfig.Binds {
+		mp, err := parser.ParseMountRaw(b, hostConfig.VolumeDriver)
+		if err != nil {
+			return errdefs.InvalidParameter(err)
+		}
+		if mp.Type == mounttypes.TypeVolume && mp.Spec.Source == "" {
+			continue
+		}
+		binds = append(binds, b)
+	}
+	hostConfig.Binds = binds
+
+	var mounts []mounttypes.Mount
+	for _, m := range hostConfig.Mounts {
+		if m.Type == mounttypes.TypeVolume && m.Source == "" {
+			continue
+		}
+		mounts = append(mounts, m)
+	}
+	for _, mp := range ctr.MountPoints {
+		if mp.Type != mounttypes.TypeVolume || mp.Spec.Source != "" || mp.Name == "" {
+			continue
+		}
+		m := mounttypes.Mount{
+			Type:     mounttypes.TypeVolume,
+			Source:   mp.Name,
+			Target:   mp.Destination,
+			ReadOnly: !mp.RW,
+		}
+		if mp.Driver != "" {
+			m.VolumeOptions = &mounttypes.VolumeOptions{
+				DriverConfig: &mounttypes.Driver{Name: mp.Driver},
+			}
+		}
+		mounts = append(mounts, m)
+	}
+	hostConfig.Mounts = mounts
+	return nil
+}
+
+// imageUpdate tracks the progress of replacing a container, so that every
+// completed step can be undone if a later step fails.
+type imageUpdate struct {
+	daemon *Daemon
+	oldCtr *container.Container
+	newCtr *container.Container
+
+	oldName    string
+	wasRunning bool
+
+	oldStopped bool
+	oldRenamed bool
+	newRenamed bool
+	newClaimed bool
+}
+
+// apply stops the old container, hands its name over to the new container
+// and, if the old container was running, starts the new one and waits for
+// it to become healthy.
+func (u *imageUpdate) apply(stopTimeout int, healthTimeout time.Duration) error {
+	if u.wasRunning {
+		// The old container is not removed when it exits if it has
+		// AutoRemove set, as it is claimed by the update.
+		if err := u.daemon.containerStop(u.oldCtr, stopTimeout); err != nil {
+			return errors.Wrap(err, "failed to stop container")
+		}
+		u.oldStopped = true
+	}
+
+	tmpName := fmt.Sprintf("%s_old_%s", u.oldName, stringid.TruncateID(u.oldCtr.ID))
+	if err := u.daemon.containerRename(u.oldCtr.ID, tmpName, true); err != nil {
+		return errors.Wrap(err, "failed to rename container")
+	}
+	u.oldRenamed = true
+	if err := u.daemon.containerRename(u.newCtr.ID, u.oldName, false); err != nil {
+		return errors.Wrap(err, "failed to rename new container")
+	}
+	u.newRenamed = true
+
+	if !u.wasRunning {
+		return nil
+	}
+	if err := u.daemon.containerStart(u.newCtr, "", "", true); err != nil {
+		return errors.Wrap(err, "failed to start new container")
+	}
+	// Claim the new container while it is not known to be healthy, so
+	// that it can neither be removed nor renamed before a rollback.
+	if inProgress := u.newCtr.SetRemovalInProgress(); inProgress {
+		return errdefs.Conflict(fmt.Errorf("removal of container %s is already in progress", u.newCtr.ID))
+	}
+	u.newClaimed = true
+	if err := u.daemon.waitUpdateImageHealthy(u.newCtr, healthTimeout); err != nil {
+		return err
+	}
+	u.newCtr.ResetRemovalInProgress()
+	u.newClaimed = false
+	return nil
+}
+
+// rollback removes the new container and restores the old container to the
+// name and state it had before the update.
+func (u *imageUpdate) rollback() error {
+	// Claim the new container like any removal does, so that no other
+	// operation can act on it while it is removed.
+	if !u.newClaimed {
+		if inProgress := u.newCtr.SetRemovalInProgress(); inProgress {
+			return errdefs.Conflict(fmt.Errorf("removal of container %s is already in progress", u.newCtr.ID))
+		}
+	}
+	err := u.daemon.cleanupContainer(u.newCtr, true, true)
+	u.newCtr.ResetRemovalInProgress()
+	if err != nil {
+		return errors.Wrap(err, "failed to remove new container")
+	}
+	if u.oldRenamed {
+		if err := u.daemon.containerRename(u.oldCtr.ID, u.oldName, true); err != nil {
+			return errors.Wrap(err, "failed to restore container name")
+		}
+	}
+	if u.oldStopped {
+		// The container cannot be started while it is claimed by the update.
+		u.oldCtr.ResetRemovalInProgress()
+		if err := u.daemon.containerStart(u.oldCtr, "", "", true); err != nil {
+			return errors.Wrap(err, "failed to restart container")
+		}
+	}
+	return nil
+}
+
+// waitUpdateImageHealthy waits for ctr to report healthy. Containers without
+// a healthcheck only need to be running. An error is returned if the
+// container exits, becomes unhealthy or is still starting after timeout.
+func (daemon *Daemon) waitUpdateImageHealthy(ctr *container.Container, timeout time.Duration) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(updateImageHealthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ctr.Lock()
+		running := ctr.Running && !ctr.Restarting
+		exitCode := ctr.ExitCode()
+		status := ""
+		if ctr.State.Health != nil {
+			status = ctr.State.Health.Status()
+		}
+		hasProbe := getProbe(ctr) != nil
+		ctr.Unlock()
+
+		switch {
+		case !running:
+			return errors.Errorf("new container exited with code %d", exitCode)
+		case !hasProbe, status == types.Healthy:
+			return nil
+		case status == types.Unhealthy:
+			return errors.New("new container is unhealthy")
+		}
+
+		select {
+		case <-deadline.C:
+			return errors.Errorf("new container did not become healthy within %s", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// deepCopyJSON copies src into dst by marshaling it to JSON, which is how
+// container configurations are persisted.
+func deepCopyJSON(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// removeEnv returns env without the variables named key.
+func removeEnv(env []string, key string) []string {
+	var out []string
+	for _, e := range env {
+		if strings.SplitN(e, "=", 2)[0] == key {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
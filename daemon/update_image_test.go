@@ -0,0 +1,110 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/container"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/docker/go-connections/nat"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestResetImageDefaults(t *testing.T) {
+	imgConfig := &containertypes.Config{
+		User:         "app",
+		Env:          []string{"PATH=/usr/bin", "APP_VERSION=1"},
+		Labels:       map[string]string{"version": "1", "vendor": "acme"},
+		Cmd:          []string{"serve"},
+		Entrypoint:   []string{"/app"},
+		WorkingDir:   "/srv",
+		ExposedPorts: nat.PortSet{"80/tcp": {}},
+		Volumes:      map[string]struct{}{"/data": {}},
+		StopSignal:   "SIGINT",
+	}
+	config := &containertypes.Config{
+		Env:          []string{"APP_VERSION=2", "DEBUG=1"},
+		Labels:       map[string]string{"vendor": "custom"},
+		ExposedPorts: nat.PortSet{"8080/tcp": {}},
+	}
+	assert.NilError(t, merge(config, imgConfig))
+
+	resetImageDefaults(config, imgConfig)
+	assert.Check(t, is.Equal(config.User, ""))
+	assert.Check(t, is.DeepEqual(config.Env, []string{"APP_VERSION=2", "DEBUG=1"}))
+	assert.Check(t, is.DeepEqual(config.Labels, map[string]string{"vendor": "custom"}))
+	assert.Check(t, is.Nil(config.Entrypoint))
+	assert.Check(t, is.Nil(config.Cmd))
+	assert.Check(t, is.Equal(config.WorkingDir, ""))
+	assert.Check(t, is.DeepEqual(config.ExposedPorts, nat.PortSet{"8080/tcp": {}}))
+	assert.Check(t, is.Len(config.Volumes, 0))
+	assert.Check(t, is.Equal(config.StopSignal, ""))
+}
+
+func TestResetImageDefaultsKeepsUserCommand(t *testing.T) {
+	imgConfig := &containertypes.Config{
+		Cmd:        []string{"serve"},
+		Entrypoint: []string{"/app"},
+	}
+	config := &containertypes.Config{
+		Cmd: []string{"migrate"},
+	}
+	assert.NilError(t, merge(config, imgConfig))
+
+	resetImageDefaults(config, imgConfig)
+	assert.Check(t, is.Nil(config.Entrypoint))
+	assert.Check(t, is.DeepEqual([]string(config.Cmd), []string{"migrate"}))
+}
+
+func TestKeepAnonymousVolumes(t *testing.T) {
+	ctr := &container.Container{
+		MountPoints: map[string]*volumemounts.MountPoint{
+			"/cache": {
+				Type:        mounttypes.TypeVolume,
+				Name:        "0123456789abcdef",
+				Destination: "/cache",
+				Driver:      "local",
+				RW:          true,
+			},
+			"/config": {
+				Type:        mounttypes.TypeVolume,
+				Name:        "config",
+				Destination: "/config",
+				RW:          true,
+				Spec:        mounttypes.Mount{Type: mounttypes.TypeVolume, Source: "config", Target: "/config"},
+			},
+		},
+	}
+	hostConfig := &containertypes.HostConfig{
+		Binds: []string{"/cache", "config:/config"},
+	}
+
+	assert.NilError(t, keepAnonymousVolumes(ctr, hostConfig))
+	assert.Check(t, is.DeepEqual(hostConfig.Binds, []string{"config:/config"}))
+	assert.Check(t, is.DeepEqual(hostConfig.Mounts, []mounttypes.Mount{{
+		Type:          mounttypes.TypeVolume,
+		Source:        "0123456789abcdef",
+		Target:        "/cache",
+		VolumeOptions: &mounttypes.VolumeOptions{DriverConfig: &mounttypes.Driver{Name: "local"}},
+	}}))
+}
+
+func TestRemoveEnv(t *testing.T) {
+	env := removeEnv([]string{"FOO=1", "CONTAINER_ID=abc", "BAR=2"}, "CONTAINER_ID")
+	assert.Check(t, is.DeepEqual(env, []string{"FOO=1", "BAR=2"}))
+}
+
+func TestAutoRemoveSkipsClaimedContainer(t *testing.T) {
+	// The daemon has nothing to remove the container with, so this fails
+	// if the container is removed.
+	d := &Daemon{}
+	ctr := &container.Container{
+		ID:         "0123456789abcdef",
+		State:      container.NewState(),
+		HostConfig: &containertypes.HostConfig{AutoRemove: true},
+	}
+	assert.Check(t, !ctr.SetRemovalInProgress())
+	d.autoRemove(ctr)
+}
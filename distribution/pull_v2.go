@@ -202,6 +202,7 @@ func (ld *v2LayerDescriptor) Read(p []byte) (int, error) {
 
 		logDownloadRetry(ld.downloadRetries, "waiting %vs before retrying layer download", sleepDurationInSecs)
 
+		retry := true
 		for sleepDurationInSecs > 0 {
 			if ld.ctx.Err() == context.Canceled {
 				// Stop the pull immediately on context cancelation, caused
@@ -211,7 +212,12 @@ func (ld *v2LayerDescriptor) Read(p []byte) (int, error) {
 				return 0, context.Canceled
 			}
 			plural := (map[bool]string{true: "s"})[sleepDurationInSecs != 1]
-			progress.Updatef(ld.progressOutput, ld.ID(), "Retrying in %v second%v", sleepDurationInSecs, plural)
+			ld.progressOutput.WriteProgress(progress.Progress{
+				ID:     ld.ID(),
+				Action: fmt.Sprintf("Retrying in %v second%v", sleepDurationInSecs, plural),
+				Retry:  retry,
+			})
+			retry = false
 			time.Sleep(time.Second)
 			sleepDurationInSecs--
 		}
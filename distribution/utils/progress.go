@@ -14,7 +14,34 @@ import (
 // WriteDistributionProgress is a helper for writing progress from chan to JSON
 // stream with an optional cancel function.
 func WriteDistributionProgress(cancelFunc func(), outStream io.Writer, progressChan <-chan progress.Progress) {
-	progressOutput := streamformatter.NewJSONProgressOutput(outStream, false)
+	writeProgress(cancelFunc, unstructuredOutput{streamformatter.NewJSONProgressOutput(outStream, false)}, progressChan)
+}
+
+// unstructuredOutput is a progress.Output dropping the progress only meant
+// for structured pull progress reports.
+type unstructuredOutput struct {
+	progress.Output
+}
+
+func (out unstructuredOutput) WriteProgress(prog progress.Progress) error {
+	if prog.StructuredOnly {
+		return nil
+	}
+	return out.Output.WriteProgress(prog)
+}
+
+// WriteStructuredPullProgress is like WriteDistributionProgress, but also
+// writes types.PullProgress reports summarizing the state of a pull.
+func WriteStructuredPullProgress(cancelFunc func(), outStream io.Writer, progressChan <-chan progress.Progress) {
+	progressOutput := NewPullProgressOutput(streamformatter.NewJSONProgressOutput(outStream, false))
+	if !writeProgress(cancelFunc, progressOutput, progressChan) {
+		progressOutput.Finish()
+	}
+}
+
+// writeProgress writes progress from chan to progressOutput, and reports
+// whether the operation was cancelled because of a write error.
+func writeProgress(cancelFunc func(), progressOutput progress.Output, progressChan <-chan progress.Progress) bool {
 	operationCancelled := false
 
 	for prog := range progressChan {
@@ -31,6 +58,7 @@ func WriteDistributionProgress(cancelFunc func(), outStream io.Writer, progressC
 			// progressChan until it's closed to avoid a deadlock.
 		}
 	}
+	return operationCancelled
 }
 
 func isBrokenPipe(e error) bool {
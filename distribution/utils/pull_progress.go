@@ -0,0 +1,194 @@
+package utils // import "github.com/docker/docker/distribution/utils"
+
+import (
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/progress"
+)
+
+const (
+	// pullProgressInterval is the minimum interval between two structured
+	// progress reports.
+	pullProgressInterval = time.Second
+	// rateSmoothing is the weight of the most recent sample in the
+	// exponentially weighted moving average of the transfer rate.
+	rateSmoothing = 0.3
+	// totalID is the ID of the aggregate progress written by the layer
+	// download manager.
+	totalID = "Total"
+)
+
+type layerPullState struct {
+	types.LayerPullProgress
+	delta    bool
+	started  time.Time
+	received time.Time
+}
+
+// PullProgressOutput is a progress.Output that forwards progress to another
+// output, except the progress only meant for the reports, and in addition emits a types.PullProgress report,
+// identified by types.PullProgressID, summarizing the state of the pull.
+// Reports are rate limited; Finish emits the final one.
+type PullProgressOutput struct {
+	mu     sync.Mutex
+	out    progress.Output
+	now    func() time.Time
+	layers map[string]*layerPullState
+	order  []string
+
+	current    int64
+	total      int64
+	retries    int
+	rate       float64
+	lastSample time.Time
+	lastBytes  int64
+	lastReport time.Time
+}
+
+// NewPullProgressOutput returns a PullProgressOutput that writes to out.
+func NewPullProgressOutput(out progress.Output) *PullProgressOutput {
+	return &PullProgressOutput{
+		out:    out,
+		now:    time.Now,
+		layers: make(map[string]*layerPullState),
+	}
+}
+
+// WriteProgress forwards prog to the underlying output and updates the
+// state of the pull.
+func (p *PullProgressOutput) WriteProgress(prog progress.Progress) error {
+	if !prog.StructuredOnly {
+		if err := p.out.WriteProgress(prog); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if prog.ID == totalID {
+		p.current = prog.Current
+		if prog.Total > 0 {
+			p.total = prog.Total
+		}
+		p.sampleRate(now)
+	} else if prog.ID != "" && prog.Message == "" && prog.Aux == nil {
+		p.updateLayer(prog, now)
+	} else {
+		return nil
+	}
+
+	if now.Sub(p.lastReport) < pullProgressInterval {
+		return nil
+	}
+	return p.report(now, false)
+}
+
+// Finish emits the final report of the pull.
+func (p *PullProgressOutput) Finish() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.report(p.now(), true)
+}
+
+func (p *PullProgressOutput) updateLayer(prog progress.Progress, now time.Time) {
+	l, ok := p.layers[prog.ID]
+	if !ok {
+		l = &layerPullState{LayerPullProgress: types.LayerPullProgress{ID: prog.ID}}
+		p.layers[prog.ID] = l
+		p.order = append(p.order, prog.ID)
+	}
+
+	if prog.Retry {
+		// The countdown until the retry that follows doesn't change the
+		// phase.
+		p.retries++
+		l.Retries++
+		l.Phase = types.PullPhaseRetrying
+		return
+	}
+
+	switch prog.Action {
+	case "Pulling fs layer", "Waiting":
+		l.Phase = types.PullPhaseWaiting
+	case "Ready to download":
+		l.Phase = types.PullPhaseDownloading
+		if l.started.IsZero() {
+			l.started = now
+		}
+	case "Applying delta":
+		l.delta = true
+	case "Extracting":
+		if l.started.IsZero() {
+			l.started = now
+		}
+		l.Current, l.Total = prog.Current, prog.Total
+		switch {
+		case l.Total > 0 && l.Current >= l.Total:
+			if l.received.IsZero() {
+				l.received = now
+			}
+			l.Phase = types.PullPhaseExtracting
+		case l.delta:
+			l.Phase = types.PullPhasePatching
+		default:
+			l.Phase = types.PullPhaseDownloading
+		}
+	case "Pull complete":
+		if l.received.IsZero() {
+			l.received = now
+		}
+		if !l.started.IsZero() {
+			l.DownloadDuration = l.received.Sub(l.started)
+		}
+		l.ExtractDuration = now.Sub(l.received)
+		l.Phase = types.PullPhaseComplete
+	case "Already exists":
+		l.Phase = types.PullPhaseExists
+	}
+}
+
+func (p *PullProgressOutput) sampleRate(now time.Time) {
+	if p.lastSample.IsZero() {
+		p.lastSample, p.lastBytes = now, p.current
+		return
+	}
+	elapsed := now.Sub(p.lastSample)
+	if elapsed < 100*time.Millisecond {
+		return
+	}
+	sample := float64(p.current-p.lastBytes) / elapsed.Seconds()
+	if p.rate == 0 {
+		p.rate = sample
+	} else {
+		p.rate = rateSmoothing*sample + (1-rateSmoothing)*p.rate
+	}
+	p.lastSample, p.lastBytes = now, p.current
+}
+
+func (p *PullProgressOutput) report(now time.Time, done bool) error {
+	p.lastReport = now
+
+	pp := types.PullProgress{
+		Current: p.current,
+		Total:   p.total,
+		Retries: p.retries,
+		Done:    done,
+		Layers:  make([]types.LayerPullProgress, 0, len(p.order)),
+	}
+	for _, id := range p.order {
+		pp.Layers = append(pp.Layers, p.layers[id].LayerPullProgress)
+	}
+	if !done {
+		pp.Rate = int64(p.rate)
+		if pp.Rate > 0 && pp.Total > pp.Current {
+			pp.ETA = (pp.Total - pp.Current + pp.Rate - 1) / pp.Rate
+		}
+	}
+
+	return p.out.WriteProgress(progress.Progress{ID: types.PullProgressID, Aux: &pp})
+}
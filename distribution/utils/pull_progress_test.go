@@ -0,0 +1,107 @@
+package utils // import "github.com/docker/docker/distribution/utils"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/progress"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type recordingOutput struct {
+	progress []progress.Progress
+}
+
+func (o *recordingOutput) WriteProgress(p progress.Progress) error {
+	o.progress = append(o.progress, p)
+	return nil
+}
+
+func (o *recordingOutput) reports() []*types.PullProgress {
+	var reports []*types.PullProgress
+	for _, p := range o.progress {
+		if p.ID == types.PullProgressID {
+			reports = append(reports, p.Aux.(*types.PullProgress))
+		}
+	}
+	return reports
+}
+
+func TestPullProgressOutput(t *testing.T) {
+	rec := &recordingOutput{}
+	out := NewPullProgressOutput(rec)
+	now := time.Unix(0, 0)
+	out.now = func() time.Time { return now }
+
+	write := func(p progress.Progress) {
+		assert.NilError(t, out.WriteProgress(p))
+	}
+
+	write(progress.Progress{ID: "a", Action: "Already exists"})
+	write(progress.Progress{ID: "b", Action: "Pulling fs layer"})
+	write(progress.Progress{ID: "c", Action: "Pulling fs layer"})
+	write(progress.Progress{ID: "b", Action: "Ready to download"})
+	write(progress.Progress{ID: "c", Action: "Ready to download"})
+	write(progress.Progress{ID: "c", Action: "Applying delta", StructuredOnly: true})
+
+	now = now.Add(time.Second)
+	write(progress.Progress{ID: "b", Action: "Extracting", Current: 100, Total: 100})
+	write(progress.Progress{ID: "c", Action: "Extracting", Current: 50, Total: 300})
+	write(progress.Progress{ID: "Total", Current: 150, Total: 400})
+
+	now = now.Add(500 * time.Millisecond)
+	write(progress.Progress{ID: "c", Action: "Retrying in 2 seconds", Retry: true})
+	write(progress.Progress{ID: "c", Action: "Retrying in 1 second"})
+	now = now.Add(500 * time.Millisecond)
+	write(progress.Progress{ID: "Total", Current: 250, Total: 400})
+
+	reports := rec.reports()
+	assert.Assert(t, is.Len(reports, 3))
+	r := reports[2]
+	assert.Check(t, is.Equal(r.Current, int64(250)))
+	assert.Check(t, is.Equal(r.Total, int64(400)))
+	assert.Check(t, is.Equal(r.Rate, int64(100)))
+	assert.Check(t, is.Equal(r.ETA, int64(2)))
+	assert.Check(t, is.Equal(r.Retries, 1))
+	assert.Assert(t, is.Len(r.Layers, 3))
+	assert.Check(t, is.Equal(r.Layers[0].Phase, types.PullPhaseExists))
+	assert.Check(t, is.Equal(r.Layers[1].Phase, types.PullPhaseExtracting))
+	assert.Check(t, is.Equal(r.Layers[2].Phase, types.PullPhaseRetrying))
+
+	write(progress.Progress{ID: "c", Action: "Extracting", Current: 200, Total: 300})
+	layerC := out.layers["c"].LayerPullProgress
+	assert.Check(t, is.Equal(layerC.Phase, types.PullPhasePatching))
+
+	// Every retry is counted, even if it starts over from the first
+	// attempt.
+	write(progress.Progress{ID: "c", Action: "Retrying in 2 seconds", Retry: true})
+	write(progress.Progress{ID: "c", Action: "Extracting", Current: 250, Total: 300})
+	write(progress.Progress{ID: "c", Action: "Retrying in 2 seconds", Retry: true})
+	assert.Check(t, is.Equal(out.retries, 3))
+	assert.Check(t, is.Equal(out.layers["c"].Retries, 3))
+
+	now = now.Add(3 * time.Second)
+	write(progress.Progress{ID: "c", Action: "Extracting", Current: 300, Total: 300})
+	now = now.Add(time.Second)
+	write(progress.Progress{ID: "b", Action: "Pull complete"})
+	write(progress.Progress{ID: "c", Action: "Pull complete"})
+	assert.NilError(t, out.Finish())
+
+	reports = rec.reports()
+	r = reports[len(reports)-1]
+	assert.Check(t, r.Done)
+	assert.Check(t, is.Equal(r.Layers[1].Phase, types.PullPhaseComplete))
+	assert.Check(t, is.Equal(r.Layers[1].DownloadDuration, time.Second))
+	assert.Check(t, is.Equal(r.Layers[1].ExtractDuration, 5*time.Second))
+	assert.Check(t, is.Equal(r.Layers[2].DownloadDuration, 5*time.Second))
+	assert.Check(t, is.Equal(r.Layers[2].ExtractDuration, time.Second))
+
+	// All progress but the one only meant for the reports is forwarded to
+	// the underlying output.
+	assert.Check(t, is.Len(rec.progress, 18+len(reports)))
+	for _, p := range rec.progress {
+		assert.Check(t, !p.StructuredOnly)
+	}
+}
@@ -311,9 +311,16 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				delay := retries * 5
 				ticker := time.NewTicker(ldm.waitDuration)
 
+				retry := true
+
 			selectLoop:
 				for {
-					progress.Updatef(progressOutput, descriptor.ID(), "Retrying in %d second%s", delay, (map[bool]string{true: "s"})[delay != 1])
+					progressOutput.WriteProgress(progress.Progress{
+						ID:     descriptor.ID(),
+						Action: fmt.Sprintf("Retrying in %d second%s", delay, (map[bool]string{true: "s"})[delay != 1]),
+						Retry:  retry,
+					})
+					retry = false
 					select {
 					case <-ticker.C:
 						delay--
@@ -350,6 +357,15 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				parentLayer = l.ChainID()
 			}
 
+			deltaBase := descriptor.DeltaBase()
+			if deltaBase != nil {
+				progressOutput.WriteProgress(progress.Progress{
+					ID:             descriptor.ID(),
+					Action:         "Applying delta",
+					StructuredOnly: true,
+				})
+			}
+
 			reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(d.Transfer.Context(), downloadReader), progressOutput, size, descriptor.ID(), "Extracting")
 			defer reader.Close()
 
@@ -360,7 +376,6 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 			}
 			defer inflatedLayerData.Close()
 
-			deltaBase := descriptor.DeltaBase()
 			layerData := DecorateWithDeltaPatcher(inflatedLayerData, deltaBase)
 
 			var src distribution.Descriptor
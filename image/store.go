@@ -1,7 +1,9 @@
 package image // import "github.com/docker/docker/image"
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -25,12 +27,22 @@ type Store interface {
 	GetParent(id ID) (ID, error)
 	SetLastUpdated(id ID) error
 	GetLastUpdated(id ID) (time.Time, error)
+	SetPins(id ID, pins []Pin) error
+	GetPins(id ID) ([]Pin, error)
 	Children(id ID) []ID
 	Map() map[ID]*Image
 	Heads() map[ID]*Image
 	Len() int
 }
 
+// Pin protects an image from being removed by a prune or a non-forced image
+// delete. Each owner holds at most one pin on an image.
+type Pin struct {
+	Owner   string
+	Reason  string `json:",omitempty"`
+	Created time.Time
+}
+
 // LayerGetReleaser is a minimal interface for getting and releasing images.
 type LayerGetReleaser interface {
 	Get(layer.ChainID) (layer.Layer, error)
@@ -336,6 +348,39 @@ func (is *store) GetLastUpdated(id ID) (time.Time, error) {
 	return time.Parse(time.RFC3339Nano, string(bytes))
 }
 
+// SetPins records the pins protecting the image ID from removal. An empty
+// list removes all pins.
+func (is *store) SetPins(id ID, pins []Pin) error {
+	if len(pins) == 0 {
+		return is.fs.DeleteMetadata(id.Digest(), "pins")
+	}
+	data, err := json.Marshal(pins)
+	if err != nil {
+		return err
+	}
+	return is.fs.SetMetadata(id.Digest(), "pins", data)
+}
+
+// GetPins returns the pins protecting the image ID from removal
+func (is *store) GetPins(id ID) ([]Pin, error) {
+	data, err := is.fs.GetMetadata(id.Digest(), "pins")
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			// Not pinned
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var pins []Pin
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
 func (is *store) Children(id ID) []ID {
 	is.RLock()
 	defer is.RUnlock()
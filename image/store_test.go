@@ -2,8 +2,11 @@ package image // import "github.com/docker/docker/image"
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/layer"
 	"gotest.tools/v3/assert"
@@ -179,6 +182,46 @@ func TestGetAndSetLastUpdated(t *testing.T) {
 	assert.Check(t, cmp.Equal(updated.IsZero(), false))
 }
 
+func TestGetAndSetPins(t *testing.T) {
+	store, cleanup := defaultImageStore(t)
+	defer cleanup()
+
+	id, err := store.Create([]byte(`{"comment": "abc1", "rootfs": {"type": "layers"}}`))
+	assert.NilError(t, err)
+
+	pins, err := store.GetPins(id)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Len(pins, 0))
+
+	pin := Pin{Owner: "supervisor", Reason: "rollback", Created: time.Unix(1600000000, 0).UTC()}
+	assert.NilError(t, store.SetPins(id, []Pin{pin}))
+
+	pins, err = store.GetPins(id)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.DeepEqual(pins, []Pin{pin}))
+
+	assert.NilError(t, store.SetPins(id, nil))
+	pins, err = store.GetPins(id)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Len(pins, 0))
+}
+
+func TestGetPinsReadError(t *testing.T) {
+	fsBackend, cleanup := defaultFSStoreBackend(t)
+	defer cleanup()
+	store, err := NewImageStore(fsBackend, map[string]LayerGetReleaser{runtime.GOOS: &mockLayerGetReleaser{}})
+	assert.NilError(t, err)
+
+	id, err := store.Create([]byte(`{"comment": "abc1", "rootfs": {"type": "layers"}}`))
+	assert.NilError(t, err)
+
+	// Errors other than the pins not existing must not be taken for the
+	// image not being pinned.
+	assert.NilError(t, os.MkdirAll(filepath.Join(fsBackend.(*fs).metadataDir(id.Digest()), "pins"), 0700))
+	_, err = store.GetPins(id)
+	assert.Check(t, err != nil)
+}
+
 func TestStoreLen(t *testing.T) {
 	store, cleanup := defaultImageStore(t)
 	defer cleanup()
@@ -0,0 +1,67 @@
+package container // import "github.com/docker/docker/integration/container"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/integration/internal/container"
+	"github.com/docker/docker/testutil/daemon"
+	"golang.org/x/sys/unix"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+	"gotest.tools/v3/skip"
+)
+
+// TestDeviceHotplug adds and removes a loop device while a container allowed
+// to access loop devices is running.
+func TestDeviceHotplug(t *testing.T) {
+	skip.If(t, testEnv.IsRemoteDaemon, "cannot start daemon on remote test run")
+	skip.If(t, testEnv.IsRootless, "rootless mode cannot create device nodes")
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	loopControl, err := os.OpenFile("/dev/loop-control", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("loop devices are not available: %v", err)
+	}
+	defer loopControl.Close()
+
+	d := daemon.New(t)
+	d.StartWithBusybox(t, "--iptables=false", "--device-hotplug")
+	defer d.Stop(t)
+	client := d.NewClientT(t)
+	ctx := context.Background()
+
+	id := container.Run(ctx, t, client, container.WithCmd("top"), func(c *container.TestContainerConfig) {
+		c.HostConfig.DeviceCgroupRules = []string{"b 7:* rwm"}
+	})
+
+	minor := 200 + os.Getpid()%50
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, loopControl.Fd(), unix.LOOP_CTL_ADD, uintptr(minor)); errno != 0 {
+		t.Skipf("cannot add loop device %d: %v", minor, errno)
+	}
+	defer unix.Syscall(unix.SYS_IOCTL, loopControl.Fd(), unix.LOOP_CTL_REMOVE, uintptr(minor))
+
+	node := fmt.Sprintf("/dev/loop%d", minor)
+	poll.WaitOn(t, deviceNodeExists(ctx, client, id, node, true), poll.WithDelay(100*time.Millisecond))
+
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, loopControl.Fd(), unix.LOOP_CTL_REMOVE, uintptr(minor))
+	assert.Assert(t, errno == 0, "failed to remove loop device: %v", errno)
+	poll.WaitOn(t, deviceNodeExists(ctx, client, id, node, false), poll.WithDelay(100*time.Millisecond))
+}
+
+func deviceNodeExists(ctx context.Context, client client.APIClient, id, node string, exists bool) func(poll.LogT) poll.Result {
+	return func(poll.LogT) poll.Result {
+		res, err := container.Exec(ctx, client, id, []string{"test", "-b", node})
+		if err != nil {
+			return poll.Error(err)
+		}
+		if (res.ExitCode == 0) != exists {
+			return poll.Continue("waiting for %s to exist=%v", node, exists)
+		}
+		return poll.Success()
+	}
+}
@@ -2,13 +2,18 @@ package container // import "github.com/docker/docker/integration/container"
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/integration/internal/container"
 	"github.com/docker/docker/pkg/stdcopy"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
 	"gotest.tools/v3/skip"
 )
 
@@ -32,3 +37,36 @@ func TestLogsFollowTailEmpty(t *testing.T) {
 	_, err = stdcopy.StdCopy(ioutil.Discard, ioutil.Discard, logs)
 	assert.Check(t, err)
 }
+
+func TestContainersLogsMerged(t *testing.T) {
+	skip.If(t, testEnv.DaemonInfo.OSType == "windows")
+	defer setupTest(t)()
+	client := testEnv.APIClient()
+	ctx := context.Background()
+
+	withLabel := func(c *container.TestContainerConfig) {
+		c.Config.Labels = map[string]string{"app": "merged-logs"}
+	}
+	first := container.Run(ctx, t, client, withLabel, container.WithName("first"), container.WithCmd("echo", "one"))
+	poll.WaitOn(t, container.IsStopped(ctx, client, first), poll.WithDelay(100*time.Millisecond))
+	second := container.Run(ctx, t, client, withLabel, container.WithName("second"), container.WithCmd("echo", "two"))
+	poll.WaitOn(t, container.IsStopped(ctx, client, second), poll.WithDelay(100*time.Millisecond))
+	container.Run(ctx, t, client, container.WithCmd("echo", "other"))
+
+	msgs, errs := client.ContainersLogs(ctx, types.ContainersLogsOptions{
+		Filters: filters.NewArgs(filters.Arg("label", "app=merged-logs")),
+	})
+	var lines []string
+	for {
+		select {
+		case msg := <-msgs:
+			assert.Check(t, is.Equal(msg.Error, ""))
+			lines = append(lines, msg.ContainerName+": "+msg.Line)
+			continue
+		case err := <-errs:
+			assert.Check(t, is.Equal(err, io.EOF))
+		}
+		break
+	}
+	assert.Check(t, is.DeepEqual(lines, []string{"first: one\n", "second: two\n"}))
+}
@@ -0,0 +1,76 @@
+package secret // import "github.com/docker/docker/integration/secret"
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	swarmtypes "github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/integration/internal/container"
+	"github.com/docker/docker/testutil/daemon"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+func TestStandaloneSecretsAndConfigs(t *testing.T) {
+	skip.If(t, testEnv.IsRemoteDaemon, "cannot run daemon when remote daemon")
+	skip.If(t, testEnv.DaemonInfo.OSType != "linux")
+	skip.If(t, testEnv.IsRootless, "secrets are mounted on a tmpfs owned by root")
+	defer setupTest(t)()
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	assert.NilError(t, ioutil.WriteFile(keyFile, []byte("secret store key"), 0600))
+
+	d := daemon.New(t)
+	d.StartWithBusybox(t, "--secret-store-key=file:"+keyFile)
+	defer d.Stop(t)
+	c := d.NewClientT(t)
+	defer c.Close()
+
+	ctx := context.Background()
+
+	secret, err := c.SecretCreate(ctx, swarmtypes.SecretSpec{
+		Annotations: swarmtypes.Annotations{Name: "password"},
+		Data:        []byte("hunter2"),
+	})
+	assert.NilError(t, err)
+	_, err = c.ConfigCreate(ctx, swarmtypes.ConfigSpec{
+		Annotations: swarmtypes.Annotations{Name: "app-config"},
+		Data:        []byte("debug=true"),
+	})
+	assert.NilError(t, err)
+
+	id := container.Run(ctx, t, c, func(tc *container.TestContainerConfig) {
+		tc.HostConfig.Secrets = []containertypes.FileReference{{Name: "password"}}
+		tc.HostConfig.Configs = []containertypes.FileReference{{Name: "app-config", Target: "/etc/app.conf", Mode: 0400}}
+	})
+
+	res, err := container.Exec(ctx, c, id, []string{"cat", "/run/secrets/password"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(res.ExitCode, 0))
+	assert.Check(t, is.Equal(res.Stdout(), "hunter2"))
+
+	res, err = container.Exec(ctx, c, id, []string{"stat", "-c", "%a", "/etc/app.conf"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(res.Stdout(), "400\n"))
+
+	// A secret can't be removed while a container references it.
+	err = c.SecretRemove(ctx, secret.ID)
+	assert.Check(t, errdefs.IsConflict(err), "got: %v", err)
+
+	container.CreateExpectingErr(ctx, t, c, "secret missing not found", func(tc *container.TestContainerConfig) {
+		tc.HostConfig.Secrets = []containertypes.FileReference{{Name: "missing"}}
+	})
+
+	// Secrets are still readable after a restart of the daemon.
+	d.Restart(t, "--secret-store-key=file:"+keyFile)
+	assert.NilError(t, c.ContainerStart(ctx, id, types.ContainerStartOptions{}))
+	res, err = container.Exec(ctx, c, id, []string{"cat", "/run/secrets/password"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(res.Stdout(), "hunter2"))
+}
@@ -10,12 +10,17 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/versions"
 	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/integration/internal/container"
 	"github.com/docker/docker/testutil/request"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+	"gotest.tools/v3/skip"
 )
 
 func TestVolumesCreateAndList(t *testing.T) {
@@ -104,6 +109,46 @@ func TestVolumesInspect(t *testing.T) {
 	assert.Check(t, createdAt.Unix()-now.Unix() < 60, "CreatedAt (%s) exceeds creation time (%s) 60s", createdAt, now)
 }
 
+func TestVolumesExportImportSnapshot(t *testing.T) {
+	skip.If(t, testEnv.OSType == "windows", "export and snapshot are not supported by the windows local driver")
+	skip.If(t, versions.LessThan(testEnv.DaemonAPIVersion(), "1.41"), "requires API v1.41")
+	defer setupTest(t)()
+	client := testEnv.APIClient()
+	ctx := context.Background()
+
+	src, err := client.VolumeCreate(ctx, volumetypes.VolumeCreateBody{Labels: map[string]string{"origin": "test"}})
+	assert.NilError(t, err)
+	id := container.Run(ctx, t, client,
+		container.WithMount(mounttypes.Mount{Type: mounttypes.TypeVolume, Source: src.Name, Target: "/vol"}),
+		container.WithCmd("sh", "-c", "echo hello > /vol/hello"),
+	)
+	poll.WaitOn(t, container.IsStopped(ctx, client, id), poll.WithDelay(100*time.Millisecond))
+
+	snap, err := client.VolumeSnapshot(ctx, src.Name, volumetypes.VolumeSnapshotBody{Name: "snapshot-" + src.Name})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(snap.Name, "snapshot-"+src.Name))
+	assert.Check(t, is.DeepEqual(snap.Labels, src.Labels))
+
+	_, err = client.VolumeSnapshot(ctx, src.Name, volumetypes.VolumeSnapshotBody{Name: snap.Name})
+	assert.Check(t, errdefs.IsConflict(err), "got: %v", err)
+
+	rdr, err := client.VolumeExport(ctx, snap.Name)
+	assert.NilError(t, err)
+	dst, err := client.VolumeCreate(ctx, volumetypes.VolumeCreateBody{})
+	assert.NilError(t, err)
+	err = client.VolumeImport(ctx, dst.Name, rdr)
+	rdr.Close()
+	assert.NilError(t, err)
+
+	id = container.Run(ctx, t, client,
+		container.WithMount(mounttypes.Mount{Type: mounttypes.TypeVolume, Source: dst.Name, Target: "/vol"}),
+	)
+	res, err := container.Exec(ctx, client, id, []string{"cat", "/vol/hello"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(res.ExitCode, 0))
+	assert.Check(t, is.Equal(strings.TrimSpace(res.Stdout()), "hello"))
+}
+
 func TestVolumesInvalidJSON(t *testing.T) {
 	defer setupTest(t)()
 
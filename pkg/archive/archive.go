@@ -54,6 +54,9 @@ type (
 		// replaced with the matching name from this map.
 		RebaseNames map[string]string
 		InUserNS    bool
+		// When creating an archive, stores all the extended attributes of
+		// the files, instead of only security.capability.
+		IncludeXattrs bool
 	}
 )
 
@@ -443,6 +446,9 @@ type tarAppender struct {
 	// by the AUFS standard are used as the tar whiteout
 	// standard.
 	WhiteoutConverter tarWhiteoutConverter
+
+	// IncludeXattrs stores all the extended attributes of the files.
+	IncludeXattrs bool
 }
 
 func newTarAppender(idMapping *idtools.IdentityMapping, writer io.Writer, chownOpts *idtools.Identity) *tarAppender {
@@ -490,6 +496,11 @@ func (ta *tarAppender) addTarFile(path, name string) error {
 	if err := ReadSecurityXattrToTarHeader(path, hdr); err != nil {
 		return err
 	}
+	if ta.IncludeXattrs {
+		if err := readXattrsToTarHeader(path, hdr); err != nil {
+			return err
+		}
+	}
 
 	// if it's not a directory and has more than 1 link,
 	// it's hard linked, so set the type flag accordingly
@@ -770,6 +781,7 @@ func TarWithOptions(srcPath string, options *TarOptions) (io.ReadCloser, error)
 			options.ChownOpts,
 		)
 		ta.WhiteoutConverter = whiteoutConverter
+		ta.IncludeXattrs = options.IncludeXattrs
 
 		defer func() {
 			// Make sure to check the error on Close.
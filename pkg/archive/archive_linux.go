@@ -11,6 +11,33 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// readXattrsToTarHeader reads the extended attributes of path, other than
+// security.capability which ReadSecurityXattrToTarHeader converts, to a tar
+// header.
+func readXattrsToTarHeader(path string, hdr *tar.Header) error {
+	attrs, err := system.Llistxattr(path)
+	if err != nil {
+		if err == unix.ENOTSUP {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to list xattrs of %s", path)
+	}
+	for _, attr := range attrs {
+		if attr == "security.capability" {
+			continue
+		}
+		value, err := system.Lgetxattr(path, attr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get xattr %s of %s", attr, path)
+		}
+		if hdr.Xattrs == nil {
+			hdr.Xattrs = make(map[string]string)
+		}
+		hdr.Xattrs[attr] = string(value)
+	}
+	return nil
+}
+
 func getWhiteoutConverter(format WhiteoutFormat, inUserNS bool) (tarWhiteoutConverter, error) {
 	if format == OverlayWhiteoutFormat {
 		if inUserNS {
@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/pkg/system"
 	"golang.org/x/sys/unix"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/skip"
 )
 
@@ -162,3 +163,31 @@ func TestOverlayTarAUFSUntar(t *testing.T) {
 	checkFileMode(t, filepath.Join(dst, "d2", "f1"), 0660)
 	checkFileMode(t, filepath.Join(dst, "d3", WhiteoutPrefix+"f1"), 0600)
 }
+
+func TestTarUntarXattrs(t *testing.T) {
+	src := t.TempDir()
+	file := filepath.Join(src, "f1")
+	assert.NilError(t, ioutil.WriteFile(file, []byte("hello"), 0600))
+	if err := system.Lsetxattr(file, "user.origin", []byte("test"), 0); err == unix.ENOTSUP {
+		t.Skip("user xattrs are not supported by the filesystem")
+	} else {
+		assert.NilError(t, err)
+	}
+
+	for _, includeXattrs := range []bool{false, true} {
+		dst := t.TempDir()
+		archive, err := TarWithOptions(src, &TarOptions{IncludeXattrs: includeXattrs})
+		assert.NilError(t, err)
+		err = Untar(archive, dst, &TarOptions{})
+		archive.Close()
+		assert.NilError(t, err)
+
+		value, err := system.Lgetxattr(filepath.Join(dst, "f1"), "user.origin")
+		assert.NilError(t, err)
+		if includeXattrs {
+			assert.Check(t, is.Equal(string(value), "test"))
+		} else {
+			assert.Check(t, is.Nil(value))
+		}
+	}
+}
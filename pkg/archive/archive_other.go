@@ -3,6 +3,13 @@
 
 package archive // import "github.com/docker/docker/pkg/archive"
 
+import "archive/tar"
+
+// readXattrsToTarHeader is a no-op on platforms other than linux.
+func readXattrsToTarHeader(path string, hdr *tar.Header) error {
+	return nil
+}
+
 func getWhiteoutConverter(format WhiteoutFormat, inUserNS bool) (tarWhiteoutConverter, error) {
 	return nil, nil
 }
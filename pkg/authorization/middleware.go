@@ -9,11 +9,13 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// Middleware uses a list of plugins to
+// Middleware uses a built-in policy and a list of plugins to
 // handle authorization in the API requests.
 type Middleware struct {
 	mu      sync.Mutex
 	plugins []Plugin
+	policy  *Policy
+	labels  LabelLookup
 }
 
 // NewMiddleware creates a new Middleware
@@ -31,6 +33,29 @@ func (m *Middleware) getAuthzPlugins() []Plugin {
 	return m.plugins
 }
 
+func (m *Middleware) getPolicy() (*Policy, LabelLookup) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.policy, m.labels
+}
+
+// SetPolicy sets the built-in policy requests are authorized with before
+// being passed to the authorization plugins. A nil policy allows all
+// requests.
+func (m *Middleware) SetPolicy(policy *Policy) {
+	m.mu.Lock()
+	m.policy = policy
+	m.mu.Unlock()
+}
+
+// SetLabelLookup sets the function used to get the labels of the objects
+// requests operate on, for policy rules matching labels.
+func (m *Middleware) SetLabelLookup(lookup LabelLookup) {
+	m.mu.Lock()
+	m.labels = lookup
+	m.mu.Unlock()
+}
+
 // SetPlugins sets the plugin used for authorization
 func (m *Middleware) SetPlugins(names []string) {
 	m.mu.Lock()
@@ -54,6 +79,12 @@ func (m *Middleware) RemovePlugin(name string) {
 // WrapHandler returns a new handler function wrapping the previous one in the request chain.
 func (m *Middleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		if policy, lookup := m.getPolicy(); policy != nil {
+			if err := policy.authorize(ctx, r, vars, lookup); err != nil {
+				return err
+			}
+		}
+
 		plugins := m.getAuthzPlugins()
 		if len(plugins) == 0 {
 			return handler(ctx, w, r, vars)
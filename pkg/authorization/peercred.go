@@ -0,0 +1,25 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import "context"
+
+// PeerCredentials are the credentials of the process at the other end of a
+// unix socket, as reported by the kernel when the connection was accepted.
+type PeerCredentials struct {
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+type peerCredentialsKey struct{}
+
+// WithPeerCredentials returns a copy of ctx carrying the peer credentials.
+func WithPeerCredentials(ctx context.Context, cred PeerCredentials) context.Context {
+	return context.WithValue(ctx, peerCredentialsKey{}, cred)
+}
+
+// PeerCredentialsFromContext returns the peer credentials stored in ctx by
+// ConnContext, if any.
+func PeerCredentialsFromContext(ctx context.Context) (PeerCredentials, bool) {
+	cred, ok := ctx.Value(peerCredentialsKey{}).(PeerCredentials)
+	return cred, ok
+}
@@ -0,0 +1,39 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"context"
+	"net"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// ConnContext is meant to be used as the ConnContext of an http.Server. For
+// unix socket connections, it stores the credentials of the peer process in
+// the context of the requests received on the connection.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		logrus.WithError(err).Debug("failed to get raw unix connection")
+		return ctx
+	}
+	var (
+		ucred   *unix.Ucred
+		credErr error
+	)
+	err = raw.Control(func(fd uintptr) {
+		ucred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err == nil {
+		err = credErr
+	}
+	if err != nil {
+		logrus.WithError(err).Debug("failed to get peer credentials of unix connection")
+		return ctx
+	}
+	return WithPeerCredentials(ctx, PeerCredentials{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid})
+}
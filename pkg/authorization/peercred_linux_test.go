@@ -0,0 +1,56 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestConnContext(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+
+	creds := make(chan PeerCredentials, 1)
+	srv := &http.Server{
+		ConnContext: ConnContext,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cred, ok := PeerCredentialsFromContext(r.Context())
+			assert.Check(t, ok)
+			creds <- cred
+		}),
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+			},
+		},
+	}
+	resp, err := client.Get("http://docker/_ping")
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	cred := <-creds
+	assert.Check(t, is.Equal(cred.UID, uint32(os.Getuid())))
+	assert.Check(t, is.Equal(cred.GID, uint32(os.Getgid())))
+	assert.Check(t, is.Equal(cred.PID, int32(os.Getpid())))
+}
+
+func TestConnContextTCP(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	_, ok := PeerCredentialsFromContext(ConnContext(context.Background(), server))
+	assert.Check(t, !ok)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"context"
+	"net"
+)
+
+// ConnContext is meant to be used as the ConnContext of an http.Server. Peer
+// credentials are not supported on this platform, so ctx is returned as is.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return ctx
+}
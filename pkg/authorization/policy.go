@@ -0,0 +1,321 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PolicyAllow is the action allowing the requests matched by a rule.
+	PolicyAllow = "allow"
+	// PolicyDeny is the action denying the requests matched by a rule.
+	PolicyDeny = "deny"
+)
+
+// Policy is an authorization policy evaluated by the daemon itself, without
+// an authorization plugin. The rules are evaluated in order, and the action
+// of the first rule matching a request is applied. Requests matching no rule
+// get the default action, which denies them unless set to "allow".
+type Policy struct {
+	DefaultAction string       `json:"default-action,omitempty"`
+	Rules         []PolicyRule `json:"rules,omitempty"`
+}
+
+// PolicyRule matches API requests on the identity of the caller, the route
+// and the method of the request, and the labels of the object the request
+// operates on. Fields left empty match any request.
+type PolicyRule struct {
+	// Name identifies the rule in logs and errors.
+	Name string `json:"name,omitempty"`
+	// Action is applied to the requests matched by the rule, "allow" or
+	// "deny".
+	Action string `json:"action"`
+	// UIDs and GIDs match the user ID and primary group ID of the processes
+	// connected to a unix socket. Users match the common name of the
+	// verified TLS client certificates. A caller matching any of them
+	// matches the rule.
+	UIDs  []uint32 `json:"uids,omitempty"`
+	GIDs  []uint32 `json:"gids,omitempty"`
+	Users []string `json:"users,omitempty"`
+	// Methods match the HTTP method of the request.
+	Methods []string `json:"methods,omitempty"`
+	// Routes match the path of the request, without the API version prefix.
+	// A "*" segment matches a single path segment and a "**" segment any
+	// number of segments, e.g. "/containers/*/json" or "/images/**".
+	Routes []string `json:"routes,omitempty"`
+	// Labels match the labels, as "key" or "key=value", of the object
+	// (container, image, network, volume, secret or config) the request
+	// operates on or creates. Requests for which the labels are unknown,
+	// such as listing containers or operating on an object that cannot be
+	// found, match "deny" rules with labels but never "allow" rules with
+	// labels.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// LabelLookup returns the labels of an object the API operates on. The kind
+// is the first segment of the route, e.g. "containers", "exec" or "images".
+type LabelLookup func(ctx context.Context, kind, name string) (map[string]string, error)
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9.]+/`)
+
+// Validate checks that the policy is well formed.
+func (p *Policy) Validate() error {
+	switch p.DefaultAction {
+	case "", PolicyAllow, PolicyDeny:
+	default:
+		return fmt.Errorf("invalid authorization policy default-action %q", p.DefaultAction)
+	}
+	for i, rule := range p.Rules {
+		name := rule.name(i)
+		switch rule.Action {
+		case PolicyAllow, PolicyDeny:
+		default:
+			return fmt.Errorf("authorization policy rule %s: invalid action %q", name, rule.Action)
+		}
+		for _, m := range rule.Methods {
+			if m == "" || strings.ContainsAny(m, " /") {
+				return fmt.Errorf("authorization policy rule %s: invalid method %q", name, m)
+			}
+		}
+		for _, r := range rule.Routes {
+			if !strings.HasPrefix(r, "/") {
+				return fmt.Errorf("authorization policy rule %s: route %q must start with /", name, r)
+			}
+			for _, seg := range strings.Split(r, "/") {
+				if _, err := path.Match(seg, ""); err != nil {
+					return fmt.Errorf("authorization policy rule %s: invalid route %q", name, r)
+				}
+			}
+		}
+		for _, l := range rule.Labels {
+			if strings.SplitN(l, "=", 2)[0] == "" {
+				return fmt.Errorf("authorization policy rule %s: invalid label %q", name, l)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *PolicyRule) name(i int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// policyRequest is an API request evaluated against a policy.
+type policyRequest struct {
+	cred   *PeerCredentials
+	user   string
+	method string
+	path   string
+	// labels returns the labels of the object the request operates on, and
+	// whether they are known.
+	labels func() (map[string]string, bool)
+}
+
+// evaluate returns whether the policy allows the request, and the name of
+// the rule that matched it, if any.
+func (p *Policy) evaluate(req *policyRequest) (bool, string) {
+	var (
+		labels      map[string]string
+		labelsKnown bool
+		labelsDone  bool
+	)
+	for i, rule := range p.Rules {
+		if !rule.matchCaller(req) || !rule.matchMethod(req.method) || !rule.matchRoute(req.path) {
+			continue
+		}
+		if len(rule.Labels) > 0 {
+			if !labelsDone {
+				labels, labelsKnown = req.labels()
+				labelsDone = true
+			}
+			// Rules denying requests fail closed on unknown labels.
+			if labelsKnown && !matchLabels(rule.Labels, labels) {
+				continue
+			}
+			if !labelsKnown && rule.Action != PolicyDeny {
+				continue
+			}
+		}
+		return rule.Action == PolicyAllow, rule.name(i)
+	}
+	return p.DefaultAction == PolicyAllow, ""
+}
+
+func (r *PolicyRule) matchCaller(req *policyRequest) bool {
+	if len(r.UIDs) == 0 && len(r.GIDs) == 0 && len(r.Users) == 0 {
+		return true
+	}
+	if req.cred != nil {
+		for _, uid := range r.UIDs {
+			if uid == req.cred.UID {
+				return true
+			}
+		}
+		for _, gid := range r.GIDs {
+			if gid == req.cred.GID {
+				return true
+			}
+		}
+	}
+	if req.user != "" {
+		for _, u := range r.Users {
+			if u == req.user {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *PolicyRule) matchMethod(method string) bool {
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, m := range r.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PolicyRule) matchRoute(p string) bool {
+	if len(r.Routes) == 0 {
+		return true
+	}
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+	for _, route := range r.Routes {
+		if matchSegments(strings.Split(strings.Trim(route, "/"), "/"), segs) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(segs); i >= 0; i-- {
+				if matchSegments(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+func matchLabels(filters []string, labels map[string]string) bool {
+	for _, f := range filters {
+		kv := strings.SplitN(f, "=", 2)
+		v, ok := labels[kv[0]]
+		if !ok || (len(kv) == 2 && v != kv[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// authorize evaluates the policy for an API request and returns an error if
+// the request is denied.
+func (p *Policy) authorize(ctx context.Context, r *http.Request, vars map[string]string, lookup LabelLookup) error {
+	req := &policyRequest{
+		method: r.Method,
+		path:   path.Clean("/" + versionPrefix.ReplaceAllString(r.URL.Path, "/")),
+	}
+	if cred, ok := PeerCredentialsFromContext(r.Context()); ok {
+		req.cred = &cred
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		req.user = r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+	req.labels = func() (map[string]string, bool) {
+		return requestLabels(ctx, r, vars, req.path, lookup)
+	}
+
+	allowed, rule := p.evaluate(req)
+
+	logger := logrus.WithFields(logrus.Fields{"method": req.method, "path": req.path})
+	if req.cred != nil {
+		logger = logger.WithFields(logrus.Fields{"uid": req.cred.UID, "gid": req.cred.GID, "pid": req.cred.PID})
+	}
+	if req.user != "" {
+		logger = logger.WithField("user", req.user)
+	}
+	if rule != "" {
+		logger = logger.WithField("rule", rule)
+	}
+	if allowed {
+		logger.Debug("request allowed by authorization policy")
+		return nil
+	}
+	logger.Info("request denied by authorization policy")
+	if rule == "" {
+		return authorizationError{error: fmt.Errorf("authorization denied by policy: no rule allows %s %s", req.method, req.path)}
+	}
+	return authorizationError{error: fmt.Errorf("authorization denied by policy rule %s", rule)}
+}
+
+// requestLabels returns the labels of the object a request operates on. For
+// requests creating an object, they are read from the request body;
+// otherwise they are looked up by the name in the route.
+func requestLabels(ctx context.Context, r *http.Request, vars map[string]string, p string, lookup LabelLookup) (map[string]string, bool) {
+	segs := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	kind := segs[0]
+	if len(segs) == 2 && segs[1] == "create" && r.Method == http.MethodPost {
+		switch kind {
+		case "configs", "containers", "networks", "secrets", "volumes":
+			return bodyLabels(r)
+		}
+	}
+
+	name := vars["name"]
+	if name == "" {
+		name = vars["id"]
+	}
+	if name == "" || lookup == nil {
+		return nil, false
+	}
+	labels, err := lookup(ctx, kind, name)
+	if err != nil {
+		logrus.WithError(err).WithField("path", p).Debug("failed to look up labels for authorization policy")
+		return nil, false
+	}
+	return labels, true
+}
+
+func bodyLabels(r *http.Request) (map[string]string, bool) {
+	if r.Body == nil {
+		return nil, false
+	}
+	body, newBody, err := drainBody(r.Body)
+	r.Body = newBody
+	if err != nil || body == nil {
+		return nil, false
+	}
+	var v struct {
+		Labels map[string]string
+	}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, false
+	}
+	return v.Labels, true
+}
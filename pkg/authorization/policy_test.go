@@ -0,0 +1,268 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		policy      Policy
+		expectedErr string
+	}{
+		{policy: Policy{DefaultAction: "allow"}},
+		{policy: Policy{DefaultAction: "maybe"}, expectedErr: `invalid authorization policy default-action "maybe"`},
+		{policy: Policy{Rules: []PolicyRule{{}}}, expectedErr: `authorization policy rule #1: invalid action ""`},
+		{policy: Policy{Rules: []PolicyRule{{Action: "deny", Methods: []string{"GET /"}}}}, expectedErr: `authorization policy rule #1: invalid method "GET /"`},
+		{policy: Policy{Rules: []PolicyRule{{Name: "r", Action: "deny", Routes: []string{"containers"}}}}, expectedErr: `authorization policy rule r: route "containers" must start with /`},
+		{policy: Policy{Rules: []PolicyRule{{Name: "r", Action: "deny", Routes: []string{"/containers/["}}}}, expectedErr: `authorization policy rule r: invalid route "/containers/["`},
+		{policy: Policy{Rules: []PolicyRule{{Name: "r", Action: "deny", Labels: []string{"=foo"}}}}, expectedErr: `authorization policy rule r: invalid label "=foo"`},
+		{policy: Policy{Rules: []PolicyRule{{Action: "allow", UIDs: []uint32{0}, Routes: []string{"/images/**"}, Labels: []string{"app", "tier=web"}}}}},
+	}
+	for _, tc := range testCases {
+		err := tc.policy.Validate()
+		if tc.expectedErr == "" {
+			assert.Check(t, err)
+		} else {
+			assert.Check(t, is.Error(err, tc.expectedErr))
+		}
+	}
+}
+
+func TestPolicyMatchRoute(t *testing.T) {
+	testCases := []struct {
+		route, path string
+		match       bool
+	}{
+		{"/", "/", true},
+		{"/_ping", "/_ping", true},
+		{"/_ping", "/version", false},
+		{"/containers/*/json", "/containers/abc/json", true},
+		{"/containers/*/json", "/containers/json", false},
+		{"/containers/*", "/containers/abc/json", false},
+		{"/containers/**", "/containers/abc/json", true},
+		{"/containers/**", "/containers", true},
+		{"/images/**/json", "/images/library/busybox/json", true},
+		{"/images/**/json", "/images/library/busybox/history", false},
+		{"/exec/*/st*", "/exec/abc/start", true},
+		{"/**", "/info", true},
+	}
+	for _, tc := range testCases {
+		r := PolicyRule{Routes: []string{tc.route}}
+		assert.Check(t, is.Equal(r.matchRoute(tc.path), tc.match), "route %s, path %s", tc.route, tc.path)
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := &Policy{
+		DefaultAction: "deny",
+		Rules: []PolicyRule{
+			{Name: "root", Action: "allow", UIDs: []uint32{0}},
+			{Name: "no-exec", Action: "deny", GIDs: []uint32{1001}, Routes: []string{"/containers/*/exec", "/exec/**"}},
+			{Name: "updater", Action: "allow", GIDs: []uint32{1001}, Methods: []string{"POST"}, Routes: []string{"/images/create", "/containers/create", "/containers/*/start"}},
+			{Name: "monitoring", Action: "allow", UIDs: []uint32{1002}, Users: []string{"prometheus"}, Methods: []string{"get", "HEAD"}},
+			{Name: "protected", Action: "deny", UIDs: []uint32{1004}, Methods: []string{"DELETE"}, Labels: []string{"protected"}},
+			{Name: "app", Action: "allow", UIDs: []uint32{1003, 1004}, Labels: []string{"owner=app"}},
+		},
+	}
+	labels := func(l map[string]string, known bool) func() (map[string]string, bool) {
+		return func() (map[string]string, bool) { return l, known }
+	}
+	noLabels := func() (map[string]string, bool) {
+		t.Error("labels must not be looked up")
+		return nil, false
+	}
+
+	testCases := []struct {
+		name    string
+		req     policyRequest
+		allowed bool
+		rule    string
+	}{
+		{
+			name:    "root",
+			req:     policyRequest{cred: &PeerCredentials{UID: 0, GID: 0}, method: "DELETE", path: "/containers/abc", labels: noLabels},
+			allowed: true,
+			rule:    "root",
+		},
+		{
+			name:    "updater exec",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1001, GID: 1001}, method: "POST", path: "/containers/abc/exec", labels: noLabels},
+			allowed: false,
+			rule:    "no-exec",
+		},
+		{
+			name:    "updater create",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1001, GID: 1001}, method: "POST", path: "/containers/create", labels: noLabels},
+			allowed: true,
+			rule:    "updater",
+		},
+		{
+			name:    "updater delete",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1001, GID: 1001}, method: "DELETE", path: "/containers/abc", labels: noLabels},
+			allowed: false,
+		},
+		{
+			name:    "monitoring uid",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1002, GID: 1002}, method: "GET", path: "/containers/json", labels: noLabels},
+			allowed: true,
+			rule:    "monitoring",
+		},
+		{
+			name:    "monitoring TLS user",
+			req:     policyRequest{user: "prometheus", method: "GET", path: "/info", labels: noLabels},
+			allowed: true,
+			rule:    "monitoring",
+		},
+		{
+			name:    "monitoring write",
+			req:     policyRequest{user: "prometheus", method: "POST", path: "/containers/abc/stop", labels: noLabels},
+			allowed: false,
+		},
+		{
+			name:    "anonymous",
+			req:     policyRequest{method: "GET", path: "/info", labels: noLabels},
+			allowed: false,
+		},
+		{
+			name:    "app own container",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1003}, method: "POST", path: "/containers/abc/restart", labels: labels(map[string]string{"owner": "app"}, true)},
+			allowed: true,
+			rule:    "app",
+		},
+		{
+			name:    "app other container",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1003}, method: "POST", path: "/containers/abc/restart", labels: labels(map[string]string{"owner": "other"}, true)},
+			allowed: false,
+		},
+		{
+			name:    "app unknown labels",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1003}, method: "GET", path: "/containers/json", labels: labels(nil, false)},
+			allowed: false,
+		},
+		{
+			name:    "protected container",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1004}, method: "DELETE", path: "/containers/abc", labels: labels(map[string]string{"owner": "app", "protected": ""}, true)},
+			allowed: false,
+			rule:    "protected",
+		},
+		{
+			name:    "unprotected container",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1004}, method: "DELETE", path: "/containers/abc", labels: labels(map[string]string{"owner": "app"}, true)},
+			allowed: true,
+			rule:    "app",
+		},
+		{
+			name:    "deny unknown labels",
+			req:     policyRequest{cred: &PeerCredentials{UID: 1004}, method: "DELETE", path: "/containers/abc", labels: labels(nil, false)},
+			allowed: false,
+			rule:    "protected",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, rule := policy.evaluate(&tc.req)
+			assert.Check(t, is.Equal(allowed, tc.allowed))
+			assert.Check(t, is.Equal(rule, tc.rule))
+		})
+	}
+
+	allowed, rule := (&Policy{DefaultAction: "allow"}).evaluate(&policyRequest{method: "GET", path: "/info"})
+	assert.Check(t, allowed)
+	assert.Check(t, is.Equal(rule, ""))
+}
+
+func TestMiddlewarePolicy(t *testing.T) {
+	m := NewMiddleware(nil, nil)
+	m.SetPolicy(&Policy{
+		Rules: []PolicyRule{
+			{Name: "ops", Action: "allow", Users: []string{"ops"}},
+			{Name: "app", Action: "allow", UIDs: []uint32{1000}, Labels: []string{"owner=app"}},
+		},
+	})
+	var lookups []string
+	m.SetLabelLookup(func(ctx context.Context, kind, name string) (map[string]string, error) {
+		lookups = append(lookups, kind+"/"+name)
+		if name == "missing" {
+			return nil, errors.New("not found")
+		}
+		return map[string]string{"owner": name}, nil
+	})
+
+	var body string
+	handler := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		b, err := ioutil.ReadAll(r.Body)
+		body = string(b)
+		return err
+	})
+
+	newRequest := func(method, target, content string) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(content))
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(WithPeerCredentials(req.Context(), PeerCredentials{PID: 42, UID: 1000, GID: 1000}))
+	}
+
+	// Labels of existing objects are looked up by name.
+	req := newRequest(http.MethodPost, "/v1.41/containers/app/restart", "")
+	assert.Check(t, handler(req.Context(), httptest.NewRecorder(), req, map[string]string{"name": "app"}))
+
+	req = newRequest(http.MethodDelete, "/volumes/other", "")
+	err := handler(req.Context(), httptest.NewRecorder(), req, map[string]string{"name": "other"})
+	assert.Check(t, errdefs.IsForbidden(err), "got: %v", err)
+	assert.Check(t, is.Error(err, "authorization denied by policy: no rule allows DELETE /volumes/other"))
+
+	req = newRequest(http.MethodGet, "/exec/missing/json", "")
+	err = handler(req.Context(), httptest.NewRecorder(), req, map[string]string{"id": "missing"})
+	assert.Check(t, errdefs.IsForbidden(err), "got: %v", err)
+	assert.Check(t, is.DeepEqual(lookups, []string{"containers/app", "volumes/other", "exec/missing"}))
+
+	// Labels of created objects are read from the body, which is left intact
+	// for the handler.
+	content := `{"Image": "busybox", "Labels": {"owner": "app"}}`
+	req = newRequest(http.MethodPost, "/v1.41/containers/create?name=foo", content)
+	assert.Check(t, handler(req.Context(), httptest.NewRecorder(), req, map[string]string{}))
+	assert.Check(t, is.Equal(body, content))
+
+	req = newRequest(http.MethodPost, "/networks/create", `{"Name": "foo"}`)
+	err = handler(req.Context(), httptest.NewRecorder(), req, map[string]string{})
+	assert.Check(t, errdefs.IsForbidden(err), "got: %v", err)
+
+	// Verified TLS client certificates identify the caller by common name.
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ops"}}
+	req = httptest.NewRequest(http.MethodPost, "/containers/prune", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	assert.Check(t, handler(req.Context(), httptest.NewRecorder(), req, map[string]string{}))
+
+	req = httptest.NewRequest(http.MethodPost, "/containers/prune", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	err = handler(req.Context(), httptest.NewRecorder(), req, map[string]string{})
+	assert.Check(t, errdefs.IsForbidden(err), "got: %v", err)
+
+	// Rules denying requests on labels apply when the labels cannot be
+	// looked up.
+	m.SetPolicy(&Policy{
+		DefaultAction: "allow",
+		Rules:         []PolicyRule{{Name: "keep", Action: "deny", Methods: []string{"DELETE"}, Labels: []string{"keep"}}},
+	})
+	m.SetLabelLookup(nil)
+	req = newRequest(http.MethodDelete, "/containers/app", "")
+	err = handler(req.Context(), httptest.NewRecorder(), req, map[string]string{"name": "app"})
+	assert.Check(t, is.Error(err, "authorization denied by policy rule keep"))
+
+	// Removing the policy allows all requests.
+	m.SetPolicy(nil)
+	req = httptest.NewRequest(http.MethodPost, "/containers/prune", nil)
+	assert.Check(t, handler(req.Context(), httptest.NewRecorder(), req, map[string]string{}))
+}
@@ -26,6 +26,14 @@ type Progress struct {
 	Aux interface{}
 
 	LastUpdate bool
+
+	// Retry is set on the first progress reporting that the action is
+	// retried after a failure, once per retry.
+	Retry bool
+
+	// StructuredOnly is set on progress only meant for the structured
+	// reports of a pull, which is not written to the progress stream.
+	StructuredOnly bool
 }
 
 // Output is an interface for writing progress information. It's
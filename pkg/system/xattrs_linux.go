@@ -1,6 +1,10 @@
 package system // import "github.com/docker/docker/pkg/system"
 
-import "golang.org/x/sys/unix"
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
 
 // Lgetxattr retrieves the value of the extended attribute identified by attr
 // and associated with the given path in the file system.
@@ -34,3 +38,32 @@ func Lgetxattr(path string, attr string) ([]byte, error) {
 func Lsetxattr(path string, attr string, data []byte, flags int) error {
 	return unix.Lsetxattr(path, attr, data, flags)
 }
+
+// Llistxattr lists the names of the extended attributes associated with the
+// given path in the file system.
+func Llistxattr(path string) ([]string, error) {
+	// Start with a 128 length byte array
+	dest := make([]byte, 128)
+	sz, errno := unix.Llistxattr(path, dest)
+
+	for errno == unix.ERANGE {
+		// Buffer too small, use zero-sized buffer to get the actual size
+		sz, errno = unix.Llistxattr(path, []byte{})
+		if errno != nil {
+			return nil, errno
+		}
+		dest = make([]byte, sz)
+		sz, errno = unix.Llistxattr(path, dest)
+	}
+	if errno != nil {
+		return nil, errno
+	}
+
+	var attrs []string
+	for _, name := range bytes.Split(dest[:sz], []byte{0}) {
+		if len(name) > 0 {
+			attrs = append(attrs, string(name))
+		}
+	}
+	return attrs, nil
+}
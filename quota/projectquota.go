@@ -2,12 +2,11 @@
 // +build linux,!exclude_disk_quota,cgo
 
 //
-// projectquota.go - implements XFS project quota controls
+// projectquota.go - implements XFS and ext4 project quota controls
 // for setting quota limits on a newly created directory.
-// It currently supports the legacy XFS specific ioctls.
-//
-// TODO: use generic quota control ioctl FS_IOC_FS{GET,SET}XATTR
-//       for both xfs/ext4 for kernel version >= v4.5
+// It uses the XFS quotactl commands, which the kernel also implements for
+// ext4, and the generic FS_IOC_FS{GET,SET}XATTR ioctls to assign project
+// ids, which ext4 supports since kernel v4.5.
 //
 
 package quota // import "github.com/docker/docker/quota"
@@ -98,8 +97,16 @@ func (state *pquotaState) updateMinProjID(minProjectID uint32) {
 //
 // Returns nil (and error) if project quota is not supported.
 //
+// The backing fs must be xfs, mounted with the pquota option, or ext4
+// with the project and quota features, mounted with the prjquota option:
+//    mkfs.ext4 -O quota,project /dev/sdX
+//    mount -o prjquota /dev/sdX /var/lib/docker
+// On ext4 the processes with CAP_SYS_RESOURCE are not limited by quotas,
+// which containers do not have by default, but the daemon has: the quotas
+// are not enforced on the files it writes on behalf of containers. Overlayfs
+// writes with the credentials of its mounter, so overlay2 mounts without it.
+//
 // First get the project id of the home directory.
-// This test will fail if the backing fs is not xfs.
 //
 // xfs_quota tool can be used to assign a project id to the driver home directory, e.g.:
 //    echo 999:/var/lib/docker/overlay2 >> /etc/projects
@@ -123,6 +130,11 @@ func NewControl(basePath string) (*Control, error) {
 		return nil, ErrQuotaNotSupported
 	}
 
+	backingFsType, err := getBackingFsType(basePath)
+	if err != nil {
+		return nil, err
+	}
+
 	//
 	// create backing filesystem device node
 	//
@@ -134,12 +146,15 @@ func NewControl(basePath string) (*Control, error) {
 	// check if we can call quotactl with project quotas
 	// as a mechanism to determine (early) if we have support
 	hasQuotaSupport, err := hasQuotaSupport(backingFsBlockDev)
+	if err == nil && !hasQuotaSupport {
+		err = ErrQuotaNotSupported
+	}
 	if err != nil {
+		// ext4 filesystems are often mounted without quotas, leave no
+		// device node behind in their home directory.
+		unix.Unlink(backingFsBlockDev)
 		return nil, err
 	}
-	if !hasQuotaSupport {
-		return nil, ErrQuotaNotSupported
-	}
 
 	//
 	// Get project id of parent dir as minimal id to be used by driver
@@ -180,7 +195,7 @@ func NewControl(basePath string) (*Control, error) {
 		return nil, err
 	}
 
-	logrus.Debugf("NewControl(%s): nextProjectID = %d", basePath, state.nextProjectID)
+	logrus.Debugf("NewControl(%s): backingFs = %s, nextProjectID = %d", basePath, backingFsType, state.nextProjectID)
 	return &q, nil
 }
 
@@ -219,7 +234,7 @@ func (q *Control) SetQuota(targetPath string, quota Quota) error {
 	return setProjectQuota(q.backingFsBlockDev, projectID, quota)
 }
 
-// setProjectQuota - set the quota for project id on xfs block device
+// setProjectQuota - set the quota for project id on xfs or ext4 block device
 func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
 	var d C.fs_disk_quota_t
 	d.d_version = C.FS_DQUOT_VERSION
@@ -273,7 +288,7 @@ func (q *Control) GetQuota(targetPath string, quota *Quota) error {
 	return nil
 }
 
-// getProjectID - get the project id of path on xfs
+// getProjectID - get the project id of path on xfs or ext4
 func getProjectID(targetPath string) (uint32, error) {
 	dir, err := openDir(targetPath)
 	if err != nil {
@@ -291,7 +306,7 @@ func getProjectID(targetPath string) (uint32, error) {
 	return uint32(fsx.fsx_projid), nil
 }
 
-// setProjectID - set the project id of path on xfs
+// setProjectID - set the project id of path on xfs or ext4
 func setProjectID(targetPath string, projectID uint32) error {
 	dir, err := openDir(targetPath)
 	if err != nil {
@@ -309,6 +324,10 @@ func setProjectID(targetPath string, projectID uint32) error {
 	fsx.fsx_xflags |= C.FS_XFLAG_PROJINHERIT
 	_, _, errno = unix.Syscall(unix.SYS_IOCTL, getDirFd(dir), C.FS_IOC_FSSETXATTR,
 		uintptr(unsafe.Pointer(&fsx)))
+	if errno == unix.EOVERFLOW {
+		// ext4 stores the project id in the extra space of large inodes.
+		return errors.Wrapf(errno, "failed to set projid for %s: the inodes of the filesystem are too small, it must be created with an inode size of at least 256 bytes", targetPath)
+	}
 	if errno != 0 {
 		return errors.Wrapf(errno, "failed to set projid for %s", targetPath)
 	}
@@ -397,6 +416,23 @@ func getDirFd(dir *C.DIR) uintptr {
 	return uintptr(C.dirfd(dir))
 }
 
+// getBackingFsType returns the name of the filesystem of home, or
+// ErrQuotaNotSupported if it does not support project quotas.
+func getBackingFsType(home string) (string, error) {
+	var buf unix.Statfs_t
+	if err := unix.Statfs(home, &buf); err != nil {
+		return "", errors.Wrapf(err, "failed to statfs %s", home)
+	}
+	switch buf.Type {
+	case unix.XFS_SUPER_MAGIC:
+		return "xfs", nil
+	case unix.EXT4_SUPER_MAGIC:
+		return "ext4", nil
+	default:
+		return "", ErrQuotaNotSupported
+	}
+}
+
 // makeBackingFsDev gets the backing block device of the driver home directory
 // and creates a block device node under the home directory to be used by
 // quotactl commands.
@@ -18,22 +18,34 @@ import (
 const testQuotaSize = 10 * 1024 * 1024
 
 func TestBlockDev(t *testing.T) {
-	if msg, ok := CanTestQuota(); !ok {
-		t.Skip(msg)
-	}
+	for _, fsType := range []string{"xfs", "ext4"} {
+		fsType := fsType
+		t.Run(fsType, func(t *testing.T) {
+			if msg, ok := CanTestQuota(fsType); !ok {
+				t.Skip(msg)
+			}
+
+			// get sparse test image
+			imageFileName, err := PrepareQuotaTestImage(t, fsType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(imageFileName)
 
-	// get sparse xfs test image
-	imageFileName, err := PrepareQuotaTestImage(t)
-	if err != nil {
-		t.Fatal(err)
+			t.Run("testBlockDevQuotaDisabled", WrapMountTest(imageFileName, fsType, false, testBlockDevQuotaDisabled))
+			t.Run("testBlockDevQuotaEnabled", WrapMountTest(imageFileName, fsType, true, testBlockDevQuotaEnabled))
+			t.Run("testSmallerThanQuota", WrapMountTest(imageFileName, fsType, true, WrapQuotaTest(testSmallerThanQuota)))
+			t.Run("testBiggerThanQuota", WrapMountTest(imageFileName, fsType, true, WrapQuotaTest(testBiggerThanQuota)))
+			t.Run("testRetrieveQuota", WrapMountTest(imageFileName, fsType, true, WrapQuotaTest(testRetrieveQuota)))
+			t.Run("testNextProjectID", WrapMountTest(imageFileName, fsType, true, WrapQuotaTest(testNextProjectID)))
+		})
 	}
-	defer os.Remove(imageFileName)
+}
 
-	t.Run("testBlockDevQuotaDisabled", WrapMountTest(imageFileName, false, testBlockDevQuotaDisabled))
-	t.Run("testBlockDevQuotaEnabled", WrapMountTest(imageFileName, true, testBlockDevQuotaEnabled))
-	t.Run("testSmallerThanQuota", WrapMountTest(imageFileName, true, WrapQuotaTest(testSmallerThanQuota)))
-	t.Run("testBiggerThanQuota", WrapMountTest(imageFileName, true, WrapQuotaTest(testBiggerThanQuota)))
-	t.Run("testRetrieveQuota", WrapMountTest(imageFileName, true, WrapQuotaTest(testRetrieveQuota)))
+func TestUnsupportedBackingFs(t *testing.T) {
+	// procfs supports no quotas, and no device node is made on it.
+	_, err := NewControl("/proc")
+	assert.Check(t, is.Equal(err, ErrQuotaNotSupported))
 }
 
 func testBlockDevQuotaDisabled(t *testing.T, mountPoint, backingFsDev, testDir string) {
@@ -57,12 +69,13 @@ func testSmallerThanQuota(t *testing.T, ctrl *Control, homeDir, testDir, testSub
 
 func testBiggerThanQuota(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir string) {
 	// Make sure the quota is being enforced
-	// TODO: When we implement this under EXT4, we need to shed CAP_SYS_RESOURCE, otherwise
-	// we're able to violate quota without issue
 	assert.NilError(t, ctrl.SetQuota(testSubDir, Quota{testQuotaSize}))
 
 	biggerThanQuotaFile := filepath.Join(testSubDir, "bigger-than-quota")
-	err := ioutil.WriteFile(biggerThanQuotaFile, make([]byte, testQuotaSize+1), 0644)
+	var err error
+	WithoutSysResource(t, func() {
+		err = ioutil.WriteFile(biggerThanQuotaFile, make([]byte, testQuotaSize+1), 0644)
+	})
 	assert.Assert(t, is.ErrorContains(err, ""))
 	if err == io.ErrShortWrite {
 		assert.NilError(t, os.Remove(biggerThanQuotaFile))
@@ -77,3 +90,14 @@ func testRetrieveQuota(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir
 	assert.NilError(t, ctrl.GetQuota(testSubDir, &q))
 	assert.Check(t, is.Equal(uint64(testQuotaSize), q.Size))
 }
+
+func testNextProjectID(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir string) {
+	assert.NilError(t, ctrl.SetQuota(testSubDir, Quota{testQuotaSize}))
+	projectID, err := getProjectID(testSubDir)
+	assert.NilError(t, err)
+
+	// The project ids in use are found by a new control of the directory.
+	_, err = NewControl(testDir)
+	assert.NilError(t, err)
+	assert.Check(t, getPquotaState().nextProjectID > projectID)
+}
@@ -22,16 +22,20 @@ func (q *Control) GetQuota(targetPath string, quota *Quota) error {
 
 // testhelpers
 
-func CanTestQuota() (string, bool) {
+func CanTestQuota(fsType string) (string, bool) {
 	return ErrQuotaNotSupported.Error(), false
 }
 
-func PrepareQuotaTestImage(t *testing.T) (string, error) {
+func PrepareQuotaTestImage(t *testing.T, fsType string) (string, error) {
 	return "", ErrQuotaNotSupported
 }
 
-func WrapMountTest(imageFileName string, enableQuota bool, testFunc func(t *testing.T, mountPoint, backingFsDev, testDir string)) func(*testing.T) {
+func WrapMountTest(imageFileName, fsType string, enableQuota bool, testFunc func(t *testing.T, mountPoint, backingFsDev, testDir string)) func(*testing.T) {
 	return func(t *testing.T) {
 		t.Skip(ErrQuotaNotSupported.Error())
 	}
 }
+
+func WithoutSysResource(t *testing.T, fn func()) {
+	fn()
+}
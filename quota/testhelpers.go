@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"runtime"
 	"testing"
 
 	"golang.org/x/sys/unix"
@@ -16,29 +17,42 @@ import (
 
 const imageSize = 64 * 1024 * 1024
 
-// CanTestQuota - checks if xfs prjquota can be tested
+// mkfsArgs are the arguments to make the test filesystems with project
+// quota support, by filesystem type.
+var mkfsArgs = map[string][]string{
+	// The reason for disabling these options is sometimes people run with a newer userspace
+	// than kernelspace
+	"xfs": {"-m", "crc=0,finobt=0"},
+	// ext4 stores the project ids in the extra space of 256 bytes inodes.
+	"ext4": {"-F", "-q", "-I", "256", "-O", "quota,project"},
+}
+
+// CanTestQuota - checks if prjquota can be tested on fsType, xfs or ext4
 // returns a reason if not
-func CanTestQuota() (string, bool) {
+func CanTestQuota(fsType string) (string, bool) {
 	if os.Getuid() != 0 {
 		return "requires mounts", false
 	}
-	_, err := exec.LookPath("mkfs.xfs")
+	if _, ok := mkfsArgs[fsType]; !ok {
+		return "unsupported filesystem " + fsType, false
+	}
+	_, err := exec.LookPath("mkfs." + fsType)
 	if err != nil {
-		return "mkfs.xfs not found in PATH", false
+		return "mkfs." + fsType + " not found in PATH", false
 	}
 	return "", true
 }
 
-// PrepareQuotaTestImage - prepares an xfs prjquota test image
+// PrepareQuotaTestImage - prepares a fsType prjquota test image
 // returns the path the the image on success
-func PrepareQuotaTestImage(t *testing.T) (string, error) {
-	mkfs, err := exec.LookPath("mkfs.xfs")
+func PrepareQuotaTestImage(t *testing.T, fsType string) (string, error) {
+	mkfs, err := exec.LookPath("mkfs." + fsType)
 	if err != nil {
 		return "", err
 	}
 
 	// create a sparse image
-	imageFile, err := ioutil.TempFile("", "xfs-image")
+	imageFile, err := ioutil.TempFile("", fsType+"-image")
 	if err != nil {
 		return "", err
 	}
@@ -56,9 +70,7 @@ func PrepareQuotaTestImage(t *testing.T) (string, error) {
 		return "", err
 	}
 
-	// The reason for disabling these options is sometimes people run with a newer userspace
-	// than kernelspace
-	out, err := exec.Command(mkfs, "-m", "crc=0,finobt=0", imageFileName).CombinedOutput()
+	out, err := exec.Command(mkfs, append(mkfsArgs[fsType], imageFileName)...).CombinedOutput()
 	if len(out) > 0 {
 		t.Log(string(out))
 	}
@@ -72,7 +84,7 @@ func PrepareQuotaTestImage(t *testing.T) (string, error) {
 
 // WrapMountTest - wraps a test function such that it has easy access to a mountPoint and testDir
 // with guaranteed prjquota or guaranteed no prjquota support.
-func WrapMountTest(imageFileName string, enableQuota bool, testFunc func(t *testing.T, mountPoint, backingFsDev, testDir string)) func(*testing.T) {
+func WrapMountTest(imageFileName, fsType string, enableQuota bool, testFunc func(t *testing.T, mountPoint, backingFsDev, testDir string)) func(*testing.T) {
 	return func(t *testing.T) {
 		mountOptions := "loop"
 
@@ -80,15 +92,20 @@ func WrapMountTest(imageFileName string, enableQuota bool, testFunc func(t *test
 			mountOptions = mountOptions + ",prjquota"
 		}
 
-		mountPointDir := fs.NewDir(t, "xfs-mountPoint")
+		mountPointDir := fs.NewDir(t, fsType+"-mountPoint")
 		defer mountPointDir.Remove()
 		mountPoint := mountPointDir.Path()
 
-		out, err := exec.Command("mount", "-o", mountOptions, imageFileName, mountPoint).CombinedOutput()
+		out, err := exec.Command("mount", "-t", fsType, "-o", mountOptions, imageFileName, mountPoint).CombinedOutput()
 		if err != nil {
-			_, err := os.Stat("/proc/fs/xfs")
+			_, err := os.Stat("/proc/fs/" + fsType)
 			if os.IsNotExist(err) {
-				t.Skip("no /proc/fs/xfs")
+				t.Skip("no /proc/fs/" + fsType)
+			}
+			if fsType == "ext4" {
+				// ext4 images with the quota feature need a kernel with
+				// CONFIG_QUOTA and CONFIG_QFMT_V2, which /proc does not tell.
+				t.Skipf("mount failed, the kernel may not support ext4 quotas: %s", out)
 			}
 		}
 
@@ -121,3 +138,24 @@ func WrapQuotaTest(testFunc func(t *testing.T, ctrl *Control, mountPoint, testDi
 		testFunc(t, ctrl, mountPoint, testDir, testSubDir)
 	}
 }
+
+// WithoutSysResource - runs fn without CAP_SYS_RESOURCE, with which ext4
+// lets the writes exceed the quota.
+func WithoutSysResource(t *testing.T, fn func()) {
+	// The capabilities are per thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	assert.NilError(t, unix.Capget(&hdr, &data[0]))
+	effective := data[0].Effective
+	data[0].Effective &^= 1 << unix.CAP_SYS_RESOURCE
+	assert.NilError(t, unix.Capset(&hdr, &data[0]))
+	defer func() {
+		data[0].Effective = effective
+		assert.NilError(t, unix.Capset(&hdr, &data[0]))
+	}()
+
+	fn()
+}
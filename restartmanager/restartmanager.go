@@ -14,6 +14,7 @@ const (
 	backoffMultiplier = 2
 	defaultTimeout    = 100 * time.Millisecond
 	maxRestartTimeout = 1 * time.Minute
+	resetTimeoutAfter = 10 * time.Second
 )
 
 // ErrRestartCanceled is returned when the restart manager has been
@@ -24,6 +25,16 @@ var ErrRestartCanceled = errors.New("restart canceled")
 type RestartManager interface {
 	Cancel() error
 	ShouldRestart(exitCode uint32, hasBeenManuallyStopped bool, executionDuration time.Duration, health types.Health) (bool, chan error, error)
+	Backoff() BackoffState
+}
+
+// BackoffState is the state of the restart backoff of a container.
+type BackoffState struct {
+	// Delay is the delay applied to the last or pending restart.
+	Delay time.Duration
+	// NextRestart is the time at which the pending restart is due. It is
+	// zero if no restart is pending.
+	NextRestart time.Time
 }
 
 type restartManager struct {
@@ -32,6 +43,7 @@ type restartManager struct {
 	policy       container.RestartPolicy
 	restartCount int
 	timeout      time.Duration
+	nextRestart  time.Time
 	active       bool
 	cancel       chan struct{}
 	canceled     bool
@@ -67,19 +79,21 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 	if rm.active {
 		return false, nil, fmt.Errorf("invalid call on an active restart manager")
 	}
-	// if the container ran for more than 10s, regardless of status and policy reset the
-	// the timeout back to the default.
-	if executionDuration.Seconds() >= 10 {
+	initial, max, multiplier, resetAfter := rm.backoffParameters()
+
+	// if the container ran for long enough, regardless of status and policy
+	// reset the timeout back to the initial delay.
+	if executionDuration >= resetAfter {
 		rm.timeout = 0
 	}
 	switch {
 	case rm.timeout == 0:
-		rm.timeout = defaultTimeout
-	case rm.timeout < maxRestartTimeout:
-		rm.timeout *= backoffMultiplier
+		rm.timeout = initial
+	case rm.timeout < max:
+		rm.timeout = time.Duration(float64(rm.timeout) * multiplier)
 	}
-	if rm.timeout > maxRestartTimeout {
-		rm.timeout = maxRestartTimeout
+	if rm.timeout > max {
+		rm.timeout = max
 	}
 
 	var restart bool
@@ -104,6 +118,7 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 
 	unlockOnExit = false
 	rm.active = true
+	rm.nextRestart = time.Now().Add(rm.timeout)
 	rm.Unlock()
 
 	ch := make(chan error)
@@ -119,6 +134,7 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 			rm.Lock()
 			close(ch)
 			rm.active = false
+			rm.nextRestart = time.Time{}
 			rm.Unlock()
 		}
 	}()
@@ -126,10 +142,40 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 	return true, ch, nil
 }
 
+// backoffParameters returns the backoff parameters of the policy, with
+// defaults applied.
+func (rm *restartManager) backoffParameters() (initial, max time.Duration, multiplier float64, resetAfter time.Duration) {
+	initial, max, multiplier, resetAfter = defaultTimeout, maxRestartTimeout, backoffMultiplier, resetTimeoutAfter
+	if rm.policy.InitialDelay > 0 {
+		initial = rm.policy.InitialDelay
+	}
+	if rm.policy.MaxDelay > 0 {
+		max = rm.policy.MaxDelay
+	}
+	if initial > max {
+		max = initial
+	}
+	if rm.policy.BackoffMultiplier >= 1 {
+		multiplier = rm.policy.BackoffMultiplier
+	}
+	if rm.policy.ResetAfter > 0 {
+		resetAfter = rm.policy.ResetAfter
+	}
+	return initial, max, multiplier, resetAfter
+}
+
+// Backoff returns the current backoff state.
+func (rm *restartManager) Backoff() BackoffState {
+	rm.Lock()
+	defer rm.Unlock()
+	return BackoffState{Delay: rm.timeout, NextRestart: rm.nextRestart}
+}
+
 func (rm *restartManager) Cancel() error {
 	rm.Do(func() {
 		rm.Lock()
 		rm.canceled = true
+		rm.nextRestart = time.Time{}
 		close(rm.cancel)
 		rm.Unlock()
 	})
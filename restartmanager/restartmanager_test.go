@@ -37,3 +37,39 @@ func TestRestartManagerTimeoutReset(t *testing.T) {
 		t.Fatalf("restart manager should have a timeout of 100 ms but has %s", rm.timeout)
 	}
 }
+
+func TestRestartManagerCustomBackoff(t *testing.T) {
+	health := types.Health{}
+	rm := New(container.RestartPolicy{
+		Name:              "always",
+		InitialDelay:      time.Second,
+		MaxDelay:          5 * time.Second,
+		BackoffMultiplier: 3,
+		ResetAfter:        time.Minute,
+	}, 0).(*restartManager)
+
+	for _, expected := range []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second} {
+		should, _, err := rm.ShouldRestart(0, false, 30*time.Second, health)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !should {
+			t.Fatal("container should be restarted")
+		}
+		state := rm.Backoff()
+		if state.Delay != expected {
+			t.Fatalf("restart manager should have a timeout of %s but has %s", expected, state.Delay)
+		}
+		if state.NextRestart.IsZero() {
+			t.Fatal("restart manager should report the next restart time")
+		}
+		rm.active = false
+	}
+
+	if _, _, err := rm.ShouldRestart(0, false, time.Minute, health); err != nil {
+		t.Fatal(err)
+	}
+	if rm.timeout != time.Second {
+		t.Fatalf("restart manager should have a timeout of 1s but has %s", rm.timeout)
+	}
+}
@@ -0,0 +1,32 @@
+package local // import "github.com/docker/docker/volume/local"
+
+import (
+	"github.com/docker/docker/daemon/graphdriver/copy"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
+	"github.com/pkg/errors"
+)
+
+// Snapshot copies the content of the volume src to the new volume dst. The
+// files are cloned (reflinked) if the filesystem supports it. The volumes
+// mounted from a device or a network filesystem cannot be snapshotted.
+func (r *Root) Snapshot(src, dst volume.Volume) error {
+	lsrc, ok := src.(*localVolume)
+	if !ok {
+		return errdefs.System(errors.Errorf("unknown volume type %T", src))
+	}
+	ldst, ok := dst.(*localVolume)
+	if !ok {
+		return errdefs.System(errors.Errorf("unknown volume type %T", dst))
+	}
+
+	lsrc.m.Lock()
+	defer lsrc.m.Unlock()
+	if lsrc.needsMount() {
+		return errdefs.InvalidParameter(errors.Errorf("volume %s is mounted from %s and cannot be snapshotted", lsrc.name, lsrc.opts.MountDevice))
+	}
+	if err := copy.DirCopy(lsrc.path, ldst.path, copy.Content, true); err != nil {
+		return errdefs.System(errors.Wrapf(err, "error copying volume %s to %s", lsrc.name, ldst.name))
+	}
+	return nil
+}
@@ -19,19 +19,24 @@ const quotaSize = 1024 * 1024
 const quotaSizeLiteral = "1M"
 
 func TestQuota(t *testing.T) {
-	if msg, ok := quota.CanTestQuota(); !ok {
-		t.Skip(msg)
+	for _, fsType := range []string{"xfs", "ext4"} {
+		fsType := fsType
+		t.Run(fsType, func(t *testing.T) {
+			if msg, ok := quota.CanTestQuota(fsType); !ok {
+				t.Skip(msg)
+			}
+
+			// get sparse test image
+			imageFileName, err := quota.PrepareQuotaTestImage(t, fsType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(imageFileName)
+
+			t.Run("testVolWithQuota", quota.WrapMountTest(imageFileName, fsType, true, testVolWithQuota))
+			t.Run("testVolQuotaUnsupported", quota.WrapMountTest(imageFileName, fsType, false, testVolQuotaUnsupported))
+		})
 	}
-
-	// get sparse xfs test image
-	imageFileName, err := quota.PrepareQuotaTestImage(t)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.Remove(imageFileName)
-
-	t.Run("testVolWithQuota", quota.WrapMountTest(imageFileName, true, testVolWithQuota))
-	t.Run("testVolQuotaUnsupported", quota.WrapMountTest(imageFileName, false, testVolQuotaUnsupported))
 }
 
 func testVolWithQuota(t *testing.T, mountPoint, backingFsDev, testDir string) {
@@ -63,7 +68,9 @@ func testVolWithQuota(t *testing.T, mountPoint, backingFsDev, testDir string) {
 	assert.NilError(t, os.Remove(testfile))
 
 	// test writing fiel larger than quota
-	err = ioutil.WriteFile(testfile, make([]byte, quotaSize+1), 0644)
+	quota.WithoutSysResource(t, func() {
+		err = ioutil.WriteFile(testfile, make([]byte, quotaSize+1), 0644)
+	})
 	assert.ErrorContains(t, err, "")
 	if _, err := os.Stat(testfile); err == nil {
 		assert.NilError(t, os.Remove(testfile))
@@ -1,13 +1,18 @@
 package service // import "github.com/docker/docker/volume/service"
 
 import (
+	"archive/tar"
 	"context"
+	"io"
+	"io/ioutil"
 	"strconv"
 	"sync/atomic"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/directory"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/plugingetter"
@@ -34,12 +39,13 @@ type VolumesService struct {
 	ds           ds
 	pruneRunning int32
 	eventLogger  volumeEventLogger
+	idMapping    *idtools.IdentityMapping
 }
 
 // NewVolumeService creates a new volume service
-func NewVolumeService(root string, pg plugingetter.PluginGetter, rootIDs idtools.Identity, logger volumeEventLogger) (*VolumesService, error) {
+func NewVolumeService(root string, pg plugingetter.PluginGetter, idMapping *idtools.IdentityMapping, logger volumeEventLogger) (*VolumesService, error) {
 	ds := drivers.NewStore(pg)
-	if err := setupDefaultDriver(ds, root, rootIDs); err != nil {
+	if err := setupDefaultDriver(ds, root, idMapping.RootPair()); err != nil {
 		return nil, err
 	}
 
@@ -47,7 +53,7 @@ func NewVolumeService(root string, pg plugingetter.PluginGetter, rootIDs idtools
 	if err != nil {
 		return nil, err
 	}
-	return &VolumesService{vs: vs, ds: ds, eventLogger: logger}, nil
+	return &VolumesService{vs: vs, ds: ds, eventLogger: logger, idMapping: idMapping}, nil
 }
 
 // GetDriverList gets the list of registered volume drivers
@@ -261,6 +267,165 @@ func (s *VolumesService) List(ctx context.Context, filter filters.Args) (volumes
 	return s.volumesToAPI(ctx, volumes, useCachedPath(true)), warnings, nil
 }
 
+// Export writes to out a tar archive of the content of the volume, with the
+// ownership and the extended attributes of the files.
+func (s *VolumesService) Export(ctx context.Context, name string, out io.Writer) error {
+	ref := "export-" + stringid.GenerateRandomID()
+	v, err := s.vs.Get(ctx, name, opts.WithGetReference(ref))
+	if err != nil {
+		return err
+	}
+	defer s.vs.Release(ctx, v.Name(), ref)
+
+	path, err := v.Mount(ref)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := v.Unmount(ref); err != nil {
+			logrus.WithError(err).WithField("volume", v.Name()).Warn("error unmounting volume after export")
+		}
+	}()
+
+	rdr, err := archive.TarWithOptions(path, &archive.TarOptions{
+		Compression:   archive.Uncompressed,
+		UIDMaps:       s.idMapping.UIDs(),
+		GIDMaps:       s.idMapping.GIDs(),
+		IncludeXattrs: true,
+	})
+	if err != nil {
+		return errdefs.System(errors.Wrapf(err, "error exporting volume %s", v.Name()))
+	}
+	defer rdr.Close()
+	_, err = io.Copy(out, rdr)
+	return err
+}
+
+// Import extracts the tar archive in, which may be compressed, into the
+// volume. The files of the volume are kept, unless the archive replaces them.
+func (s *VolumesService) Import(ctx context.Context, name string, in io.Reader) error {
+	ref := "import-" + stringid.GenerateRandomID()
+	v, err := s.vs.Get(ctx, name, opts.WithGetReference(ref))
+	if err != nil {
+		return err
+	}
+	defer s.vs.Release(ctx, v.Name(), ref)
+
+	path, err := v.Mount(ref)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := v.Unmount(ref); err != nil {
+			logrus.WithError(err).WithField("volume", v.Name()).Warn("error unmounting volume after import")
+		}
+	}()
+
+	// The archive is unpacked in another process, which only reports the
+	// text of its errors, so it is also read here to tell malformed
+	// archives from failures to write their content.
+	pr, pw := io.Pipe()
+	checked := make(chan error, 1)
+	go func() {
+		checked <- checkArchive(pr)
+	}()
+	err = chrootarchive.Untar(io.TeeReader(in, pw), path, &archive.TarOptions{
+		UIDMaps: s.idMapping.UIDs(),
+		GIDMaps: s.idMapping.GIDs(),
+	})
+	pw.Close()
+	malformed := <-checked
+	if err != nil {
+		if malformed != nil {
+			return errdefs.InvalidParameter(errors.Wrapf(malformed, "error importing volume %s", v.Name()))
+		}
+		return errdefs.System(errors.Wrapf(err, "error importing volume %s", v.Name()))
+	}
+	return nil
+}
+
+// checkArchive reads the possibly compressed tar archive from r, and
+// returns the error making it malformed, if any. r is read until its end.
+func checkArchive(r io.Reader) error {
+	defer io.Copy(ioutil.Discard, r)
+
+	dr, err := archive.DecompressStream(r)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+	tr := tar.NewReader(dr)
+	for {
+		if _, err := tr.Next(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// snapshotDriver is implemented by the volume drivers able to copy the
+// content of their volumes.
+type snapshotDriver interface {
+	Snapshot(src, dst volume.Volume) error
+}
+
+// Snapshot creates the volume target with a copy of the content of the
+// volume name, and its driver options. The labels of the new volume are
+// those of the volume name, unless some are passed. The volume is copied as
+// is, even if it is in use.
+func (s *VolumesService) Snapshot(ctx context.Context, name, target string, labels map[string]string) (*types.Volume, error) {
+	ref := "snapshot-" + stringid.GenerateRandomID()
+	v, err := s.vs.Get(ctx, name, opts.WithGetReference(ref))
+	if err != nil {
+		return nil, err
+	}
+	defer s.vs.Release(ctx, v.Name(), ref)
+
+	d, err := s.vs.drivers.GetDriver(v.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	sd, ok := d.(snapshotDriver)
+	if !ok {
+		return nil, errdefs.NotImplemented(errors.Errorf("volume driver %s does not support snapshots", v.DriverName()))
+	}
+
+	if target == "" {
+		target = stringid.GenerateRandomID()
+	}
+	if _, err := s.vs.Get(ctx, target); err == nil {
+		return nil, errdefs.Conflict(errors.Errorf("volume %s already exists", target))
+	} else if !IsNotExist(err) {
+		return nil, err
+	}
+
+	var options map[string]string
+	if dv, ok := v.(volume.DetailedVolume); ok {
+		options = dv.Options()
+		if labels == nil {
+			labels = dv.Labels()
+		}
+	}
+	dst, err := s.vs.Create(ctx, target, v.DriverName(), opts.WithCreateOptions(options), opts.WithCreateLabels(labels), opts.WithCreateReference(ref))
+	if err != nil {
+		return nil, err
+	}
+	err = sd.Snapshot(unwrapVolume(v), unwrapVolume(dst))
+	s.vs.Release(ctx, dst.Name(), ref)
+	if err != nil {
+		if rmErr := s.vs.Remove(ctx, dst); rmErr != nil {
+			logrus.WithError(rmErr).WithField("volume", dst.Name()).Warn("error removing volume after failed snapshot")
+		}
+		return nil, err
+	}
+
+	s.eventLogger.LogVolumeEvent(dst.Name(), "create", map[string]string{"driver": dst.DriverName(), "source": v.Name()})
+	apiV := volumeToAPIType(dst)
+	return &apiV, nil
+}
+
 // Shutdown shuts down the image service and dependencies
 func (s *VolumesService) Shutdown() error {
 	return s.vs.Shutdown()
@@ -1,13 +1,17 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/volume"
 	volumedrivers "github.com/docker/docker/volume/drivers"
 	"github.com/docker/docker/volume/local"
@@ -15,8 +19,16 @@ import (
 	"github.com/docker/docker/volume/testutils"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
 )
 
+func TestMain(m *testing.M) {
+	if reexec.Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
 func TestLocalVolumeSize(t *testing.T) {
 	t.Parallel()
 
@@ -64,3 +76,108 @@ func TestLocalVolumeSize(t *testing.T) {
 		}
 	}
 }
+
+func newLocalTestService(t *testing.T) (*VolumesService, func()) {
+	t.Helper()
+
+	ds := volumedrivers.NewStore(nil)
+	dir, err := ioutil.TempDir("", "local-volumes")
+	assert.NilError(t, err)
+
+	l, err := local.New(dir, idtools.Identity{UID: os.Getuid(), GID: os.Getegid()})
+	assert.NilError(t, err)
+	assert.Assert(t, ds.Register(l, volume.DefaultDriverName))
+	assert.Assert(t, ds.Register(testutils.NewFakeDriver("fake"), "fake"))
+
+	service, cleanup := newTestService(t, ds)
+	return service, func() {
+		cleanup()
+		assert.Check(t, os.RemoveAll(dir))
+	}
+}
+
+func TestVolumeExportImport(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "requires root to chroot and chown")
+	service, cleanup := newLocalTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	src, err := service.Create(ctx, "src", volume.DefaultDriverName)
+	assert.NilError(t, err)
+	file := filepath.Join(src.Mountpoint, "dir", "file")
+	assert.NilError(t, os.Mkdir(filepath.Dir(file), 0750))
+	assert.NilError(t, ioutil.WriteFile(file, []byte("hello"), 0640))
+	assert.NilError(t, os.Chown(file, 1234, 5678))
+	xattrs := system.Lsetxattr(file, "user.origin", []byte("test"), 0) == nil
+
+	var archive bytes.Buffer
+	assert.NilError(t, service.Export(ctx, "src", &archive))
+
+	dst, err := service.Create(ctx, "dst", volume.DefaultDriverName)
+	assert.NilError(t, err)
+	assert.NilError(t, service.Import(ctx, "dst", &archive))
+
+	file = filepath.Join(dst.Mountpoint, "dir", "file")
+	content, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(content), "hello"))
+	fi, err := os.Stat(file)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(fi.Mode(), os.FileMode(0640)))
+	stat, err := system.Stat(file)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(stat.UID(), uint32(1234)))
+	assert.Check(t, is.Equal(stat.GID(), uint32(5678)))
+	if xattrs {
+		value, err := system.Lgetxattr(file, "user.origin")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(string(value), "test"))
+	}
+
+	// The volumes are referenced while they are exported or imported only.
+	assert.NilError(t, service.Remove(ctx, "src"))
+
+	err = service.Import(ctx, "dst", bytes.NewBufferString("not a tar archive"))
+	assert.Check(t, errdefs.IsInvalidParameter(err), err)
+	err = service.Export(ctx, "missing", &archive)
+	assert.Check(t, errdefs.IsNotFound(err), err)
+}
+
+func TestVolumeSnapshot(t *testing.T) {
+	service, cleanup := newLocalTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	src, err := service.Create(ctx, "src", volume.DefaultDriverName, opts.WithCreateLabels(map[string]string{"app": "db"}))
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(src.Mountpoint, "file"), []byte("v1"), 0600))
+
+	snap, err := service.Snapshot(ctx, "src", "snap", nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(snap.Name, "snap"))
+	assert.Check(t, is.Equal(snap.Driver, volume.DefaultDriverName))
+	assert.Check(t, is.DeepEqual(snap.Labels, map[string]string{"app": "db"}))
+
+	// The snapshot is a copy.
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(src.Mountpoint, "file"), []byte("v2"), 0600))
+	content, err := ioutil.ReadFile(filepath.Join(snap.Mountpoint, "file"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(content), "v1"))
+
+	labeled, err := service.Snapshot(ctx, "src", "", map[string]string{"backup": "true"})
+	assert.NilError(t, err)
+	assert.Check(t, labeled.Name != "")
+	assert.Check(t, is.DeepEqual(labeled.Labels, map[string]string{"backup": "true"}))
+
+	_, err = service.Snapshot(ctx, "src", "snap", nil)
+	assert.Check(t, errdefs.IsConflict(err), err)
+	_, err = service.Snapshot(ctx, "missing", "other", nil)
+	assert.Check(t, errdefs.IsNotFound(err), err)
+
+	_, err = service.Create(ctx, "remote", "fake")
+	assert.NilError(t, err)
+	_, err = service.Snapshot(ctx, "remote", "other", nil)
+	assert.Check(t, errdefs.IsNotImplemented(err), err)
+	_, err = service.Get(ctx, "other")
+	assert.Check(t, IsNotExist(err), err)
+}
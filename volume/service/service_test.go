@@ -1,6 +1,8 @@
 package service
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"io/ioutil"
 	"os"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/volume"
 	volumedrivers "github.com/docker/docker/volume/drivers"
 	"github.com/docker/docker/volume/service/opts"
@@ -16,6 +19,20 @@ import (
 	is "gotest.tools/v3/assert/cmp"
 )
 
+func TestCheckArchive(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.NilError(t, tw.WriteHeader(&tar.Header{Name: "file", Mode: 0644, Size: 4}))
+	_, err := tw.Write([]byte("data"))
+	assert.NilError(t, err)
+	assert.NilError(t, tw.Close())
+	valid := buf.Bytes()
+
+	assert.Check(t, checkArchive(bytes.NewReader(valid)))
+	assert.Check(t, checkArchive(bytes.NewReader(valid[:514])) != nil)
+	assert.Check(t, checkArchive(bytes.NewReader([]byte("not a tar archive"))) != nil)
+}
+
 func TestServiceCreate(t *testing.T) {
 	t.Parallel()
 
@@ -241,7 +258,7 @@ func newTestService(t *testing.T, ds *volumedrivers.Store) (*VolumesService, fun
 
 	store, err := NewStore(dir, ds)
 	assert.NilError(t, err)
-	s := &VolumesService{vs: store, eventLogger: dummyEventLogger{}}
+	s := &VolumesService{vs: store, eventLogger: dummyEventLogger{}, idMapping: &idtools.IdentityMapping{}}
 	return s, func() {
 		assert.Check(t, s.Shutdown())
 		assert.Check(t, os.RemoveAll(dir))